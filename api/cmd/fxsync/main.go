@@ -0,0 +1,69 @@
+// Comando fxsync importa as cotações de referência do Banco Central Europeu para
+// a tabela fx_rates, junto com as cotações cruzadas derivadas via EUR.
+//
+//	go run ./cmd/fxsync                                  # arquivo diário (FX_SYNC_SOURCE)
+//	go run ./cmd/fxsync -source hist                     # histórico completo desde 1999
+//	go run ./cmd/fxsync -source ./eurofxref-hist.xml -currencies BRL,USD,GBP
+//
+// As migrações precisam já ter sido aplicadas pelo servidor.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/martinsdevv/fincore/internal/config"
+	"github.com/martinsdevv/fincore/internal/fx"
+	"github.com/martinsdevv/fincore/pkg/database"
+	"github.com/martinsdevv/fincore/pkg/external/fxrates"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Atalhos aceitos em -source
+var sources = map[string]string{
+	"daily": fxrates.ECBDailyURL,
+	"90d":   fxrates.ECB90DaysURL,
+	"hist":  fxrates.ECBHistURL,
+}
+
+func main() {
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr, TimeFormat: time.RFC3339})
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Não foi possível carregar as configurações")
+	}
+
+	source := flag.String("source", cfg.FXSyncSource, "URL ou arquivo XML do BCE, ou daily, 90d, hist")
+	currencies := flag.String("currencies", cfg.FXSyncCurrencies, "moedas das cotações cruzadas (ex.: BRL,USD); vazio = todas")
+	flag.Parse()
+
+	if url, ok := sources[*source]; ok {
+		*source = url
+	}
+
+	if err := database.ConnectDB(cfg); err != nil {
+		log.Fatal().Err(err).Msg("Não foi possível conectar ao banco")
+	}
+	defer database.CloseConnections()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// Só o histórico é usado; o provedor e as cotações travadas ficam de fora
+	svc := fx.NewService(fx.NewRepository(database.DB), nil, nil, fx.QuoteOptions{})
+
+	started := time.Now()
+	imported, err := svc.SyncECB(ctx, *source, strings.Split(*currencies, ","))
+	if err != nil {
+		log.Fatal().Err(err).Int("imported", imported).Str("source", *source).Msg("Falha ao importar cotações do BCE")
+	}
+	log.Info().Int("rates", imported).Dur("took", time.Since(started)).Str("source", *source).Msg("Cotações do BCE importadas")
+}
@@ -6,15 +6,28 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/martinsdevv/fincore/internal/accounts"
 	"github.com/martinsdevv/fincore/internal/auth"
+	"github.com/martinsdevv/fincore/internal/categories"
 	"github.com/martinsdevv/fincore/internal/config"
+	"github.com/martinsdevv/fincore/internal/duplicates"
+	"github.com/martinsdevv/fincore/internal/fx"
+	"github.com/martinsdevv/fincore/internal/imports"
+	"github.com/martinsdevv/fincore/internal/movements"
+	"github.com/martinsdevv/fincore/internal/payments"
+	"github.com/martinsdevv/fincore/internal/reconciliations"
+	"github.com/martinsdevv/fincore/internal/reports"
+	"github.com/martinsdevv/fincore/internal/schedules"
 	"github.com/martinsdevv/fincore/pkg/database"
+	"github.com/martinsdevv/fincore/pkg/events"
+	"github.com/martinsdevv/fincore/pkg/external/fxrates"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
@@ -75,14 +88,80 @@ func main() {
 		}
 	})
 
+	webAuthn, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.WebAuthnRPID,
+		RPDisplayName: cfg.WebAuthnRPName,
+		RPOrigins:     strings.Split(cfg.WebAuthnRPOrigins, ","),
+	})
+	if err != nil {
+		log.Fatal().Err(err).Msg("Configuração WebAuthn inválida")
+	}
+
 	authRepo := auth.NewRepository(database.DB)
-	authSvc := auth.NewService(authRepo, cfg.JWTSecret)
+	authSvc := auth.NewService(authRepo, cfg.JWTSecret, webAuthn, auth.NewRedisSessionStore(database.Redis))
 	authHandler := auth.NewHandler(authSvc, cfg.JWTSecret)
 
+	eventsPublisher := events.NewRedisPublisher(database.Redis, events.DefaultChannel)
+	transactor := database.NewTransactor(database.DB)
+
+	rateProvider, err := newRateProvider(cfg)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Não foi possível configurar o provedor de cotações")
+	}
+	fxRepo := fx.NewRepository(database.DB)
+	fxSvc := fx.NewService(
+		fxRepo,
+		fxrates.NewCachedProvider(rateProvider, database.Redis, cfg.FXCacheTTL),
+		fx.NewRedisQuoteStore(database.Redis),
+		fx.QuoteOptions{TTL: cfg.FXQuoteTTL, Spread: cfg.FXQuoteSpread},
+	)
+	fxHandler := fx.NewHandler(fxSvc)
+
 	accountsRepo := accounts.NewRepository(database.DB)
-	accountsSvc := accounts.NewService(accountsRepo)
+	accountsSvc := accounts.NewService(accountsRepo, eventsPublisher, cfg.CreditUtilizationThreshold, fx.NewAccountsRateProvider(fxSvc))
 	accountsHandler := accounts.NewHandler(accountsSvc)
 
+	categoriesSvc := categories.NewService(categories.NewRepository(database.DB))
+	categoriesHandler := categories.NewHandler(categoriesSvc)
+
+	movementsRepo := movements.NewRepository(database.DB)
+	movementsSvc := movements.NewService(movementsRepo, accountsSvc, fxSvc, categoriesSvc, transactor)
+	movementsHandler := movements.NewHandler(movementsSvc)
+
+	reportsSvc := reports.NewService(reports.NewRepository(database.DB), fxSvc, cfg.FXFunctionalCurrency)
+	reportsHandler := reports.NewHandler(reportsSvc)
+
+	schedulesSvc := schedules.NewService(schedules.NewRepository(database.DB), accountsSvc, categoriesSvc, movementsSvc, transactor)
+	schedulesHandler := schedules.NewHandler(schedulesSvc)
+
+	importsSvc := imports.NewService(imports.NewRepository(database.DB), accountsSvc, movementsSvc, transactor)
+	importsHandler := imports.NewHandler(importsSvc)
+
+	paymentsHandler := payments.NewHandler(payments.NewService(accountsSvc))
+
+	reconciliationsSvc := reconciliations.NewService(reconciliations.NewRepository(database.DB), accountsSvc, transactor)
+	reconciliationsHandler := reconciliations.NewHandler(reconciliationsSvc)
+
+	duplicatesSvc := duplicates.NewService(duplicates.NewRepository(database.DB), accountsSvc, movementsSvc)
+	duplicatesHandler := duplicates.NewHandler(duplicatesSvc)
+
+	// --- Jobs em segundo plano ---
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go accounts.RunBalanceSnapshots(jobsCtx, accountsSvc, cfg.BalanceSnapshotInterval)
+	if cfg.FXSyncInterval > 0 {
+		go fx.RunECBSync(jobsCtx, fxSvc, cfg.FXSyncSource, strings.Split(cfg.FXSyncCurrencies, ","), cfg.FXSyncInterval)
+	}
+	if cfg.FXRevaluationInterval > 0 {
+		go reports.RunFXRevaluation(jobsCtx, reportsSvc, cfg.FXRevaluationInterval)
+	}
+	if cfg.SchedulerInterval > 0 {
+		go schedules.RunScheduler(jobsCtx, schedulesSvc, schedules.NewRedisLocker(database.Redis), cfg.SchedulerInterval)
+	}
+	if cfg.HoldExpiryInterval > 0 {
+		go movements.RunHoldExpiry(jobsCtx, movementsSvc, cfg.HoldExpiryInterval)
+	}
+
 	// --- Rotas Públicas ---
 	authHandler.RegisterRoutes(r)
 
@@ -93,9 +172,19 @@ func main() {
 
 		// Rota de teste /me
 		r.Get("/auth/me", authHandler.GetMe)
+		authHandler.RegisterProtectedRoutes(r)
 
 		// Rotas do módulo accounts
 		accountsHandler.RegisterRoutes(r)
+		movementsHandler.RegisterRoutes(r)
+		categoriesHandler.RegisterRoutes(r)
+		fxHandler.RegisterRoutes(r)
+		reportsHandler.RegisterRoutes(r)
+		schedulesHandler.RegisterRoutes(r)
+		importsHandler.RegisterRoutes(r)
+		paymentsHandler.RegisterRoutes(r)
+		reconciliationsHandler.RegisterRoutes(r)
+		duplicatesHandler.RegisterRoutes(r)
 	})
 
 	serverAddr := fmt.Sprintf(":%s", cfg.APIPort)
@@ -114,6 +203,7 @@ func main() {
 	<-stop
 
 	log.Info().Msg("Servidor recebendo sinal de parada. Desligando...")
+	stopJobs()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -125,6 +215,18 @@ func main() {
 	log.Info().Msg("Servidor desligado com sucesso.")
 }
 
+// newRateProvider escolhe a fonte de cotações conforme FX_PROVIDER
+func newRateProvider(cfg *config.Config) (fxrates.RateProvider, error) {
+	switch cfg.FXProvider {
+	case "file":
+		return fxrates.NewFileProvider(cfg.FXRatesFile)
+	case "http":
+		return fxrates.NewHTTPProvider(cfg.FXAPIURL, cfg.FXAPIKey, nil), nil
+	default:
+		return nil, fmt.Errorf("FX_PROVIDER inválido: %q", cfg.FXProvider)
+	}
+}
+
 func runMigrations(cfg *config.Config) error {
 	dsn := fmt.Sprintf("pgx5://%s:%s@%s:%s/%s?sslmode=disable",
 		cfg.DBUser,
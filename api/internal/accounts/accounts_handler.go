@@ -4,10 +4,18 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/martinsdevv/fincore/internal/auth"
+	"github.com/martinsdevv/fincore/pkg/iban"
+	"github.com/martinsdevv/fincore/pkg/money"
+	"github.com/martinsdevv/fincore/pkg/pagination"
 	"github.com/rs/zerolog/log"
 )
 
@@ -17,9 +25,20 @@ type Handler struct {
 }
 
 func NewHandler(service Service) *Handler {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	if err := validate.RegisterValidation("account_type", func(fl validator.FieldLevel) bool {
+		return AccountType(fl.Field().String()).IsValid()
+	}); err != nil {
+		panic(err)
+	}
+	// Regras como gte=0 valem sobre o valor em unidades mínimas
+	validate.RegisterCustomTypeFunc(func(v reflect.Value) interface{} {
+		return v.Interface().(money.Money).Amount
+	}, money.Money{})
+
 	return &Handler{
 		service:  service,
-		validate: validator.New(validator.WithRequiredStructEnabled()),
+		validate: validate,
 	}
 }
 
@@ -41,6 +60,107 @@ func (h *Handler) getUserIDFromContext(r *http.Request) (string, bool) {
 	return userID, true
 }
 
+func etag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// etagMatches compara a ETag atual com a lista de um If-Match / If-None-Match.
+// Como a versão muda a cada escrita, comparações fracas (W/) também valem.
+func etagMatches(header string, current string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == current {
+			return true
+		}
+	}
+	return false
+}
+
+// requireIfMatch resolve o If-Match das escritas para a versão esperada da
+// conta. Uma ETag só dispensa leitura; "*" ou uma lista são comparados com a
+// versão atual, como em etagMatches, e a escrita segue com ela. Responde 428
+// se o header não veio e 412 se nenhuma ETag corresponder.
+func (h *Handler) requireIfMatch(w http.ResponseWriter, r *http.Request, userID string) (int64, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		h.writeJSON(w, http.StatusPreconditionRequired, map[string]string{"error": "missing If-Match header"})
+		return 0, false
+	}
+
+	value := strings.TrimPrefix(header, "W/")
+	if strings.Count(value, `"`) == 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		if version, err := strconv.ParseInt(strings.Trim(value, `"`), 10, 64); err == nil {
+			return version, true
+		}
+	}
+
+	accountResp, err := h.service.GetAccount(r.Context(), chi.URLParam(r, "accountID"), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve account")
+		return 0, false
+	}
+	if !etagMatches(header, etag(accountResp.Version)) {
+		h.writeJSON(w, http.StatusPreconditionFailed, map[string]string{"error": "account was modified, reload it and try again"})
+		return 0, false
+	}
+	return accountResp.Version, true
+}
+
+// writeServiceError traduz os erros do serviço para respostas HTTP
+func (h *Handler) writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrAccountNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "account not found"})
+	case errors.Is(err, ErrForbidden):
+		h.writeJSON(w, http.StatusForbidden, map[string]string{"error": "you do not have permission for this account"})
+	case errors.Is(err, ErrAccountArchived):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": "account is archived"})
+	case errors.Is(err, ErrVersionMismatch):
+		h.writeJSON(w, http.StatusPreconditionFailed, map[string]string{"error": "account was modified, reload it and try again"})
+	case errors.Is(err, ErrNegativeBalanceNotAllowed):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "account type does not allow a negative balance"})
+	case errors.Is(err, ErrCreditLimitNotAllowed):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "account type does not support a credit limit or overdraft"})
+	case errors.Is(err, ErrInsufficientFunds):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "insufficient funds: credit limit exceeded"})
+	case errors.Is(err, ErrAccountNotEmpty):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": "only accounts with zero balance and no history can be deleted"})
+	case errors.Is(err, ErrAccountHasChildren):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": "move or delete the child accounts first"})
+	case errors.Is(err, ErrInvalidParent):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "parent account not found or not editable"})
+	case errors.Is(err, ErrHierarchyCycle):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "account cannot be moved under itself or one of its descendants"})
+	case errors.Is(err, ErrRateUnavailable):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrUserNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "no user registered with this email"})
+	case errors.Is(err, ErrMemberNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "member not found"})
+	case errors.Is(err, ErrMemberExists):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": "user is already a member of this account"})
+	case errors.Is(err, ErrLastOwner):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": "account must keep at least one owner"})
+	case errors.Is(err, money.ErrCurrencyMismatch):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, iban.ErrInvalidIBAN):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "invalid IBAN"})
+	case errors.Is(err, ErrIBANExists):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	default:
+		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": fallback})
+	}
+}
+
+// writeDecodeError detalha valores monetários inválidos; o resto é corpo inválido
+func (h *Handler) writeDecodeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, money.ErrInvalidAmount) || errors.Is(err, money.ErrOverflow) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+}
+
 func (h *Handler) HandleCreateAccount(w http.ResponseWriter, r *http.Request) {
 	userID, ok := h.getUserIDFromContext(r)
 	if !ok {
@@ -50,7 +170,7 @@ func (h *Handler) HandleCreateAccount(w http.ResponseWriter, r *http.Request) {
 
 	var req CreateAccountRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		h.writeDecodeError(w, err)
 		return
 	}
 
@@ -61,10 +181,11 @@ func (h *Handler) HandleCreateAccount(w http.ResponseWriter, r *http.Request) {
 
 	accountResp, err := h.service.CreateAccount(r.Context(), req, userID)
 	if err != nil {
-		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create account"})
+		h.writeServiceError(w, err, "failed to create account")
 		return
 	}
 
+	w.Header().Set("ETag", etag(accountResp.Version))
 	h.writeJSON(w, http.StatusCreated, accountResp)
 }
 
@@ -95,9 +216,72 @@ func (h *Handler) HandleGetAccount(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tag := etag(accountResp.Version)
+	w.Header().Set("ETag", tag)
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	h.writeJSON(w, http.StatusOK, accountResp)
 }
 
+// parsePointInTime aceita um timestamp RFC 3339 ou uma data (2025-03-31). Uma data
+// vale pelo fim do dia em UTC, que é o que se espera de "saldo em 31/03".
+func parsePointInTime(raw string) (time.Time, error) {
+	if at, err := time.Parse(time.RFC3339Nano, raw); err == nil {
+		return at.UTC(), nil
+	}
+	day, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, errors.New("invalid point in time: use RFC 3339 or YYYY-MM-DD")
+	}
+	return day.Add(24*time.Hour - time.Microsecond), nil
+}
+
+// parseListFilter lê ?type=&currency=&archived=&q=&as_of=. archived aceita false (padrão),
+// true (só arquivadas) e all; include_archived=true continua valendo como all.
+func (h *Handler) parseListFilter(q url.Values) (ListAccountsFilter, error) {
+	filter := ListAccountsFilter{
+		Type:     AccountType(q.Get("type")),
+		Currency: strings.ToUpper(q.Get("currency")),
+		Archived: ArchivedExclude,
+		Search:   strings.TrimSpace(q.Get("q")),
+	}
+
+	if filter.Type != "" && !filter.Type.IsValid() {
+		return filter, errors.New("invalid account type filter")
+	}
+	if filter.Currency != "" {
+		if err := h.validate.Var(filter.Currency, "iso4217"); err != nil {
+			return filter, errors.New("invalid currency filter")
+		}
+	}
+
+	if raw := q.Get("as_of"); raw != "" {
+		asOf, err := parsePointInTime(raw)
+		if err != nil {
+			return filter, err
+		}
+		filter.AsOf = &asOf
+	}
+
+	switch q.Get("archived") {
+	case "", "false":
+		if q.Get("include_archived") == "true" {
+			filter.Archived = ArchivedInclude
+		}
+	case "true":
+		filter.Archived = ArchivedOnly
+	case "all":
+		filter.Archived = ArchivedInclude
+	default:
+		return filter, errors.New("invalid archived filter: use true, false or all")
+	}
+
+	return filter, nil
+}
+
 func (h *Handler) HandleListAccounts(w http.ResponseWriter, r *http.Request) {
 	userID, ok := h.getUserIDFromContext(r)
 	if !ok {
@@ -105,7 +289,19 @@ func (h *Handler) HandleListAccounts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	accounts, err := h.service.ListAccounts(r.Context(), userID)
+	filter, err := h.parseListFilter(r.URL.Query())
+	if err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	page, err := pagination.Parse(r.URL.Query(), AccountSortFields, DefaultAccountSort)
+	if err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	accounts, err := h.service.ListAccounts(r.Context(), userID, filter, page)
 	if err != nil {
 		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to retrieve accounts"})
 		return
@@ -113,3 +309,260 @@ func (h *Handler) HandleListAccounts(w http.ResponseWriter, r *http.Request) {
 
 	h.writeJSON(w, http.StatusOK, accounts)
 }
+
+func (h *Handler) HandleGetBalanceAt(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	at := time.Now().UTC()
+	if raw := r.URL.Query().Get("at"); raw != "" {
+		var err error
+		if at, err = parsePointInTime(raw); err != nil {
+			h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+	}
+
+	balance, err := h.service.GetBalanceAt(r.Context(), chi.URLParam(r, "accountID"), userID, at)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to compute balance")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, balance)
+}
+
+func (h *Handler) HandleUpdateAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	version, ok := h.requireIfMatch(w, r, userID)
+	if !ok {
+		return
+	}
+
+	var req UpdateAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeDecodeError(w, err)
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	accountResp, err := h.service.UpdateAccount(r.Context(), chi.URLParam(r, "accountID"), userID, version, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to update account")
+		return
+	}
+
+	w.Header().Set("ETag", etag(accountResp.Version))
+	h.writeJSON(w, http.StatusOK, accountResp)
+}
+
+func (h *Handler) HandleArchiveAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	version, ok := h.requireIfMatch(w, r, userID)
+	if !ok {
+		return
+	}
+
+	accountResp, err := h.service.ArchiveAccount(r.Context(), chi.URLParam(r, "accountID"), userID, version)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to archive account")
+		return
+	}
+
+	w.Header().Set("ETag", etag(accountResp.Version))
+	h.writeJSON(w, http.StatusOK, accountResp)
+}
+
+func (h *Handler) HandleDeleteAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	version, ok := h.requireIfMatch(w, r, userID)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteAccount(r.Context(), chi.URLParam(r, "accountID"), userID, version); err != nil {
+		h.writeServiceError(w, err, "failed to delete account")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) HandleMoveAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	version, ok := h.requireIfMatch(w, r, userID)
+	if !ok {
+		return
+	}
+
+	var req MoveAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	accountResp, err := h.service.MoveAccount(r.Context(), chi.URLParam(r, "accountID"), userID, version, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to move account")
+		return
+	}
+
+	w.Header().Set("ETag", etag(accountResp.Version))
+	h.writeJSON(w, http.StatusOK, accountResp)
+}
+
+func (h *Handler) HandleGetAccountTree(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	includeArchived := r.URL.Query().Get("include_archived") == "true"
+
+	tree, err := h.service.GetAccountTree(r.Context(), userID, includeArchived)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve account tree")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, tree)
+}
+
+func (h *Handler) HandleGetSummary(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	currency := strings.ToUpper(r.URL.Query().Get("currency"))
+	if err := h.validate.Var(currency, "required,iso4217"); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "currency must be an ISO 4217 code"})
+		return
+	}
+
+	summary, err := h.service.GetSummary(r.Context(), userID, currency)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to compute summary")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, summary)
+}
+
+func (h *Handler) HandleListMembers(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	members, err := h.service.ListMembers(r.Context(), chi.URLParam(r, "accountID"), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve account members")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, members)
+}
+
+func (h *Handler) HandleAddMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req AddMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	member, err := h.service.AddMember(r.Context(), chi.URLParam(r, "accountID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to add account member")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, member)
+}
+
+func (h *Handler) HandleUpdateMemberRole(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req UpdateMemberRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	member, err := h.service.UpdateMemberRole(r.Context(), chi.URLParam(r, "accountID"), userID, chi.URLParam(r, "memberID"), req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to update account member")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, member)
+}
+
+func (h *Handler) HandleRemoveMember(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	if err := h.service.RemoveMember(r.Context(), chi.URLParam(r, "accountID"), userID, chi.URLParam(r, "memberID")); err != nil {
+		h.writeServiceError(w, err, "failed to remove account member")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) HandleListAccountTypes(w http.ResponseWriter, r *http.Request) {
+	h.writeJSON(w, http.StatusOK, AccountTypes())
+}
@@ -0,0 +1,32 @@
+package accounts
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RunBalanceSnapshots grava, a cada interval, o snapshot de saldo da meia-noite
+// (UTC) do dia corrente. Roda até ctx ser cancelado. Como a gravação é
+// idempotente, várias réplicas podem rodar o job ao mesmo tempo.
+func RunBalanceSnapshots(ctx context.Context, svc Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		asOf := time.Now().UTC().Truncate(24 * time.Hour)
+		taken, err := svc.TakeBalanceSnapshots(ctx, asOf)
+		if err != nil {
+			log.Error().Err(err).Time("asOf", asOf).Msg("Failed to take balance snapshots")
+		} else if taken > 0 {
+			log.Info().Int("accounts", taken).Time("asOf", asOf).Msg("Balance snapshots taken")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -4,32 +4,345 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/pkg/money"
+	"github.com/martinsdevv/fincore/pkg/pagination"
 )
 
+type AccountType string
+
+const (
+	AccountTypeChecking   AccountType = "checking"
+	AccountTypeSavings    AccountType = "savings"
+	AccountTypeCash       AccountType = "cash"
+	AccountTypeCreditCard AccountType = "credit_card"
+	AccountTypeInvestment AccountType = "investment"
+	AccountTypeLoan       AccountType = "loan"
+	AccountTypeAsset      AccountType = "asset"
+	AccountTypeLiability  AccountType = "liability"
+)
+
+// Natureza contábil do tipo de conta
+type AccountNature string
+
+const (
+	NatureAsset     AccountNature = "asset"
+	NatureLiability AccountNature = "liability"
+)
+
+// AccountTypeInfo descreve as regras de um tipo de conta. Também é o que o
+// frontend recebe em GET /account-types.
+type AccountTypeInfo struct {
+	Type              AccountType   `json:"type"`
+	Label             string        `json:"label"`
+	Nature            AccountNature `json:"nature"`
+	AllowsNegative    bool          `json:"allows_negative"`
+	SupportsOverdraft bool          `json:"supports_overdraft"`
+}
+
+// accountTypes é a lista fechada de tipos, na ordem exibida ao usuário.
+// Deve ficar em sincronia com as constraints accounts_type_check e
+// accounts_balance_within_limit.
+var accountTypes = []AccountTypeInfo{
+	{Type: AccountTypeChecking, Label: "Conta corrente", Nature: NatureAsset, SupportsOverdraft: true},
+	{Type: AccountTypeSavings, Label: "Poupança", Nature: NatureAsset},
+	{Type: AccountTypeCash, Label: "Dinheiro", Nature: NatureAsset},
+	{Type: AccountTypeCreditCard, Label: "Cartão de crédito", Nature: NatureLiability, AllowsNegative: true},
+	{Type: AccountTypeInvestment, Label: "Investimentos", Nature: NatureAsset},
+	{Type: AccountTypeLoan, Label: "Empréstimo", Nature: NatureLiability, AllowsNegative: true},
+	{Type: AccountTypeAsset, Label: "Outro ativo", Nature: NatureAsset},
+	{Type: AccountTypeLiability, Label: "Outro passivo", Nature: NatureLiability, AllowsNegative: true},
+}
+
+// AccountTypes retorna os tipos de conta suportados
+func AccountTypes() []AccountTypeInfo {
+	types := make([]AccountTypeInfo, len(accountTypes))
+	copy(types, accountTypes)
+	return types
+}
+
+// Info retorna as regras do tipo. ok é false para tipos desconhecidos.
+func (t AccountType) Info() (info AccountTypeInfo, ok bool) {
+	for _, info := range accountTypes {
+		if info.Type == t {
+			return info, true
+		}
+	}
+	return AccountTypeInfo{}, false
+}
+
+func (t AccountType) IsValid() bool {
+	_, ok := t.Info()
+	return ok
+}
+
 type Account struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"user_id"`
-	Name      string    `json:"name"`
-	Type      string    `json:"type"`
-	Balance   int64     `json:"balance"`
-	Currency  string    `json:"currency"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID               uuid.UUID   `json:"id"`
+	UserID           uuid.UUID   `json:"user_id"`
+	ParentID         *uuid.UUID  `json:"parent_id"`
+	Name             string      `json:"name"`
+	Type             AccountType `json:"type"`
+	Balance          int64       `json:"balance"`
+	Held             int64       `json:"held"` // soma das retenções pendentes; não entra no saldo contábil
+	Currency         string      `json:"currency"`
+	CreditLimit      int64       `json:"credit_limit"`
+	OverdraftAllowed bool        `json:"overdraft_allowed"`
+	IBAN             *string     `json:"iban"` // normalizado, veja iban.Normalize
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	ArchivedAt       *time.Time  `json:"archived_at"`
+	Version          int64       `json:"version"`
+}
+
+// NegativeLimit é o quanto o saldo pode ficar abaixo de zero
+func (a *Account) NegativeLimit() int64 {
+	info, _ := a.Type.Info()
+	if info.AllowsNegative || (info.SupportsOverdraft && a.OverdraftAllowed) {
+		return a.CreditLimit
+	}
+	return 0
 }
 
+// AvailableBalance é o saldo que ainda pode ser gasto: o saldo contábil menos
+// as retenções pendentes, mais o limite
+func (a *Account) AvailableBalance() int64 {
+	return a.Balance - a.Held + a.NegativeLimit()
+}
+
+// Utilization é o percentual do limite em uso (0 quando não há limite)
+func (a *Account) Utilization() float64 {
+	limit := a.NegativeLimit()
+	if limit == 0 || a.Balance >= 0 {
+		return 0
+	}
+	return float64(-a.Balance) / float64(limit) * 100
+}
+
+// IsArchived indica se a conta foi arquivada. Contas arquivadas não aceitam novas movimentações.
+func (a *Account) IsArchived() bool {
+	return a.ArchivedAt != nil
+}
+
+// InitialBalance só pode ser negativo para tipos que aceitam saldo negativo,
+// e no máximo até o CreditLimit. Os valores podem vir sem moeda (unidades
+// mínimas) ou com a mesma moeda da conta.
 type CreateAccountRequest struct {
-	Name           string `json:"name" validate:"required"`
-	Type           string `json:"type" validate:"required"`
-	Currency       string `json:"currency" validate:"required,iso4217"`
-	InitialBalance int64  `json:"initial_balance" validate:"gte=0"`
+	Name             string      `json:"name" validate:"required"`
+	Type             AccountType `json:"type" validate:"required,account_type"`
+	Currency         string      `json:"currency" validate:"required,iso4217"`
+	InitialBalance   money.Money `json:"initial_balance"`
+	CreditLimit      money.Money `json:"credit_limit" validate:"gte=0"`
+	OverdraftAllowed bool        `json:"overdraft_allowed"`
+	ParentID         *uuid.UUID  `json:"parent_id"`
+	IBAN             *string     `json:"iban" validate:"omitempty,max=42"`
+}
+
+// MoveAccountRequest coloca a conta sob outra; parent_id null a torna raiz
+type MoveAccountRequest struct {
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+// UpdateAccountRequest só altera os campos enviados; iban vazio remove o IBAN
+type UpdateAccountRequest struct {
+	Name             *string      `json:"name" validate:"omitempty,min=1,max=255"`
+	Type             *AccountType `json:"type" validate:"omitempty,account_type"`
+	CreditLimit      *money.Money `json:"credit_limit" validate:"omitempty,gte=0"`
+	OverdraftAllowed *bool        `json:"overdraft_allowed"`
+	IBAN             *string      `json:"iban" validate:"omitempty,max=42"`
 }
 
 type AccountResponse struct {
-	ID        uuid.UUID `json:"id"`
-	UserID    uuid.UUID `json:"user_id"`
-	Name      string    `json:"name"`
-	Type      string    `json:"type"`
-	Balance   int64     `json:"balance"`
-	Currency  string    `json:"currency"`
-	CreatedAt time.Time `json:"created_at"`
+	ID               uuid.UUID   `json:"id"`
+	UserID           uuid.UUID   `json:"user_id"`
+	ParentID         *uuid.UUID  `json:"parent_id"`
+	Name             string      `json:"name"`
+	Type             AccountType `json:"type"`
+	Balance          money.Money `json:"balance"`
+	Held             money.Money `json:"held"`
+	AvailableBalance money.Money `json:"available_balance"`
+	Currency         string      `json:"currency"`
+	CreditLimit      money.Money `json:"credit_limit"`
+	OverdraftAllowed bool        `json:"overdraft_allowed"`
+	IBAN             *string     `json:"iban"`
+	CreatedAt        time.Time   `json:"created_at"`
+	UpdatedAt        time.Time   `json:"updated_at"`
+	ArchivedAt       *time.Time  `json:"archived_at"`
+	Version          int64       `json:"version"`
+
+	// Preenchidos só quando a listagem pede as_of
+	AsOf        *time.Time   `json:"as_of,omitempty"`
+	BalanceAsOf *money.Money `json:"balance_as_of,omitempty"`
+}
+
+// BalanceAtResponse é o saldo de uma conta num instante do passado
+type BalanceAtResponse struct {
+	AccountID uuid.UUID   `json:"account_id"`
+	Balance   money.Money `json:"balance"`
+	At        time.Time   `json:"at"`
+}
+
+// AccountRollup é o saldo de uma conta somado ao de todas as descendentes, por moeda
+type AccountRollup struct {
+	AccountID uuid.UUID
+	Currency  string
+	Balance   int64
+}
+
+// AccountTreeNode é uma conta no plano de contas, com o total da subárvore
+// em cada moeda
+type AccountTreeNode struct {
+	AccountResponse
+	Rollup   []money.Money     `json:"rollup"`
+	Children []AccountTreeNode `json:"children"`
+}
+
+// BalanceTotal é a soma dos saldos das contas de um tipo numa moeda
+type BalanceTotal struct {
+	Type     AccountType
+	Currency string
+	Balance  int64
+	Accounts int
+}
+
+// TypeSummary é o total de um tipo de conta já convertido
+type TypeSummary struct {
+	Type     AccountType   `json:"type"`
+	Label    string        `json:"label"`
+	Nature   AccountNature `json:"nature"`
+	Total    money.Money   `json:"total"`
+	Accounts int           `json:"accounts"`
+}
+
+// ConversionInfo registra a cotação usada para trazer uma moeda para a do resumo
+type ConversionInfo struct {
+	From     string    `json:"from"`
+	To       string    `json:"to"`
+	Rate     float64   `json:"rate"`
+	RateDate time.Time `json:"rate_date"`
+}
+
+// SummaryResponse consolida as contas ativas do usuário numa moeda. Liabilities
+// é o valor devido, positivo; NetWorth = Assets - Liabilities.
+type SummaryResponse struct {
+	Currency    string           `json:"currency"`
+	ByType      []TypeSummary    `json:"by_type"`
+	Assets      money.Money      `json:"assets"`
+	Liabilities money.Money      `json:"liabilities"`
+	NetWorth    money.Money      `json:"net_worth"`
+	Conversions []ConversionInfo `json:"conversions"`
+}
+
+// MemberRole é o papel de um usuário numa conta compartilhada
+type MemberRole string
+
+const (
+	RoleOwner  MemberRole = "owner"  // tudo, inclusive gerenciar membros, arquivar e excluir
+	RoleEditor MemberRole = "editor" // lê e altera os dados da conta
+	RoleViewer MemberRole = "viewer" // só leitura
+)
+
+func (r MemberRole) rank() int {
+	switch r {
+	case RoleOwner:
+		return 3
+	case RoleEditor:
+		return 2
+	case RoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (r MemberRole) IsValid() bool {
+	return r.rank() > 0
+}
+
+// Allows diz se o papel tem pelo menos as permissões de min
+func (r MemberRole) Allows(min MemberRole) bool {
+	return r.IsValid() && r.rank() >= min.rank()
+}
+
+// AccountMember é o vínculo de um usuário com uma conta
+type AccountMember struct {
+	AccountID uuid.UUID  `json:"account_id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Email     string     `json:"email"`
+	Role      MemberRole `json:"role"`
+	InvitedBy *uuid.UUID `json:"invited_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// AddMemberRequest convida um usuário já cadastrado, pelo e-mail
+type AddMemberRequest struct {
+	Email string     `json:"email" validate:"required,email"`
+	Role  MemberRole `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+type UpdateMemberRoleRequest struct {
+	Role MemberRole `json:"role" validate:"required,oneof=owner editor viewer"`
+}
+
+type MemberResponse struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	Email     string     `json:"email"`
+	Role      MemberRole `json:"role"`
+	InvitedBy *uuid.UUID `json:"invited_by"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ArchivedFilter controla como contas arquivadas entram na listagem
+type ArchivedFilter string
+
+const (
+	ArchivedExclude ArchivedFilter = "exclude"
+	ArchivedInclude ArchivedFilter = "include"
+	ArchivedOnly    ArchivedFilter = "only"
+)
+
+// ListAccountsFilter são os filtros opcionais da listagem de contas.
+// Campos vazios não filtram.
+type ListAccountsFilter struct {
+	Type     AccountType
+	Currency string
+	Archived ArchivedFilter
+	Search   string // trecho do nome, sem diferenciar maiúsculas
+
+	// AsOf pede também o saldo naquele instante e esconde contas criadas depois dele
+	AsOf *time.Time
+}
+
+// AccountSortFields são os campos aceitos em ?sort= na listagem de contas
+var AccountSortFields = []pagination.SortField{
+	{Name: "created_at", Column: "created_at", Kind: pagination.KindTime},
+	{Name: "updated_at", Column: "updated_at", Kind: pagination.KindTime},
+	{Name: "name", Column: "name", Kind: pagination.KindString},
+	{Name: "balance", Column: "balance", Kind: pagination.KindInt},
+}
+
+// DefaultAccountSort mantém a ordem original: contas mais novas primeiro
+const DefaultAccountSort = "-created_at"
+
+// sortKey devolve o valor do campo de ordenação e o ID, para montar o cursor
+func (a Account) sortKey(field string) (interface{}, string) {
+	switch field {
+	case "updated_at":
+		return a.UpdatedAt, a.ID.String()
+	case "name":
+		return a.Name, a.ID.String()
+	case "balance":
+		return a.Balance, a.ID.String()
+	default:
+		return a.CreatedAt, a.ID.String()
+	}
+}
+
+// CreditUtilizationEvent é o payload do EventCreditUtilizationCrossed
+type CreditUtilizationEvent struct {
+	AccountID   uuid.UUID `json:"account_id"`
+	Utilization float64   `json:"utilization"`
+	Threshold   float64   `json:"threshold"`
+	Balance     int64     `json:"balance"`
+	CreditLimit int64     `json:"credit_limit"`
+	Currency    string    `json:"currency"`
 }
@@ -0,0 +1,23 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrRateUnavailable = errors.New("exchange rate unavailable")
+
+// ExchangeRate diz quanto vale 1 unidade de From em To, na data Date
+type ExchangeRate struct {
+	From  string
+	To    string
+	Value float64
+	Date  time.Time
+}
+
+// RateProvider fornece as cotações usadas para consolidar saldos em moedas diferentes.
+// Deve retornar ErrRateUnavailable quando não houver cotação para o par.
+type RateProvider interface {
+	Rate(ctx context.Context, from string, to string, date time.Time) (*ExchangeRate, error)
+}
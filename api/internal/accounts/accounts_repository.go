@@ -3,18 +3,44 @@ package accounts
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/martinsdevv/fincore/pkg/database"
+	"github.com/martinsdevv/fincore/pkg/pagination"
 )
 
 type Repository interface {
 	CreateAccount(ctx context.Context, account *Account) error
 	GetAccountByID(ctx context.Context, id uuid.UUID) (*Account, error)
-	ListAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]Account, error)
-	// UpdateAccountBalance (vamos adicionar isso depois com as transações)
-	// DeleteAccount (podemos adicionar depois)
+	ListAccountsByUserID(ctx context.Context, userID uuid.UUID, filter ListAccountsFilter, page pagination.Params) ([]Account, error)
+	UpdateAccount(ctx context.Context, account *Account) (bool, error)
+	DeleteAccount(ctx context.Context, id uuid.UUID, version int64) (bool, error)
+	AdjustBalance(ctx context.Context, id uuid.UUID, delta int64, occurredAt time.Time) (*Account, error)
+	AdjustHeld(ctx context.Context, id uuid.UUID, delta int64) (*Account, error)
+	HasMovements(ctx context.Context, id uuid.UUID) (bool, error)
+	BalancesBefore(ctx context.Context, ids []uuid.UUID, before time.Time) (map[uuid.UUID]int64, error)
+	ListAccountsWithoutSnapshot(ctx context.Context, asOf time.Time) ([]uuid.UUID, error)
+	CreateBalanceSnapshot(ctx context.Context, accountID uuid.UUID, asOf time.Time) error
+
+	MoveAccount(ctx context.Context, account *Account) (bool, error)
+	HasChildren(ctx context.Context, id uuid.UUID) (bool, error)
+	GetAccountTree(ctx context.Context, userID uuid.UUID, includeArchived bool) ([]Account, []AccountRollup, error)
+	SumBalances(ctx context.Context, userID uuid.UUID) ([]BalanceTotal, error)
+	// ListAccountsByIBAN devolve as contas com o IBAN de que o usuário é membro
+	ListAccountsByIBAN(ctx context.Context, userID uuid.UUID, iban string) ([]Account, error)
+
+	GetMember(ctx context.Context, accountID uuid.UUID, userID uuid.UUID) (*AccountMember, error)
+	ListMembers(ctx context.Context, accountID uuid.UUID) ([]AccountMember, error)
+	AddMember(ctx context.Context, member *AccountMember) error
+	UpdateMemberRole(ctx context.Context, accountID uuid.UUID, userID uuid.UUID, role MemberRole) (bool, error)
+	RemoveMember(ctx context.Context, accountID uuid.UUID, userID uuid.UUID) (bool, error)
+	FindUserIDByEmail(ctx context.Context, email string) (*uuid.UUID, error)
 }
 
 type pgxRepository struct {
@@ -25,41 +51,93 @@ func NewRepository(db *pgxpool.Pool) Repository {
 	return &pgxRepository{db: db}
 }
 
+// conn usa a transação aberta por um database.Transactor, se houver
+func (r *pgxRepository) conn(ctx context.Context) database.DBTX {
+	return database.Conn(ctx, r.db)
+}
+
+// Colunas lidas por scanAccount, sempre nesta ordem
+const accountColumns = `id, user_id, parent_id, name, type, balance, held, currency, credit_limit, overdraft_allowed,
+	iban, created_at, updated_at, archived_at, version`
+
+func scanAccount(row pgx.Row, acc *Account) error {
+	return row.Scan(
+		&acc.ID,
+		&acc.UserID,
+		&acc.ParentID,
+		&acc.Name,
+		&acc.Type,
+		&acc.Balance,
+		&acc.Held,
+		&acc.Currency,
+		&acc.CreditLimit,
+		&acc.OverdraftAllowed,
+		&acc.IBAN,
+		&acc.CreatedAt,
+		&acc.UpdatedAt,
+		&acc.ArchivedAt,
+		&acc.Version,
+	)
+}
+
+// CreateAccount grava a conta, o criador como owner e o lançamento de abertura
+// com o saldo inicial, na mesma transação
 func (r *pgxRepository) CreateAccount(ctx context.Context, acc *Account) error {
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
 	query := `
-		INSERT INTO accounts (id, user_id, name, type, balance, currency, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+		INSERT INTO accounts (id, user_id, parent_id, name, type, balance, currency, credit_limit, overdraft_allowed, iban, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`
 
-	_, err := r.db.Exec(ctx, query,
+	_, err = tx.Exec(ctx, query,
 		acc.ID,
 		acc.UserID,
+		acc.ParentID,
 		acc.Name,
 		acc.Type,
 		acc.Balance,
 		acc.Currency,
+		acc.CreditLimit,
+		acc.OverdraftAllowed,
+		acc.IBAN,
 		acc.CreatedAt,
 		acc.UpdatedAt,
 	)
-	return err
+	if err != nil {
+		return mapConstraintViolation(err)
+	}
+
+	memberQuery := `
+		INSERT INTO account_members (account_id, user_id, role, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)`
+
+	if _, err := tx.Exec(ctx, memberQuery, acc.ID, acc.UserID, RoleOwner, acc.CreatedAt); err != nil {
+		return err
+	}
+
+	openingQuery := `
+		INSERT INTO movements (account_id, kind, amount, description, occurred_at, created_by, created_at)
+		VALUES ($1, 'opening', $2, 'Saldo inicial', $3, $4, $3)`
+
+	if _, err := tx.Exec(ctx, openingQuery, acc.ID, acc.Balance, acc.CreatedAt, acc.UserID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
 func (r *pgxRepository) GetAccountByID(ctx context.Context, id uuid.UUID) (*Account, error) {
 	query := `
-		SELECT id, user_id, name, type, balance, currency, created_at, updated_at
+		SELECT ` + accountColumns + `
 		FROM accounts
 		WHERE id = $1`
 
 	var acc Account
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&acc.ID,
-		&acc.UserID,
-		&acc.Name,
-		&acc.Type,
-		&acc.Balance,
-		&acc.Currency,
-		&acc.CreatedAt,
-		&acc.UpdatedAt,
-	)
+	err := scanAccount(r.conn(ctx).QueryRow(ctx, query, id), &acc)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -70,14 +148,50 @@ func (r *pgxRepository) GetAccountByID(ctx context.Context, id uuid.UUID) (*Acco
 	return &acc, nil
 }
 
-func (r *pgxRepository) ListAccountsByUserID(ctx context.Context, userID uuid.UUID) ([]Account, error) {
+// ListAccountsByUserID devolve até page.FetchLimit() contas do usuário que passam
+// pelo filtro, na ordem pedida e a partir do cursor
+func (r *pgxRepository) ListAccountsByUserID(ctx context.Context, userID uuid.UUID, filter ListAccountsFilter, page pagination.Params) ([]Account, error) {
+	// A conta aparece para todos os membros, não só para quem a criou
+	conditions := []string{"id IN (SELECT account_id FROM account_members WHERE user_id = $1)"}
+	args := []interface{}{userID}
+
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	switch filter.Archived {
+	case ArchivedInclude:
+	case ArchivedOnly:
+		conditions = append(conditions, "archived_at IS NOT NULL")
+	default:
+		conditions = append(conditions, "archived_at IS NULL")
+	}
+	if filter.Type != "" {
+		conditions = append(conditions, "type = "+addArg(filter.Type))
+	}
+	if filter.Currency != "" {
+		conditions = append(conditions, "currency = "+addArg(filter.Currency))
+	}
+	if filter.Search != "" {
+		conditions = append(conditions, "name ILIKE "+addArg("%"+escapeLike(filter.Search)+"%"))
+	}
+	if filter.AsOf != nil {
+		conditions = append(conditions, "created_at <= "+addArg(*filter.AsOf))
+	}
+
+	keyset, keysetArgs := page.Where("id", len(args)+1)
+	conditions = append(conditions, keyset)
+	args = append(args, keysetArgs...)
+
 	query := `
-		SELECT id, user_id, name, type, balance, currency, created_at, updated_at
+		SELECT ` + accountColumns + `
 		FROM accounts
-		WHERE user_id = $1
-		ORDER BY created_at DESC`
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + page.OrderBy("id") + `
+		LIMIT ` + addArg(page.FetchLimit())
 
-	rows, err := r.db.Query(ctx, query, userID)
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -86,21 +200,549 @@ func (r *pgxRepository) ListAccountsByUserID(ctx context.Context, userID uuid.UU
 	var accounts []Account
 	for rows.Next() {
 		var acc Account
-		err := rows.Scan(
-			&acc.ID,
-			&acc.UserID,
-			&acc.Name,
-			&acc.Type,
-			&acc.Balance,
-			&acc.Currency,
-			&acc.CreatedAt,
-			&acc.UpdatedAt,
+		if err := scanAccount(rows, &acc); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, acc)
+	}
+
+	return accounts, rows.Err()
+}
+
+// escapeLike escapa os curingas do LIKE para que a busca seja literal
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// UpdateAccount grava a conta se a versão no banco ainda for acc.Version e
+// incrementa a versão. Retorna false quando outra escrita chegou antes.
+func (r *pgxRepository) UpdateAccount(ctx context.Context, acc *Account) (bool, error) {
+	query := `
+		UPDATE accounts
+		SET name = $3, type = $4, credit_limit = $5, overdraft_allowed = $6, archived_at = $7,
+		    iban = $8, updated_at = $9, version = version + 1
+		WHERE id = $1 AND version = $2
+		RETURNING version`
+
+	err := r.conn(ctx).QueryRow(ctx, query,
+		acc.ID,
+		acc.Version,
+		acc.Name,
+		acc.Type,
+		acc.CreditLimit,
+		acc.OverdraftAllowed,
+		acc.ArchivedAt,
+		acc.IBAN,
+		acc.UpdatedAt,
+	).Scan(&acc.Version)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, mapConstraintViolation(err)
+	}
+	return true, nil
+}
+
+// DeleteAccount só remove contas com saldo zerado e na versão informada.
+// Retorna false se nada foi removido.
+func (r *pgxRepository) DeleteAccount(ctx context.Context, id uuid.UUID, version int64) (bool, error) {
+	query := `DELETE FROM accounts WHERE id = $1 AND version = $2 AND balance = 0`
+
+	tag, err := r.conn(ctx).Exec(ctx, query, id, version)
+	if err != nil {
+		// Uma subconta foi criada entre a checagem do serviço e o delete
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return false, ErrAccountHasChildren
+		}
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// AdjustBalance soma delta ao saldo de uma conta ativa e retorna a conta atualizada.
+// A constraint accounts_balance_within_limit garante o limite mesmo com escritas concorrentes.
+// Snapshots posteriores a occurredAt também recebem o delta, para que lançamentos
+// retroativos não deixem o histórico desatualizado.
+func (r *pgxRepository) AdjustBalance(ctx context.Context, id uuid.UUID, delta int64, occurredAt time.Time) (*Account, error) {
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE accounts
+		SET balance = balance + $2, updated_at = NOW(), version = version + 1
+		WHERE id = $1 AND archived_at IS NULL
+		RETURNING ` + accountColumns
+
+	var acc Account
+	err = scanAccount(tx.QueryRow(ctx, query, id, delta), &acc)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, mapConstraintViolation(err)
+	}
+
+	snapshotQuery := `
+		UPDATE balance_snapshots
+		SET balance = balance + $2
+		WHERE account_id = $1 AND as_of > $3`
+
+	if _, err := tx.Exec(ctx, snapshotQuery, id, delta, occurredAt); err != nil {
+		return nil, err
+	}
+
+	return &acc, tx.Commit(ctx)
+}
+
+// AdjustHeld soma delta às retenções da conta. Reservas (delta > 0) só valem
+// para contas ativas; retorna (nil, nil) se a conta foi arquivada.
+func (r *pgxRepository) AdjustHeld(ctx context.Context, id uuid.UUID, delta int64) (*Account, error) {
+	query := `
+		UPDATE accounts
+		SET held = held + $2, updated_at = NOW(), version = version + 1
+		WHERE id = $1 AND (archived_at IS NULL OR $2 < 0)
+		RETURNING ` + accountColumns
+
+	var acc Account
+	if err := scanAccount(r.conn(ctx).QueryRow(ctx, query, id, delta), &acc); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, mapConstraintViolation(err)
+	}
+	return &acc, nil
+}
+
+// HasMovements diz se a conta tem lançamentos além da abertura
+func (r *pgxRepository) HasMovements(ctx context.Context, id uuid.UUID) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM movements WHERE account_id = $1 AND kind <> 'opening')`
+	err := r.conn(ctx).QueryRow(ctx, query, id).Scan(&exists)
+	return exists, err
+}
+
+// balanceBeforeExpr é o saldo de a.id contando os movimentos com occurred_at < $2:
+// parte do snapshot mais recente até $2 e soma só o que veio depois dele.
+// Depende de um LEFT JOIN LATERAL com alias s sobre balance_snapshots.
+const balanceBeforeExpr = `(COALESCE(s.balance, 0) + COALESCE((
+		SELECT SUM(m.amount) FROM movements m
+		WHERE m.account_id = a.id AND m.status = 'posted' AND m.occurred_at < $2
+		  AND (s.as_of IS NULL OR m.occurred_at >= s.as_of)
+	), 0))::BIGINT`
+
+const latestSnapshotJoin = `LEFT JOIN LATERAL (
+		SELECT as_of, balance FROM balance_snapshots
+		WHERE account_id = a.id AND as_of <= $2
+		ORDER BY as_of DESC
+		LIMIT 1
+	) s ON TRUE`
+
+// BalancesBefore devolve o saldo de cada conta considerando só os movimentos
+// anteriores a before
+func (r *pgxRepository) BalancesBefore(ctx context.Context, ids []uuid.UUID, before time.Time) (map[uuid.UUID]int64, error) {
+	query := `
+		SELECT a.id, ` + balanceBeforeExpr + `
+		FROM accounts a
+		` + latestSnapshotJoin + `
+		WHERE a.id = ANY($1)`
+
+	rows, err := r.conn(ctx).Query(ctx, query, ids, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := make(map[uuid.UUID]int64, len(ids))
+	for rows.Next() {
+		var id uuid.UUID
+		var balance int64
+		if err := rows.Scan(&id, &balance); err != nil {
+			return nil, err
+		}
+		balances[id] = balance
+	}
+
+	return balances, rows.Err()
+}
+
+// ListAccountsWithoutSnapshot devolve as contas criadas antes de asOf que ainda
+// não têm snapshot nessa data
+func (r *pgxRepository) ListAccountsWithoutSnapshot(ctx context.Context, asOf time.Time) ([]uuid.UUID, error) {
+	query := `
+		SELECT a.id FROM accounts a
+		WHERE a.created_at < $1
+		  AND NOT EXISTS (SELECT 1 FROM balance_snapshots s WHERE s.account_id = a.id AND s.as_of = $1)`
+
+	rows, err := r.conn(ctx).Query(ctx, query, asOf)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// CreateBalanceSnapshot grava o saldo da conta antes de asOf. A conta fica
+// travada para leitura antes do cálculo: um AdjustBalance em andamento termina
+// primeiro e entra na soma, e os próximos já encontram o snapshot para corrigir.
+func (r *pgxRepository) CreateBalanceSnapshot(ctx context.Context, accountID uuid.UUID, asOf time.Time) error {
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM accounts WHERE id = $1 FOR SHARE`, accountID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO balance_snapshots (account_id, as_of, balance)
+		SELECT a.id, $2, ` + balanceBeforeExpr + `
+		FROM accounts a
+		` + latestSnapshotJoin + `
+		WHERE a.id = $1
+		ON CONFLICT (account_id, as_of) DO NOTHING`
+
+	if _, err := tx.Exec(ctx, query, accountID, asOf); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// MoveAccount troca o parent_id da conta se ela ainda estiver em acc.Version.
+// Retorna ErrHierarchyCycle se o novo pai for a própria conta ou uma descendente.
+func (r *pgxRepository) MoveAccount(ctx context.Context, acc *Account) (bool, error) {
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Serializa as mudanças de hierarquia: dois moves simultâneos (A sob B e B sob A)
+	// passariam cada um na checagem de ciclo
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('accounts:hierarchy'))`); err != nil {
+		return false, err
+	}
+
+	if acc.ParentID != nil {
+		cycleQuery := `
+			WITH RECURSIVE ancestors AS (
+				SELECT id, parent_id FROM accounts WHERE id = $1
+				UNION
+				SELECT a.id, a.parent_id FROM accounts a JOIN ancestors an ON a.id = an.parent_id
+			)
+			SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)`
+
+		var cycle bool
+		if err := tx.QueryRow(ctx, cycleQuery, *acc.ParentID, acc.ID).Scan(&cycle); err != nil {
+			return false, err
+		}
+		if cycle {
+			return false, ErrHierarchyCycle
+		}
+	}
+
+	query := `
+		UPDATE accounts
+		SET parent_id = $3, updated_at = $4, version = version + 1
+		WHERE id = $1 AND version = $2
+		RETURNING version`
+
+	err = tx.QueryRow(ctx, query, acc.ID, acc.Version, acc.ParentID, acc.UpdatedAt).Scan(&acc.Version)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+func (r *pgxRepository) HasChildren(ctx context.Context, id uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.conn(ctx).QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM accounts WHERE parent_id = $1)`, id).Scan(&exists)
+	return exists, err
+}
+
+// GetAccountTree devolve as contas que o usuário pode ver e, para cada uma, a soma
+// dos saldos da subárvore por moeda. Contas que o usuário não vê ficam fora das somas.
+func (r *pgxRepository) GetAccountTree(ctx context.Context, userID uuid.UUID, includeArchived bool) ([]Account, []AccountRollup, error) {
+	query := `
+		SELECT ` + accountColumns + `
+		FROM accounts
+		WHERE id IN (SELECT account_id FROM account_members WHERE user_id = $1)
+		  AND ($2 OR archived_at IS NULL)
+		ORDER BY name, id`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID, includeArchived)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var accounts []Account
+	for rows.Next() {
+		var acc Account
+		if err := scanAccount(rows, &acc); err != nil {
+			return nil, nil, err
+		}
+		accounts = append(accounts, acc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	// closure liga cada conta a ela mesma e a todas as descendentes visíveis
+	rollupQuery := `
+		WITH RECURSIVE visible AS (
+			SELECT id, parent_id, balance, currency
+			FROM accounts
+			WHERE id IN (SELECT account_id FROM account_members WHERE user_id = $1)
+			  AND ($2 OR archived_at IS NULL)
+		),
+		closure AS (
+			SELECT id AS ancestor_id, id AS descendant_id FROM visible
+			UNION
+			SELECT c.ancestor_id, v.id
+			FROM closure c
+			JOIN visible v ON v.parent_id = c.descendant_id
 		)
-		if err != nil {
+		SELECT c.ancestor_id, d.currency, SUM(d.balance)::BIGINT
+		FROM closure c
+		JOIN visible d ON d.id = c.descendant_id
+		GROUP BY c.ancestor_id, d.currency
+		ORDER BY c.ancestor_id, d.currency`
+
+	rows, err = r.conn(ctx).Query(ctx, rollupQuery, userID, includeArchived)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	var rollups []AccountRollup
+	for rows.Next() {
+		var ru AccountRollup
+		if err := rows.Scan(&ru.AccountID, &ru.Currency, &ru.Balance); err != nil {
+			return nil, nil, err
+		}
+		rollups = append(rollups, ru)
+	}
+
+	return accounts, rollups, rows.Err()
+}
+
+// SumBalances soma os saldos das contas ativas do usuário por tipo e moeda
+func (r *pgxRepository) SumBalances(ctx context.Context, userID uuid.UUID) ([]BalanceTotal, error) {
+	query := `
+		SELECT type, currency, SUM(balance)::BIGINT, COUNT(*)
+		FROM accounts
+		WHERE id IN (SELECT account_id FROM account_members WHERE user_id = $1)
+		  AND archived_at IS NULL
+		GROUP BY type, currency
+		ORDER BY type, currency`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []BalanceTotal
+	for rows.Next() {
+		var t BalanceTotal
+		if err := rows.Scan(&t.Type, &t.Currency, &t.Balance, &t.Accounts); err != nil {
+			return nil, err
+		}
+		totals = append(totals, t)
+	}
+
+	return totals, rows.Err()
+}
+
+func (r *pgxRepository) ListAccountsByIBAN(ctx context.Context, userID uuid.UUID, iban string) ([]Account, error) {
+	query := `
+		SELECT ` + accountColumns + `
+		FROM accounts
+		WHERE iban = $2
+		  AND id IN (SELECT account_id FROM account_members WHERE user_id = $1)
+		ORDER BY archived_at NULLS FIRST, id`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID, iban)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []Account
+	for rows.Next() {
+		var acc Account
+		if err := scanAccount(rows, &acc); err != nil {
 			return nil, err
 		}
 		accounts = append(accounts, acc)
 	}
+	return accounts, rows.Err()
+}
+
+func (r *pgxRepository) GetMember(ctx context.Context, accountID uuid.UUID, userID uuid.UUID) (*AccountMember, error) {
+	query := `
+		SELECT m.account_id, m.user_id, u.email, m.role, m.invited_by, m.created_at, m.updated_at
+		FROM account_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.account_id = $1 AND m.user_id = $2`
+
+	var m AccountMember
+	err := r.conn(ctx).QueryRow(ctx, query, accountID, userID).Scan(
+		&m.AccountID,
+		&m.UserID,
+		&m.Email,
+		&m.Role,
+		&m.InvitedBy,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *pgxRepository) ListMembers(ctx context.Context, accountID uuid.UUID) ([]AccountMember, error) {
+	query := `
+		SELECT m.account_id, m.user_id, u.email, m.role, m.invited_by, m.created_at, m.updated_at
+		FROM account_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.account_id = $1
+		ORDER BY m.created_at, m.user_id`
+
+	rows, err := r.conn(ctx).Query(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []AccountMember
+	for rows.Next() {
+		var m AccountMember
+		if err := rows.Scan(&m.AccountID, &m.UserID, &m.Email, &m.Role, &m.InvitedBy, &m.CreatedAt, &m.UpdatedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+// AddMember retorna ErrMemberExists se o usuário já faz parte da conta
+func (r *pgxRepository) AddMember(ctx context.Context, m *AccountMember) error {
+	query := `
+		INSERT INTO account_members (account_id, user_id, role, invited_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
 
-	return accounts, nil
+	_, err := r.conn(ctx).Exec(ctx, query, m.AccountID, m.UserID, m.Role, m.InvitedBy, m.CreatedAt, m.UpdatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrMemberExists
+	}
+	return err
+}
+
+// UpdateMemberRole e RemoveMember nunca deixam a conta sem owner: a operação
+// só acontece se sobrar outro owner. Retornam false quando nada mudou.
+func (r *pgxRepository) UpdateMemberRole(ctx context.Context, accountID uuid.UUID, userID uuid.UUID, role MemberRole) (bool, error) {
+	query := `
+		UPDATE account_members
+		SET role = $3, updated_at = NOW()
+		WHERE account_id = $1 AND user_id = $2
+		  AND ($3 = 'owner' OR role <> 'owner' OR EXISTS (
+		      SELECT 1 FROM account_members o
+		      WHERE o.account_id = $1 AND o.user_id <> $2 AND o.role = 'owner'))`
+
+	return r.execWithAccountLock(ctx, accountID, query, accountID, userID, role)
+}
+
+func (r *pgxRepository) RemoveMember(ctx context.Context, accountID uuid.UUID, userID uuid.UUID) (bool, error) {
+	query := `
+		DELETE FROM account_members
+		WHERE account_id = $1 AND user_id = $2
+		  AND (role <> 'owner' OR EXISTS (
+		      SELECT 1 FROM account_members o
+		      WHERE o.account_id = $1 AND o.user_id <> $2 AND o.role = 'owner'))`
+
+	return r.execWithAccountLock(ctx, accountID, query, accountID, userID)
+}
+
+// execWithAccountLock executa query com a linha da conta travada, para que duas
+// alterações de membros simultâneas não removam cada uma o "outro" owner
+func (r *pgxRepository) execWithAccountLock(ctx context.Context, accountID uuid.UUID, query string, args ...interface{}) (bool, error) {
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT 1 FROM accounts WHERE id = $1 FOR UPDATE`, accountID); err != nil {
+		return false, err
+	}
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *pgxRepository) FindUserIDByEmail(ctx context.Context, email string) (*uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.conn(ctx).QueryRow(ctx, `SELECT id FROM users WHERE email = $1`, email).Scan(&id)
+
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &id, nil
+}
+
+// mapConstraintViolation converte a violação da constraint de limite em
+// ErrInsufficientFunds e a do IBAN repetido em ErrIBANExists
+func mapConstraintViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.ConstraintName {
+		case "accounts_balance_within_limit":
+			return ErrInsufficientFunds
+		case "idx_accounts_user_iban":
+			return ErrIBANExists
+		}
+	}
+	return err
 }
@@ -5,7 +5,17 @@ import "github.com/go-chi/chi/v5"
 func (h *Handler) RegisterRoutes(r chi.Router) {
 	r.Post("/accounts", h.HandleCreateAccount)
 	r.Get("/accounts", h.HandleListAccounts)
+	r.Get("/accounts/tree", h.HandleGetAccountTree)
+	r.Get("/accounts/summary", h.HandleGetSummary)
 	r.Get("/accounts/{accountID}", h.HandleGetAccount)
-	// r.Put("/accounts/{accountID}", h.HandleUpdateAccount)
-	// r.Delete("/accounts/{accountID}", h.HandleDeleteAccount)
+	r.Patch("/accounts/{accountID}", h.HandleUpdateAccount)
+	r.Delete("/accounts/{accountID}", h.HandleDeleteAccount)
+	r.Post("/accounts/{accountID}/archive", h.HandleArchiveAccount)
+	r.Post("/accounts/{accountID}/move", h.HandleMoveAccount)
+	r.Get("/accounts/{accountID}/balance", h.HandleGetBalanceAt)
+	r.Get("/accounts/{accountID}/members", h.HandleListMembers)
+	r.Post("/accounts/{accountID}/members", h.HandleAddMember)
+	r.Patch("/accounts/{accountID}/members/{memberID}", h.HandleUpdateMemberRole)
+	r.Delete("/accounts/{accountID}/members/{memberID}", h.HandleRemoveMember)
+	r.Get("/account-types", h.HandleListAccountTypes)
 }
@@ -3,29 +3,101 @@ package accounts
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/pkg/database"
+	"github.com/martinsdevv/fincore/pkg/events"
+	"github.com/martinsdevv/fincore/pkg/iban"
+	"github.com/martinsdevv/fincore/pkg/money"
+	"github.com/martinsdevv/fincore/pkg/pagination"
 	"github.com/rs/zerolog/log"
 )
 
 var (
 	ErrAccountNotFound = errors.New("account not found")
 	ErrForbidden       = errors.New("user does not have permission for this account")
+	ErrAccountArchived = errors.New("account is archived")
+	ErrAccountNotEmpty = errors.New("account has a non-zero balance or history")
+	ErrVersionMismatch = errors.New("account was modified by another request")
+
+	ErrNegativeBalanceNotAllowed = errors.New("account type does not allow a negative balance")
+	ErrCreditLimitNotAllowed     = errors.New("account type does not support a credit limit or overdraft")
+	ErrInsufficientFunds         = errors.New("insufficient funds: credit limit exceeded")
+
+	ErrUserNotFound   = errors.New("user not found")
+	ErrMemberNotFound = errors.New("member not found")
+	ErrMemberExists   = errors.New("user is already a member of this account")
+	ErrLastOwner      = errors.New("account must keep at least one owner")
+
+	ErrInvalidParent      = errors.New("parent account not found or not editable")
+	ErrHierarchyCycle     = errors.New("account cannot be moved under itself or one of its descendants")
+	ErrAccountHasChildren = errors.New("account has child accounts")
+
+	ErrIBANExists    = errors.New("another active account of this owner already has this IBAN")
+	ErrAmbiguousIBAN = errors.New("more than one active account has this IBAN")
 )
 
+// Evento publicado quando o uso do limite de crédito passa do threshold configurado
+const EventCreditUtilizationCrossed = "account.credit_utilization_threshold_crossed"
+
 type Service interface {
 	CreateAccount(ctx context.Context, req CreateAccountRequest, userID string) (*AccountResponse, error)
 	GetAccount(ctx context.Context, accountID string, userID string) (*AccountResponse, error)
-	ListAccounts(ctx context.Context, userID string) ([]AccountResponse, error)
+	ListAccounts(ctx context.Context, userID string, filter ListAccountsFilter, page pagination.Params) (*pagination.Page[AccountResponse], error)
+	UpdateAccount(ctx context.Context, accountID string, userID string, version int64, req UpdateAccountRequest) (*AccountResponse, error)
+	ArchiveAccount(ctx context.Context, accountID string, userID string, version int64) (*AccountResponse, error)
+	DeleteAccount(ctx context.Context, accountID string, userID string, version int64) error
+	MoveAccount(ctx context.Context, accountID string, userID string, version int64, req MoveAccountRequest) (*AccountResponse, error)
+	GetAccountTree(ctx context.Context, userID string, includeArchived bool) ([]AccountTreeNode, error)
+	GetSummary(ctx context.Context, userID string, currency string) (*SummaryResponse, error)
+
+	ListMembers(ctx context.Context, accountID string, userID string) ([]MemberResponse, error)
+	AddMember(ctx context.Context, accountID string, userID string, req AddMemberRequest) (*MemberResponse, error)
+	UpdateMemberRole(ctx context.Context, accountID string, userID string, memberID string, req UpdateMemberRoleRequest) (*MemberResponse, error)
+	RemoveMember(ctx context.Context, accountID string, userID string, memberID string) error
+
+	// AuthorizeAccount é o controle de acesso dos módulos que operam sobre contas:
+	// devolve a conta se o usuário for membro com pelo menos o papel pedido.
+	AuthorizeAccount(ctx context.Context, accountID string, userID string, role MemberRole) (*Account, error)
+	// FindAccountByIBAN é o AuthorizeAccount de quem só conhece o IBAN, como um
+	// extrato CAMT.053. Uma conta ativa tem preferência sobre as arquivadas.
+	FindAccountByIBAN(ctx context.Context, iban string, userID string, role MemberRole) (*Account, error)
+
+	// GetBalanceAt calcula o saldo da conta no instante at a partir dos movimentos
+	GetBalanceAt(ctx context.Context, accountID string, userID string, at time.Time) (*BalanceAtResponse, error)
+
+	// TakeBalanceSnapshots grava o saldo de todas as contas em asOf. É idempotente.
+	TakeBalanceSnapshots(ctx context.Context, asOf time.Time) (int, error)
+
+	// AdjustBalance é o ponto único para operações que alteram saldo. Não verifica
+	// permissões: quem chama já deve ter autorizado o usuário.
+	// occurredAt é a data do fato que gerou o ajuste, usada para manter o histórico.
+	AdjustBalance(ctx context.Context, accountID uuid.UUID, delta int64, occurredAt time.Time) (*Account, error)
+	// AdjustHeld soma delta às retenções da conta: positivo reserva saldo
+	// disponível (com as mesmas regras de limite de um débito), negativo libera.
+	// Também não verifica permissões.
+	AdjustHeld(ctx context.Context, accountID uuid.UUID, delta int64) (*Account, error)
 }
 
 type service struct {
-	repo Repository
+	repo                 Repository
+	publisher            events.Publisher
+	utilizationThreshold float64
+	rates                RateProvider
 }
 
-func NewService(repo Repository) Service {
-	return &service{repo: repo}
+// NewService cria o serviço de contas. publisher pode ser nil, e nesse caso
+// nenhuma notificação de uso de limite é enviada.
+func NewService(repo Repository, publisher events.Publisher, utilizationThreshold float64, rates RateProvider) Service {
+	return &service{
+		repo:                 repo,
+		publisher:            publisher,
+		utilizationThreshold: utilizationThreshold,
+		rates:                rates,
+	}
 }
 
 func (s *service) parseAndValidateIDs(userIDStr string, accountIDStr ...string) (uuid.UUID, uuid.UUID, error) {
@@ -53,78 +125,853 @@ func (s *service) CreateAccount(ctx context.Context, req CreateAccountRequest, u
 		return nil, err
 	}
 
+	initialBalance, err := amountIn(req.InitialBalance, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+	creditLimit, err := amountIn(req.CreditLimit, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	accountIBAN, err := normalizeIBAN(req.IBAN)
+	if err != nil {
+		return nil, err
+	}
+
 	now := time.Now().UTC()
 	account := &Account{
-		ID:        uuid.New(),
-		UserID:    userID,
-		Name:      req.Name,
-		Type:      req.Type,
-		Balance:   req.InitialBalance,
-		Currency:  req.Currency,
-		CreatedAt: now,
-		UpdatedAt: now,
+		ID:               uuid.New(),
+		UserID:           userID,
+		Name:             req.Name,
+		Type:             req.Type,
+		Balance:          initialBalance,
+		Currency:         req.Currency,
+		CreditLimit:      creditLimit,
+		OverdraftAllowed: req.OverdraftAllowed,
+		IBAN:             accountIBAN,
+		ParentID:         req.ParentID,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+		Version:          1,
+	}
+
+	if err := validateLimits(account); err != nil {
+		return nil, err
+	}
+
+	if req.ParentID != nil {
+		if err := s.checkParent(ctx, *req.ParentID, userIDStr); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := s.repo.CreateAccount(ctx, account); err != nil {
-		log.Error().Err(err).Msg("Failed to create account in repository")
+		if !errors.Is(err, ErrIBANExists) {
+			log.Error().Err(err).Msg("Failed to create account in repository")
+		}
 		return nil, err
 	}
 
 	return toAccountResponse(account), nil
 }
 
+func (s *service) AuthorizeAccount(ctx context.Context, accountIDStr string, userIDStr string, role MemberRole) (*Account, error) {
+	account, _, err := s.getAccountForRole(ctx, accountIDStr, userIDStr, role)
+	return account, err
+}
+
+func (s *service) FindAccountByIBAN(ctx context.Context, ibanStr string, userIDStr string, role MemberRole) (*Account, error) {
+	userID, _, err := s.parseAndValidateIDs(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+	normalized, err := iban.Normalize(ibanStr)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := s.repo.ListAccountsByIBAN(ctx, userID, normalized)
+	if err != nil {
+		log.Error().Err(err).Str("userID", userIDStr).Msg("Failed to list accounts by IBAN from repository")
+		return nil, err
+	}
+
+	var active []Account
+	for _, acc := range candidates {
+		if !acc.IsArchived() {
+			active = append(active, acc)
+		}
+	}
+	switch {
+	case len(active) > 1:
+		return nil, ErrAmbiguousIBAN
+	case len(active) == 1:
+		candidates = active
+	case len(candidates) == 0:
+		return nil, ErrAccountNotFound
+	}
+	return s.AuthorizeAccount(ctx, candidates[0].ID.String(), userIDStr, role)
+}
+
 func (s *service) GetAccount(ctx context.Context, accountIDStr string, userIDStr string) (*AccountResponse, error) {
-	userID, accountID, err := s.parseAndValidateIDs(userIDStr, accountIDStr)
+	account, _, err := s.getAccountForRole(ctx, accountIDStr, userIDStr, RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return toAccountResponse(account), nil
+}
+
+// getAccountAtVersion é o getAccountForRole das escritas: a conta precisa estar
+// na versão que o cliente leu (If-Match)
+func (s *service) getAccountAtVersion(ctx context.Context, accountIDStr string, userIDStr string, role MemberRole, version int64) (*Account, error) {
+	account, _, err := s.getAccountForRole(ctx, accountIDStr, userIDStr, role)
 	if err != nil {
 		return nil, err
 	}
+	if account.Version != version {
+		return nil, ErrVersionMismatch
+	}
+	return account, nil
+}
+
+// getAccountForRole busca a conta e garante que o usuário é membro dela com
+// pelo menos o papel pedido. Devolve também o vínculo do usuário.
+func (s *service) getAccountForRole(ctx context.Context, accountIDStr string, userIDStr string, role MemberRole) (*Account, *AccountMember, error) {
+	userID, accountID, err := s.parseAndValidateIDs(userIDStr, accountIDStr)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	account, err := s.repo.GetAccountByID(ctx, accountID)
 	if err != nil {
 		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to get account from repository")
-		return nil, err
+		return nil, nil, err
 	}
 	if account == nil {
-		return nil, ErrAccountNotFound
+		return nil, nil, ErrAccountNotFound
+	}
+
+	member, err := s.repo.GetMember(ctx, accountID, userID)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to get account member from repository")
+		return nil, nil, err
 	}
 
-	// Verifica se a conta pertence ao usuário que fez a requisição
-	if account.UserID != userID {
-		log.Warn().Str("userID", userIDStr).Str("accountOwnerID", account.UserID.String()).Msg("Forbidden access attempt")
-		return nil, ErrForbidden
+	// O acesso vem do vínculo com a conta, não de quem a criou
+	if member == nil || !member.Role.Allows(role) {
+		log.Warn().Str("userID", userIDStr).Str("accountID", accountIDStr).Str("requiredRole", string(role)).Msg("Forbidden access attempt")
+		return nil, nil, ErrForbidden
 	}
 
-	return toAccountResponse(account), nil
+	return account, member, nil
 }
 
-func (s *service) ListAccounts(ctx context.Context, userIDStr string) ([]AccountResponse, error) {
+func (s *service) ListAccounts(ctx context.Context, userIDStr string, filter ListAccountsFilter, page pagination.Params) (*pagination.Page[AccountResponse], error) {
 	userID, _, err := s.parseAndValidateIDs(userIDStr)
 	if err != nil {
 		return nil, err
 	}
 
-	accounts, err := s.repo.ListAccountsByUserID(ctx, userID)
+	accounts, err := s.repo.ListAccountsByUserID(ctx, userID, filter, page)
 	if err != nil {
 		log.Error().Err(err).Str("userID", userIDStr).Msg("Failed to list accounts from repository")
 		return nil, err
 	}
 
-	responses := make([]AccountResponse, len(accounts))
-	for i, acc := range accounts {
-		responses[i] = *toAccountResponse(&acc)
+	result := pagination.NewPage(accounts, page, func(acc Account) (interface{}, string) {
+		return acc.sortKey(page.Sort.Name)
+	})
+	responses := pagination.Map(result, func(acc Account) AccountResponse {
+		return *toAccountResponse(&acc)
+	})
+
+	if filter.AsOf != nil && len(responses.Items) > 0 {
+		ids := make([]uuid.UUID, len(responses.Items))
+		for i, item := range responses.Items {
+			ids[i] = item.ID
+		}
+
+		balances, err := s.repo.BalancesBefore(ctx, ids, balanceBound(*filter.AsOf))
+		if err != nil {
+			log.Error().Err(err).Str("userID", userIDStr).Msg("Failed to compute historical balances")
+			return nil, err
+		}
+
+		for i := range responses.Items {
+			balance := money.New(balances[responses.Items[i].ID], responses.Items[i].Currency)
+			responses.Items[i].BalanceAsOf = &balance
+			responses.Items[i].AsOf = filter.AsOf
+		}
+	}
+
+	return &responses, nil
+}
+
+func (s *service) GetBalanceAt(ctx context.Context, accountIDStr string, userIDStr string, at time.Time) (*BalanceAtResponse, error) {
+	account, _, err := s.getAccountForRole(ctx, accountIDStr, userIDStr, RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := s.repo.BalancesBefore(ctx, []uuid.UUID{account.ID}, balanceBound(at))
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to compute historical balance")
+		return nil, err
+	}
+
+	return &BalanceAtResponse{
+		AccountID: account.ID,
+		Balance:   money.New(balances[account.ID], account.Currency),
+		At:        at,
+	}, nil
+}
+
+// balanceBound converte o instante pedido (inclusivo) no limite exclusivo usado
+// pelos snapshots, na precisão de microssegundos do Postgres
+func balanceBound(at time.Time) time.Time {
+	return at.Truncate(time.Microsecond).Add(time.Microsecond)
+}
+
+func (s *service) TakeBalanceSnapshots(ctx context.Context, asOf time.Time) (int, error) {
+	ids, err := s.repo.ListAccountsWithoutSnapshot(ctx, asOf)
+	if err != nil {
+		return 0, err
+	}
+
+	taken := 0
+	for _, id := range ids {
+		if err := s.repo.CreateBalanceSnapshot(ctx, id, asOf); err != nil {
+			// Uma conta com problema não impede as outras; ela entra na próxima rodada
+			log.Error().Err(err).Str("accountID", id.String()).Msg("Failed to create balance snapshot")
+			continue
+		}
+		taken++
+	}
+	return taken, nil
+}
+
+func (s *service) UpdateAccount(ctx context.Context, accountIDStr string, userIDStr string, version int64, req UpdateAccountRequest) (*AccountResponse, error) {
+	account, err := s.getAccountAtVersion(ctx, accountIDStr, userIDStr, RoleEditor, version)
+	if err != nil {
+		return nil, err
+	}
+
+	before := *account
+
+	if req.Name != nil {
+		account.Name = *req.Name
+	}
+	if req.Type != nil {
+		account.Type = *req.Type
+	}
+	if req.CreditLimit != nil {
+		if account.CreditLimit, err = amountIn(*req.CreditLimit, account.Currency); err != nil {
+			return nil, err
+		}
+	}
+	if req.OverdraftAllowed != nil {
+		account.OverdraftAllowed = *req.OverdraftAllowed
+	}
+	if req.IBAN != nil {
+		if account.IBAN, err = normalizeIBAN(req.IBAN); err != nil {
+			return nil, err
+		}
+	}
+
+	// O saldo atual precisa continuar válido com o novo tipo e limite
+	if err := validateLimits(account); err != nil {
+		return nil, err
+	}
+	account.UpdatedAt = time.Now().UTC()
+
+	updated, err := s.repo.UpdateAccount(ctx, account)
+	if err != nil {
+		if !errors.Is(err, ErrIBANExists) {
+			log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to update account in repository")
+		}
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrVersionMismatch
+	}
+
+	s.notifyUtilization(ctx, &before, account)
+
+	return toAccountResponse(account), nil
+}
+
+func (s *service) ArchiveAccount(ctx context.Context, accountIDStr string, userIDStr string, version int64) (*AccountResponse, error) {
+	account, err := s.getAccountAtVersion(ctx, accountIDStr, userIDStr, RoleOwner, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// Arquivar de novo não muda nada
+	if account.IsArchived() {
+		return toAccountResponse(account), nil
+	}
+
+	now := time.Now().UTC()
+	account.ArchivedAt = &now
+	account.UpdatedAt = now
+
+	updated, err := s.repo.UpdateAccount(ctx, account)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to archive account in repository")
+		return nil, err
+	}
+	if !updated {
+		return nil, ErrVersionMismatch
+	}
+
+	return toAccountResponse(account), nil
+}
+
+func (s *service) DeleteAccount(ctx context.Context, accountIDStr string, userIDStr string, version int64) error {
+	account, err := s.getAccountAtVersion(ctx, accountIDStr, userIDStr, RoleOwner, version)
+	if err != nil {
+		return err
+	}
+
+	if account.Balance != 0 {
+		return ErrAccountNotEmpty
+	}
+
+	hasMovements, err := s.repo.HasMovements(ctx, account.ID)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to check account movements")
+		return err
+	}
+	if hasMovements {
+		return ErrAccountNotEmpty
+	}
+
+	hasChildren, err := s.repo.HasChildren(ctx, account.ID)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to check child accounts")
+		return err
+	}
+	if hasChildren {
+		return ErrAccountHasChildren
+	}
+
+	deleted, err := s.repo.DeleteAccount(ctx, account.ID, version)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to delete account in repository")
+		return err
+	}
+	// A conta mudou entre a leitura e o delete
+	if !deleted {
+		return ErrVersionMismatch
+	}
+
+	return nil
+}
+
+// MoveAccount troca o pai da conta. É preciso poder editar a conta e o novo pai.
+func (s *service) MoveAccount(ctx context.Context, accountIDStr string, userIDStr string, version int64, req MoveAccountRequest) (*AccountResponse, error) {
+	account, err := s.getAccountAtVersion(ctx, accountIDStr, userIDStr, RoleEditor, version)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ParentID != nil {
+		if *req.ParentID == account.ID {
+			return nil, ErrHierarchyCycle
+		}
+		if err := s.checkParent(ctx, *req.ParentID, userIDStr); err != nil {
+			return nil, err
+		}
+	}
+
+	account.ParentID = req.ParentID
+	account.UpdatedAt = time.Now().UTC()
+
+	moved, err := s.repo.MoveAccount(ctx, account)
+	if err != nil {
+		if !errors.Is(err, ErrHierarchyCycle) {
+			log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to move account in repository")
+		}
+		return nil, err
+	}
+	if !moved {
+		return nil, ErrVersionMismatch
 	}
 
+	return toAccountResponse(account), nil
+}
+
+// checkParent garante que a conta pai existe, está ativa e pode ser editada pelo usuário
+func (s *service) checkParent(ctx context.Context, parentID uuid.UUID, userIDStr string) error {
+	parent, _, err := s.getAccountForRole(ctx, parentID.String(), userIDStr, RoleEditor)
+	if err != nil {
+		if errors.Is(err, ErrAccountNotFound) || errors.Is(err, ErrForbidden) {
+			return ErrInvalidParent
+		}
+		return err
+	}
+	if parent.IsArchived() {
+		return ErrAccountArchived
+	}
+	return nil
+}
+
+// GetAccountTree monta o plano de contas do usuário. Contas cujo pai ele não
+// pode ver aparecem como raízes.
+func (s *service) GetAccountTree(ctx context.Context, userIDStr string, includeArchived bool) ([]AccountTreeNode, error) {
+	userID, _, err := s.parseAndValidateIDs(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, rollups, err := s.repo.GetAccountTree(ctx, userID, includeArchived)
+	if err != nil {
+		log.Error().Err(err).Str("userID", userIDStr).Msg("Failed to get account tree from repository")
+		return nil, err
+	}
+
+	rollupsByAccount := make(map[uuid.UUID][]money.Money)
+	for _, ru := range rollups {
+		rollupsByAccount[ru.AccountID] = append(rollupsByAccount[ru.AccountID], money.New(ru.Balance, ru.Currency))
+	}
+
+	visible := make(map[uuid.UUID]bool, len(accounts))
+	children := make(map[uuid.UUID][]*Account)
+	for i := range accounts {
+		visible[accounts[i].ID] = true
+	}
+
+	var roots []*Account
+	for i := range accounts {
+		acc := &accounts[i]
+		if acc.ParentID != nil && visible[*acc.ParentID] {
+			children[*acc.ParentID] = append(children[*acc.ParentID], acc)
+		} else {
+			roots = append(roots, acc)
+		}
+	}
+
+	var build func(acc *Account) AccountTreeNode
+	build = func(acc *Account) AccountTreeNode {
+		node := AccountTreeNode{
+			AccountResponse: *toAccountResponse(acc),
+			Rollup:          rollupsByAccount[acc.ID],
+			Children:        make([]AccountTreeNode, 0, len(children[acc.ID])),
+		}
+		if node.Rollup == nil {
+			node.Rollup = []money.Money{}
+		}
+		for _, child := range children[acc.ID] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	tree := make([]AccountTreeNode, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, build(root))
+	}
+	return tree, nil
+}
+
+// GetSummary consolida as contas ativas na moeda pedida. Cada moeda é convertida
+// uma única vez, pela cotação do dia, e a cotação usada vai na resposta.
+func (s *service) GetSummary(ctx context.Context, userIDStr string, currency string) (*SummaryResponse, error) {
+	userID, _, err := s.parseAndValidateIDs(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := s.repo.SumBalances(ctx, userID)
+	if err != nil {
+		log.Error().Err(err).Str("userID", userIDStr).Msg("Failed to sum account balances")
+		return nil, err
+	}
+
+	summary := &SummaryResponse{
+		Currency:    currency,
+		ByType:      []TypeSummary{},
+		Assets:      money.New(0, currency),
+		Liabilities: money.New(0, currency),
+		Conversions: []ConversionInfo{},
+	}
+	rates := map[string]float64{currency: 1}
+	byType := make(map[AccountType]*TypeSummary)
+	today := time.Now().UTC()
+
+	for _, t := range totals {
+		rate, ok := rates[t.Currency]
+		if !ok {
+			if s.rates == nil {
+				return nil, fmt.Errorf("%w: %s to %s", ErrRateUnavailable, t.Currency, currency)
+			}
+			r, err := s.rates.Rate(ctx, t.Currency, currency, today)
+			if err != nil {
+				return nil, err
+			}
+			rate = r.Value
+			rates[t.Currency] = rate
+			summary.Conversions = append(summary.Conversions, ConversionInfo{
+				From:     t.Currency,
+				To:       currency,
+				Rate:     r.Value,
+				RateDate: r.Date,
+			})
+		}
+
+		converted, err := money.New(t.Balance, t.Currency).Convert(rate, currency)
+		if err != nil {
+			return nil, err
+		}
+		info, _ := t.Type.Info()
+
+		ts, ok := byType[t.Type]
+		if !ok {
+			ts = &TypeSummary{Type: t.Type, Label: info.Label, Nature: info.Nature, Total: money.New(0, currency)}
+			byType[t.Type] = ts
+		}
+		if ts.Total, err = ts.Total.Add(converted); err != nil {
+			return nil, err
+		}
+		ts.Accounts += t.Accounts
+
+		// Passivos têm saldo negativo; Liabilities mostra o valor devido
+		if info.Nature == NatureLiability {
+			summary.Liabilities, err = summary.Liabilities.Sub(converted)
+		} else {
+			summary.Assets, err = summary.Assets.Add(converted)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Mesma ordem de AccountTypes()
+	for _, info := range AccountTypes() {
+		if ts, ok := byType[info.Type]; ok {
+			summary.ByType = append(summary.ByType, *ts)
+		}
+	}
+	if summary.NetWorth, err = summary.Assets.Sub(summary.Liabilities); err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// amountIn devolve o valor em unidades mínimas da moeda da conta. Valores sem
+// moeda são lidos como da própria conta.
+func amountIn(m money.Money, currency string) (int64, error) {
+	if m.Currency != "" && m.Currency != currency {
+		return 0, fmt.Errorf("%w: amount in %s for a %s account", money.ErrCurrencyMismatch, m.Currency, currency)
+	}
+	return m.Amount, nil
+}
+
+func (s *service) ListMembers(ctx context.Context, accountIDStr string, userIDStr string) ([]MemberResponse, error) {
+	account, _, err := s.getAccountForRole(ctx, accountIDStr, userIDStr, RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.repo.ListMembers(ctx, account.ID)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to list account members from repository")
+		return nil, err
+	}
+
+	responses := make([]MemberResponse, len(members))
+	for i, m := range members {
+		responses[i] = *toMemberResponse(&m)
+	}
 	return responses, nil
 }
 
+// AddMember dá acesso à conta a um usuário já cadastrado. Só owners convidam.
+func (s *service) AddMember(ctx context.Context, accountIDStr string, userIDStr string, req AddMemberRequest) (*MemberResponse, error) {
+	account, inviter, err := s.getAccountForRole(ctx, accountIDStr, userIDStr, RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	memberID, err := s.repo.FindUserIDByEmail(ctx, req.Email)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to find user by email")
+		return nil, err
+	}
+	if memberID == nil {
+		return nil, ErrUserNotFound
+	}
+
+	now := time.Now().UTC()
+	member := &AccountMember{
+		AccountID: account.ID,
+		UserID:    *memberID,
+		Email:     req.Email,
+		Role:      req.Role,
+		InvitedBy: &inviter.UserID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.AddMember(ctx, member); err != nil {
+		if !errors.Is(err, ErrMemberExists) {
+			log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to add account member in repository")
+		}
+		return nil, err
+	}
+
+	return toMemberResponse(member), nil
+}
+
+func (s *service) UpdateMemberRole(ctx context.Context, accountIDStr string, userIDStr string, memberIDStr string, req UpdateMemberRoleRequest) (*MemberResponse, error) {
+	account, _, err := s.getAccountForRole(ctx, accountIDStr, userIDStr, RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.getMember(ctx, account.ID, memberIDStr)
+	if err != nil {
+		return nil, err
+	}
+	if member.Role == req.Role {
+		return toMemberResponse(member), nil
+	}
+
+	updated, err := s.repo.UpdateMemberRole(ctx, account.ID, member.UserID, req.Role)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to update account member role in repository")
+		return nil, err
+	}
+	// O membro existe, então a única recusa possível é rebaixar o último owner
+	if !updated {
+		return nil, ErrLastOwner
+	}
+
+	member.Role = req.Role
+	return toMemberResponse(member), nil
+}
+
+// RemoveMember tira o acesso de um membro. Owners removem qualquer um e
+// qualquer membro pode sair da conta por conta própria.
+func (s *service) RemoveMember(ctx context.Context, accountIDStr string, userIDStr string, memberIDStr string) error {
+	role := RoleOwner
+	if memberIDStr == userIDStr {
+		role = RoleViewer
+	}
+
+	account, _, err := s.getAccountForRole(ctx, accountIDStr, userIDStr, role)
+	if err != nil {
+		return err
+	}
+
+	member, err := s.getMember(ctx, account.ID, memberIDStr)
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.repo.RemoveMember(ctx, account.ID, member.UserID)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to remove account member in repository")
+		return err
+	}
+	if !removed {
+		return ErrLastOwner
+	}
+
+	return nil
+}
+
+func (s *service) getMember(ctx context.Context, accountID uuid.UUID, memberIDStr string) (*AccountMember, error) {
+	memberID, err := uuid.Parse(memberIDStr)
+	if err != nil {
+		return nil, ErrMemberNotFound
+	}
+
+	member, err := s.repo.GetMember(ctx, accountID, memberID)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountID.String()).Msg("Failed to get account member from repository")
+		return nil, err
+	}
+	if member == nil {
+		return nil, ErrMemberNotFound
+	}
+	return member, nil
+}
+
+func (s *service) AdjustBalance(ctx context.Context, accountID uuid.UUID, delta int64, occurredAt time.Time) (*Account, error) {
+	account, err := s.repo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountID.String()).Msg("Failed to get account from repository")
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrAccountNotFound
+	}
+	if account.IsArchived() {
+		return nil, ErrAccountArchived
+	}
+
+	// Checagem antecipada para dar um erro claro; a constraint do banco cobre as corridas
+	if delta < 0 {
+		projected := *account
+		projected.Balance += delta
+		if err := validateLimits(&projected); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.repo.AdjustBalance(ctx, accountID, delta, occurredAt)
+	if err != nil {
+		if !errors.Is(err, ErrInsufficientFunds) {
+			log.Error().Err(err).Str("accountID", accountID.String()).Msg("Failed to adjust account balance")
+		}
+		return nil, err
+	}
+	// A conta foi arquivada entre a leitura e o update
+	if updated == nil {
+		return nil, ErrAccountArchived
+	}
+
+	before := *updated
+	before.Balance -= delta
+	s.notifyUtilization(ctx, &before, updated)
+
+	return updated, nil
+}
+
+func (s *service) AdjustHeld(ctx context.Context, accountID uuid.UUID, delta int64) (*Account, error) {
+	account, err := s.repo.GetAccountByID(ctx, accountID)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountID.String()).Msg("Failed to get account from repository")
+		return nil, err
+	}
+	if account == nil {
+		return nil, ErrAccountNotFound
+	}
+	// Liberar uma retenção vale mesmo em conta arquivada; reservar, não
+	if delta > 0 {
+		if account.IsArchived() {
+			return nil, ErrAccountArchived
+		}
+		projected := *account
+		projected.Held += delta
+		if err := validateLimits(&projected); err != nil {
+			return nil, err
+		}
+	}
+
+	updated, err := s.repo.AdjustHeld(ctx, accountID, delta)
+	if err != nil {
+		if !errors.Is(err, ErrInsufficientFunds) {
+			log.Error().Err(err).Str("accountID", accountID.String()).Msg("Failed to adjust account holds")
+		}
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrAccountArchived
+	}
+	return updated, nil
+}
+
+// notifyUtilization publica um evento quando o uso do limite passa do threshold
+func (s *service) notifyUtilization(ctx context.Context, before *Account, after *Account) {
+	if s.publisher == nil || s.utilizationThreshold <= 0 {
+		return
+	}
+	if before.Utilization() >= s.utilizationThreshold || after.Utilization() < s.utilizationThreshold {
+		return
+	}
+
+	event := events.Event{
+		Type:       EventCreditUtilizationCrossed,
+		UserID:     after.UserID.String(),
+		OccurredAt: time.Now().UTC(),
+		Payload: CreditUtilizationEvent{
+			AccountID:   after.ID,
+			Utilization: after.Utilization(),
+			Threshold:   s.utilizationThreshold,
+			Balance:     after.Balance,
+			CreditLimit: after.CreditLimit,
+			Currency:    after.Currency,
+		},
+	}
+	// Dentro de uma transação, o evento só sai se ela for confirmada
+	database.AfterCommit(ctx, func(ctx context.Context) {
+		if err := s.publisher.Publish(ctx, event); err != nil {
+			log.Error().Err(err).Str("accountID", after.ID.String()).Msg("Failed to publish credit utilization event")
+		}
+	})
+}
+
+// validateLimits aplica as regras de saldo e limite do tipo de conta
+func validateLimits(acc *Account) error {
+	info, ok := acc.Type.Info()
+	if !ok {
+		return fmt.Errorf("unknown account type %q", acc.Type)
+	}
+
+	if acc.OverdraftAllowed && !info.SupportsOverdraft {
+		return ErrCreditLimitNotAllowed
+	}
+	if acc.CreditLimit > 0 && !info.AllowsNegative && !info.SupportsOverdraft {
+		return ErrCreditLimitNotAllowed
+	}
+
+	// As retenções pendentes contam como já gastas
+	if acc.Balance-acc.Held >= 0 {
+		return nil
+	}
+	if !info.AllowsNegative && !(info.SupportsOverdraft && acc.OverdraftAllowed) {
+		return ErrNegativeBalanceNotAllowed
+	}
+	if acc.AvailableBalance() < 0 {
+		return ErrInsufficientFunds
+	}
+	return nil
+}
+
+// normalizeIBAN valida o IBAN enviado; vazio ou ausente é conta sem IBAN
+func normalizeIBAN(value *string) (*string, error) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, nil
+	}
+	normalized, err := iban.Normalize(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &normalized, nil
+}
+
 func toAccountResponse(acc *Account) *AccountResponse {
 	return &AccountResponse{
-		ID:        acc.ID,
-		UserID:    acc.UserID,
-		Name:      acc.Name,
-		Type:      acc.Type,
-		Balance:   acc.Balance,
-		Currency:  acc.Currency,
-		CreatedAt: acc.CreatedAt,
+		ID:               acc.ID,
+		UserID:           acc.UserID,
+		ParentID:         acc.ParentID,
+		Name:             acc.Name,
+		Type:             acc.Type,
+		Balance:          money.New(acc.Balance, acc.Currency),
+		Held:             money.New(acc.Held, acc.Currency),
+		AvailableBalance: money.New(acc.AvailableBalance(), acc.Currency),
+		Currency:         acc.Currency,
+		CreditLimit:      money.New(acc.CreditLimit, acc.Currency),
+		OverdraftAllowed: acc.OverdraftAllowed,
+		IBAN:             acc.IBAN,
+		CreatedAt:        acc.CreatedAt,
+		UpdatedAt:        acc.UpdatedAt,
+		ArchivedAt:       acc.ArchivedAt,
+		Version:          acc.Version,
+	}
+}
+
+func toMemberResponse(m *AccountMember) *MemberResponse {
+	return &MemberResponse{
+		UserID:    m.UserID,
+		Email:     m.Email,
+		Role:      m.Role,
+		InvitedBy: m.InvitedBy,
+		CreatedAt: m.CreatedAt,
 	}
 }
@@ -0,0 +1,1166 @@
+package accounts
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/pkg/events"
+	"github.com/martinsdevv/fincore/pkg/iban"
+	"github.com/martinsdevv/fincore/pkg/money"
+	"github.com/martinsdevv/fincore/pkg/pagination"
+)
+
+// MockRepository é a simulação da nossa interface Repository
+type MockRepository struct {
+	CreateAccountFunc        func(ctx context.Context, account *Account) error
+	GetAccountByIDFunc       func(ctx context.Context, id uuid.UUID) (*Account, error)
+	ListAccountsByUserIDFunc func(ctx context.Context, userID uuid.UUID, filter ListAccountsFilter, page pagination.Params) ([]Account, error)
+	UpdateAccountFunc        func(ctx context.Context, account *Account) (bool, error)
+	DeleteAccountFunc        func(ctx context.Context, id uuid.UUID, version int64) (bool, error)
+	AdjustBalanceFunc        func(ctx context.Context, id uuid.UUID, delta int64, occurredAt time.Time) (*Account, error)
+	AdjustHeldFunc           func(ctx context.Context, id uuid.UUID, delta int64) (*Account, error)
+	GetMemberFunc            func(ctx context.Context, accountID uuid.UUID, userID uuid.UUID) (*AccountMember, error)
+	ListMembersFunc          func(ctx context.Context, accountID uuid.UUID) ([]AccountMember, error)
+	AddMemberFunc            func(ctx context.Context, member *AccountMember) error
+	UpdateMemberRoleFunc     func(ctx context.Context, accountID uuid.UUID, userID uuid.UUID, role MemberRole) (bool, error)
+	RemoveMemberFunc         func(ctx context.Context, accountID uuid.UUID, userID uuid.UUID) (bool, error)
+	FindUserIDByEmailFunc    func(ctx context.Context, email string) (*uuid.UUID, error)
+	MoveAccountFunc          func(ctx context.Context, account *Account) (bool, error)
+	HasChildrenFunc          func(ctx context.Context, id uuid.UUID) (bool, error)
+	GetAccountTreeFunc       func(ctx context.Context, userID uuid.UUID, includeArchived bool) ([]Account, []AccountRollup, error)
+
+	HasMovementsFunc                func(ctx context.Context, id uuid.UUID) (bool, error)
+	BalancesBeforeFunc              func(ctx context.Context, ids []uuid.UUID, before time.Time) (map[uuid.UUID]int64, error)
+	ListAccountsWithoutSnapshotFunc func(ctx context.Context, asOf time.Time) ([]uuid.UUID, error)
+	CreateBalanceSnapshotFunc       func(ctx context.Context, accountID uuid.UUID, asOf time.Time) error
+	SumBalancesFunc                 func(ctx context.Context, userID uuid.UUID) ([]BalanceTotal, error)
+	ListAccountsByIBANFunc          func(ctx context.Context, userID uuid.UUID, iban string) ([]Account, error)
+}
+
+func (m *MockRepository) CreateAccount(ctx context.Context, account *Account) error {
+	if m.CreateAccountFunc != nil {
+		return m.CreateAccountFunc(ctx, account)
+	}
+	return nil
+}
+
+func (m *MockRepository) GetAccountByID(ctx context.Context, id uuid.UUID) (*Account, error) {
+	if m.GetAccountByIDFunc != nil {
+		return m.GetAccountByIDFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) ListAccountsByUserID(ctx context.Context, userID uuid.UUID, filter ListAccountsFilter, page pagination.Params) ([]Account, error) {
+	if m.ListAccountsByUserIDFunc != nil {
+		return m.ListAccountsByUserIDFunc(ctx, userID, filter, page)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) UpdateAccount(ctx context.Context, account *Account) (bool, error) {
+	if m.UpdateAccountFunc != nil {
+		return m.UpdateAccountFunc(ctx, account)
+	}
+	account.Version++
+	return true, nil
+}
+
+func (m *MockRepository) DeleteAccount(ctx context.Context, id uuid.UUID, version int64) (bool, error) {
+	if m.DeleteAccountFunc != nil {
+		return m.DeleteAccountFunc(ctx, id, version)
+	}
+	return true, nil
+}
+
+func (m *MockRepository) AdjustBalance(ctx context.Context, id uuid.UUID, delta int64, occurredAt time.Time) (*Account, error) {
+	if m.AdjustBalanceFunc != nil {
+		return m.AdjustBalanceFunc(ctx, id, delta, occurredAt)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) AdjustHeld(ctx context.Context, id uuid.UUID, delta int64) (*Account, error) {
+	if m.AdjustHeldFunc != nil {
+		return m.AdjustHeldFunc(ctx, id, delta)
+	}
+	return nil, nil
+}
+
+// GetMember, sem GetMemberFunc, trata quem criou a conta como seu único owner
+func (m *MockRepository) GetMember(ctx context.Context, accountID uuid.UUID, userID uuid.UUID) (*AccountMember, error) {
+	if m.GetMemberFunc != nil {
+		return m.GetMemberFunc(ctx, accountID, userID)
+	}
+	acc, err := m.GetAccountByID(ctx, accountID)
+	if err != nil || acc == nil || acc.UserID != userID {
+		return nil, err
+	}
+	return &AccountMember{AccountID: accountID, UserID: userID, Role: RoleOwner}, nil
+}
+
+func (m *MockRepository) ListMembers(ctx context.Context, accountID uuid.UUID) ([]AccountMember, error) {
+	if m.ListMembersFunc != nil {
+		return m.ListMembersFunc(ctx, accountID)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) AddMember(ctx context.Context, member *AccountMember) error {
+	if m.AddMemberFunc != nil {
+		return m.AddMemberFunc(ctx, member)
+	}
+	return nil
+}
+
+func (m *MockRepository) UpdateMemberRole(ctx context.Context, accountID uuid.UUID, userID uuid.UUID, role MemberRole) (bool, error) {
+	if m.UpdateMemberRoleFunc != nil {
+		return m.UpdateMemberRoleFunc(ctx, accountID, userID, role)
+	}
+	return true, nil
+}
+
+func (m *MockRepository) RemoveMember(ctx context.Context, accountID uuid.UUID, userID uuid.UUID) (bool, error) {
+	if m.RemoveMemberFunc != nil {
+		return m.RemoveMemberFunc(ctx, accountID, userID)
+	}
+	return true, nil
+}
+
+func (m *MockRepository) FindUserIDByEmail(ctx context.Context, email string) (*uuid.UUID, error) {
+	if m.FindUserIDByEmailFunc != nil {
+		return m.FindUserIDByEmailFunc(ctx, email)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) MoveAccount(ctx context.Context, account *Account) (bool, error) {
+	if m.MoveAccountFunc != nil {
+		return m.MoveAccountFunc(ctx, account)
+	}
+	account.Version++
+	return true, nil
+}
+
+func (m *MockRepository) HasChildren(ctx context.Context, id uuid.UUID) (bool, error) {
+	if m.HasChildrenFunc != nil {
+		return m.HasChildrenFunc(ctx, id)
+	}
+	return false, nil
+}
+
+func (m *MockRepository) GetAccountTree(ctx context.Context, userID uuid.UUID, includeArchived bool) ([]Account, []AccountRollup, error) {
+	if m.GetAccountTreeFunc != nil {
+		return m.GetAccountTreeFunc(ctx, userID, includeArchived)
+	}
+	return nil, nil, nil
+}
+
+func (m *MockRepository) HasMovements(ctx context.Context, id uuid.UUID) (bool, error) {
+	if m.HasMovementsFunc != nil {
+		return m.HasMovementsFunc(ctx, id)
+	}
+	return false, nil
+}
+
+func (m *MockRepository) BalancesBefore(ctx context.Context, ids []uuid.UUID, before time.Time) (map[uuid.UUID]int64, error) {
+	if m.BalancesBeforeFunc != nil {
+		return m.BalancesBeforeFunc(ctx, ids, before)
+	}
+	return map[uuid.UUID]int64{}, nil
+}
+
+func (m *MockRepository) ListAccountsWithoutSnapshot(ctx context.Context, asOf time.Time) ([]uuid.UUID, error) {
+	if m.ListAccountsWithoutSnapshotFunc != nil {
+		return m.ListAccountsWithoutSnapshotFunc(ctx, asOf)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) CreateBalanceSnapshot(ctx context.Context, accountID uuid.UUID, asOf time.Time) error {
+	if m.CreateBalanceSnapshotFunc != nil {
+		return m.CreateBalanceSnapshotFunc(ctx, accountID, asOf)
+	}
+	return nil
+}
+
+func (m *MockRepository) SumBalances(ctx context.Context, userID uuid.UUID) ([]BalanceTotal, error) {
+	if m.SumBalancesFunc != nil {
+		return m.SumBalancesFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) ListAccountsByIBAN(ctx context.Context, userID uuid.UUID, iban string) ([]Account, error) {
+	if m.ListAccountsByIBANFunc != nil {
+		return m.ListAccountsByIBANFunc(ctx, userID, iban)
+	}
+	return nil, nil
+}
+
+// MockPublisher guarda os eventos publicados
+type MockPublisher struct {
+	Events []events.Event
+}
+
+func (m *MockPublisher) Publish(ctx context.Context, event events.Event) error {
+	m.Events = append(m.Events, event)
+	return nil
+}
+
+func newTestAccount(userID uuid.UUID) *Account {
+	now := time.Now().UTC()
+	return &Account{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      "Conta Corrente",
+		Type:      "checking",
+		Currency:  "BRL",
+		CreatedAt: now,
+		UpdatedAt: now,
+		Version:   3,
+	}
+}
+
+func TestService_UpdateAccount(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("deve atualizar o nome e incrementar a versão", func(t *testing.T) {
+		account := newTestAccount(userID)
+		mockRepo := &MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return account, nil
+			},
+		}
+
+		service := NewService(mockRepo, nil, 80, nil)
+		name := "Conta Principal"
+
+		resp, err := service.UpdateAccount(ctx, account.ID.String(), userID.String(), 3, UpdateAccountRequest{Name: &name})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if resp.Name != name {
+			t.Errorf("nome não foi atualizado: %s", resp.Name)
+		}
+		if resp.Version != 4 {
+			t.Errorf("esperava versão 4, obteve %d", resp.Version)
+		}
+	})
+
+	t.Run("deve retornar erro para versão desatualizada", func(t *testing.T) {
+		account := newTestAccount(userID)
+		mockRepo := &MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return account, nil
+			},
+			UpdateAccountFunc: func(ctx context.Context, account *Account) (bool, error) {
+				t.Error("não deveria gravar uma versão desatualizada")
+				return false, nil
+			},
+		}
+
+		service := NewService(mockRepo, nil, 80, nil)
+		name := "Outro Nome"
+
+		_, err := service.UpdateAccount(ctx, account.ID.String(), userID.String(), 2, UpdateAccountRequest{Name: &name})
+		if !errors.Is(err, ErrVersionMismatch) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrVersionMismatch, err)
+		}
+	})
+
+	t.Run("deve retornar erro se outra escrita chegou antes", func(t *testing.T) {
+		account := newTestAccount(userID)
+		mockRepo := &MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return account, nil
+			},
+			UpdateAccountFunc: func(ctx context.Context, account *Account) (bool, error) {
+				return false, nil
+			},
+		}
+
+		service := NewService(mockRepo, nil, 80, nil)
+
+		_, err := service.ArchiveAccount(ctx, account.ID.String(), userID.String(), 3)
+		if !errors.Is(err, ErrVersionMismatch) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrVersionMismatch, err)
+		}
+	})
+
+	t.Run("deve negar acesso à conta de outro usuário", func(t *testing.T) {
+		account := newTestAccount(uuid.New())
+		mockRepo := &MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return account, nil
+			},
+		}
+
+		service := NewService(mockRepo, nil, 80, nil)
+		name := "Invasor"
+
+		_, err := service.UpdateAccount(ctx, account.ID.String(), userID.String(), 3, UpdateAccountRequest{Name: &name})
+		if !errors.Is(err, ErrForbidden) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrForbidden, err)
+		}
+	})
+}
+
+func TestService_DeleteAccount(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("deve recusar conta com saldo", func(t *testing.T) {
+		account := newTestAccount(userID)
+		account.Balance = 1500
+		mockRepo := &MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return account, nil
+			},
+		}
+
+		service := NewService(mockRepo, nil, 80, nil)
+
+		err := service.DeleteAccount(ctx, account.ID.String(), userID.String(), 3)
+		if !errors.Is(err, ErrAccountNotEmpty) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrAccountNotEmpty, err)
+		}
+	})
+
+	t.Run("deve remover conta zerada", func(t *testing.T) {
+		account := newTestAccount(userID)
+		deleted := false
+		mockRepo := &MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return account, nil
+			},
+			DeleteAccountFunc: func(ctx context.Context, id uuid.UUID, version int64) (bool, error) {
+				deleted = id == account.ID && version == 3
+				return true, nil
+			},
+		}
+
+		service := NewService(mockRepo, nil, 80, nil)
+
+		if err := service.DeleteAccount(ctx, account.ID.String(), userID.String(), 3); err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if !deleted {
+			t.Error("a conta não foi removida no repositório")
+		}
+	})
+}
+
+func TestService_AccountTypeRules(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("deve recusar saldo inicial negativo em conta de ativo", func(t *testing.T) {
+		service := NewService(&MockRepository{
+			CreateAccountFunc: func(ctx context.Context, account *Account) error {
+				t.Error("não deveria criar a conta")
+				return nil
+			},
+		}, nil, 80, nil)
+
+		_, err := service.CreateAccount(ctx, CreateAccountRequest{
+			Name:           "Poupança",
+			Type:           AccountTypeSavings,
+			Currency:       "BRL",
+			InitialBalance: money.Money{Amount: -100},
+		}, userID.String())
+		if !errors.Is(err, ErrNegativeBalanceNotAllowed) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrNegativeBalanceNotAllowed, err)
+		}
+	})
+
+	t.Run("deve aceitar saldo inicial negativo em cartão de crédito", func(t *testing.T) {
+		service := NewService(&MockRepository{}, nil, 80, nil)
+
+		resp, err := service.CreateAccount(ctx, CreateAccountRequest{
+			Name:           "Cartão",
+			Type:           AccountTypeCreditCard,
+			Currency:       "BRL",
+			InitialBalance: money.Money{Amount: -100},
+			CreditLimit:    money.Money{Amount: 1000},
+		}, userID.String())
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if resp.Balance.Amount != -100 || resp.AvailableBalance.Amount != 900 || resp.Balance.Currency != "BRL" {
+			t.Errorf("saldos incorretos: balance=%v available=%v", resp.Balance, resp.AvailableBalance)
+		}
+	})
+
+	t.Run("não deve mudar para um tipo que não aceita o saldo atual", func(t *testing.T) {
+		account := newTestAccount(userID)
+		account.Type = AccountTypeLoan
+		account.Balance = -5000
+		service := NewService(&MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return account, nil
+			},
+		}, nil, 80, nil)
+		newType := AccountTypeAsset
+
+		_, err := service.UpdateAccount(ctx, account.ID.String(), userID.String(), 3, UpdateAccountRequest{Type: &newType})
+		if !errors.Is(err, ErrNegativeBalanceNotAllowed) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrNegativeBalanceNotAllowed, err)
+		}
+	})
+}
+
+func TestService_CreditLimits(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	newCreditCard := func() *Account {
+		account := newTestAccount(userID)
+		account.Type = AccountTypeCreditCard
+		account.CreditLimit = 10000
+		account.Balance = -7000
+		return account
+	}
+
+	// adjustInMemory aplica o delta na própria conta, como o UPDATE do banco
+	adjustInMemory := func(account *Account) func(ctx context.Context, id uuid.UUID, delta int64, occurredAt time.Time) (*Account, error) {
+		return func(ctx context.Context, id uuid.UUID, delta int64, occurredAt time.Time) (*Account, error) {
+			updated := *account
+			updated.Balance += delta
+			return &updated, nil
+		}
+	}
+
+	t.Run("deve contar as retenções no saldo disponível", func(t *testing.T) {
+		account := newCreditCard()
+		service := NewService(&MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return account, nil
+			},
+			AdjustBalanceFunc: adjustInMemory(account),
+			AdjustHeldFunc: func(ctx context.Context, id uuid.UUID, delta int64) (*Account, error) {
+				account.Held += delta
+				updated := *account
+				return &updated, nil
+			},
+		}, nil, 80, nil)
+
+		updated, err := service.AdjustHeld(ctx, account.ID, 2000)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		// Limite 100,00, saldo -70,00 e 20,00 retidos
+		if updated.Balance != -7000 || updated.AvailableBalance() != 1000 {
+			t.Errorf("saldos incorretos: balance=%d available=%d", updated.Balance, updated.AvailableBalance())
+		}
+
+		if _, err := service.AdjustHeld(ctx, account.ID, 1001); !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInsufficientFunds, err)
+		}
+		if _, err := service.AdjustBalance(ctx, account.ID, -1500, time.Now()); !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInsufficientFunds, err)
+		}
+		if updated, err := service.AdjustHeld(ctx, account.ID, -2000); err != nil || updated.AvailableBalance() != 3000 {
+			t.Errorf("esperava a retenção liberada, obteve %+v (%v)", updated, err)
+		}
+	})
+
+	t.Run("deve recusar saldo inicial além do limite", func(t *testing.T) {
+		service := NewService(&MockRepository{}, nil, 80, nil)
+
+		_, err := service.CreateAccount(ctx, CreateAccountRequest{
+			Name:           "Cartão",
+			Type:           AccountTypeCreditCard,
+			Currency:       "BRL",
+			InitialBalance: money.Money{Amount: -1500},
+			CreditLimit:    money.Money{Amount: 1000},
+		}, userID.String())
+		if !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInsufficientFunds, err)
+		}
+	})
+
+	t.Run("deve recusar limite em tipo que não usa crédito", func(t *testing.T) {
+		service := NewService(&MockRepository{}, nil, 80, nil)
+
+		_, err := service.CreateAccount(ctx, CreateAccountRequest{
+			Name:        "Poupança",
+			Type:        AccountTypeSavings,
+			Currency:    "BRL",
+			CreditLimit: money.Money{Amount: 1000},
+		}, userID.String())
+		if !errors.Is(err, ErrCreditLimitNotAllowed) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrCreditLimitNotAllowed, err)
+		}
+	})
+
+	t.Run("deve recusar valores em moeda diferente da conta", func(t *testing.T) {
+		service := NewService(&MockRepository{}, nil, 80, nil)
+
+		_, err := service.CreateAccount(ctx, CreateAccountRequest{
+			Name:           "Conta",
+			Type:           AccountTypeChecking,
+			Currency:       "BRL",
+			InitialBalance: money.New(1000, "USD"),
+		}, userID.String())
+		if !errors.Is(err, money.ErrCurrencyMismatch) {
+			t.Errorf("esperava o erro %v, mas obteve %v", money.ErrCurrencyMismatch, err)
+		}
+	})
+
+	t.Run("deve permitir cheque especial até o limite", func(t *testing.T) {
+		account := newTestAccount(userID)
+		account.OverdraftAllowed = true
+		account.CreditLimit = 500
+		service := NewService(&MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return account, nil
+			},
+			AdjustBalanceFunc: adjustInMemory(account),
+		}, nil, 80, nil)
+
+		updated, err := service.AdjustBalance(ctx, account.ID, -500, time.Now())
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if updated.AvailableBalance() != 0 {
+			t.Errorf("esperava saldo disponível 0, obteve %d", updated.AvailableBalance())
+		}
+
+		_, err = service.AdjustBalance(ctx, account.ID, -501, time.Now())
+		if !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInsufficientFunds, err)
+		}
+	})
+
+	t.Run("deve recusar débito além do limite sem tocar no banco", func(t *testing.T) {
+		account := newCreditCard()
+		service := NewService(&MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return account, nil
+			},
+			AdjustBalanceFunc: func(ctx context.Context, id uuid.UUID, delta int64, occurredAt time.Time) (*Account, error) {
+				t.Error("não deveria ajustar o saldo")
+				return nil, nil
+			},
+		}, nil, 80, nil)
+
+		_, err := service.AdjustBalance(ctx, account.ID, -3001, time.Now())
+		if !errors.Is(err, ErrInsufficientFunds) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInsufficientFunds, err)
+		}
+	})
+
+	t.Run("deve recusar movimentação em conta arquivada", func(t *testing.T) {
+		account := newCreditCard()
+		archivedAt := time.Now()
+		account.ArchivedAt = &archivedAt
+		service := NewService(&MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return account, nil
+			},
+		}, nil, 80, nil)
+
+		_, err := service.AdjustBalance(ctx, account.ID, 100, time.Now())
+		if !errors.Is(err, ErrAccountArchived) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrAccountArchived, err)
+		}
+	})
+
+	t.Run("deve notificar só quando o uso cruza o threshold", func(t *testing.T) {
+		account := newCreditCard()
+		publisher := &MockPublisher{}
+		service := NewService(&MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return account, nil
+			},
+			AdjustBalanceFunc: func(ctx context.Context, id uuid.UUID, delta int64, occurredAt time.Time) (*Account, error) {
+				account.Balance += delta
+				updated := *account
+				return &updated, nil
+			},
+		}, publisher, 80, nil)
+
+		// 70% -> 75%: abaixo do threshold
+		if _, err := service.AdjustBalance(ctx, account.ID, -500, time.Now()); err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		// 75% -> 85%: cruza
+		if _, err := service.AdjustBalance(ctx, account.ID, -1000, time.Now()); err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		// 85% -> 90%: já estava acima
+		if _, err := service.AdjustBalance(ctx, account.ID, -500, time.Now()); err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+
+		if len(publisher.Events) != 1 {
+			t.Fatalf("esperava 1 evento, obteve %d", len(publisher.Events))
+		}
+		event := publisher.Events[0]
+		if event.Type != EventCreditUtilizationCrossed {
+			t.Errorf("tipo de evento incorreto: %s", event.Type)
+		}
+		payload, ok := event.Payload.(CreditUtilizationEvent)
+		if !ok || payload.Utilization != 85 {
+			t.Errorf("payload incorreto: %+v", event.Payload)
+		}
+	})
+}
+
+func TestService_ListAccounts(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	base := time.Now().Add(-time.Hour)
+
+	accounts := make([]Account, 3)
+	for i := range accounts {
+		accounts[i] = *newTestAccount(userID)
+		accounts[i].CreatedAt = base.Add(-time.Duration(i) * time.Minute)
+	}
+
+	t.Run("deve repassar filtro e paginação e devolver o próximo cursor", func(t *testing.T) {
+		page, err := pagination.Parse(url.Values{"limit": {"2"}}, AccountSortFields, DefaultAccountSort)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		filter := ListAccountsFilter{Type: AccountTypeChecking, Archived: ArchivedExclude}
+
+		mockRepo := &MockRepository{
+			ListAccountsByUserIDFunc: func(ctx context.Context, id uuid.UUID, f ListAccountsFilter, p pagination.Params) ([]Account, error) {
+				if id != userID || f != filter || p.FetchLimit() != 3 {
+					t.Errorf("argumentos inesperados: %v %+v %+v", id, f, p)
+				}
+				return accounts, nil
+			},
+		}
+		service := NewService(mockRepo, nil, 80, nil)
+
+		result, err := service.ListAccounts(ctx, userID.String(), filter, page)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if len(result.Items) != 2 {
+			t.Errorf("esperava 2 contas, mas obteve %d", len(result.Items))
+		}
+		if result.NextCursor == nil {
+			t.Fatal("esperava um próximo cursor")
+		}
+
+		next, err := pagination.Parse(url.Values{"cursor": {*result.NextCursor}}, AccountSortFields, DefaultAccountSort)
+		if err != nil {
+			t.Fatalf("o cursor devolvido deveria ser válido: %v", err)
+		}
+		if _, args := next.Where("id", 1); args[1] != accounts[1].ID.String() {
+			t.Errorf("o cursor deveria apontar para a última conta da página, obteve %v", args)
+		}
+	})
+
+	t.Run("deve devolver lista vazia sem cursor", func(t *testing.T) {
+		page, _ := pagination.Parse(url.Values{}, AccountSortFields, DefaultAccountSort)
+		service := NewService(&MockRepository{}, nil, 80, nil)
+
+		result, err := service.ListAccounts(ctx, userID.String(), ListAccountsFilter{}, page)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if result.Items == nil || len(result.Items) != 0 || result.NextCursor != nil {
+			t.Errorf("esperava página vazia, obteve %+v", result)
+		}
+	})
+}
+
+// membersRepo simula uma conta compartilhada com os membros informados
+func membersRepo(account *Account, members map[uuid.UUID]MemberRole) *MockRepository {
+	return &MockRepository{
+		GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+			return account, nil
+		},
+		GetMemberFunc: func(ctx context.Context, accountID uuid.UUID, userID uuid.UUID) (*AccountMember, error) {
+			role, ok := members[userID]
+			if !ok {
+				return nil, nil
+			}
+			return &AccountMember{AccountID: accountID, UserID: userID, Role: role}, nil
+		},
+	}
+}
+
+func TestService_SharedAccounts(t *testing.T) {
+	ctx := context.Background()
+	ownerID, editorID, viewerID, outsiderID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	members := map[uuid.UUID]MemberRole{ownerID: RoleOwner, editorID: RoleEditor, viewerID: RoleViewer}
+
+	t.Run("membros leem a conta e quem não é membro não", func(t *testing.T) {
+		account := newTestAccount(ownerID)
+		service := NewService(membersRepo(account, members), nil, 80, nil)
+
+		if _, err := service.GetAccount(ctx, account.ID.String(), viewerID.String()); err != nil {
+			t.Errorf("viewer deveria ler a conta, mas obteve %v", err)
+		}
+		if _, err := service.GetAccount(ctx, account.ID.String(), outsiderID.String()); !errors.Is(err, ErrForbidden) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrForbidden, err)
+		}
+	})
+
+	t.Run("editor altera a conta e viewer não", func(t *testing.T) {
+		account := newTestAccount(ownerID)
+		service := NewService(membersRepo(account, members), nil, 80, nil)
+		name := "Conta da Casa"
+		req := UpdateAccountRequest{Name: &name}
+
+		if _, err := service.UpdateAccount(ctx, account.ID.String(), viewerID.String(), account.Version, req); !errors.Is(err, ErrForbidden) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrForbidden, err)
+		}
+		if _, err := service.UpdateAccount(ctx, account.ID.String(), editorID.String(), account.Version, req); err != nil {
+			t.Errorf("editor deveria alterar a conta, mas obteve %v", err)
+		}
+	})
+
+	t.Run("só owners arquivam e excluem", func(t *testing.T) {
+		account := newTestAccount(ownerID)
+		service := NewService(membersRepo(account, members), nil, 80, nil)
+
+		if _, err := service.ArchiveAccount(ctx, account.ID.String(), editorID.String(), account.Version); !errors.Is(err, ErrForbidden) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrForbidden, err)
+		}
+		if err := service.DeleteAccount(ctx, account.ID.String(), editorID.String(), account.Version); !errors.Is(err, ErrForbidden) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrForbidden, err)
+		}
+	})
+
+	t.Run("owner convida usuário cadastrado", func(t *testing.T) {
+		account := newTestAccount(ownerID)
+		invitedID := uuid.New()
+		mockRepo := membersRepo(account, members)
+		mockRepo.FindUserIDByEmailFunc = func(ctx context.Context, email string) (*uuid.UUID, error) {
+			return &invitedID, nil
+		}
+		var added *AccountMember
+		mockRepo.AddMemberFunc = func(ctx context.Context, member *AccountMember) error {
+			added = member
+			return nil
+		}
+		service := NewService(mockRepo, nil, 80, nil)
+
+		req := AddMemberRequest{Email: "parceira@example.com", Role: RoleEditor}
+		resp, err := service.AddMember(ctx, account.ID.String(), ownerID.String(), req)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if added == nil || added.UserID != invitedID || added.Role != RoleEditor || *added.InvitedBy != ownerID {
+			t.Errorf("membro gravado incorreto: %+v", added)
+		}
+		if resp.Email != req.Email {
+			t.Errorf("esperava o e-mail %s, mas obteve %s", req.Email, resp.Email)
+		}
+
+		if _, err := service.AddMember(ctx, account.ID.String(), editorID.String(), req); !errors.Is(err, ErrForbidden) {
+			t.Errorf("editor não deveria convidar: esperava %v, obteve %v", ErrForbidden, err)
+		}
+	})
+
+	t.Run("convite para e-mail desconhecido", func(t *testing.T) {
+		account := newTestAccount(ownerID)
+		service := NewService(membersRepo(account, members), nil, 80, nil)
+
+		req := AddMemberRequest{Email: "ninguem@example.com", Role: RoleViewer}
+		if _, err := service.AddMember(ctx, account.ID.String(), ownerID.String(), req); !errors.Is(err, ErrUserNotFound) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrUserNotFound, err)
+		}
+	})
+
+	t.Run("não deve rebaixar o último owner", func(t *testing.T) {
+		account := newTestAccount(ownerID)
+		mockRepo := membersRepo(account, members)
+		mockRepo.UpdateMemberRoleFunc = func(ctx context.Context, accountID uuid.UUID, userID uuid.UUID, role MemberRole) (bool, error) {
+			return false, nil
+		}
+		service := NewService(mockRepo, nil, 80, nil)
+
+		req := UpdateMemberRoleRequest{Role: RoleViewer}
+		if _, err := service.UpdateMemberRole(ctx, account.ID.String(), ownerID.String(), ownerID.String(), req); !errors.Is(err, ErrLastOwner) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrLastOwner, err)
+		}
+	})
+
+	t.Run("membro pode sair mas não remover outros", func(t *testing.T) {
+		account := newTestAccount(ownerID)
+		service := NewService(membersRepo(account, members), nil, 80, nil)
+
+		if err := service.RemoveMember(ctx, account.ID.String(), viewerID.String(), editorID.String()); !errors.Is(err, ErrForbidden) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrForbidden, err)
+		}
+		if err := service.RemoveMember(ctx, account.ID.String(), viewerID.String(), viewerID.String()); err != nil {
+			t.Errorf("viewer deveria poder sair, mas obteve %v", err)
+		}
+		if err := service.RemoveMember(ctx, account.ID.String(), ownerID.String(), outsiderID.String()); !errors.Is(err, ErrMemberNotFound) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrMemberNotFound, err)
+		}
+	})
+}
+
+func TestService_AccountHierarchy(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("deve montar a árvore com os totais da subárvore", func(t *testing.T) {
+		assets := newTestAccount(userID)
+		assets.Name = "Ativos"
+		bank := newTestAccount(userID)
+		bank.Name = "Bancos"
+		bank.ParentID = &assets.ID
+		checking := newTestAccount(userID)
+		checking.ParentID = &bank.ID
+		// Pai que o usuário não vê: a conta vira raiz
+		hiddenParent := uuid.New()
+		orphan := newTestAccount(userID)
+		orphan.ParentID = &hiddenParent
+
+		mockRepo := &MockRepository{
+			GetAccountTreeFunc: func(ctx context.Context, id uuid.UUID, includeArchived bool) ([]Account, []AccountRollup, error) {
+				return []Account{*assets, *bank, *checking, *orphan}, []AccountRollup{
+					{AccountID: assets.ID, Currency: "BRL", Balance: 1500},
+					{AccountID: assets.ID, Currency: "USD", Balance: 20},
+					{AccountID: bank.ID, Currency: "BRL", Balance: 1500},
+				}, nil
+			},
+		}
+		service := NewService(mockRepo, nil, 80, nil)
+
+		tree, err := service.GetAccountTree(ctx, userID.String(), false)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if len(tree) != 2 {
+			t.Fatalf("esperava 2 raízes, mas obteve %d", len(tree))
+		}
+		if tree[0].ID != assets.ID || len(tree[0].Rollup) != 2 {
+			t.Errorf("raiz incorreta: %+v", tree[0])
+		}
+		if len(tree[0].Children) != 1 || len(tree[0].Children[0].Children) != 1 || tree[0].Children[0].Children[0].ID != checking.ID {
+			t.Errorf("hierarquia incorreta: %+v", tree[0].Children)
+		}
+		if tree[1].ID != orphan.ID || tree[1].Rollup == nil || tree[1].Children == nil {
+			t.Errorf("conta com pai invisível deveria ser raiz com listas vazias: %+v", tree[1])
+		}
+	})
+
+	t.Run("deve recusar mover a conta para dentro dela mesma", func(t *testing.T) {
+		account := newTestAccount(userID)
+		service := NewService(membersRepo(account, map[uuid.UUID]MemberRole{userID: RoleOwner}), nil, 80, nil)
+
+		_, err := service.MoveAccount(ctx, account.ID.String(), userID.String(), account.Version, MoveAccountRequest{ParentID: &account.ID})
+		if !errors.Is(err, ErrHierarchyCycle) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrHierarchyCycle, err)
+		}
+	})
+
+	t.Run("deve repassar o ciclo detectado no banco", func(t *testing.T) {
+		accounts := map[uuid.UUID]*Account{}
+		child, parent := newTestAccount(userID), newTestAccount(userID)
+		accounts[child.ID], accounts[parent.ID] = child, parent
+		mockRepo := &MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return accounts[id], nil
+			},
+			MoveAccountFunc: func(ctx context.Context, account *Account) (bool, error) {
+				return false, ErrHierarchyCycle
+			},
+		}
+		service := NewService(mockRepo, nil, 80, nil)
+
+		_, err := service.MoveAccount(ctx, parent.ID.String(), userID.String(), parent.Version, MoveAccountRequest{ParentID: &child.ID})
+		if !errors.Is(err, ErrHierarchyCycle) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrHierarchyCycle, err)
+		}
+	})
+
+	t.Run("deve recusar pai que o usuário não pode editar", func(t *testing.T) {
+		account := newTestAccount(userID)
+		service := NewService(membersRepo(account, map[uuid.UUID]MemberRole{userID: RoleViewer}), nil, 80, nil)
+
+		req := CreateAccountRequest{Name: "Filha", Type: AccountTypeChecking, Currency: "BRL", ParentID: &account.ID}
+		if _, err := service.CreateAccount(ctx, req, userID.String()); !errors.Is(err, ErrInvalidParent) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidParent, err)
+		}
+	})
+
+	t.Run("não deve excluir conta com subcontas", func(t *testing.T) {
+		account := newTestAccount(userID)
+		mockRepo := membersRepo(account, map[uuid.UUID]MemberRole{userID: RoleOwner})
+		mockRepo.HasChildrenFunc = func(ctx context.Context, id uuid.UUID) (bool, error) {
+			return true, nil
+		}
+		service := NewService(mockRepo, nil, 80, nil)
+
+		if err := service.DeleteAccount(ctx, account.ID.String(), userID.String(), account.Version); !errors.Is(err, ErrAccountHasChildren) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrAccountHasChildren, err)
+		}
+	})
+}
+
+func TestService_PointInTimeBalances(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	at := time.Date(2025, 3, 31, 23, 59, 59, 999999000, time.UTC)
+
+	t.Run("deve consultar os movimentos até o instante pedido, inclusive", func(t *testing.T) {
+		account := newTestAccount(userID)
+		mockRepo := &MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return account, nil
+			},
+			BalancesBeforeFunc: func(ctx context.Context, ids []uuid.UUID, before time.Time) (map[uuid.UUID]int64, error) {
+				if !before.Equal(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)) {
+					t.Errorf("limite incorreto: %v", before)
+				}
+				return map[uuid.UUID]int64{account.ID: 4200}, nil
+			},
+		}
+		service := NewService(mockRepo, nil, 80, nil)
+
+		resp, err := service.GetBalanceAt(ctx, account.ID.String(), userID.String(), at)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if resp.Balance.Amount != 4200 || resp.Balance.Currency != account.Currency || !resp.At.Equal(at) {
+			t.Errorf("resposta incorreta: %+v", resp)
+		}
+	})
+
+	t.Run("as_of deve preencher o saldo histórico na listagem", func(t *testing.T) {
+		account := newTestAccount(userID)
+		mockRepo := &MockRepository{
+			ListAccountsByUserIDFunc: func(ctx context.Context, id uuid.UUID, f ListAccountsFilter, p pagination.Params) ([]Account, error) {
+				return []Account{*account}, nil
+			},
+			BalancesBeforeFunc: func(ctx context.Context, ids []uuid.UUID, before time.Time) (map[uuid.UUID]int64, error) {
+				return map[uuid.UUID]int64{account.ID: -150}, nil
+			},
+		}
+		service := NewService(mockRepo, nil, 80, nil)
+		page, _ := pagination.Parse(url.Values{}, AccountSortFields, DefaultAccountSort)
+
+		result, err := service.ListAccounts(ctx, userID.String(), ListAccountsFilter{AsOf: &at}, page)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		item := result.Items[0]
+		if item.BalanceAsOf == nil || item.BalanceAsOf.Amount != -150 || item.AsOf == nil {
+			t.Errorf("saldo histórico não preenchido: %+v", item)
+		}
+	})
+
+	t.Run("snapshots seguem para as próximas contas quando uma falha", func(t *testing.T) {
+		ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+		mockRepo := &MockRepository{
+			ListAccountsWithoutSnapshotFunc: func(ctx context.Context, asOf time.Time) ([]uuid.UUID, error) {
+				return ids, nil
+			},
+			CreateBalanceSnapshotFunc: func(ctx context.Context, accountID uuid.UUID, asOf time.Time) error {
+				if accountID == ids[1] {
+					return errors.New("falha simulada")
+				}
+				return nil
+			},
+		}
+		service := NewService(mockRepo, nil, 80, nil)
+
+		taken, err := service.TakeBalanceSnapshots(ctx, at)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if taken != 2 {
+			t.Errorf("esperava 2 snapshots, mas obteve %d", taken)
+		}
+	})
+
+	t.Run("não deve excluir conta com histórico", func(t *testing.T) {
+		account := newTestAccount(userID)
+		mockRepo := &MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return account, nil
+			},
+			HasMovementsFunc: func(ctx context.Context, id uuid.UUID) (bool, error) {
+				return true, nil
+			},
+		}
+		service := NewService(mockRepo, nil, 80, nil)
+
+		if err := service.DeleteAccount(ctx, account.ID.String(), userID.String(), account.Version); !errors.Is(err, ErrAccountNotEmpty) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrAccountNotEmpty, err)
+		}
+	})
+}
+
+func TestParsePointInTime(t *testing.T) {
+	day, err := parsePointInTime("2025-03-31")
+	if err != nil || !day.Equal(time.Date(2025, 3, 31, 23, 59, 59, 999999000, time.UTC)) {
+		t.Errorf("data deveria valer pelo fim do dia: %v %v", day, err)
+	}
+
+	ts, err := parsePointInTime("2025-03-31T12:00:00-03:00")
+	if err != nil || !ts.Equal(time.Date(2025, 3, 31, 15, 0, 0, 0, time.UTC)) {
+		t.Errorf("timestamp incorreto: %v %v", ts, err)
+	}
+
+	if _, err := parsePointInTime("31/03/2025"); err == nil {
+		t.Error("esperava erro para formato inválido")
+	}
+}
+
+func TestService_GetSummary(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	rateDate := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+	rates := &staticRates{rates: map[string]float64{"BRL": 1, "USD": 5, "EUR": 6}, date: rateDate}
+
+	mockRepo := &MockRepository{
+		SumBalancesFunc: func(ctx context.Context, id uuid.UUID) ([]BalanceTotal, error) {
+			return []BalanceTotal{
+				{Type: AccountTypeChecking, Currency: "BRL", Balance: 100000, Accounts: 2},
+				{Type: AccountTypeChecking, Currency: "USD", Balance: 1000, Accounts: 1},
+				{Type: AccountTypeCreditCard, Currency: "BRL", Balance: -30000, Accounts: 1},
+				{Type: AccountTypeSavings, Currency: "EUR", Balance: 500, Accounts: 1},
+			}, nil
+		},
+	}
+
+	t.Run("deve consolidar por tipo e natureza na moeda pedida", func(t *testing.T) {
+		service := NewService(mockRepo, nil, 80, rates)
+
+		summary, err := service.GetSummary(ctx, userID.String(), "BRL")
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		// 100000 + 1000*5 + 500*6
+		if summary.Assets.Amount != 108000 || summary.Liabilities.Amount != 30000 || summary.NetWorth.Amount != 78000 {
+			t.Errorf("totais incorretos: %+v", summary)
+		}
+		if len(summary.ByType) != 3 || summary.ByType[0].Type != AccountTypeChecking || summary.ByType[0].Total.Amount != 105000 || summary.ByType[0].Accounts != 3 {
+			t.Errorf("totais por tipo incorretos: %+v", summary.ByType)
+		}
+		if len(summary.Conversions) != 2 || !summary.Conversions[0].RateDate.Equal(rateDate) {
+			t.Errorf("conversões incorretas: %+v", summary.Conversions)
+		}
+	})
+
+	t.Run("deve converter entre moedas que não são a base", func(t *testing.T) {
+		service := NewService(mockRepo, nil, 80, rates)
+
+		summary, err := service.GetSummary(ctx, userID.String(), "USD")
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		// 100000/5 + 1000 + 500*6/5 = 21600 em ativos; 6000 em passivos
+		if summary.Assets.Amount != 21600 || summary.Liabilities.Amount != 6000 {
+			t.Errorf("totais incorretos: %+v", summary)
+		}
+	})
+
+	t.Run("deve falhar sem cotação para alguma moeda", func(t *testing.T) {
+		service := NewService(mockRepo, nil, 80, &staticRates{rates: map[string]float64{"BRL": 1}, date: rateDate})
+
+		if _, err := service.GetSummary(ctx, userID.String(), "BRL"); !errors.Is(err, ErrRateUnavailable) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrRateUnavailable, err)
+		}
+	})
+}
+
+// staticRates é um RateProvider com cotações fixas: rates diz quanto vale 1
+// unidade de cada moeda em BRL
+type staticRates struct {
+	rates map[string]float64
+	date  time.Time
+}
+
+func (p *staticRates) Rate(ctx context.Context, from string, to string, date time.Time) (*ExchangeRate, error) {
+	fromRate, okFrom := p.rates[from]
+	toRate, okTo := p.rates[to]
+	if !okFrom || !okTo {
+		return nil, fmt.Errorf("%w: %s to %s", ErrRateUnavailable, from, to)
+	}
+	return &ExchangeRate{From: from, To: to, Value: fromRate / toRate, Date: p.date}, nil
+}
+
+func TestService_IBAN(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("deve normalizar o IBAN na criação e recusar um inválido", func(t *testing.T) {
+		service := NewService(&MockRepository{}, nil, 80, nil)
+		req := CreateAccountRequest{Name: "Conta PJ", Type: AccountTypeChecking, Currency: "EUR"}
+
+		valid, invalid := "de89 3704 0044 0532 0130 00", "DE89370400440532013001"
+		req.IBAN = &valid
+		resp, err := service.CreateAccount(ctx, req, userID.String())
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if resp.IBAN == nil || *resp.IBAN != "DE89370400440532013000" {
+			t.Errorf("IBAN não foi normalizado: %v", resp.IBAN)
+		}
+
+		req.IBAN = &invalid
+		if _, err := service.CreateAccount(ctx, req, userID.String()); !errors.Is(err, iban.ErrInvalidIBAN) {
+			t.Errorf("esperava o erro %v, mas obteve %v", iban.ErrInvalidIBAN, err)
+		}
+	})
+
+	t.Run("deve achar a conta ativa pelo IBAN", func(t *testing.T) {
+		active := newTestAccount(userID)
+		archived := newTestAccount(userID)
+		archivedAt := time.Now().UTC()
+		archived.ArchivedAt = &archivedAt
+		accounts := map[uuid.UUID]*Account{active.ID: active, archived.ID: archived}
+
+		mockRepo := &MockRepository{
+			GetAccountByIDFunc: func(ctx context.Context, id uuid.UUID) (*Account, error) {
+				return accounts[id], nil
+			},
+			ListAccountsByIBANFunc: func(ctx context.Context, id uuid.UUID, value string) ([]Account, error) {
+				if value != "DE89370400440532013000" {
+					return nil, nil
+				}
+				return []Account{*archived, *active}, nil
+			},
+		}
+		service := NewService(mockRepo, nil, 80, nil)
+
+		found, err := service.FindAccountByIBAN(ctx, "DE89 3704 0044 0532 0130 00", userID.String(), RoleEditor)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if found.ID != active.ID {
+			t.Errorf("esperava a conta ativa %s, obteve %s", active.ID, found.ID)
+		}
+
+		if _, err := service.FindAccountByIBAN(ctx, "GB82WEST12345698765432", userID.String(), RoleEditor); !errors.Is(err, ErrAccountNotFound) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrAccountNotFound, err)
+		}
+		if _, err := service.FindAccountByIBAN(ctx, "DE89370400440532013000", uuid.NewString(), RoleEditor); !errors.Is(err, ErrForbidden) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrForbidden, err)
+		}
+	})
+
+	t.Run("deve recusar IBAN em mais de uma conta ativa", func(t *testing.T) {
+		first, second := newTestAccount(userID), newTestAccount(userID)
+		mockRepo := &MockRepository{
+			ListAccountsByIBANFunc: func(ctx context.Context, id uuid.UUID, value string) ([]Account, error) {
+				return []Account{*first, *second}, nil
+			},
+		}
+		service := NewService(mockRepo, nil, 80, nil)
+
+		if _, err := service.FindAccountByIBAN(ctx, "DE89370400440532013000", userID.String(), RoleViewer); !errors.Is(err, ErrAmbiguousIBAN) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrAmbiguousIBAN, err)
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/rs/zerolog/log"
@@ -150,3 +151,142 @@ func (h *Handler) GetMe(w http.ResponseWriter, r *http.Request) {
 
 	h.writeJSON(w, http.StatusOK, userResponse)
 }
+
+func (h *Handler) userIDFromContext(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(UserContextKey).(string)
+	if !ok {
+		log.Error().Msg("UserID não encontrado no contexto, middleware mal configurado")
+		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "internal server error"})
+		return "", false
+	}
+	return userID, true
+}
+
+func (h *Handler) writePasskeyError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrPasskeysDisabled):
+		h.writeJSON(w, http.StatusNotImplemented, map[string]string{"error": "passkeys are not enabled"})
+	case errors.Is(err, ErrPasskeySessionNotFound):
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "passkey ceremony not found or expired"})
+	case errors.Is(err, ErrPasskeyVerification), errors.Is(err, ErrInvalidCredentials):
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "passkey verification failed"})
+	case errors.Is(err, ErrPasskeyNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "passkey not found"})
+	case errors.Is(err, ErrUserNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+	default:
+		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": fallback})
+	}
+}
+
+func (h *Handler) BeginPasskeyRegistration(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	creation, err := h.service.BeginPasskeyRegistration(r.Context(), userID)
+	if err != nil {
+		h.writePasskeyError(w, err, "failed to begin passkey registration")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, creation)
+}
+
+func (h *Handler) FinishPasskeyRegistration(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	var req FinishPasskeyRegistrationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	passkey, err := h.service.FinishPasskeyRegistration(r.Context(), userID, req)
+	if err != nil {
+		h.writePasskeyError(w, err, "failed to register passkey")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, passkey)
+}
+
+func (h *Handler) BeginPasskeyLogin(w http.ResponseWriter, r *http.Request) {
+	var req BeginPasskeyLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	resp, err := h.service.BeginPasskeyLogin(r.Context(), req)
+	if err != nil {
+		h.writePasskeyError(w, err, "failed to begin passkey login")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) FinishPasskeyLogin(w http.ResponseWriter, r *http.Request) {
+	var req FinishPasskeyLoginRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	resp, err := h.service.FinishPasskeyLogin(r.Context(), req)
+	if err != nil {
+		h.writePasskeyError(w, err, "passkey login failed")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, resp)
+}
+
+func (h *Handler) ListPasskeys(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	passkeys, err := h.service.ListPasskeys(r.Context(), userID)
+	if err != nil {
+		h.writePasskeyError(w, err, "failed to retrieve passkeys")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, passkeys)
+}
+
+func (h *Handler) DeletePasskey(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.userIDFromContext(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeletePasskey(r.Context(), userID, chi.URLParam(r, "passkeyID")); err != nil {
+		h.writePasskeyError(w, err, "failed to delete passkey")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
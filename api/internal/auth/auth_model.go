@@ -1,6 +1,12 @@
 package auth
 
-import "github.com/google/uuid"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/google/uuid"
+)
 
 type RegisterRequest struct {
 	FirstName string `json:"first_name" validate:"required"`
@@ -33,3 +39,47 @@ type UserResponse struct {
 	LastName  string    `json:"last_name"`
 	Email     string    `json:"email"`
 }
+
+// Passkey é uma credencial WebAuthn registrada por um usuário
+type Passkey struct {
+	ID              uuid.UUID
+	UserID          uuid.UUID
+	Name            string
+	CredentialID    []byte
+	PublicKey       []byte
+	AttestationType string
+	AAGUID          []byte
+	SignCount       uint32
+	Transports      []string
+	Flags           uint8
+	CloneWarning    bool // o contador já deixou de avançar num login
+	CreatedAt       time.Time
+	LastUsedAt      *time.Time
+}
+
+type PasskeyResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Transports []string   `json:"transports"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+type FinishPasskeyRegistrationRequest struct {
+	Name       string          `json:"name" validate:"required,max=100"`
+	Credential json.RawMessage `json:"credential" validate:"required"`
+}
+
+type BeginPasskeyLoginRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type BeginPasskeyLoginResponse struct {
+	SessionID string                        `json:"session_id"`
+	Options   *protocol.CredentialAssertion `json:"options"`
+}
+
+type FinishPasskeyLoginRequest struct {
+	SessionID  string          `json:"session_id" validate:"required"`
+	Credential json.RawMessage `json:"credential" validate:"required"`
+}
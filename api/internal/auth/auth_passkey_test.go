@@ -0,0 +1,418 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "http://localhost"
+)
+
+// softAuthenticator simula um autenticador de plataforma com uma chave P-256 em memória
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	signCount    uint32
+	// backupEligible e backedUp ligam as flags BE e BS, como numa passkey sincronizada
+	backupEligible bool
+	backedUp       bool
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("falha ao gerar chave: %v", err)
+	}
+	credentialID := make([]byte, 32)
+	if _, err := rand.Read(credentialID); err != nil {
+		t.Fatalf("falha ao gerar credential ID: %v", err)
+	}
+	return &softAuthenticator{key: key, credentialID: credentialID}
+}
+
+func b64(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func (a *softAuthenticator) clientData(t *testing.T, ceremony string, challenge []byte) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": b64(challenge),
+		"origin":    testOrigin,
+	})
+	if err != nil {
+		t.Fatalf("falha ao montar clientDataJSON: %v", err)
+	}
+	return data
+}
+
+func (a *softAuthenticator) authData(flags byte) []byte {
+	if a.backupEligible {
+		flags |= 0x08
+	}
+	if a.backedUp {
+		flags |= 0x10
+	}
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	return binary.BigEndian.AppendUint32(data, a.signCount)
+}
+
+// register responde a um navigator.credentials.create() com atestação "none"
+func (a *softAuthenticator) register(t *testing.T, creation *protocol.CredentialCreation) json.RawMessage {
+	t.Helper()
+
+	coseKey, err := webauthncbor.Marshal(map[int]interface{}{
+		1:  2,  // kty: EC2
+		3:  -7, // alg: ES256
+		-1: 1,  // crv: P-256
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatalf("falha ao codificar chave COSE: %v", err)
+	}
+
+	// UP | UV | AT
+	authData := a.authData(0x01 | 0x04 | 0x40)
+	authData = append(authData, make([]byte, 16)...) // AAGUID zerado
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(a.credentialID)))
+	authData = append(authData, a.credentialID...)
+	authData = append(authData, coseKey...)
+
+	attestationObject, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": authData,
+	})
+	if err != nil {
+		t.Fatalf("falha ao codificar attestationObject: %v", err)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"id":    b64(a.credentialID),
+		"rawId": b64(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]interface{}{
+			"clientDataJSON":    b64(a.clientData(t, "webauthn.create", creation.Response.Challenge)),
+			"attestationObject": b64(attestationObject),
+			"transports":        []string{"internal"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("falha ao montar resposta de registro: %v", err)
+	}
+	return body
+}
+
+// assert responde a um navigator.credentials.get()
+func (a *softAuthenticator) assert(t *testing.T, assertion *protocol.CredentialAssertion, userHandle []byte) json.RawMessage {
+	t.Helper()
+
+	a.signCount++
+	authData := a.authData(0x01 | 0x04)
+	clientData := a.clientData(t, "webauthn.get", assertion.Response.Challenge)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatalf("falha ao assinar assertion: %v", err)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"id":    b64(a.credentialID),
+		"rawId": b64(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]interface{}{
+			"clientDataJSON":    b64(clientData),
+			"authenticatorData": b64(authData),
+			"signature":         b64(signature),
+			"userHandle":        b64(userHandle),
+		},
+	})
+	if err != nil {
+		t.Fatalf("falha ao montar resposta de login: %v", err)
+	}
+	return body
+}
+
+type memorySessionStore struct {
+	sessions map[string]webauthn.SessionData
+}
+
+func (m *memorySessionStore) Save(ctx context.Context, key string, session *webauthn.SessionData, ttl time.Duration) error {
+	m.sessions[key] = *session
+	return nil
+}
+
+func (m *memorySessionStore) Pop(ctx context.Context, key string) (*webauthn.SessionData, error) {
+	session, ok := m.sessions[key]
+	if !ok {
+		return nil, nil
+	}
+	delete(m.sessions, key)
+	return &session, nil
+}
+
+// newPasskeyTestService monta o serviço com um repositório em memória para as passkeys
+func newPasskeyTestService(t *testing.T, user *User) (Service, *[]Passkey) {
+	t.Helper()
+
+	wa, err := webauthn.New(&webauthn.Config{
+		RPID:          testRPID,
+		RPDisplayName: "Fincore",
+		RPOrigins:     []string{testOrigin},
+	})
+	if err != nil {
+		t.Fatalf("falha ao configurar webauthn: %v", err)
+	}
+
+	passkeys := &[]Passkey{}
+	mockRepo := &MockRepository{
+		GetUserByIDFunc: func(ctx context.Context, id uuid.UUID) (*User, error) {
+			if id == user.ID {
+				return user, nil
+			}
+			return nil, nil
+		},
+		GetUserByEmailFunc: func(ctx context.Context, email string) (*User, error) {
+			if email == user.Email {
+				return user, nil
+			}
+			return nil, nil
+		},
+		CreatePasskeyFunc: func(ctx context.Context, passkey *Passkey) error {
+			*passkeys = append(*passkeys, *passkey)
+			return nil
+		},
+		ListPasskeysByUserIDFunc: func(ctx context.Context, userID uuid.UUID) ([]Passkey, error) {
+			return *passkeys, nil
+		},
+		UpdatePasskeyUsageFunc: func(ctx context.Context, passkey *Passkey) error {
+			for i := range *passkeys {
+				if (*passkeys)[i].ID == passkey.ID {
+					(*passkeys)[i] = *passkey
+				}
+			}
+			return nil
+		},
+	}
+
+	store := &memorySessionStore{sessions: map[string]webauthn.SessionData{}}
+	return NewService(mockRepo, "test_secret", wa, store), passkeys
+}
+
+func registerPasskey(t *testing.T, svc Service, user *User, authenticator *softAuthenticator) *PasskeyResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	creation, err := svc.BeginPasskeyRegistration(ctx, user.ID.String())
+	if err != nil {
+		t.Fatalf("esperava nenhum erro no begin do registro, mas obteve %v", err)
+	}
+
+	passkey, err := svc.FinishPasskeyRegistration(ctx, user.ID.String(), FinishPasskeyRegistrationRequest{
+		Name:       "Notebook",
+		Credential: authenticator.register(t, creation),
+	})
+	if err != nil {
+		t.Fatalf("esperava nenhum erro no finish do registro, mas obteve %v", err)
+	}
+	return passkey
+}
+
+func TestService_Passkeys(t *testing.T) {
+	ctx := context.Background()
+	user := &User{
+		ID:        uuid.New(),
+		FirstName: "Admin",
+		LastName:  "Financeiro",
+		Email:     "admin@exemplo.com",
+	}
+
+	t.Run("deve registrar uma passkey e logar com ela", func(t *testing.T) {
+		svc, passkeys := newPasskeyTestService(t, user)
+		authenticator := newSoftAuthenticator(t)
+
+		passkey := registerPasskey(t, svc, user, authenticator)
+		if passkey.Name != "Notebook" {
+			t.Errorf("nome da passkey incorreto: %s", passkey.Name)
+		}
+		if len(*passkeys) != 1 {
+			t.Fatalf("esperava 1 passkey salva, obteve %d", len(*passkeys))
+		}
+		if (*passkeys)[0].Transports[0] != "internal" {
+			t.Errorf("transports não foram salvos: %v", (*passkeys)[0].Transports)
+		}
+
+		begin, err := svc.BeginPasskeyLogin(ctx, BeginPasskeyLoginRequest{Email: user.Email})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro no begin do login, mas obteve %v", err)
+		}
+
+		resp, err := svc.FinishPasskeyLogin(ctx, FinishPasskeyLoginRequest{
+			SessionID:  begin.SessionID,
+			Credential: authenticator.assert(t, begin.Options, user.ID[:]),
+		})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro no finish do login, mas obteve %v", err)
+		}
+
+		token, err := jwt.Parse(resp.AccessToken, func(token *jwt.Token) (interface{}, error) {
+			return []byte("test_secret"), nil
+		})
+		if err != nil || !token.Valid {
+			t.Fatalf("token inválido: %v", err)
+		}
+		if claims := token.Claims.(jwt.MapClaims); claims["sub"] != user.ID.String() {
+			t.Error("a claim 'sub' do token está incorreta")
+		}
+
+		if (*passkeys)[0].SignCount != 1 || (*passkeys)[0].LastUsedAt == nil {
+			t.Errorf("uso da passkey não foi registrado: %+v", (*passkeys)[0])
+		}
+	})
+
+	t.Run("não deve aceitar a mesma sessão de login duas vezes", func(t *testing.T) {
+		svc, _ := newPasskeyTestService(t, user)
+		authenticator := newSoftAuthenticator(t)
+		registerPasskey(t, svc, user, authenticator)
+
+		begin, err := svc.BeginPasskeyLogin(ctx, BeginPasskeyLoginRequest{Email: user.Email})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro no begin do login, mas obteve %v", err)
+		}
+		req := FinishPasskeyLoginRequest{
+			SessionID:  begin.SessionID,
+			Credential: authenticator.assert(t, begin.Options, user.ID[:]),
+		}
+		if _, err := svc.FinishPasskeyLogin(ctx, req); err != nil {
+			t.Fatalf("esperava nenhum erro no primeiro login, mas obteve %v", err)
+		}
+
+		_, err = svc.FinishPasskeyLogin(ctx, req)
+		if !errors.Is(err, ErrPasskeySessionNotFound) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrPasskeySessionNotFound, err)
+		}
+	})
+
+	t.Run("deve rejeitar assinatura de outro autenticador", func(t *testing.T) {
+		svc, _ := newPasskeyTestService(t, user)
+		authenticator := newSoftAuthenticator(t)
+		registerPasskey(t, svc, user, authenticator)
+
+		begin, err := svc.BeginPasskeyLogin(ctx, BeginPasskeyLoginRequest{Email: user.Email})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro no begin do login, mas obteve %v", err)
+		}
+
+		impostor := newSoftAuthenticator(t)
+		impostor.credentialID = authenticator.credentialID
+
+		_, err = svc.FinishPasskeyLogin(ctx, FinishPasskeyLoginRequest{
+			SessionID:  begin.SessionID,
+			Credential: impostor.assert(t, begin.Options, user.ID[:]),
+		})
+		if !errors.Is(err, ErrPasskeyVerification) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrPasskeyVerification, err)
+		}
+	})
+
+	t.Run("deve gravar as flags atualizadas no login", func(t *testing.T) {
+		svc, passkeys := newPasskeyTestService(t, user)
+		authenticator := newSoftAuthenticator(t)
+		authenticator.backupEligible = true
+		registerPasskey(t, svc, user, authenticator)
+
+		// A passkey foi sincronizada com a nuvem depois do registro
+		authenticator.backedUp = true
+		begin, err := svc.BeginPasskeyLogin(ctx, BeginPasskeyLoginRequest{Email: user.Email})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro no begin do login, mas obteve %v", err)
+		}
+		_, err = svc.FinishPasskeyLogin(ctx, FinishPasskeyLoginRequest{
+			SessionID:  begin.SessionID,
+			Credential: authenticator.assert(t, begin.Options, user.ID[:]),
+		})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro no finish do login, mas obteve %v", err)
+		}
+
+		flags := protocol.AuthenticatorFlags((*passkeys)[0].Flags)
+		if !flags.HasBackupState() || !flags.HasUserVerified() || (*passkeys)[0].SignCount != 1 {
+			t.Errorf("credencial não foi atualizada: %+v", (*passkeys)[0])
+		}
+	})
+
+	t.Run("deve rejeitar contador de assinatura que não avança", func(t *testing.T) {
+		svc, passkeys := newPasskeyTestService(t, user)
+		authenticator := newSoftAuthenticator(t)
+		registerPasskey(t, svc, user, authenticator)
+
+		for i := 0; i < 2; i++ {
+			begin, err := svc.BeginPasskeyLogin(ctx, BeginPasskeyLoginRequest{Email: user.Email})
+			if err != nil {
+				t.Fatalf("esperava nenhum erro no begin do login, mas obteve %v", err)
+			}
+			credential := authenticator.assert(t, begin.Options, user.ID[:])
+			_, err = svc.FinishPasskeyLogin(ctx, FinishPasskeyLoginRequest{SessionID: begin.SessionID, Credential: credential})
+
+			if i == 0 && err != nil {
+				t.Fatalf("esperava nenhum erro no primeiro login, mas obteve %v", err)
+			}
+			if i == 1 && !errors.Is(err, ErrPasskeyVerification) {
+				t.Errorf("esperava o erro %v, mas obteve %v", ErrPasskeyVerification, err)
+			}
+			// Simula um clone que reutiliza o mesmo contador
+			authenticator.signCount--
+		}
+
+		if !(*passkeys)[0].CloneWarning {
+			t.Error("esperava o alerta de clone gravado na passkey")
+		}
+
+		// Mesmo com o contador avançando de novo, a passkey continua bloqueada
+		authenticator.signCount += 5
+		begin, err := svc.BeginPasskeyLogin(ctx, BeginPasskeyLoginRequest{Email: user.Email})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro no begin do login, mas obteve %v", err)
+		}
+		_, err = svc.FinishPasskeyLogin(ctx, FinishPasskeyLoginRequest{
+			SessionID:  begin.SessionID,
+			Credential: authenticator.assert(t, begin.Options, user.ID[:]),
+		})
+		if !errors.Is(err, ErrPasskeyVerification) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrPasskeyVerification, err)
+		}
+	})
+
+	t.Run("deve retornar erro quando passkeys não estão habilitadas", func(t *testing.T) {
+		svc := NewService(&MockRepository{}, "test_secret", nil, nil)
+
+		_, err := svc.BeginPasskeyRegistration(ctx, user.ID.String())
+		if !errors.Is(err, ErrPasskeysDisabled) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrPasskeysDisabled, err)
+		}
+	})
+}
@@ -13,6 +13,13 @@ type Repository interface {
 	CreateUser(ctx context.Context, user *User) error
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
 	GetUserByID(ctx context.Context, id uuid.UUID) (*User, error)
+
+	CreatePasskey(ctx context.Context, passkey *Passkey) error
+	ListPasskeysByUserID(ctx context.Context, userID uuid.UUID) ([]Passkey, error)
+	// UpdatePasskeyUsage grava o estado da credencial depois de um login:
+	// contador, flags, alerta de clone e last_used_at
+	UpdatePasskeyUsage(ctx context.Context, passkey *Passkey) error
+	DeletePasskey(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error)
 }
 
 type pgxRepository struct {
@@ -87,3 +94,95 @@ func (r *pgxRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*User, e
 
 	return &user, nil
 }
+
+func (r *pgxRepository) CreatePasskey(ctx context.Context, passkey *Passkey) error {
+	query := `INSERT INTO webauthn_credentials
+              (id, user_id, name, credential_id, public_key, attestation_type, aaguid, sign_count, transports, flags, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`
+
+	_, err := r.db.Exec(ctx, query,
+		passkey.ID,
+		passkey.UserID,
+		passkey.Name,
+		passkey.CredentialID,
+		passkey.PublicKey,
+		passkey.AttestationType,
+		passkey.AAGUID,
+		int64(passkey.SignCount),
+		passkey.Transports,
+		int16(passkey.Flags),
+		passkey.CreatedAt,
+	)
+	return err
+}
+
+func (r *pgxRepository) ListPasskeysByUserID(ctx context.Context, userID uuid.UUID) ([]Passkey, error) {
+	query := `SELECT id, user_id, name, credential_id, public_key, attestation_type, aaguid,
+                     sign_count, transports, flags, clone_warning, created_at, last_used_at
+              FROM webauthn_credentials
+              WHERE user_id = $1
+              ORDER BY created_at`
+
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var passkeys []Passkey
+	for rows.Next() {
+		var (
+			p         Passkey
+			signCount int64
+			flags     int16
+		)
+		err := rows.Scan(
+			&p.ID,
+			&p.UserID,
+			&p.Name,
+			&p.CredentialID,
+			&p.PublicKey,
+			&p.AttestationType,
+			&p.AAGUID,
+			&signCount,
+			&p.Transports,
+			&flags,
+			&p.CloneWarning,
+			&p.CreatedAt,
+			&p.LastUsedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		p.SignCount = uint32(signCount)
+		p.Flags = uint8(flags)
+		passkeys = append(passkeys, p)
+	}
+
+	return passkeys, rows.Err()
+}
+
+func (r *pgxRepository) UpdatePasskeyUsage(ctx context.Context, passkey *Passkey) error {
+	query := `UPDATE webauthn_credentials
+              SET sign_count = $2, flags = $3, clone_warning = $4, last_used_at = $5
+              WHERE id = $1`
+
+	_, err := r.db.Exec(ctx, query,
+		passkey.ID,
+		int64(passkey.SignCount),
+		int16(passkey.Flags),
+		passkey.CloneWarning,
+		passkey.LastUsedAt,
+	)
+	return err
+}
+
+func (r *pgxRepository) DeletePasskey(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error) {
+	query := `DELETE FROM webauthn_credentials WHERE id = $1 AND user_id = $2`
+
+	tag, err := r.db.Exec(ctx, query, id, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -5,4 +5,14 @@ import "github.com/go-chi/chi/v5"
 func (h *Handler) RegisterRoutes(r *chi.Mux) {
 	r.Post("/auth/register", h.Register)
 	r.Post("/auth/login", h.Login)
+	r.Post("/auth/passkeys/login/begin", h.BeginPasskeyLogin)
+	r.Post("/auth/passkeys/login/finish", h.FinishPasskeyLogin)
+}
+
+// RegisterProtectedRoutes registra as rotas que exigem o AuthMiddleware
+func (h *Handler) RegisterProtectedRoutes(r chi.Router) {
+	r.Get("/auth/passkeys", h.ListPasskeys)
+	r.Post("/auth/passkeys/register/begin", h.BeginPasskeyRegistration)
+	r.Post("/auth/passkeys/register/finish", h.FinishPasskeyRegistration)
+	r.Delete("/auth/passkeys/{passkeyID}", h.DeletePasskey)
 }
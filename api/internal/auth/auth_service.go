@@ -1,10 +1,13 @@
 package auth
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"time"
 
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
@@ -15,23 +18,44 @@ type Service interface {
 	Register(ctx context.Context, req RegisterRequest) error
 	Login(ctx context.Context, req LoginRequest) (*LoginResponse, error)
 	GetMe(ctx context.Context, userID string) (*UserResponse, error)
+
+	BeginPasskeyRegistration(ctx context.Context, userID string) (*protocol.CredentialCreation, error)
+	FinishPasskeyRegistration(ctx context.Context, userID string, req FinishPasskeyRegistrationRequest) (*PasskeyResponse, error)
+	BeginPasskeyLogin(ctx context.Context, req BeginPasskeyLoginRequest) (*BeginPasskeyLoginResponse, error)
+	FinishPasskeyLogin(ctx context.Context, req FinishPasskeyLoginRequest) (*LoginResponse, error)
+	ListPasskeys(ctx context.Context, userID string) ([]PasskeyResponse, error)
+	DeletePasskey(ctx context.Context, userID string, passkeyID string) error
 }
 
 var (
 	ErrEmailConflict      = errors.New("email already in use")
 	ErrInvalidCredentials = errors.New("invalid email or password")
 	ErrUserNotFound       = errors.New("user not found")
+
+	ErrPasskeysDisabled       = errors.New("passkeys are not enabled")
+	ErrPasskeyNotFound        = errors.New("passkey not found")
+	ErrPasskeySessionNotFound = errors.New("passkey ceremony not found or expired")
+	ErrPasskeyVerification    = errors.New("passkey verification failed")
 )
 
+// Tempo que o desafio de uma cerimônia WebAuthn fica válido no Redis
+const passkeySessionTTL = 5 * time.Minute
+
 type service struct {
 	repo      Repository
 	jwtSecret string
+	webAuthn  *webauthn.WebAuthn
+	sessions  SessionStore
 }
 
-func NewService(repo Repository, jwtSecret string) Service {
+// NewService cria o serviço de autenticação. webAuthn e sessions podem ser nil,
+// e nesse caso apenas o login por senha fica disponível.
+func NewService(repo Repository, jwtSecret string, webAuthn *webauthn.WebAuthn, sessions SessionStore) Service {
 	return &service{
 		repo:      repo,
 		jwtSecret: jwtSecret,
+		webAuthn:  webAuthn,
+		sessions:  sessions,
 	}
 }
 
@@ -71,6 +95,10 @@ func (s *service) Login(ctx context.Context, req LoginRequest) (*LoginResponse,
 		return nil, ErrInvalidCredentials
 	}
 
+	return s.issueToken(user)
+}
+
+func (s *service) issueToken(user *User) (*LoginResponse, error) {
 	claims := jwt.MapClaims{
 		"sub":   user.ID,
 		"email": user.Email,
@@ -114,3 +142,310 @@ func (s *service) GetMe(ctx context.Context, userID string) (*UserResponse, erro
 		Email:     user.Email,
 	}, nil
 }
+
+// passkeyUser adapta um User e suas passkeys para a interface webauthn.User
+type passkeyUser struct {
+	user     *User
+	passkeys []Passkey
+}
+
+func (u *passkeyUser) WebAuthnID() []byte {
+	return u.user.ID[:]
+}
+
+func (u *passkeyUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	return u.user.FirstName + " " + u.user.LastName
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, len(u.passkeys))
+	for i, p := range u.passkeys {
+		transports := make([]protocol.AuthenticatorTransport, len(p.Transports))
+		for j, t := range p.Transports {
+			transports[j] = protocol.AuthenticatorTransport(t)
+		}
+
+		credentials[i] = webauthn.Credential{
+			ID:              p.CredentialID,
+			PublicKey:       p.PublicKey,
+			AttestationType: p.AttestationType,
+			Transport:       transports,
+			Flags:           webauthn.NewCredentialFlags(protocol.AuthenticatorFlags(p.Flags)),
+			Authenticator: webauthn.Authenticator{
+				AAGUID:       p.AAGUID,
+				SignCount:    p.SignCount,
+				CloneWarning: p.CloneWarning,
+			},
+		}
+	}
+	return credentials
+}
+
+func (s *service) loadPasskeyUser(ctx context.Context, id uuid.UUID) (*passkeyUser, error) {
+	user, err := s.repo.GetUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	passkeys, err := s.repo.ListPasskeysByUserID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &passkeyUser{user: user, passkeys: passkeys}, nil
+}
+
+func (s *service) BeginPasskeyRegistration(ctx context.Context, userID string) (*protocol.CredentialCreation, error) {
+	if s.webAuthn == nil {
+		return nil, ErrPasskeysDisabled
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.New("invalid user ID format")
+	}
+
+	pUser, err := s.loadPasskeyUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Impede que o mesmo autenticador seja registrado duas vezes
+	exclusions := webauthn.Credentials(pUser.WebAuthnCredentials()).CredentialDescriptors()
+	creation, session, err := s.webAuthn.BeginRegistration(pUser,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		log.Error().Err(err).Str("userID", userID).Msg("Failed to begin passkey registration")
+		return nil, err
+	}
+
+	if err := s.sessions.Save(ctx, "register:"+id.String(), session, passkeySessionTTL); err != nil {
+		return nil, err
+	}
+
+	return creation, nil
+}
+
+func (s *service) FinishPasskeyRegistration(ctx context.Context, userID string, req FinishPasskeyRegistrationRequest) (*PasskeyResponse, error) {
+	if s.webAuthn == nil {
+		return nil, ErrPasskeysDisabled
+	}
+
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.New("invalid user ID format")
+	}
+
+	session, err := s.sessions.Pop(ctx, "register:"+id.String())
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrPasskeySessionNotFound
+	}
+
+	pUser, err := s.loadPasskeyUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(req.Credential)
+	if err != nil {
+		log.Warn().Err(err).Str("userID", userID).Msg("Invalid passkey registration response")
+		return nil, ErrPasskeyVerification
+	}
+
+	credential, err := s.webAuthn.CreateCredential(pUser, *session, parsed)
+	if err != nil {
+		log.Warn().Err(err).Str("userID", userID).Msg("Passkey registration verification failed")
+		return nil, ErrPasskeyVerification
+	}
+
+	transports := make([]string, len(credential.Transport))
+	for i, t := range credential.Transport {
+		transports[i] = string(t)
+	}
+
+	passkey := &Passkey{
+		ID:              uuid.New(),
+		UserID:          id,
+		Name:            req.Name,
+		CredentialID:    credential.ID,
+		PublicKey:       credential.PublicKey,
+		AttestationType: credential.AttestationType,
+		AAGUID:          credential.Authenticator.AAGUID,
+		SignCount:       credential.Authenticator.SignCount,
+		Transports:      transports,
+		Flags:           uint8(credential.Flags.ProtocolValue()),
+		CreatedAt:       time.Now().UTC(),
+	}
+
+	if err := s.repo.CreatePasskey(ctx, passkey); err != nil {
+		log.Error().Err(err).Str("userID", userID).Msg("Failed to store passkey")
+		return nil, err
+	}
+
+	return toPasskeyResponse(passkey), nil
+}
+
+func (s *service) BeginPasskeyLogin(ctx context.Context, req BeginPasskeyLoginRequest) (*BeginPasskeyLoginResponse, error) {
+	if s.webAuthn == nil {
+		return nil, ErrPasskeysDisabled
+	}
+
+	user, err := s.repo.GetUserByEmail(ctx, req.Email)
+	if err != nil || user == nil {
+		return nil, ErrInvalidCredentials
+	}
+
+	pUser, err := s.loadPasskeyUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pUser.passkeys) == 0 {
+		return nil, ErrInvalidCredentials
+	}
+
+	assertion, session, err := s.webAuthn.BeginLogin(pUser)
+	if err != nil {
+		log.Error().Err(err).Str("userID", user.ID.String()).Msg("Failed to begin passkey login")
+		return nil, err
+	}
+
+	sessionID := uuid.NewString()
+	if err := s.sessions.Save(ctx, "login:"+sessionID, session, passkeySessionTTL); err != nil {
+		return nil, err
+	}
+
+	return &BeginPasskeyLoginResponse{
+		SessionID: sessionID,
+		Options:   assertion,
+	}, nil
+}
+
+func (s *service) FinishPasskeyLogin(ctx context.Context, req FinishPasskeyLoginRequest) (*LoginResponse, error) {
+	if s.webAuthn == nil {
+		return nil, ErrPasskeysDisabled
+	}
+
+	session, err := s.sessions.Pop(ctx, "login:"+req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrPasskeySessionNotFound
+	}
+
+	id, err := uuid.FromBytes(session.UserID)
+	if err != nil {
+		return nil, ErrPasskeyVerification
+	}
+
+	pUser, err := s.loadPasskeyUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(req.Credential)
+	if err != nil {
+		log.Warn().Err(err).Str("userID", id.String()).Msg("Invalid passkey assertion response")
+		return nil, ErrPasskeyVerification
+	}
+
+	credential, err := s.webAuthn.ValidateLogin(pUser, *session, parsed)
+	if err != nil {
+		log.Warn().Err(err).Str("userID", id.String()).Msg("Passkey assertion verification failed")
+		return nil, ErrPasskeyVerification
+	}
+
+	// Grava a credencial como o autenticador a devolveu: flags como backup
+	// state mudam entre logins, e o alerta de clone fica guardado
+	for _, p := range pUser.passkeys {
+		if bytes.Equal(p.CredentialID, credential.ID) {
+			p.SignCount = credential.Authenticator.SignCount
+			p.Flags = uint8(credential.Flags.ProtocolValue())
+			p.CloneWarning = credential.Authenticator.CloneWarning
+			if !p.CloneWarning {
+				now := time.Now().UTC()
+				p.LastUsedAt = &now
+			}
+			if err := s.repo.UpdatePasskeyUsage(ctx, &p); err != nil {
+				log.Error().Err(err).Str("passkeyID", p.ID.String()).Msg("Failed to update passkey usage")
+				return nil, err
+			}
+			break
+		}
+	}
+
+	// Contador que não avança indica um possível autenticador clonado; a
+	// passkey fica bloqueada, porque o alerta volta em todo login seguinte
+	if credential.Authenticator.CloneWarning {
+		log.Warn().Str("userID", id.String()).Msg("Passkey sign count did not increase, possible cloned authenticator")
+		return nil, ErrPasskeyVerification
+	}
+
+	return s.issueToken(pUser.user)
+}
+
+func (s *service) ListPasskeys(ctx context.Context, userID string) ([]PasskeyResponse, error) {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, errors.New("invalid user ID format")
+	}
+
+	passkeys, err := s.repo.ListPasskeysByUserID(ctx, id)
+	if err != nil {
+		log.Error().Err(err).Str("userID", userID).Msg("Failed to list passkeys")
+		return nil, err
+	}
+
+	responses := make([]PasskeyResponse, len(passkeys))
+	for i := range passkeys {
+		responses[i] = *toPasskeyResponse(&passkeys[i])
+	}
+	return responses, nil
+}
+
+func (s *service) DeletePasskey(ctx context.Context, userID string, passkeyID string) error {
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return errors.New("invalid user ID format")
+	}
+	pid, err := uuid.Parse(passkeyID)
+	if err != nil {
+		return ErrPasskeyNotFound
+	}
+
+	deleted, err := s.repo.DeletePasskey(ctx, pid, id)
+	if err != nil {
+		log.Error().Err(err).Str("passkeyID", passkeyID).Msg("Failed to delete passkey")
+		return err
+	}
+	if !deleted {
+		return ErrPasskeyNotFound
+	}
+	return nil
+}
+
+func toPasskeyResponse(p *Passkey) *PasskeyResponse {
+	transports := p.Transports
+	if transports == nil {
+		transports = []string{}
+	}
+	return &PasskeyResponse{
+		ID:         p.ID,
+		Name:       p.Name,
+		Transports: transports,
+		CreatedAt:  p.CreatedAt,
+		LastUsedAt: p.LastUsedAt,
+	}
+}
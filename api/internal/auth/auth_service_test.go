@@ -15,6 +15,11 @@ type MockRepository struct {
 	CreateUserFunc     func(ctx context.Context, user *User) error
 	GetUserByEmailFunc func(ctx context.Context, email string) (*User, error)
 	GetUserByIDFunc    func(ctx context.Context, id uuid.UUID) (*User, error)
+
+	CreatePasskeyFunc        func(ctx context.Context, passkey *Passkey) error
+	ListPasskeysByUserIDFunc func(ctx context.Context, userID uuid.UUID) ([]Passkey, error)
+	UpdatePasskeyUsageFunc   func(ctx context.Context, passkey *Passkey) error
+	DeletePasskeyFunc        func(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error)
 }
 
 func (m *MockRepository) CreateUser(ctx context.Context, user *User) error {
@@ -38,6 +43,34 @@ func (m *MockRepository) GetUserByID(ctx context.Context, id uuid.UUID) (*User,
 	return nil, nil
 }
 
+func (m *MockRepository) CreatePasskey(ctx context.Context, passkey *Passkey) error {
+	if m.CreatePasskeyFunc != nil {
+		return m.CreatePasskeyFunc(ctx, passkey)
+	}
+	return nil
+}
+
+func (m *MockRepository) ListPasskeysByUserID(ctx context.Context, userID uuid.UUID) ([]Passkey, error) {
+	if m.ListPasskeysByUserIDFunc != nil {
+		return m.ListPasskeysByUserIDFunc(ctx, userID)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) UpdatePasskeyUsage(ctx context.Context, passkey *Passkey) error {
+	if m.UpdatePasskeyUsageFunc != nil {
+		return m.UpdatePasskeyUsageFunc(ctx, passkey)
+	}
+	return nil
+}
+
+func (m *MockRepository) DeletePasskey(ctx context.Context, id uuid.UUID, userID uuid.UUID) (bool, error) {
+	if m.DeletePasskeyFunc != nil {
+		return m.DeletePasskeyFunc(ctx, id, userID)
+	}
+	return false, nil
+}
+
 func TestService_Register(t *testing.T) {
 	ctx := context.Background()
 
@@ -59,7 +92,7 @@ func TestService_Register(t *testing.T) {
 			},
 		}
 
-		service := NewService(mockRepo, "test_secret")
+		service := NewService(mockRepo, "test_secret", nil, nil)
 		req := RegisterRequest{
 			FirstName: "Teste",
 			LastName:  "Usuario",
@@ -81,7 +114,7 @@ func TestService_Register(t *testing.T) {
 			},
 		}
 
-		service := NewService(mockRepo, "test_secret")
+		service := NewService(mockRepo, "test_secret", nil, nil)
 		req := RegisterRequest{Email: "existente@exemplo.com", Password: "senha123"}
 
 		err := service.Register(ctx, req)
@@ -99,7 +132,7 @@ func TestService_Register(t *testing.T) {
 			},
 		}
 
-		service := NewService(mockRepo, "test_secret")
+		service := NewService(mockRepo, "test_secret", nil, nil)
 		req := RegisterRequest{Email: "teste@exemplo.com", Password: "senha123"}
 
 		err := service.Register(ctx, req)
@@ -133,7 +166,7 @@ func TestService_Login(t *testing.T) {
 			},
 		}
 
-		service := NewService(mockRepo, jwtSecret)
+		service := NewService(mockRepo, jwtSecret, nil, nil)
 		req := LoginRequest{Email: "usuario@exemplo.com", Password: "senha123"}
 
 		resp, err := service.Login(ctx, req)
@@ -171,7 +204,7 @@ func TestService_Login(t *testing.T) {
 			},
 		}
 
-		service := NewService(mockRepo, jwtSecret)
+		service := NewService(mockRepo, jwtSecret, nil, nil)
 		req := LoginRequest{Email: "naoencontrado@exemplo.com", Password: "senha123"}
 
 		resp, err := service.Login(ctx, req)
@@ -190,7 +223,7 @@ func TestService_Login(t *testing.T) {
 			},
 		}
 
-		service := NewService(mockRepo, jwtSecret)
+		service := NewService(mockRepo, jwtSecret, nil, nil)
 		req := LoginRequest{Email: "usuario@exemplo.com", Password: "SENHA_ERRADA"}
 
 		resp, err := service.Login(ctx, req)
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// SessionStore guarda os desafios das cerimônias WebAuthn entre o begin e o finish.
+// Cada sessão só pode ser consumida uma vez.
+type SessionStore interface {
+	Save(ctx context.Context, key string, session *webauthn.SessionData, ttl time.Duration) error
+	Pop(ctx context.Context, key string) (*webauthn.SessionData, error)
+}
+
+type redisSessionStore struct {
+	client *redis.Client
+}
+
+func NewRedisSessionStore(client *redis.Client) SessionStore {
+	return &redisSessionStore{client: client}
+}
+
+func (s *redisSessionStore) Save(ctx context.Context, key string, session *webauthn.SessionData, ttl time.Duration) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, "webauthn:session:"+key, data, ttl).Err()
+}
+
+func (s *redisSessionStore) Pop(ctx context.Context, key string) (*webauthn.SessionData, error) {
+	data, err := s.client.GetDel(ctx, "webauthn:session:"+key).Bytes()
+	if err != nil {
+		// Sessão inexistente ou expirada: (nil, nil), como nos repositórios
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var session webauthn.SessionData
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
@@ -0,0 +1,244 @@
+package categories
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/auth"
+	"github.com/rs/zerolog/log"
+)
+
+type Handler struct {
+	service  Service
+	validate *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(validator.WithRequiredStructEnabled()),
+	}
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("Falha ao escrever resposta JSON")
+	}
+}
+
+func (h *Handler) getUserIDFromContext(r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(auth.UserContextKey).(string)
+	if !ok {
+		log.Error().Msg("UserID não encontrado no contexto, middleware mal configurado")
+		return "", false
+	}
+	return userID, true
+}
+
+func (h *Handler) writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrCategoryNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "category not found"})
+	case errors.Is(err, ErrSystemCategory):
+		h.writeJSON(w, http.StatusForbidden, map[string]string{"error": "system categories cannot be changed"})
+	case errors.Is(err, ErrCategoryExists):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": "a category with this name already exists at this level"})
+	case errors.Is(err, ErrCategoryInUse):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": "category has movements or subcategories: use reassign_to or merge it into another category"})
+	case errors.Is(err, ErrInvalidParent):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "parent category not found"})
+	case errors.Is(err, ErrHierarchyCycle):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "category cannot be moved under itself or one of its subcategories"})
+	case errors.Is(err, ErrKindMismatch):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "categories must have the same kind"})
+	case errors.Is(err, ErrSameCategory):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "source and target categories must differ"})
+	default:
+		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": fallback})
+	}
+}
+
+func (h *Handler) HandleListCategories(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	categories, err := h.service.ListCategories(r.Context(), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve categories")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, categories)
+}
+
+func (h *Handler) HandleGetCategoryTree(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	tree, err := h.service.GetCategoryTree(r.Context(), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve category tree")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, tree)
+}
+
+func (h *Handler) HandleGetCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	category, err := h.service.GetCategory(r.Context(), chi.URLParam(r, "categoryID"), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve category")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, category)
+}
+
+func (h *Handler) HandleCreateCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req CreateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	category, err := h.service.CreateCategory(r.Context(), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to create category")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, category)
+}
+
+func (h *Handler) HandleUpdateCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req UpdateCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	category, err := h.service.UpdateCategory(r.Context(), chi.URLParam(r, "categoryID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to update category")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, category)
+}
+
+func (h *Handler) HandleMoveCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req MoveCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	category, err := h.service.MoveCategory(r.Context(), chi.URLParam(r, "categoryID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to move category")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, category)
+}
+
+func (h *Handler) HandleMergeCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req MergeCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	result, err := h.service.MergeCategory(r.Context(), chi.URLParam(r, "categoryID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to merge category")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result)
+}
+
+// HandleDeleteCategory responde DELETE /categories/{id}?reassign_to={id}. Sem
+// reassign_to, só remove categorias sem lançamentos nem subcategorias.
+func (h *Handler) HandleDeleteCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var reassignTo *uuid.UUID
+	if raw := r.URL.Query().Get("reassign_to"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid reassign_to"})
+			return
+		}
+		reassignTo = &id
+	}
+
+	if err := h.service.DeleteCategory(r.Context(), chi.URLParam(r, "categoryID"), userID, reassignTo); err != nil {
+		h.writeServiceError(w, err, "failed to delete category")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,91 @@
+package categories
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CategoryKind separa categorias de receita e de despesa
+type CategoryKind string
+
+const (
+	KindIncome  CategoryKind = "income"
+	KindExpense CategoryKind = "expense"
+)
+
+func (k CategoryKind) IsValid() bool {
+	return k == KindIncome || k == KindExpense
+}
+
+// Category classifica lançamentos. UserID nil marca uma categoria padrão do
+// sistema, visível para todos e somente leitura.
+type Category struct {
+	ID        uuid.UUID
+	UserID    *uuid.UUID
+	ParentID  *uuid.UUID
+	Name      string
+	Kind      CategoryKind
+	Icon      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (c *Category) IsSystem() bool {
+	return c.UserID == nil
+}
+
+// VisibleTo diz se o usuário pode ver e usar a categoria
+func (c *Category) VisibleTo(userID uuid.UUID) bool {
+	return c.UserID == nil || *c.UserID == userID
+}
+
+type CreateCategoryRequest struct {
+	Name     string       `json:"name" validate:"required,min=1,max=100"`
+	Kind     CategoryKind `json:"kind" validate:"required,oneof=income expense"`
+	Icon     string       `json:"icon" validate:"max=50"`
+	ParentID *uuid.UUID   `json:"parent_id"`
+}
+
+// UpdateCategoryRequest só altera os campos enviados. O kind não muda depois
+// de criada; para trocar de pai use /categories/{id}/move.
+type UpdateCategoryRequest struct {
+	Name *string `json:"name" validate:"omitempty,min=1,max=100"`
+	Icon *string `json:"icon" validate:"omitempty,max=50"`
+}
+
+// MoveCategoryRequest coloca a categoria sob outra; parent_id null a torna raiz
+type MoveCategoryRequest struct {
+	ParentID *uuid.UUID `json:"parent_id"`
+}
+
+// MergeCategoryRequest move os lançamentos, agendamentos e subcategorias para
+// target_id e remove a categoria de origem
+type MergeCategoryRequest struct {
+	TargetID uuid.UUID `json:"target_id" validate:"required"`
+}
+
+type CategoryResponse struct {
+	ID        uuid.UUID    `json:"id"`
+	ParentID  *uuid.UUID   `json:"parent_id"`
+	Name      string       `json:"name"`
+	Kind      CategoryKind `json:"kind"`
+	Icon      string       `json:"icon"`
+	System    bool         `json:"system"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// CategoryTreeNode é uma categoria com suas subcategorias
+type CategoryTreeNode struct {
+	CategoryResponse
+	Children []CategoryTreeNode `json:"children"`
+}
+
+// MergeResult diz quanto foi movido para a categoria de destino
+type MergeResult struct {
+	Target             CategoryResponse `json:"target"`
+	MovedMovements     int64            `json:"moved_movements"` // inclui as linhas de lançamentos divididos
+	MovedSubcategories int64            `json:"moved_subcategories"`
+	MovedSchedules     int64            `json:"moved_schedules"`
+}
@@ -0,0 +1,243 @@
+package categories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/martinsdevv/fincore/pkg/database"
+)
+
+type Repository interface {
+	// CreateCategory retorna ErrCategoryExists se já houver uma irmã com o mesmo nome
+	CreateCategory(ctx context.Context, category *Category) error
+	GetCategory(ctx context.Context, id uuid.UUID) (*Category, error)
+	// ListCategories devolve as categorias do sistema e as do usuário
+	ListCategories(ctx context.Context, userID uuid.UUID) ([]Category, error)
+	UpdateCategory(ctx context.Context, category *Category) error
+	// MoveCategory retorna ErrHierarchyCycle se o novo pai for a própria categoria ou uma descendente
+	MoveCategory(ctx context.Context, category *Category) error
+	// MergeCategory passa os lançamentos (e linhas de divisão), os agendamentos e
+	// as subcategorias de source para target e remove source, tudo numa transação.
+	// Devolve as contagens; Target fica para o serviço.
+	MergeCategory(ctx context.Context, sourceID uuid.UUID, targetID uuid.UUID) (*MergeResult, error)
+	// DeleteCategory retorna ErrCategoryInUse se ainda houver lançamentos ou subcategorias
+	DeleteCategory(ctx context.Context, id uuid.UUID) error
+}
+
+type pgxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgxRepository{db: db}
+}
+
+// conn usa a transação aberta por um database.Transactor, se houver
+func (r *pgxRepository) conn(ctx context.Context) database.DBTX {
+	return database.Conn(ctx, r.db)
+}
+
+// Colunas lidas por scanCategory, sempre nesta ordem
+const categoryColumns = `id, user_id, parent_id, name, kind, icon, created_at, updated_at`
+
+func scanCategory(row pgx.Row, c *Category) error {
+	return row.Scan(
+		&c.ID,
+		&c.UserID,
+		&c.ParentID,
+		&c.Name,
+		&c.Kind,
+		&c.Icon,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+}
+
+// translateError traduz as violações de constraint para os erros do serviço
+func translateError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return ErrCategoryExists
+		case "23503":
+			return ErrCategoryInUse
+		}
+	}
+	return err
+}
+
+func (r *pgxRepository) CreateCategory(ctx context.Context, c *Category) error {
+	query := `
+		INSERT INTO categories (` + categoryColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+
+	_, err := r.conn(ctx).Exec(ctx, query,
+		c.ID,
+		c.UserID,
+		c.ParentID,
+		c.Name,
+		c.Kind,
+		c.Icon,
+		c.CreatedAt,
+		c.UpdatedAt,
+	)
+	return translateError(err)
+}
+
+func (r *pgxRepository) GetCategory(ctx context.Context, id uuid.UUID) (*Category, error) {
+	query := `SELECT ` + categoryColumns + ` FROM categories WHERE id = $1`
+
+	var c Category
+	if err := scanCategory(r.conn(ctx).QueryRow(ctx, query, id), &c); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *pgxRepository) ListCategories(ctx context.Context, userID uuid.UUID) ([]Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM categories
+		WHERE user_id IS NULL OR user_id = $1
+		ORDER BY kind, LOWER(name), id`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []Category
+	for rows.Next() {
+		var c Category
+		if err := scanCategory(rows, &c); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+func (r *pgxRepository) UpdateCategory(ctx context.Context, c *Category) error {
+	query := `UPDATE categories SET name = $2, icon = $3, updated_at = $4 WHERE id = $1`
+
+	_, err := r.conn(ctx).Exec(ctx, query, c.ID, c.Name, c.Icon, c.UpdatedAt)
+	return translateError(err)
+}
+
+// lockHierarchy serializa as mudanças de hierarquia: dois moves simultâneos
+// (A sob B e B sob A) passariam cada um na checagem de ciclo
+func lockHierarchy(ctx context.Context, tx pgx.Tx) error {
+	_, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('categories:hierarchy'))`)
+	return err
+}
+
+// isDescendant diz se descendant está na subárvore de id (ou é o próprio id)
+func isDescendant(ctx context.Context, tx pgx.Tx, descendant uuid.UUID, id uuid.UUID) (bool, error) {
+	query := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id FROM categories WHERE id = $1
+			UNION
+			SELECT c.id, c.parent_id FROM categories c JOIN ancestors an ON c.id = an.parent_id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $2)`
+
+	var found bool
+	err := tx.QueryRow(ctx, query, descendant, id).Scan(&found)
+	return found, err
+}
+
+func (r *pgxRepository) MoveCategory(ctx context.Context, c *Category) error {
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockHierarchy(ctx, tx); err != nil {
+		return err
+	}
+
+	if c.ParentID != nil {
+		cycle, err := isDescendant(ctx, tx, *c.ParentID, c.ID)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return ErrHierarchyCycle
+		}
+	}
+
+	query := `UPDATE categories SET parent_id = $2, updated_at = $3 WHERE id = $1`
+	if _, err := tx.Exec(ctx, query, c.ID, c.ParentID, c.UpdatedAt); err != nil {
+		return translateError(err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *pgxRepository) MergeCategory(ctx context.Context, sourceID uuid.UUID, targetID uuid.UUID) (*MergeResult, error) {
+	tx, err := r.conn(ctx).Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockHierarchy(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	// As subcategorias da origem vão para o destino; se ele estiver entre elas,
+	// viraria pai de um dos próprios ancestrais
+	cycle, err := isDescendant(ctx, tx, targetID, sourceID)
+	if err != nil {
+		return nil, err
+	}
+	if cycle {
+		return nil, ErrHierarchyCycle
+	}
+
+	movements, err := tx.Exec(ctx, `UPDATE movements SET category_id = $2 WHERE category_id = $1`, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	splits, err := tx.Exec(ctx, `UPDATE movement_splits SET category_id = $2 WHERE category_id = $1`, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	// Sem isso, o DELETE abaixo zeraria a categoria dos agendamentos (ON DELETE
+	// SET NULL) e as próximas ocorrências sairiam sem categoria
+	schedules, err := tx.Exec(ctx, `UPDATE schedules SET category_id = $2 WHERE category_id = $1`, sourceID, targetID)
+	if err != nil {
+		return nil, err
+	}
+	children, err := tx.Exec(ctx, `UPDATE categories SET parent_id = $2, updated_at = NOW() WHERE parent_id = $1`, sourceID, targetID)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM categories WHERE id = $1`, sourceID); err != nil {
+		return nil, translateError(err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &MergeResult{
+		MovedMovements:     movements.RowsAffected() + splits.RowsAffected(),
+		MovedSubcategories: children.RowsAffected(),
+		MovedSchedules:     schedules.RowsAffected(),
+	}, nil
+}
+
+func (r *pgxRepository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	_, err := r.conn(ctx).Exec(ctx, `DELETE FROM categories WHERE id = $1`, id)
+	return translateError(err)
+}
@@ -0,0 +1,14 @@
+package categories
+
+import "github.com/go-chi/chi/v5"
+
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/categories", h.HandleListCategories)
+	r.Post("/categories", h.HandleCreateCategory)
+	r.Get("/categories/tree", h.HandleGetCategoryTree)
+	r.Get("/categories/{categoryID}", h.HandleGetCategory)
+	r.Patch("/categories/{categoryID}", h.HandleUpdateCategory)
+	r.Delete("/categories/{categoryID}", h.HandleDeleteCategory)
+	r.Post("/categories/{categoryID}/move", h.HandleMoveCategory)
+	r.Post("/categories/{categoryID}/merge", h.HandleMergeCategory)
+}
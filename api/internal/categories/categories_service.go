@@ -0,0 +1,329 @@
+package categories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrSystemCategory   = errors.New("system categories cannot be changed")
+	ErrCategoryExists   = errors.New("a category with this name already exists at this level")
+	ErrCategoryInUse    = errors.New("category has movements or subcategories")
+	ErrInvalidParent    = errors.New("parent category not found")
+	ErrHierarchyCycle   = errors.New("category cannot be moved under itself or one of its subcategories")
+	ErrKindMismatch     = errors.New("categories must have the same kind")
+	ErrSameCategory     = errors.New("source and target categories must differ")
+)
+
+type Service interface {
+	ListCategories(ctx context.Context, userID string) ([]CategoryResponse, error)
+	GetCategoryTree(ctx context.Context, userID string) ([]CategoryTreeNode, error)
+	GetCategory(ctx context.Context, categoryID string, userID string) (*CategoryResponse, error)
+	CreateCategory(ctx context.Context, userID string, req CreateCategoryRequest) (*CategoryResponse, error)
+	UpdateCategory(ctx context.Context, categoryID string, userID string, req UpdateCategoryRequest) (*CategoryResponse, error)
+	MoveCategory(ctx context.Context, categoryID string, userID string, req MoveCategoryRequest) (*CategoryResponse, error)
+	MergeCategory(ctx context.Context, categoryID string, userID string, req MergeCategoryRequest) (*MergeResult, error)
+	// DeleteCategory remove uma categoria sem uso; com reassignTo, passa antes
+	// os lançamentos e subcategorias para ela (o mesmo que um merge)
+	DeleteCategory(ctx context.Context, categoryID string, userID string, reassignTo *uuid.UUID) error
+
+	// ResolveCategory é usado pelos outros módulos: devolve a categoria se o
+	// usuário puder usá-la em lançamentos
+	ResolveCategory(ctx context.Context, categoryID uuid.UUID, userID uuid.UUID) (*Category, error)
+}
+
+type service struct {
+	repo Repository
+}
+
+func NewService(repo Repository) Service {
+	return &service{repo: repo}
+}
+
+func (s *service) ListCategories(ctx context.Context, userIDStr string) ([]CategoryResponse, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.repo.ListCategories(ctx, userID)
+	if err != nil {
+		log.Error().Err(err).Str("userID", userIDStr).Msg("Failed to list categories from repository")
+		return nil, err
+	}
+
+	responses := make([]CategoryResponse, 0, len(categories))
+	for i := range categories {
+		responses = append(responses, *toCategoryResponse(&categories[i]))
+	}
+	return responses, nil
+}
+
+func (s *service) GetCategoryTree(ctx context.Context, userIDStr string) ([]CategoryTreeNode, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := s.repo.ListCategories(ctx, userID)
+	if err != nil {
+		log.Error().Err(err).Str("userID", userIDStr).Msg("Failed to list categories from repository")
+		return nil, err
+	}
+
+	visible := make(map[uuid.UUID]bool, len(categories))
+	for i := range categories {
+		visible[categories[i].ID] = true
+	}
+
+	children := make(map[uuid.UUID][]*Category)
+	var roots []*Category
+	for i := range categories {
+		c := &categories[i]
+		if c.ParentID != nil && visible[*c.ParentID] {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+		} else {
+			roots = append(roots, c)
+		}
+	}
+
+	var build func(c *Category) CategoryTreeNode
+	build = func(c *Category) CategoryTreeNode {
+		node := CategoryTreeNode{
+			CategoryResponse: *toCategoryResponse(c),
+			Children:         make([]CategoryTreeNode, 0, len(children[c.ID])),
+		}
+		for _, child := range children[c.ID] {
+			node.Children = append(node.Children, build(child))
+		}
+		return node
+	}
+
+	tree := make([]CategoryTreeNode, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, build(root))
+	}
+	return tree, nil
+}
+
+func (s *service) GetCategory(ctx context.Context, categoryIDStr string, userIDStr string) (*CategoryResponse, error) {
+	category, _, err := s.getCategory(ctx, categoryIDStr, userIDStr, false)
+	if err != nil {
+		return nil, err
+	}
+	return toCategoryResponse(category), nil
+}
+
+func (s *service) CreateCategory(ctx context.Context, userIDStr string, req CreateCategoryRequest) (*CategoryResponse, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ParentID != nil {
+		if err := s.checkParent(ctx, *req.ParentID, userID, req.Kind); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	category := &Category{
+		ID:        uuid.New(),
+		UserID:    &userID,
+		ParentID:  req.ParentID,
+		Name:      strings.TrimSpace(req.Name),
+		Kind:      req.Kind,
+		Icon:      req.Icon,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := s.repo.CreateCategory(ctx, category); err != nil {
+		if !errors.Is(err, ErrCategoryExists) {
+			log.Error().Err(err).Str("userID", userIDStr).Msg("Failed to create category in repository")
+		}
+		return nil, err
+	}
+
+	return toCategoryResponse(category), nil
+}
+
+func (s *service) UpdateCategory(ctx context.Context, categoryIDStr string, userIDStr string, req UpdateCategoryRequest) (*CategoryResponse, error) {
+	category, _, err := s.getCategory(ctx, categoryIDStr, userIDStr, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		category.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Icon != nil {
+		category.Icon = *req.Icon
+	}
+	category.UpdatedAt = time.Now().UTC()
+
+	if err := s.repo.UpdateCategory(ctx, category); err != nil {
+		if !errors.Is(err, ErrCategoryExists) {
+			log.Error().Err(err).Str("categoryID", categoryIDStr).Msg("Failed to update category in repository")
+		}
+		return nil, err
+	}
+
+	return toCategoryResponse(category), nil
+}
+
+func (s *service) MoveCategory(ctx context.Context, categoryIDStr string, userIDStr string, req MoveCategoryRequest) (*CategoryResponse, error) {
+	category, userID, err := s.getCategory(ctx, categoryIDStr, userIDStr, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ParentID != nil {
+		if *req.ParentID == category.ID {
+			return nil, ErrHierarchyCycle
+		}
+		if err := s.checkParent(ctx, *req.ParentID, userID, category.Kind); err != nil {
+			return nil, err
+		}
+	}
+
+	category.ParentID = req.ParentID
+	category.UpdatedAt = time.Now().UTC()
+
+	if err := s.repo.MoveCategory(ctx, category); err != nil {
+		if !errors.Is(err, ErrHierarchyCycle) && !errors.Is(err, ErrCategoryExists) {
+			log.Error().Err(err).Str("categoryID", categoryIDStr).Msg("Failed to move category in repository")
+		}
+		return nil, err
+	}
+
+	return toCategoryResponse(category), nil
+}
+
+// MergeCategory junta uma categoria do usuário em outra que ele possa usar (dele
+// ou do sistema) do mesmo kind
+func (s *service) MergeCategory(ctx context.Context, categoryIDStr string, userIDStr string, req MergeCategoryRequest) (*MergeResult, error) {
+	source, userID, err := s.getCategory(ctx, categoryIDStr, userIDStr, true)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.mergeTarget(ctx, source, req.TargetID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.repo.MergeCategory(ctx, source.ID, target.ID)
+	if err != nil {
+		if !errors.Is(err, ErrHierarchyCycle) && !errors.Is(err, ErrCategoryExists) {
+			log.Error().Err(err).Str("categoryID", categoryIDStr).Msg("Failed to merge category in repository")
+		}
+		return nil, err
+	}
+
+	result.Target = *toCategoryResponse(target)
+	return result, nil
+}
+
+func (s *service) DeleteCategory(ctx context.Context, categoryIDStr string, userIDStr string, reassignTo *uuid.UUID) error {
+	if reassignTo != nil {
+		_, err := s.MergeCategory(ctx, categoryIDStr, userIDStr, MergeCategoryRequest{TargetID: *reassignTo})
+		return err
+	}
+
+	category, _, err := s.getCategory(ctx, categoryIDStr, userIDStr, true)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteCategory(ctx, category.ID); err != nil {
+		if !errors.Is(err, ErrCategoryInUse) {
+			log.Error().Err(err).Str("categoryID", categoryIDStr).Msg("Failed to delete category in repository")
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *service) ResolveCategory(ctx context.Context, categoryID uuid.UUID, userID uuid.UUID) (*Category, error) {
+	category, err := s.repo.GetCategory(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil || !category.VisibleTo(userID) {
+		return nil, ErrCategoryNotFound
+	}
+	return category, nil
+}
+
+// getCategory busca a categoria; categorias de outros usuários não existem para
+// quem pergunta. Com forWrite, as do sistema são recusadas.
+func (s *service) getCategory(ctx context.Context, categoryIDStr string, userIDStr string, forWrite bool) (*Category, uuid.UUID, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, uuid.Nil, err
+	}
+	categoryID, err := uuid.Parse(categoryIDStr)
+	if err != nil {
+		return nil, uuid.Nil, ErrCategoryNotFound
+	}
+
+	category, err := s.ResolveCategory(ctx, categoryID, userID)
+	if err != nil {
+		if !errors.Is(err, ErrCategoryNotFound) {
+			log.Error().Err(err).Str("categoryID", categoryIDStr).Msg("Failed to get category from repository")
+		}
+		return nil, uuid.Nil, err
+	}
+	if forWrite && category.IsSystem() {
+		return nil, uuid.Nil, ErrSystemCategory
+	}
+	return category, userID, nil
+}
+
+// checkParent garante que o pai existe, é visível para o usuário e tem o mesmo kind
+func (s *service) checkParent(ctx context.Context, parentID uuid.UUID, userID uuid.UUID, kind CategoryKind) error {
+	parent, err := s.ResolveCategory(ctx, parentID, userID)
+	if err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			return ErrInvalidParent
+		}
+		return err
+	}
+	if parent.Kind != kind {
+		return ErrKindMismatch
+	}
+	return nil
+}
+
+func (s *service) mergeTarget(ctx context.Context, source *Category, targetID uuid.UUID, userID uuid.UUID) (*Category, error) {
+	if targetID == source.ID {
+		return nil, ErrSameCategory
+	}
+	target, err := s.ResolveCategory(ctx, targetID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if target.Kind != source.Kind {
+		return nil, ErrKindMismatch
+	}
+	return target, nil
+}
+
+func toCategoryResponse(c *Category) *CategoryResponse {
+	return &CategoryResponse{
+		ID:        c.ID,
+		ParentID:  c.ParentID,
+		Name:      c.Name,
+		Kind:      c.Kind,
+		Icon:      c.Icon,
+		System:    c.IsSystem(),
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
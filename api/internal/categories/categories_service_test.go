@@ -0,0 +1,212 @@
+package categories
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// MockRepository guarda as categorias em memória
+type MockRepository struct {
+	categories map[uuid.UUID]*Category
+
+	CreateCategoryFunc func(ctx context.Context, category *Category) error
+	MoveCategoryFunc   func(ctx context.Context, category *Category) error
+	MergeCategoryFunc  func(ctx context.Context, sourceID uuid.UUID, targetID uuid.UUID) (*MergeResult, error)
+	DeleteCategoryFunc func(ctx context.Context, id uuid.UUID) error
+}
+
+func (m *MockRepository) CreateCategory(ctx context.Context, category *Category) error {
+	if m.CreateCategoryFunc != nil {
+		return m.CreateCategoryFunc(ctx, category)
+	}
+	return nil
+}
+
+func (m *MockRepository) GetCategory(ctx context.Context, id uuid.UUID) (*Category, error) {
+	if c, ok := m.categories[id]; ok {
+		copied := *c
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) ListCategories(ctx context.Context, userID uuid.UUID) ([]Category, error) {
+	var list []Category
+	for _, c := range m.categories {
+		if c.VisibleTo(userID) {
+			list = append(list, *c)
+		}
+	}
+	return list, nil
+}
+
+func (m *MockRepository) UpdateCategory(ctx context.Context, category *Category) error {
+	return nil
+}
+
+func (m *MockRepository) MoveCategory(ctx context.Context, category *Category) error {
+	if m.MoveCategoryFunc != nil {
+		return m.MoveCategoryFunc(ctx, category)
+	}
+	return nil
+}
+
+func (m *MockRepository) MergeCategory(ctx context.Context, sourceID uuid.UUID, targetID uuid.UUID) (*MergeResult, error) {
+	if m.MergeCategoryFunc != nil {
+		return m.MergeCategoryFunc(ctx, sourceID, targetID)
+	}
+	return &MergeResult{}, nil
+}
+
+func (m *MockRepository) DeleteCategory(ctx context.Context, id uuid.UUID) error {
+	if m.DeleteCategoryFunc != nil {
+		return m.DeleteCategoryFunc(ctx, id)
+	}
+	return nil
+}
+
+func newCategory(owner *uuid.UUID, parent *uuid.UUID, kind CategoryKind) *Category {
+	return &Category{ID: uuid.New(), UserID: owner, ParentID: parent, Name: "Categoria", Kind: kind}
+}
+
+func newMockRepository(list ...*Category) *MockRepository {
+	m := &MockRepository{categories: make(map[uuid.UUID]*Category)}
+	for _, c := range list {
+		m.categories[c.ID] = c
+	}
+	return m
+}
+
+func TestService_CreateCategory(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	otherUser := uuid.New()
+
+	system := newCategory(nil, nil, KindExpense)
+	foreign := newCategory(&otherUser, nil, KindExpense)
+
+	t.Run("deve criar subcategoria do usuário sob uma categoria do sistema", func(t *testing.T) {
+		var created *Category
+		repo := newMockRepository(system)
+		repo.CreateCategoryFunc = func(ctx context.Context, c *Category) error {
+			created = c
+			return nil
+		}
+		service := NewService(repo)
+
+		resp, err := service.CreateCategory(ctx, userID.String(), CreateCategoryRequest{Name: " Feira ", Kind: KindExpense, ParentID: &system.ID})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if created == nil || *created.UserID != userID || created.Name != "Feira" || resp.System {
+			t.Errorf("categoria criada incorreta: %+v", created)
+		}
+	})
+
+	t.Run("deve recusar pai de outro usuário ou de outro kind", func(t *testing.T) {
+		service := NewService(newMockRepository(system, foreign))
+
+		_, err := service.CreateCategory(ctx, userID.String(), CreateCategoryRequest{Name: "X", Kind: KindExpense, ParentID: &foreign.ID})
+		if !errors.Is(err, ErrInvalidParent) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidParent, err)
+		}
+
+		_, err = service.CreateCategory(ctx, userID.String(), CreateCategoryRequest{Name: "X", Kind: KindIncome, ParentID: &system.ID})
+		if !errors.Is(err, ErrKindMismatch) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrKindMismatch, err)
+		}
+	})
+}
+
+func TestService_ChangeCategory(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	otherUser := uuid.New()
+
+	system := newCategory(nil, nil, KindExpense)
+	income := newCategory(&userID, nil, KindIncome)
+	own := newCategory(&userID, &system.ID, KindExpense)
+	foreign := newCategory(&otherUser, nil, KindExpense)
+
+	t.Run("não deve alterar categorias do sistema nem ver as de outros usuários", func(t *testing.T) {
+		service := NewService(newMockRepository(system, foreign))
+		name := "Outro nome"
+
+		if _, err := service.UpdateCategory(ctx, system.ID.String(), userID.String(), UpdateCategoryRequest{Name: &name}); !errors.Is(err, ErrSystemCategory) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrSystemCategory, err)
+		}
+		if _, err := service.GetCategory(ctx, foreign.ID.String(), userID.String()); !errors.Is(err, ErrCategoryNotFound) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrCategoryNotFound, err)
+		}
+	})
+
+	t.Run("deve juntar numa categoria do mesmo kind", func(t *testing.T) {
+		repo := newMockRepository(system, income, own)
+		repo.MergeCategoryFunc = func(ctx context.Context, sourceID uuid.UUID, targetID uuid.UUID) (*MergeResult, error) {
+			if sourceID != own.ID || targetID != system.ID {
+				t.Errorf("merge incorreto: %s em %s", sourceID, targetID)
+			}
+			return &MergeResult{MovedMovements: 3, MovedSubcategories: 1, MovedSchedules: 2}, nil
+		}
+		service := NewService(repo)
+
+		result, err := service.MergeCategory(ctx, own.ID.String(), userID.String(), MergeCategoryRequest{TargetID: system.ID})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if result.MovedMovements != 3 || result.MovedSubcategories != 1 || result.MovedSchedules != 2 || result.Target.ID != system.ID {
+			t.Errorf("resultado incorreto: %+v", result)
+		}
+
+		if _, err := service.MergeCategory(ctx, own.ID.String(), userID.String(), MergeCategoryRequest{TargetID: income.ID}); !errors.Is(err, ErrKindMismatch) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrKindMismatch, err)
+		}
+		if _, err := service.MergeCategory(ctx, own.ID.String(), userID.String(), MergeCategoryRequest{TargetID: own.ID}); !errors.Is(err, ErrSameCategory) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrSameCategory, err)
+		}
+	})
+
+	t.Run("deve remover com reassign_to por meio do merge", func(t *testing.T) {
+		merged := false
+		repo := newMockRepository(system, own)
+		repo.MergeCategoryFunc = func(ctx context.Context, sourceID uuid.UUID, targetID uuid.UUID) (*MergeResult, error) {
+			merged = true
+			return &MergeResult{}, nil
+		}
+		repo.DeleteCategoryFunc = func(ctx context.Context, id uuid.UUID) error {
+			return ErrCategoryInUse
+		}
+		service := NewService(repo)
+
+		if err := service.DeleteCategory(ctx, own.ID.String(), userID.String(), nil); !errors.Is(err, ErrCategoryInUse) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrCategoryInUse, err)
+		}
+		if err := service.DeleteCategory(ctx, own.ID.String(), userID.String(), &system.ID); err != nil || !merged {
+			t.Errorf("esperava merge sem erro, obteve %v (merge: %v)", err, merged)
+		}
+	})
+
+	t.Run("não deve mover a categoria para baixo dela mesma", func(t *testing.T) {
+		service := NewService(newMockRepository(own))
+		if _, err := service.MoveCategory(ctx, own.ID.String(), userID.String(), MoveCategoryRequest{ParentID: &own.ID}); !errors.Is(err, ErrHierarchyCycle) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrHierarchyCycle, err)
+		}
+	})
+}
+
+func TestService_GetCategoryTree(t *testing.T) {
+	userID := uuid.New()
+	root := newCategory(nil, nil, KindExpense)
+	child := newCategory(&userID, &root.ID, KindExpense)
+
+	tree, err := NewService(newMockRepository(root, child)).GetCategoryTree(context.Background(), userID.String())
+	if err != nil {
+		t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+	}
+	if len(tree) != 1 || len(tree[0].Children) != 1 || tree[0].Children[0].ID != child.ID || !tree[0].System {
+		t.Errorf("árvore incorreta: %+v", tree)
+	}
+}
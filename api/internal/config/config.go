@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/spf13/viper"
 )
 
@@ -13,6 +15,50 @@ type Config struct {
 	DBPassword string `mapstructure:"DB_PASSWORD"`
 	DBName     string `mapstructure:"DB_NAME"`
 	RedisAddr  string `mapstructure:"REDIS_ADDR"`
+
+	WebAuthnRPID      string `mapstructure:"WEBAUTHN_RP_ID"`
+	WebAuthnRPName    string `mapstructure:"WEBAUTHN_RP_NAME"`
+	WebAuthnRPOrigins string `mapstructure:"WEBAUTHN_RP_ORIGINS"`
+
+	// Percentual de uso do limite de crédito que dispara uma notificação
+	CreditUtilizationThreshold float64 `mapstructure:"CREDIT_UTILIZATION_THRESHOLD"`
+
+	// Frequência com que o servidor confere os snapshots diários de saldo
+	BalanceSnapshotInterval time.Duration `mapstructure:"BALANCE_SNAPSHOT_INTERVAL"`
+
+	// Fonte das cotações de câmbio: "file" (arquivo local, para desenvolvimento)
+	// ou "http" (API no estilo exchangerate.host)
+	FXProvider  string        `mapstructure:"FX_PROVIDER"`
+	FXRatesFile string        `mapstructure:"FX_RATES_FILE"`
+	FXAPIURL    string        `mapstructure:"FX_API_URL"`
+	FXAPIKey    string        `mapstructure:"FX_API_KEY"`
+	FXCacheTTL  time.Duration `mapstructure:"FX_CACHE_TTL"`
+
+	// Por quanto tempo uma cotação de /fx/quotes fica travada e o spread cobrado
+	// sobre a cotação de mercado (0.005 = 0,5%)
+	FXQuoteTTL    time.Duration `mapstructure:"FX_QUOTE_TTL"`
+	FXQuoteSpread float64       `mapstructure:"FX_QUOTE_SPREAD"`
+
+	// Importação das cotações de referência do BCE (URL ou arquivo local). O job
+	// do servidor roda a cada FX_SYNC_INTERVAL; 0 desliga. FX_SYNC_CURRENCIES
+	// ("BRL,USD") limita as cotações cruzadas gravadas; vazio grava todos os pares.
+	FXSyncSource     string        `mapstructure:"FX_SYNC_SOURCE"`
+	FXSyncInterval   time.Duration `mapstructure:"FX_SYNC_INTERVAL"`
+	FXSyncCurrencies string        `mapstructure:"FX_SYNC_CURRENCIES"`
+
+	// Moeda em que a reavaliação cambial mede os ganhos e perdas não realizados
+	// das contas em moeda estrangeira. O job roda a cada FX_REVALUATION_INTERVAL
+	// reavaliando o dia anterior; 0 desliga.
+	FXFunctionalCurrency  string        `mapstructure:"FX_FUNCTIONAL_CURRENCY"`
+	FXRevaluationInterval time.Duration `mapstructure:"FX_REVALUATION_INTERVAL"`
+
+	// A cada SCHEDULER_INTERVAL uma das réplicas lança as ocorrências vencidas
+	// dos agendamentos; 0 desliga o scheduler nesta instância.
+	SchedulerInterval time.Duration `mapstructure:"SCHEDULER_INTERVAL"`
+
+	// A cada HOLD_EXPIRY_INTERVAL as retenções vencidas são anuladas e o saldo
+	// disponível volta a ficar livre; 0 desliga.
+	HoldExpiryInterval time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -41,6 +87,25 @@ func LoadConfig() (*Config, error) {
 		"DB_PASSWORD",
 		"DB_NAME",
 		"REDIS_ADDR",
+		"WEBAUTHN_RP_ID",
+		"WEBAUTHN_RP_NAME",
+		"WEBAUTHN_RP_ORIGINS",
+		"CREDIT_UTILIZATION_THRESHOLD",
+		"BALANCE_SNAPSHOT_INTERVAL",
+		"FX_PROVIDER",
+		"FX_RATES_FILE",
+		"FX_API_URL",
+		"FX_API_KEY",
+		"FX_CACHE_TTL",
+		"FX_QUOTE_TTL",
+		"FX_QUOTE_SPREAD",
+		"FX_SYNC_SOURCE",
+		"FX_SYNC_INTERVAL",
+		"FX_SYNC_CURRENCIES",
+		"FX_FUNCTIONAL_CURRENCY",
+		"FX_REVALUATION_INTERVAL",
+		"SCHEDULER_INTERVAL",
+		"HOLD_EXPIRY_INTERVAL",
 	} {
 		if err := v.BindEnv(k); err != nil {
 			return nil, err
@@ -49,6 +114,23 @@ func LoadConfig() (*Config, error) {
 
 	v.SetDefault("API_PORT", "8080")
 	v.SetDefault("DB_PORT", "5432")
+	v.SetDefault("WEBAUTHN_RP_ID", "localhost")
+	v.SetDefault("WEBAUTHN_RP_NAME", "Fincore")
+	v.SetDefault("WEBAUTHN_RP_ORIGINS", "http://localhost")
+	v.SetDefault("CREDIT_UTILIZATION_THRESHOLD", 80)
+	v.SetDefault("BALANCE_SNAPSHOT_INTERVAL", "1h")
+	v.SetDefault("FX_PROVIDER", "file")
+	v.SetDefault("FX_RATES_FILE", "./data/fx_rates.json")
+	v.SetDefault("FX_API_URL", "https://api.exchangerate.host")
+	v.SetDefault("FX_CACHE_TTL", "1h")
+	v.SetDefault("FX_QUOTE_TTL", "30s")
+	v.SetDefault("FX_QUOTE_SPREAD", 0.005)
+	v.SetDefault("FX_SYNC_SOURCE", "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml")
+	v.SetDefault("FX_SYNC_INTERVAL", "12h")
+	v.SetDefault("FX_FUNCTIONAL_CURRENCY", "BRL")
+	v.SetDefault("FX_REVALUATION_INTERVAL", "6h")
+	v.SetDefault("SCHEDULER_INTERVAL", "1m")
+	v.SetDefault("HOLD_EXPIRY_INTERVAL", "15m")
 
 	var cfg Config
 	if err := v.Unmarshal(&cfg); err != nil {
@@ -0,0 +1,181 @@
+package duplicates
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/auth"
+	"github.com/martinsdevv/fincore/internal/movements"
+	"github.com/rs/zerolog/log"
+)
+
+type Handler struct {
+	service  Service
+	validate *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(validator.WithRequiredStructEnabled()),
+	}
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("Falha ao escrever resposta JSON")
+	}
+}
+
+func (h *Handler) getUserIDFromContext(r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(auth.UserContextKey).(string)
+	if !ok {
+		log.Error().Msg("UserID não encontrado no contexto, middleware mal configurado")
+		return "", false
+	}
+	return userID, true
+}
+
+// writeServiceError traduz os erros do serviço, incluindo os da conta e do estorno, para respostas HTTP
+func (h *Handler) writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, movements.ErrMovementNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "movement not found"})
+	case errors.Is(err, ErrInvalidOptions):
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrSameMovement), errors.Is(err, ErrNotDuplicate),
+		errors.Is(err, movements.ErrMovementNotReversible), errors.Is(err, accounts.ErrInsufficientFunds),
+		errors.Is(err, accounts.ErrNegativeBalanceNotAllowed):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, movements.ErrMovementReconciled), errors.Is(err, movements.ErrAlreadyReversed):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, accounts.ErrAccountNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "account not found"})
+	case errors.Is(err, accounts.ErrForbidden):
+		h.writeJSON(w, http.StatusForbidden, map[string]string{"error": "you do not have permission for this account"})
+	case errors.Is(err, accounts.ErrAccountArchived):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": "account is archived"})
+	default:
+		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": fallback})
+	}
+}
+
+// parseDetectOptions lê ?from=&to= em RFC 3339, ?date_tolerance_days= (padrão 3)
+// e ?min_similarity= entre 0 e 1 (padrão 0.5)
+func parseDetectOptions(q url.Values) (DetectOptions, error) {
+	opts := DetectOptions{DateToleranceDays: defaultDateTolerance, MinSimilarity: defaultMinSimilarity}
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &opts.From}, {"to", &opts.To}} {
+		raw := q.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return opts, errors.New("invalid " + p.name + ": use RFC 3339")
+		}
+		*p.dst = &t
+	}
+	if raw := q.Get("date_tolerance_days"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 0 || days > maxDateTolerance {
+			return opts, errors.New("invalid date_tolerance_days: use 0 to 31")
+		}
+		opts.DateToleranceDays = days
+	}
+	if raw := q.Get("min_similarity"); raw != "" {
+		similarity, err := strconv.ParseFloat(raw, 64)
+		if err != nil || similarity < 0 || similarity > 1 {
+			return opts, errors.New("invalid min_similarity: use 0 to 1")
+		}
+		opts.MinSimilarity = similarity
+	}
+	return opts, nil
+}
+
+func (h *Handler) HandleFindDuplicates(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	opts, err := parseDetectOptions(r.URL.Query())
+	if err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	duplicates, err := h.service.FindDuplicates(r.Context(), chi.URLParam(r, "accountID"), userID, opts)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to find duplicates")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, duplicates)
+}
+
+func (h *Handler) HandleMergeDuplicates(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req MergeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	reversal, err := h.service.MergeDuplicates(r.Context(), chi.URLParam(r, "accountID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to merge duplicates")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, reversal)
+}
+
+func (h *Handler) HandleDismissDuplicate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req DismissRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	if err := h.service.DismissDuplicate(r.Context(), chi.URLParam(r, "accountID"), userID, req); err != nil {
+		h.writeServiceError(w, err, "failed to dismiss duplicate")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
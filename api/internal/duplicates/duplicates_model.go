@@ -0,0 +1,75 @@
+package duplicates
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// defaultDateTolerance é quantos dias podem separar dois lançamentos
+	// duplicados, quando a busca não diz outro valor
+	defaultDateTolerance = 3
+	maxDateTolerance     = 31
+	// defaultMinSimilarity é a semelhança mínima das descrições (ver textsim.Similarity)
+	defaultMinSimilarity = 0.5
+	// defaultWindow é o período varrido quando a busca não informa from
+	defaultWindow = 90 * 24 * time.Hour
+)
+
+// Entry é um lançamento da conta visto pelo detector. ExternalID é o
+// identificador do banco, quando o lançamento veio de um extrato importado.
+type Entry struct {
+	ID           uuid.UUID
+	Kind         string
+	Status       string
+	Amount       int64
+	Description  string
+	OccurredAt   time.Time
+	ExternalID   *string
+	ReconciledAt *time.Time
+}
+
+// Pair identifica dois lançamentos sem ordem: First é sempre o menor ID,
+// como na tabela duplicate_dismissals
+type Pair struct {
+	First  uuid.UUID
+	Second uuid.UUID
+}
+
+// DetectOptions ajusta a busca de GET /duplicates. From e To limitam
+// occurred_at; sem From, valem os últimos 90 dias.
+type DetectOptions struct {
+	From              *time.Time
+	To                *time.Time
+	DateToleranceDays int
+	MinSimilarity     float64
+}
+
+// MergeRequest mantém keep_id e estorna duplicate_id, que sai do saldo
+type MergeRequest struct {
+	KeepID      uuid.UUID `json:"keep_id" validate:"required"`
+	DuplicateID uuid.UUID `json:"duplicate_id" validate:"required"`
+}
+
+// DismissRequest marca os dois lançamentos como distintos
+type DismissRequest struct {
+	MovementIDs []uuid.UUID `json:"movement_ids" validate:"len=2"`
+}
+
+type DuplicateResponse struct {
+	Movements  [2]MovementReference `json:"movements"` // o mais antigo primeiro
+	Similarity float64              `json:"similarity"`
+	DaysApart  int                  `json:"days_apart"`
+}
+
+// MovementReference resume um lançamento de um par suspeito
+type MovementReference struct {
+	ID          uuid.UUID `json:"id"`
+	Kind        string    `json:"kind"`
+	Amount      int64     `json:"amount"`
+	Description string    `json:"description"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	ExternalID  *string   `json:"external_id,omitempty"`
+	Reconciled  bool      `json:"reconciled"` // o par só pode ser unido estornando o outro
+}
@@ -0,0 +1,128 @@
+package duplicates
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/martinsdevv/fincore/pkg/database"
+)
+
+type Repository interface {
+	// ListEntries devolve os lançamentos posted da conta com occurred_at em
+	// [from, to) que podem ter duplicados: ficam de fora o saldo inicial, as
+	// pernas de transferência, os estornos e os lançamentos já estornados
+	ListEntries(ctx context.Context, accountID uuid.UUID, from time.Time, to time.Time) ([]Entry, error)
+	// GetEntry devolve o lançamento da conta em qualquer status, ou nil
+	GetEntry(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Entry, error)
+	// ListDismissed devolve os pares da conta marcados como distintos
+	ListDismissed(ctx context.Context, accountID uuid.UUID) ([]Pair, error)
+	// Dismiss grava o par; dispensar de novo o mesmo par não faz nada
+	Dismiss(ctx context.Context, accountID uuid.UUID, pair Pair, userID uuid.UUID) error
+}
+
+type pgxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgxRepository{db: db}
+}
+
+// conn usa a transação aberta por um database.Transactor, se houver
+func (r *pgxRepository) conn(ctx context.Context) database.DBTX {
+	return database.Conn(ctx, r.db)
+}
+
+// Colunas lidas por scanEntry, sempre nesta ordem; e é o LEFT JOIN com import_entries
+const entryColumns = `m.id, m.kind, m.status, m.amount, m.description, m.occurred_at, e.external_id, m.reconciled_at`
+
+func scanEntry(row pgx.Row, entry *Entry) error {
+	return row.Scan(
+		&entry.ID,
+		&entry.Kind,
+		&entry.Status,
+		&entry.Amount,
+		&entry.Description,
+		&entry.OccurredAt,
+		&entry.ExternalID,
+		&entry.ReconciledAt,
+	)
+}
+
+func (r *pgxRepository) ListEntries(ctx context.Context, accountID uuid.UUID, from time.Time, to time.Time) ([]Entry, error) {
+	query := `
+		SELECT ` + entryColumns + `
+		FROM movements m
+		LEFT JOIN import_entries e ON e.movement_id = m.id
+		WHERE m.account_id = $1 AND m.status = 'posted' AND m.occurred_at >= $2 AND m.occurred_at < $3
+			AND m.kind IN ('manual', 'scheduled', 'imported')
+			AND NOT EXISTS (SELECT 1 FROM movements r WHERE r.reversal_of = m.id)
+		ORDER BY m.occurred_at, m.id`
+
+	rows, err := r.conn(ctx).Query(ctx, query, accountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var entry Entry
+		if err := scanEntry(rows, &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+func (r *pgxRepository) GetEntry(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Entry, error) {
+	query := `
+		SELECT ` + entryColumns + `
+		FROM movements m
+		LEFT JOIN import_entries e ON e.movement_id = m.id
+		WHERE m.id = $2 AND m.account_id = $1`
+
+	var entry Entry
+	if err := scanEntry(r.conn(ctx).QueryRow(ctx, query, accountID, movementID), &entry); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *pgxRepository) ListDismissed(ctx context.Context, accountID uuid.UUID) ([]Pair, error) {
+	query := `SELECT first_movement_id, second_movement_id FROM duplicate_dismissals WHERE account_id = $1`
+
+	rows, err := r.conn(ctx).Query(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pairs []Pair
+	for rows.Next() {
+		var p Pair
+		if err := rows.Scan(&p.First, &p.Second); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, p)
+	}
+	return pairs, rows.Err()
+}
+
+func (r *pgxRepository) Dismiss(ctx context.Context, accountID uuid.UUID, pair Pair, userID uuid.UUID) error {
+	query := `
+		INSERT INTO duplicate_dismissals (account_id, first_movement_id, second_movement_id, dismissed_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (first_movement_id, second_movement_id) DO NOTHING`
+
+	_, err := r.conn(ctx).Exec(ctx, query, accountID, pair.First, pair.Second, userID)
+	return err
+}
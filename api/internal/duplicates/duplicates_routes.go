@@ -0,0 +1,9 @@
+package duplicates
+
+import "github.com/go-chi/chi/v5"
+
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/accounts/{accountID}/duplicates", h.HandleFindDuplicates)
+	r.Post("/accounts/{accountID}/duplicates/merge", h.HandleMergeDuplicates)
+	r.Post("/accounts/{accountID}/duplicates/dismiss", h.HandleDismissDuplicate)
+}
@@ -0,0 +1,237 @@
+package duplicates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/movements"
+	"github.com/martinsdevv/fincore/pkg/textsim"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrSameMovement   = errors.New("a movement cannot be a duplicate of itself")
+	ErrNotDuplicate   = errors.New("movements must both be posted and have the same amount")
+	ErrInvalidOptions = errors.New("invalid duplicate search options")
+)
+
+type Service interface {
+	// FindDuplicates aponta os pares de lançamentos da conta com o mesmo valor,
+	// datas próximas e descrições parecidas, tirando os pares já dispensados
+	FindDuplicates(ctx context.Context, accountID string, userID string, opts DetectOptions) ([]DuplicateResponse, error)
+	// MergeDuplicates estorna o duplicado e devolve o estorno; o lançamento
+	// mantido não muda
+	MergeDuplicates(ctx context.Context, accountID string, userID string, req MergeRequest) (*movements.MovementResponse, error)
+	// DismissDuplicate lembra que os dois lançamentos são distintos
+	DismissDuplicate(ctx context.Context, accountID string, userID string, req DismissRequest) error
+}
+
+type service struct {
+	repo      Repository
+	accounts  accounts.Service
+	movements movements.Service
+}
+
+func NewService(repo Repository, accountsSvc accounts.Service, movementsSvc movements.Service) Service {
+	return &service{repo: repo, accounts: accountsSvc, movements: movementsSvc}
+}
+
+func (s *service) FindDuplicates(ctx context.Context, accountIDStr string, userIDStr string, opts DetectOptions) ([]DuplicateResponse, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	if opts.DateToleranceDays < 0 || opts.DateToleranceDays > maxDateTolerance || opts.MinSimilarity < 0 || opts.MinSimilarity > 1 {
+		return nil, ErrInvalidOptions
+	}
+
+	// A janela cresce pela tolerância, para achar o par de um lançamento da borda
+	to := time.Now().UTC()
+	if opts.To != nil {
+		to = *opts.To
+	}
+	from := to.Add(-defaultWindow)
+	if opts.From != nil {
+		from = *opts.From
+	}
+	if !from.Before(to) {
+		return nil, ErrInvalidOptions
+	}
+	margin := time.Duration(opts.DateToleranceDays) * 24 * time.Hour
+
+	entries, err := s.repo.ListEntries(ctx, account.ID, from.Add(-margin), to.Add(margin))
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to list movements for duplicate detection")
+		return nil, err
+	}
+	dismissed, err := s.repo.ListDismissed(ctx, account.ID)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to list dismissed duplicates")
+		return nil, err
+	}
+	skip := make(map[Pair]bool, len(dismissed))
+	for _, p := range dismissed {
+		skip[p] = true
+	}
+
+	found := detect(entries, opts.DateToleranceDays, opts.MinSimilarity, skip)
+	resp := make([]DuplicateResponse, 0, len(found))
+	for _, d := range found {
+		// Pares só com lançamentos da margem ficam para a janela vizinha
+		if d.Movements[1].OccurredAt.Before(from) || !d.Movements[0].OccurredAt.Before(to) {
+			continue
+		}
+		resp = append(resp, d)
+	}
+	return resp, nil
+}
+
+// detect compara os lançamentos de mesmo valor dois a dois. Dois lançamentos
+// importados com identificadores diferentes nunca são duplicados: o próprio
+// banco diz que são operações distintas. Os pares saem do mais recente ao
+// mais antigo e, no mesmo dia, do mais parecido ao menos.
+func detect(entries []Entry, toleranceDays int, minSimilarity float64, dismissed map[Pair]bool) []DuplicateResponse {
+	byAmount := make(map[int64][]Entry)
+	for _, e := range entries {
+		byAmount[e.Amount] = append(byAmount[e.Amount], e)
+	}
+
+	var found []DuplicateResponse
+	for _, group := range byAmount {
+		sort.Slice(group, func(i, j int) bool { return group[i].OccurredAt.Before(group[j].OccurredAt) })
+		for i := range group {
+			for j := i + 1; j < len(group); j++ {
+				a, b := group[i], group[j]
+				days := daysApart(a.OccurredAt, b.OccurredAt)
+				if days > toleranceDays {
+					break
+				}
+				if a.ExternalID != nil && b.ExternalID != nil && *a.ExternalID != *b.ExternalID {
+					continue
+				}
+				if dismissed[newPair(a.ID, b.ID)] {
+					continue
+				}
+				similarity := textsim.Similarity(a.Description, b.Description)
+				if similarity < minSimilarity {
+					continue
+				}
+				found = append(found, DuplicateResponse{
+					Movements:  [2]MovementReference{toMovementReference(a), toMovementReference(b)},
+					Similarity: math.Round(similarity*100) / 100,
+					DaysApart:  days,
+				})
+			}
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		ti, tj := found[i].Movements[1].OccurredAt, found[j].Movements[1].OccurredAt
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return found[i].Similarity > found[j].Similarity
+	})
+	return found
+}
+
+// daysApart conta os dias de calendário entre as datas, em UTC
+func daysApart(a time.Time, b time.Time) int {
+	da := a.UTC().Truncate(24 * time.Hour)
+	db := b.UTC().Truncate(24 * time.Hour)
+	days := int(db.Sub(da).Hours() / 24)
+	if days < 0 {
+		return -days
+	}
+	return days
+}
+
+// newPair ordena os IDs como o CHECK da tabela duplicate_dismissals
+func newPair(a uuid.UUID, b uuid.UUID) Pair {
+	if bytes.Compare(a[:], b[:]) > 0 {
+		a, b = b, a
+	}
+	return Pair{First: a, Second: b}
+}
+
+func (s *service) MergeDuplicates(ctx context.Context, accountIDStr string, userIDStr string, req MergeRequest) (*movements.MovementResponse, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if req.KeepID == req.DuplicateID {
+		return nil, ErrSameMovement
+	}
+
+	keep, duplicate, err := s.getPair(ctx, account.ID, req.KeepID, req.DuplicateID)
+	if err != nil {
+		return nil, err
+	}
+	if keep.Status != string(movements.StatusPosted) || duplicate.Status != string(movements.StatusPosted) || keep.Amount != duplicate.Amount {
+		return nil, ErrNotDuplicate
+	}
+	// Um lançamento conciliado bate com o extrato: é ele que fica
+	if duplicate.ReconciledAt != nil {
+		return nil, movements.ErrMovementReconciled
+	}
+
+	return s.movements.ReverseMovement(ctx, accountIDStr, duplicate.ID.String(), userIDStr, movements.ReverseMovementRequest{})
+}
+
+func (s *service) DismissDuplicate(ctx context.Context, accountIDStr string, userIDStr string, req DismissRequest) error {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return err
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return err
+	}
+	if req.MovementIDs[0] == req.MovementIDs[1] {
+		return ErrSameMovement
+	}
+
+	if _, _, err := s.getPair(ctx, account.ID, req.MovementIDs[0], req.MovementIDs[1]); err != nil {
+		return err
+	}
+
+	if err := s.repo.Dismiss(ctx, account.ID, newPair(req.MovementIDs[0], req.MovementIDs[1]), userID); err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to dismiss duplicate")
+		return err
+	}
+	return nil
+}
+
+// getPair busca os dois lançamentos na conta; qualquer um que falte é ErrMovementNotFound
+func (s *service) getPair(ctx context.Context, accountID uuid.UUID, firstID uuid.UUID, secondID uuid.UUID) (*Entry, *Entry, error) {
+	first, err := s.repo.GetEntry(ctx, accountID, firstID)
+	if err != nil {
+		return nil, nil, err
+	}
+	second, err := s.repo.GetEntry(ctx, accountID, secondID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if first == nil || second == nil {
+		return nil, nil, movements.ErrMovementNotFound
+	}
+	return first, second, nil
+}
+
+func toMovementReference(e Entry) MovementReference {
+	return MovementReference{
+		ID:          e.ID,
+		Kind:        e.Kind,
+		Amount:      e.Amount,
+		Description: e.Description,
+		OccurredAt:  e.OccurredAt,
+		ExternalID:  e.ExternalID,
+		Reconciled:  e.ReconciledAt != nil,
+	}
+}
@@ -0,0 +1,227 @@
+package duplicates
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/movements"
+)
+
+// MockRepository guarda os lançamentos e os pares dispensados em memória.
+// Como na consulta, lançamentos estornados não voltam em ListEntries.
+type MockRepository struct {
+	entries   map[uuid.UUID]*Entry
+	reversed  map[uuid.UUID]bool
+	dismissed map[Pair]bool
+}
+
+func newMockRepository(entries ...Entry) *MockRepository {
+	m := &MockRepository{
+		entries:   make(map[uuid.UUID]*Entry),
+		reversed:  make(map[uuid.UUID]bool),
+		dismissed: make(map[Pair]bool),
+	}
+	for i := range entries {
+		m.entries[entries[i].ID] = &entries[i]
+	}
+	return m
+}
+
+func (m *MockRepository) ListEntries(ctx context.Context, accountID uuid.UUID, from time.Time, to time.Time) ([]Entry, error) {
+	var entries []Entry
+	for _, e := range m.entries {
+		if e.Status == "posted" && !e.OccurredAt.Before(from) && e.OccurredAt.Before(to) && !m.reversed[e.ID] {
+			entries = append(entries, *e)
+		}
+	}
+	return entries, nil
+}
+
+func (m *MockRepository) GetEntry(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Entry, error) {
+	e, ok := m.entries[movementID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *e
+	return &copied, nil
+}
+
+func (m *MockRepository) ListDismissed(ctx context.Context, accountID uuid.UUID) ([]Pair, error) {
+	var pairs []Pair
+	for p := range m.dismissed {
+		pairs = append(pairs, p)
+	}
+	return pairs, nil
+}
+
+func (m *MockRepository) Dismiss(ctx context.Context, accountID uuid.UUID, pair Pair, userID uuid.UUID) error {
+	m.dismissed[pair] = true
+	return nil
+}
+
+// MockAccounts implementa só a autorização de accounts.Service
+type MockAccounts struct {
+	accounts.Service
+	account *accounts.Account
+}
+
+func (m *MockAccounts) AuthorizeAccount(ctx context.Context, accountID string, userID string, role accounts.MemberRole) (*accounts.Account, error) {
+	return m.account, nil
+}
+
+// MockMovements implementa só o estorno de movements.Service, marcando o
+// lançamento como estornado no repositório
+type MockMovements struct {
+	movements.Service
+	repo *MockRepository
+}
+
+func (m *MockMovements) ReverseMovement(ctx context.Context, accountID string, movementID string, userID string, req movements.ReverseMovementRequest) (*movements.MovementResponse, error) {
+	id := uuid.MustParse(movementID)
+	if m.repo.reversed[id] {
+		return nil, movements.ErrAlreadyReversed
+	}
+	m.repo.reversed[id] = true
+	original := m.repo.entries[id]
+	return &movements.MovementResponse{ID: uuid.New(), Kind: movements.KindReversal, Amount: -original.Amount, ReversalOf: &id}, nil
+}
+
+func TestService_FindDuplicates(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	account := &accounts.Account{ID: uuid.New(), UserID: userID, Currency: "BRL"}
+	at := func(date string) time.Time {
+		d, _ := time.Parse(time.DateOnly, date)
+		return d.Add(15 * time.Hour)
+	}
+	fitidA, fitidB := "20240305001", "20240305002"
+
+	manual := Entry{ID: uuid.New(), Kind: "manual", Status: "posted", Amount: -4590, Description: "Supermercado Extra", OccurredAt: at("2024-03-04")}
+	imported := Entry{ID: uuid.New(), Kind: "imported", Status: "posted", Amount: -4590, Description: "COMPRA CARTAO SUPERMERCADO EXTRA 0304", OccurredAt: at("2024-03-05"), ExternalID: &fitidA}
+	// Mesmo valor e descrição, mas o banco diz que são duas compras
+	secondImport := Entry{ID: uuid.New(), Kind: "imported", Status: "posted", Amount: -4590, Description: "COMPRA CARTAO SUPERMERCADO EXTRA 0305", OccurredAt: at("2024-03-05"), ExternalID: &fitidB}
+	// Mesmo valor e dia, outra descrição
+	pharmacy := Entry{ID: uuid.New(), Kind: "manual", Status: "posted", Amount: -4590, Description: "Farmácia", OccurredAt: at("2024-03-04")}
+	// Mesmo valor e descrição, longe demais
+	nextMonth := Entry{ID: uuid.New(), Kind: "manual", Status: "posted", Amount: -4590, Description: "Supermercado Extra", OccurredAt: at("2024-04-04")}
+
+	from, to := at("2024-03-01"), at("2024-04-30")
+	opts := DetectOptions{From: &from, To: &to, DateToleranceDays: defaultDateTolerance, MinSimilarity: defaultMinSimilarity}
+
+	t.Run("deve apontar pares de mesmo valor, datas próximas e descrições parecidas", func(t *testing.T) {
+		repo := newMockRepository(manual, imported, secondImport, pharmacy, nextMonth)
+		service := NewService(repo, &MockAccounts{account: account}, &MockMovements{repo: repo})
+
+		found, err := service.FindDuplicates(ctx, account.ID.String(), userID.String(), opts)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if len(found) != 2 {
+			t.Fatalf("esperava 2 pares, mas obteve %d: %+v", len(found), found)
+		}
+		for _, d := range found {
+			if d.Movements[0].ID != manual.ID || d.DaysApart != 1 || d.Similarity < defaultMinSimilarity {
+				t.Errorf("par incorreto: %+v", d)
+			}
+		}
+
+		strict := opts
+		strict.DateToleranceDays = 0
+		found, _ = service.FindDuplicates(ctx, account.ID.String(), userID.String(), strict)
+		if len(found) != 0 {
+			t.Errorf("esperava nenhum par no mesmo dia, mas obteve %+v", found)
+		}
+	})
+
+	t.Run("deve lembrar os pares dispensados", func(t *testing.T) {
+		repo := newMockRepository(manual, imported, secondImport)
+		service := NewService(repo, &MockAccounts{account: account}, &MockMovements{repo: repo})
+
+		err := service.DismissDuplicate(ctx, account.ID.String(), userID.String(), DismissRequest{MovementIDs: []uuid.UUID{imported.ID, manual.ID}})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if !repo.dismissed[newPair(manual.ID, imported.ID)] {
+			t.Error("esperava o par gravado na ordem da tabela")
+		}
+
+		found, _ := service.FindDuplicates(ctx, account.ID.String(), userID.String(), opts)
+		if len(found) != 1 || found[0].Movements[1].ID != secondImport.ID {
+			t.Errorf("esperava só o par com a segunda importação, mas obteve %+v", found)
+		}
+
+		cases := []struct {
+			ids []uuid.UUID
+			err error
+		}{
+			{[]uuid.UUID{manual.ID, manual.ID}, ErrSameMovement},
+			{[]uuid.UUID{manual.ID, uuid.New()}, movements.ErrMovementNotFound},
+		}
+		for _, c := range cases {
+			err := service.DismissDuplicate(ctx, account.ID.String(), userID.String(), DismissRequest{MovementIDs: c.ids})
+			if !errors.Is(err, c.err) {
+				t.Errorf("esperava o erro %v, mas obteve %v", c.err, err)
+			}
+		}
+	})
+}
+
+func TestService_MergeDuplicates(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	account := &accounts.Account{ID: uuid.New(), UserID: userID, Currency: "BRL"}
+	now := time.Now().UTC()
+	reconciledAt := now
+
+	keep := Entry{ID: uuid.New(), Kind: "imported", Status: "posted", Amount: -12000, Description: "Conta de luz", OccurredAt: now.Add(-48 * time.Hour), ReconciledAt: &reconciledAt}
+	duplicate := Entry{ID: uuid.New(), Kind: "manual", Status: "posted", Amount: -12000, Description: "Luz", OccurredAt: now.Add(-24 * time.Hour)}
+	other := Entry{ID: uuid.New(), Kind: "manual", Status: "posted", Amount: -11000, Description: "Conta de luz", OccurredAt: now.Add(-24 * time.Hour)}
+	hold := Entry{ID: uuid.New(), Kind: "manual", Status: "pending", Amount: -12000, Description: "Conta de luz", OccurredAt: now.Add(-24 * time.Hour)}
+
+	t.Run("deve estornar o duplicado e mantê-lo fora da detecção", func(t *testing.T) {
+		repo := newMockRepository(keep, duplicate)
+		service := NewService(repo, &MockAccounts{account: account}, &MockMovements{repo: repo})
+
+		reversal, err := service.MergeDuplicates(ctx, account.ID.String(), userID.String(), MergeRequest{KeepID: keep.ID, DuplicateID: duplicate.ID})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if reversal.ReversalOf == nil || *reversal.ReversalOf != duplicate.ID || reversal.Amount != 12000 {
+			t.Errorf("estorno incorreto: %+v", reversal)
+		}
+
+		found, _ := service.FindDuplicates(ctx, account.ID.String(), userID.String(), DetectOptions{DateToleranceDays: defaultDateTolerance, MinSimilarity: 0})
+		if len(found) != 0 {
+			t.Errorf("esperava nenhum par depois da união, mas obteve %+v", found)
+		}
+	})
+
+	t.Run("deve recusar pares que não são duplicados", func(t *testing.T) {
+		repo := newMockRepository(keep, duplicate, other, hold)
+		service := NewService(repo, &MockAccounts{account: account}, &MockMovements{repo: repo})
+
+		cases := []struct {
+			req MergeRequest
+			err error
+		}{
+			{MergeRequest{KeepID: keep.ID, DuplicateID: keep.ID}, ErrSameMovement},
+			{MergeRequest{KeepID: keep.ID, DuplicateID: other.ID}, ErrNotDuplicate},
+			{MergeRequest{KeepID: keep.ID, DuplicateID: hold.ID}, ErrNotDuplicate},
+			{MergeRequest{KeepID: duplicate.ID, DuplicateID: keep.ID}, movements.ErrMovementReconciled},
+			{MergeRequest{KeepID: keep.ID, DuplicateID: uuid.New()}, movements.ErrMovementNotFound},
+		}
+		for _, c := range cases {
+			_, err := service.MergeDuplicates(ctx, account.ID.String(), userID.String(), c.req)
+			if !errors.Is(err, c.err) {
+				t.Errorf("%+v: esperava o erro %v, mas obteve %v", c.req, c.err, err)
+			}
+		}
+		if len(repo.reversed) != 0 {
+			t.Errorf("esperava nenhum estorno, mas obteve %v", repo.reversed)
+		}
+	})
+}
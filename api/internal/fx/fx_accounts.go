@@ -0,0 +1,32 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/pkg/external/fxrates"
+)
+
+type accountsRateProvider struct {
+	service Service
+}
+
+// NewAccountsRateProvider expõe as cotações do módulo fx como o
+// accounts.RateProvider usado no resumo consolidado das contas
+func NewAccountsRateProvider(service Service) accounts.RateProvider {
+	return &accountsRateProvider{service: service}
+}
+
+func (p *accountsRateProvider) Rate(ctx context.Context, from string, to string, date time.Time) (*accounts.ExchangeRate, error) {
+	rate, err := p.service.Rate(ctx, from, to, date)
+	if err != nil {
+		if errors.Is(err, fxrates.ErrRateUnavailable) || errors.Is(err, fxrates.ErrProvider) {
+			return nil, fmt.Errorf("%w: %v", accounts.ErrRateUnavailable, err)
+		}
+		return nil, err
+	}
+	return &accounts.ExchangeRate{From: rate.Base, To: rate.Quote, Value: rate.Value, Date: rate.Date}, nil
+}
@@ -0,0 +1,192 @@
+package fx
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/martinsdevv/fincore/internal/auth"
+	"github.com/martinsdevv/fincore/pkg/external/fxrates"
+	"github.com/martinsdevv/fincore/pkg/money"
+	"github.com/rs/zerolog/log"
+)
+
+type Handler struct {
+	service  Service
+	validate *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	// Regras como gt=0 valem sobre o valor em unidades mínimas
+	validate.RegisterCustomTypeFunc(func(v reflect.Value) interface{} {
+		return v.Interface().(money.Money).Amount
+	}, money.Money{})
+
+	return &Handler{
+		service:  service,
+		validate: validate,
+	}
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("Falha ao escrever resposta JSON")
+	}
+}
+
+func (h *Handler) getUserIDFromContext(r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(auth.UserContextKey).(string)
+	if !ok {
+		log.Error().Msg("UserID não encontrado no contexto, middleware mal configurado")
+		return "", false
+	}
+	return userID, true
+}
+
+func (h *Handler) writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrFutureDate):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "exchange rates are not available for future dates"})
+	case errors.Is(err, fxrates.ErrRateUnavailable):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, fxrates.ErrProvider):
+		log.Error().Err(err).Msg("Falha no provedor de cotações")
+		h.writeJSON(w, http.StatusBadGateway, map[string]string{"error": "exchange rate provider unavailable"})
+	case errors.Is(err, ErrSameCurrency):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "quote currencies must differ"})
+	case errors.Is(err, money.ErrCurrencyMismatch):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, money.ErrInvalidAmount):
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, money.ErrOverflow):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "converted amount is too large"})
+	default:
+		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": fallback})
+	}
+}
+
+// parseDate lê ?date= no formato 2006-01-02; vazio pede a cotação mais recente
+func parseDate(raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return nil, errors.New("invalid date: expected YYYY-MM-DD")
+	}
+	return &date, nil
+}
+
+// HandleGetRates responde GET /fx/rates?base=USD&symbols=BRL,EUR&date=2024-01-02
+func (h *Handler) HandleGetRates(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	base := strings.ToUpper(q.Get("base"))
+	if err := h.validate.Var(base, "required,iso4217"); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "base must be an ISO 4217 currency code"})
+		return
+	}
+
+	var symbols []string
+	if raw := q.Get("symbols"); raw != "" {
+		for _, symbol := range strings.Split(raw, ",") {
+			symbol = strings.ToUpper(strings.TrimSpace(symbol))
+			if err := h.validate.Var(symbol, "iso4217"); err != nil {
+				h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid currency in symbols: " + symbol})
+				return
+			}
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	date, err := parseDate(q.Get("date"))
+	if err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	rates, err := h.service.GetRates(r.Context(), base, symbols, date)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve exchange rates")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, rates)
+}
+
+// HandleConvert responde GET /fx/convert?from=USD&to=BRL&amount=10.50&date=2024-01-02.
+// amount é decimal, com no máximo as casas da moeda de origem.
+func (h *Handler) HandleConvert(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	from, to := strings.ToUpper(q.Get("from")), strings.ToUpper(q.Get("to"))
+	if h.validate.Var(from, "required,iso4217") != nil || h.validate.Var(to, "required,iso4217") != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "from and to must be ISO 4217 currency codes"})
+		return
+	}
+
+	if q.Get("amount") == "" {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "amount is required"})
+		return
+	}
+	amount, err := money.Parse(q.Get("amount"), from)
+	if err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	date, err := parseDate(q.Get("date"))
+	if err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	conversion, err := h.service.Convert(r.Context(), amount, to, date)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to convert amount")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, conversion)
+}
+
+// HandleCreateQuote responde POST /fx/quotes, travando a cotação por alguns segundos
+func (h *Handler) HandleCreateQuote(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req CreateQuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if errors.Is(err, money.ErrInvalidAmount) {
+			h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+	req.From, req.To = strings.ToUpper(req.From), strings.ToUpper(req.To)
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	quote, err := h.service.CreateQuote(r.Context(), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to create quote")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, quote)
+}
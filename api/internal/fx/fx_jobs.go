@@ -0,0 +1,31 @@
+package fx
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RunECBSync importa as cotações do BCE de source a cada interval, até ctx ser
+// cancelado. A gravação substitui as cotações do mesmo dia, então várias réplicas
+// podem rodar o job ao mesmo tempo.
+func RunECBSync(ctx context.Context, svc Service, source string, currencies []string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		imported, err := svc.SyncECB(ctx, source, currencies)
+		if err != nil {
+			log.Error().Err(err).Str("source", source).Msg("Failed to sync ECB exchange rates")
+		} else {
+			log.Info().Int("rates", imported).Str("source", source).Msg("ECB exchange rates synced")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package fx
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/pkg/external/fxrates"
+	"github.com/martinsdevv/fincore/pkg/money"
+)
+
+// RateResponse é a cotação de 1 unidade da base numa moeda. Date é o dia
+// efetivo da cotação, que pode ser anterior ao pedido (fins de semana, feriados).
+type RateResponse struct {
+	Currency string  `json:"currency"`
+	Rate     float64 `json:"rate"`
+	Date     string  `json:"date"`
+}
+
+type RatesResponse struct {
+	Base  string         `json:"base"`
+	Rates []RateResponse `json:"rates"`
+}
+
+// ConversionResponse é um valor convertido com a cotação usada
+type ConversionResponse struct {
+	Amount   money.Money `json:"amount"`
+	Result   money.Money `json:"result"`
+	Rate     float64     `json:"rate"`
+	RateDate string      `json:"rate_date"`
+}
+
+func toRateResponse(rate fxrates.Rate) RateResponse {
+	return RateResponse{
+		Currency: rate.Quote,
+		Rate:     rate.Value,
+		Date:     rate.Date.Format(time.DateOnly),
+	}
+}
+
+// Quote é uma cotação travada por pouco tempo para converter Amount na moeda de
+// Result. Rate já desconta o Spread da cotação de mercado (MidRate).
+type Quote struct {
+	ID        uuid.UUID   `json:"id"`
+	UserID    uuid.UUID   `json:"user_id"`
+	Amount    money.Money `json:"amount"`
+	Result    money.Money `json:"result"`
+	MidRate   float64     `json:"mid_rate"`
+	Spread    float64     `json:"spread"`
+	Rate      float64     `json:"rate"`
+	RateDate  string      `json:"rate_date"`
+	CreatedAt time.Time   `json:"created_at"`
+	ExpiresAt time.Time   `json:"expires_at"`
+}
+
+// CreateQuoteRequest pede a cotação para converter amount (na moeda from, ou em
+// unidades mínimas dela) para to
+type CreateQuoteRequest struct {
+	From   string      `json:"from" validate:"required,iso4217"`
+	To     string      `json:"to" validate:"required,iso4217"`
+	Amount money.Money `json:"amount" validate:"gt=0"`
+}
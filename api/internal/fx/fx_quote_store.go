@@ -0,0 +1,75 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// QuoteStore guarda as cotações travadas até expirarem. Cada cotação só pode ser
+// consumida uma vez, e só por quem a pediu.
+type QuoteStore interface {
+	Save(ctx context.Context, quote *Quote, ttl time.Duration) error
+	// Pop devolve e apaga a cotação de userID. A de outro usuário fica intacta
+	// e volta nil, como uma inexistente.
+	Pop(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Quote, error)
+}
+
+type redisQuoteStore struct {
+	client *redis.Client
+}
+
+func NewRedisQuoteStore(client *redis.Client) QuoteStore {
+	return &redisQuoteStore{client: client}
+}
+
+func (s *redisQuoteStore) Save(ctx context.Context, quote *Quote, ttl time.Duration) error {
+	data, err := json.Marshal(quote)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, "fx:quote:"+quote.ID.String(), data, ttl).Err()
+}
+
+// Pop confere o dono antes de apagar. O WATCH faz o DEL falhar se outra
+// requisição consumir a cotação entre o GET e o DEL.
+func (s *redisQuoteStore) Pop(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Quote, error) {
+	key := "fx:quote:" + id.String()
+
+	var popped *Quote
+	err := s.client.Watch(ctx, func(tx *redis.Tx) error {
+		data, err := tx.Get(ctx, key).Bytes()
+		if err != nil {
+			return err
+		}
+		var quote Quote
+		if err := json.Unmarshal(data, &quote); err != nil {
+			return err
+		}
+		if quote.UserID != userID {
+			return nil
+		}
+
+		if _, err := tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, key)
+			return nil
+		}); err != nil {
+			return err
+		}
+		popped = &quote
+		return nil
+	}, key)
+	if err != nil {
+		// Cotação inexistente, expirada ou consumida em paralelo: (nil, nil),
+		// como nos repositórios
+		if errors.Is(err, redis.Nil) || errors.Is(err, redis.TxFailedErr) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return popped, nil
+}
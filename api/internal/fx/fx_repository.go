@@ -0,0 +1,101 @@
+package fx
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/martinsdevv/fincore/pkg/database"
+	"github.com/martinsdevv/fincore/pkg/external/fxrates"
+)
+
+type Repository interface {
+	// GetRates devolve, para cada moeda, a cotação guardada do dia date ou, se
+	// o provedor já respondeu por esse dia com uma cotação anterior (fim de
+	// semana, feriado), essa cotação com a data dela
+	GetRates(ctx context.Context, base string, symbols []string, date time.Time) ([]fxrates.Rate, error)
+	// SaveRates grava as cotações no histórico, substituindo as do mesmo dia.
+	// Com requested, registra as que vieram de um dia anterior como a resposta
+	// do provedor para requested; zero quando não houve um dia pedido.
+	SaveRates(ctx context.Context, rates []fxrates.Rate, source string, requested time.Time) error
+}
+
+type pgxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgxRepository{db: db}
+}
+
+// conn usa a transação aberta por um database.Transactor, se houver
+func (r *pgxRepository) conn(ctx context.Context) database.DBTX {
+	return database.Conn(ctx, r.db)
+}
+
+func (r *pgxRepository) GetRates(ctx context.Context, base string, symbols []string, date time.Time) ([]fxrates.Rate, error) {
+	query := `
+		SELECT DISTINCT ON (r.quote) r.base, r.quote, r.rate::float8, r.rate_date
+		FROM fx_rates r
+		LEFT JOIN fx_rate_requests q
+			ON q.base = r.base AND q.quote = r.quote AND q.rate_date = r.rate_date AND q.requested_date = $3::date
+		WHERE r.base = $1 AND r.quote = ANY($2) AND (r.rate_date = $3::date OR q.requested_date IS NOT NULL)
+		ORDER BY r.quote, r.rate_date DESC`
+
+	rows, err := r.conn(ctx).Query(ctx, query, base, symbols, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []fxrates.Rate
+	for rows.Next() {
+		var rate fxrates.Rate
+		if err := rows.Scan(&rate.Base, &rate.Quote, &rate.Value, &rate.Date); err != nil {
+			return nil, err
+		}
+		rate.Date = fxrates.Day(rate.Date)
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+func (r *pgxRepository) SaveRates(ctx context.Context, rates []fxrates.Rate, source string, requested time.Time) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	bases := make([]string, len(rates))
+	quotes := make([]string, len(rates))
+	values := make([]float64, len(rates))
+	dates := make([]time.Time, len(rates))
+	for i, rate := range rates {
+		bases[i], quotes[i], values[i], dates[i] = rate.Base, rate.Quote, rate.Value, rate.Date
+	}
+
+	query := `
+		INSERT INTO fx_rates (base, quote, rate, rate_date, source)
+		SELECT b, q, v, d, $5
+		FROM unnest($1::text[], $2::text[], $3::float8[], $4::date[]) AS t(b, q, v, d)
+		ON CONFLICT (base, quote, rate_date) DO UPDATE
+		SET rate = EXCLUDED.rate, source = EXCLUDED.source, fetched_at = NOW()`
+
+	conn := r.conn(ctx)
+	if _, err := conn.Exec(ctx, query, bases, quotes, values, dates, source); err != nil {
+		return err
+	}
+	if requested.IsZero() {
+		return nil
+	}
+
+	query = `
+		INSERT INTO fx_rate_requests (base, quote, requested_date, rate_date)
+		SELECT b, q, $4::date, d
+		FROM unnest($1::text[], $2::text[], $3::date[]) AS t(b, q, d)
+		WHERE d < $4::date
+		ON CONFLICT (base, quote, requested_date) DO UPDATE
+		SET rate_date = EXCLUDED.rate_date`
+
+	_, err := conn.Exec(ctx, query, bases, quotes, dates, requested)
+	return err
+}
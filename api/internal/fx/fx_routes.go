@@ -0,0 +1,9 @@
+package fx
+
+import "github.com/go-chi/chi/v5"
+
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/fx/rates", h.HandleGetRates)
+	r.Get("/fx/convert", h.HandleConvert)
+	r.Post("/fx/quotes", h.HandleCreateQuote)
+}
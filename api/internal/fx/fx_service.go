@@ -0,0 +1,266 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/pkg/external/fxrates"
+	"github.com/martinsdevv/fincore/pkg/money"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrFutureDate    = errors.New("exchange rates are not available for future dates")
+	ErrSameCurrency  = errors.New("quote currencies must differ")
+	ErrQuoteNotFound = errors.New("quote not found or expired")
+	ErrQuoteMismatch = errors.New("quote does not match the operation")
+)
+
+type Service interface {
+	GetRates(ctx context.Context, base string, symbols []string, date *time.Time) (*RatesResponse, error)
+	Convert(ctx context.Context, amount money.Money, to string, date *time.Time) (*ConversionResponse, error)
+
+	CreateQuote(ctx context.Context, userID string, req CreateQuoteRequest) (*Quote, error)
+
+	// Usados pelos outros módulos; date zero pede a cotação mais recente
+	Rate(ctx context.Context, from string, to string, date time.Time) (*fxrates.Rate, error)
+	ConsumeQuote(ctx context.Context, quoteID uuid.UUID, userID uuid.UUID) (*Quote, error)
+
+	// SyncECB importa para o histórico as cotações de referência do BCE lidas de
+	// source (URL ou caminho) e as cotações cruzadas entre currencies
+	SyncECB(ctx context.Context, source string, currencies []string) (int, error)
+}
+
+// QuoteOptions define por quanto tempo uma cotação fica travada e o spread
+// (fração da cotação de mercado, 0.005 = 0,5%) cobrado nas conversões
+type QuoteOptions struct {
+	TTL    time.Duration
+	Spread float64
+}
+
+type service struct {
+	repo     Repository
+	provider fxrates.RateProvider
+	quotes   QuoteStore
+	opts     QuoteOptions
+}
+
+func NewService(repo Repository, provider fxrates.RateProvider, quotes QuoteStore, opts QuoteOptions) Service {
+	return &service{repo: repo, provider: provider, quotes: quotes, opts: opts}
+}
+
+func (s *service) GetRates(ctx context.Context, base string, symbols []string, date *time.Time) (*RatesResponse, error) {
+	var day time.Time
+	if date != nil {
+		day = *date
+	}
+
+	rates, err := s.rates(ctx, base, symbols, day)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &RatesResponse{Base: strings.ToUpper(base), Rates: make([]RateResponse, len(rates))}
+	for i, rate := range rates {
+		response.Rates[i] = toRateResponse(rate)
+	}
+	return response, nil
+}
+
+func (s *service) Convert(ctx context.Context, amount money.Money, to string, date *time.Time) (*ConversionResponse, error) {
+	var day time.Time
+	if date != nil {
+		day = *date
+	}
+
+	rate, err := s.Rate(ctx, amount.Currency, to, day)
+	if err != nil {
+		return nil, err
+	}
+	result, err := amount.Convert(rate.Value, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ConversionResponse{
+		Amount:   amount,
+		Result:   result,
+		Rate:     rate.Value,
+		RateDate: rate.Date.Format(time.DateOnly),
+	}, nil
+}
+
+// CreateQuote trava a cotação atual, já com o spread, por opts.TTL
+func (s *service) CreateQuote(ctx context.Context, userIDStr string, req CreateQuoteRequest) (*Quote, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := strings.ToUpper(req.From), strings.ToUpper(req.To)
+	if from == to {
+		return nil, ErrSameCurrency
+	}
+	amount := req.Amount
+	if amount.Currency == "" {
+		amount = money.New(amount.Amount, from)
+	}
+	if amount.Currency != from {
+		return nil, fmt.Errorf("%w: amount in %s for a quote from %s", money.ErrCurrencyMismatch, amount.Currency, from)
+	}
+	if amount.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", money.ErrInvalidAmount)
+	}
+
+	mid, err := s.Rate(ctx, from, to, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	rate := mid.Value * (1 - s.opts.Spread)
+	result, err := amount.Convert(rate, to)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	quote := &Quote{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Amount:    amount,
+		Result:    result,
+		MidRate:   mid.Value,
+		Spread:    s.opts.Spread,
+		Rate:      rate,
+		RateDate:  mid.Date.Format(time.DateOnly),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.opts.TTL),
+	}
+	if err := s.quotes.Save(ctx, quote, s.opts.TTL); err != nil {
+		log.Error().Err(err).Str("userID", userIDStr).Msg("Failed to store fx quote")
+		return nil, err
+	}
+	return quote, nil
+}
+
+// ConsumeQuote entrega a cotação uma única vez, e só para quem a pediu. A
+// cotação de outro usuário é ErrQuoteNotFound e continua valendo para o dono,
+// para o ID não servir para descobrir nem para descartar cotações alheias.
+func (s *service) ConsumeQuote(ctx context.Context, quoteID uuid.UUID, userID uuid.UUID) (*Quote, error) {
+	quote, err := s.quotes.Pop(ctx, quoteID, userID)
+	if err != nil {
+		return nil, err
+	}
+	// O TTL do Redis já expira a cotação; a checagem cobre atrasos na expiração
+	if quote == nil || !time.Now().Before(quote.ExpiresAt) {
+		return nil, ErrQuoteNotFound
+	}
+	return quote, nil
+}
+
+func (s *service) Rate(ctx context.Context, from string, to string, date time.Time) (*fxrates.Rate, error) {
+	rates, err := s.rates(ctx, from, []string{to}, date)
+	if err != nil {
+		return nil, err
+	}
+	return &rates[0], nil
+}
+
+// Quantas cotações vão em cada gravação; o histórico completo do BCE passa de 200 mil
+const syncBatchSize = 5000
+
+func (s *service) SyncECB(ctx context.Context, source string, currencies []string) (int, error) {
+	eurRates, err := fxrates.LoadECB(ctx, source, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	rates := fxrates.CrossRates(eurRates, currencies)
+	for start := 0; start < len(rates); start += syncBatchSize {
+		end := min(start+syncBatchSize, len(rates))
+		if err := s.repo.SaveRates(ctx, rates[start:end], "ecb", time.Time{}); err != nil {
+			return start, err
+		}
+	}
+	return len(rates), nil
+}
+
+// rates procura primeiro no histórico (só para datas fechadas) e busca no
+// provedor apenas as moedas que faltam, gravando o que vier no histórico
+func (s *service) rates(ctx context.Context, base string, symbols []string, date time.Time) ([]fxrates.Rate, error) {
+	base = strings.ToUpper(base)
+	symbols = fxrates.NormalizeSymbols(symbols)
+
+	today := fxrates.Day(time.Now())
+	date = fxrates.Day(date)
+	if date.After(today) {
+		return nil, ErrFutureDate
+	}
+
+	found := make(map[string]fxrates.Rate, len(symbols))
+	var missing []string
+	for _, quote := range symbols {
+		if quote == base {
+			rateDate := date
+			if rateDate.IsZero() {
+				rateDate = today
+			}
+			found[quote] = fxrates.Rate{Base: base, Quote: quote, Value: 1, Date: rateDate}
+			continue
+		}
+		missing = append(missing, quote)
+	}
+
+	if !date.IsZero() && len(missing) > 0 {
+		stored, err := s.repo.GetRates(ctx, base, missing, date)
+		if err != nil {
+			return nil, err
+		}
+		for _, rate := range stored {
+			found[rate.Quote] = rate
+		}
+		missing = missing[:0]
+		for _, quote := range symbols {
+			if _, ok := found[quote]; !ok {
+				missing = append(missing, quote)
+			}
+		}
+	}
+
+	// Sem symbols, todas as moedas do provedor
+	if len(symbols) == 0 || len(missing) > 0 {
+		fetched, err := s.provider.Rates(ctx, base, missing, date)
+		if err != nil {
+			return nil, err
+		}
+		// Só um dia já fechado fica ligado à cotação anterior que o provedor
+		// mandou; hoje a fonte ainda pode publicar a do dia
+		var requested time.Time
+		if !date.IsZero() && date.Before(today) {
+			requested = date
+		}
+		// O histórico é só um registro: falhar ao gravar não impede a resposta
+		if err := s.repo.SaveRates(ctx, fetched, s.provider.Name(), requested); err != nil {
+			log.Error().Err(err).Str("base", base).Msg("Failed to store exchange rates")
+		}
+		if len(symbols) == 0 {
+			return fetched, nil
+		}
+		for _, rate := range fetched {
+			found[rate.Quote] = rate
+		}
+	}
+
+	rates := make([]fxrates.Rate, 0, len(symbols))
+	for _, quote := range symbols {
+		rate, ok := found[quote]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s to %s", fxrates.ErrRateUnavailable, base, quote)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, nil
+}
@@ -0,0 +1,347 @@
+package fx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/pkg/external/fxrates"
+	"github.com/martinsdevv/fincore/pkg/money"
+)
+
+type MockRepository struct {
+	GetRatesFunc  func(ctx context.Context, base string, symbols []string, date time.Time) ([]fxrates.Rate, error)
+	SaveRatesFunc func(ctx context.Context, rates []fxrates.Rate, source string, requested time.Time) error
+}
+
+func (m *MockRepository) GetRates(ctx context.Context, base string, symbols []string, date time.Time) ([]fxrates.Rate, error) {
+	if m.GetRatesFunc != nil {
+		return m.GetRatesFunc(ctx, base, symbols, date)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) SaveRates(ctx context.Context, rates []fxrates.Rate, source string, requested time.Time) error {
+	if m.SaveRatesFunc != nil {
+		return m.SaveRatesFunc(ctx, rates, source, requested)
+	}
+	return nil
+}
+
+// MockProvider cota cada moeda por table[moeda] unidades de base e guarda o que foi pedido
+type MockProvider struct {
+	table    map[string]float64
+	date     time.Time
+	requests [][]string
+}
+
+func (m *MockProvider) Name() string {
+	return "mock"
+}
+
+func (m *MockProvider) Rates(ctx context.Context, base string, symbols []string, date time.Time) ([]fxrates.Rate, error) {
+	m.requests = append(m.requests, symbols)
+	var rates []fxrates.Rate
+	for _, quote := range symbols {
+		value, ok := m.table[quote]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s to %s", fxrates.ErrRateUnavailable, base, quote)
+		}
+		rates = append(rates, fxrates.Rate{Base: base, Quote: quote, Value: value, Date: m.date})
+	}
+	return rates, nil
+}
+
+func day(s string) time.Time {
+	d, _ := time.Parse(time.DateOnly, s)
+	return d
+}
+
+// newHistoryRepository guarda as cotações em memória como o pgxRepository:
+// um dia sem cotação própria só acha a anterior que o provedor deu para ele
+func newHistoryRepository(stored ...fxrates.Rate) *MockRepository {
+	requested := map[string]time.Time{}
+	key := func(quote string, d time.Time) string { return quote + d.Format(time.DateOnly) }
+	return &MockRepository{
+		GetRatesFunc: func(ctx context.Context, base string, symbols []string, d time.Time) ([]fxrates.Rate, error) {
+			var rates []fxrates.Rate
+			for _, quote := range symbols {
+				for _, rate := range stored {
+					if rate.Quote != quote {
+						continue
+					}
+					if rate.Date.Equal(d) || rate.Date.Equal(requested[key(quote, d)]) {
+						rates = append(rates, rate)
+					}
+				}
+			}
+			return rates, nil
+		},
+		SaveRatesFunc: func(ctx context.Context, rates []fxrates.Rate, source string, day time.Time) error {
+			stored = append(stored, rates...)
+			for _, rate := range rates {
+				if !day.IsZero() && rate.Date.Before(day) {
+					requested[key(rate.Quote, day)] = rate.Date
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func TestService_GetRates(t *testing.T) {
+	ctx := context.Background()
+	date := day("2024-01-05")
+
+	t.Run("deve usar o histórico e buscar só o que falta", func(t *testing.T) {
+		provider := &MockProvider{table: map[string]float64{"EUR": 0.91}, date: date}
+		var saved []fxrates.Rate
+		service := NewService(&MockRepository{
+			GetRatesFunc: func(ctx context.Context, base string, symbols []string, d time.Time) ([]fxrates.Rate, error) {
+				if !d.Equal(date) {
+					t.Errorf("esperava a data %v, obteve %v", date, d)
+				}
+				return []fxrates.Rate{{Base: base, Quote: "BRL", Value: 4.9, Date: date}}, nil
+			},
+			SaveRatesFunc: func(ctx context.Context, rates []fxrates.Rate, source string, requested time.Time) error {
+				saved = rates
+				return nil
+			},
+		}, provider, nil, QuoteOptions{})
+
+		resp, err := service.GetRates(ctx, "usd", []string{"eur", "BRL", "USD"}, &date)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if len(provider.requests) != 1 || len(provider.requests[0]) != 1 || provider.requests[0][0] != "EUR" {
+			t.Errorf("esperava buscar só EUR no provedor, obteve %v", provider.requests)
+		}
+		if len(saved) != 1 || saved[0].Quote != "EUR" {
+			t.Errorf("esperava gravar a cotação buscada, obteve %v", saved)
+		}
+		if resp.Base != "USD" || len(resp.Rates) != 3 || resp.Rates[0].Currency != "BRL" || resp.Rates[2].Rate != 1 {
+			t.Errorf("cotações incorretas: %+v", resp)
+		}
+	})
+
+	t.Run("deve ir direto ao provedor para a cotação mais recente", func(t *testing.T) {
+		provider := &MockProvider{table: map[string]float64{"BRL": 4.9}, date: date}
+		service := NewService(&MockRepository{
+			GetRatesFunc: func(ctx context.Context, base string, symbols []string, d time.Time) ([]fxrates.Rate, error) {
+				t.Error("não deveria consultar o histórico sem data")
+				return nil, nil
+			},
+		}, provider, nil, QuoteOptions{})
+
+		resp, err := service.GetRates(ctx, "USD", []string{"BRL"}, nil)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if resp.Rates[0].Date != "2024-01-05" {
+			t.Errorf("esperava a data efetiva da cotação, obteve %s", resp.Rates[0].Date)
+		}
+	})
+
+	t.Run("deve buscar no provedor o dia útil que falta no histórico", func(t *testing.T) {
+		monday, tuesday := day("2024-01-08"), day("2024-01-09")
+		repo := newHistoryRepository(fxrates.Rate{Base: "USD", Quote: "BRL", Value: 4.9, Date: monday})
+		provider := &MockProvider{table: map[string]float64{"BRL": 4.95}, date: tuesday}
+		service := NewService(repo, provider, nil, QuoteOptions{})
+
+		resp, err := service.GetRates(ctx, "USD", []string{"BRL"}, &tuesday)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if len(provider.requests) != 1 {
+			t.Errorf("esperava buscar a terça no provedor, obteve %v", provider.requests)
+		}
+		if resp.Rates[0].Rate != 4.95 || resp.Rates[0].Date != "2024-01-09" {
+			t.Errorf("esperava a cotação da terça, obteve %+v", resp.Rates[0])
+		}
+	})
+
+	t.Run("deve reaproveitar a cotação que o provedor deu para um fim de semana", func(t *testing.T) {
+		friday, saturday := day("2024-01-05"), day("2024-01-06")
+		provider := &MockProvider{table: map[string]float64{"BRL": 4.9}, date: friday}
+		service := NewService(newHistoryRepository(), provider, nil, QuoteOptions{})
+
+		for range 2 {
+			resp, err := service.GetRates(ctx, "USD", []string{"BRL"}, &saturday)
+			if err != nil {
+				t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+			}
+			if resp.Rates[0].Date != "2024-01-05" {
+				t.Errorf("esperava a cotação da sexta, obteve %+v", resp.Rates[0])
+			}
+		}
+		if len(provider.requests) != 1 {
+			t.Errorf("esperava ir ao provedor uma vez só, obteve %v", provider.requests)
+		}
+	})
+
+	t.Run("deve recusar datas futuras", func(t *testing.T) {
+		service := NewService(&MockRepository{}, &MockProvider{}, nil, QuoteOptions{})
+		future := time.Now().AddDate(0, 0, 2)
+
+		if _, err := service.GetRates(ctx, "USD", []string{"BRL"}, &future); !errors.Is(err, ErrFutureDate) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrFutureDate, err)
+		}
+	})
+
+	t.Run("não deve falhar se o histórico não gravar", func(t *testing.T) {
+		service := NewService(&MockRepository{
+			SaveRatesFunc: func(ctx context.Context, rates []fxrates.Rate, source string, requested time.Time) error {
+				return errors.New("db down")
+			},
+		}, &MockProvider{table: map[string]float64{"BRL": 4.9}, date: date}, nil, QuoteOptions{})
+
+		if _, err := service.GetRates(ctx, "USD", []string{"BRL"}, nil); err != nil {
+			t.Errorf("esperava nenhum erro, mas obteve %v", err)
+		}
+	})
+}
+
+func TestService_Convert(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(&MockRepository{}, &MockProvider{table: map[string]float64{"JPY": 29.5}, date: day("2024-01-05")}, nil, QuoteOptions{})
+
+	resp, err := service.Convert(ctx, money.New(1050, "BRL"), "JPY", nil)
+	if err != nil {
+		t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+	}
+	// R$ 10,50 * 29.5 = ¥309.75 → 310
+	if resp.Result != money.New(310, "JPY") || resp.Rate != 29.5 || resp.RateDate != "2024-01-05" {
+		t.Errorf("conversão incorreta: %+v", resp)
+	}
+}
+
+func TestAccountsRateProvider(t *testing.T) {
+	provider := NewAccountsRateProvider(NewService(&MockRepository{}, &MockProvider{table: map[string]float64{"BRL": 5}}, nil, QuoteOptions{}))
+
+	rate, err := provider.Rate(context.Background(), "USD", "BRL", time.Now())
+	if err != nil || rate.Value != 5 || rate.From != "USD" || rate.To != "BRL" {
+		t.Errorf("cotação incorreta: %+v %v", rate, err)
+	}
+	if _, err := provider.Rate(context.Background(), "USD", "GBP", time.Now()); !errors.Is(err, accounts.ErrRateUnavailable) {
+		t.Errorf("esperava o erro %v, mas obteve %v", accounts.ErrRateUnavailable, err)
+	}
+}
+
+// MockQuoteStore guarda as cotações em memória
+type MockQuoteStore struct {
+	quotes map[uuid.UUID]*Quote
+}
+
+func (m *MockQuoteStore) Save(ctx context.Context, quote *Quote, ttl time.Duration) error {
+	if m.quotes == nil {
+		m.quotes = make(map[uuid.UUID]*Quote)
+	}
+	m.quotes[quote.ID] = quote
+	return nil
+}
+
+func (m *MockQuoteStore) Pop(ctx context.Context, id uuid.UUID, userID uuid.UUID) (*Quote, error) {
+	quote := m.quotes[id]
+	if quote == nil || quote.UserID != userID {
+		return nil, nil
+	}
+	delete(m.quotes, id)
+	return quote, nil
+}
+
+func TestService_Quotes(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	store := &MockQuoteStore{}
+	service := NewService(&MockRepository{}, &MockProvider{table: map[string]float64{"BRL": 5}, date: day("2024-01-05")},
+		store, QuoteOptions{TTL: time.Minute, Spread: 0.01})
+
+	t.Run("deve travar a cotação com o spread", func(t *testing.T) {
+		quote, err := service.CreateQuote(ctx, userID.String(), CreateQuoteRequest{From: "USD", To: "BRL", Amount: money.Money{Amount: 10000}})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		// US$ 100,00 * 5 * 0,99 = R$ 495,00
+		if quote.Amount != money.New(10000, "USD") || quote.Result != money.New(49500, "BRL") || quote.MidRate != 5 || quote.Spread != 0.01 {
+			t.Errorf("cotação incorreta: %+v", quote)
+		}
+		if !quote.ExpiresAt.After(time.Now()) {
+			t.Errorf("cotação já expirada: %v", quote.ExpiresAt)
+		}
+
+		consumed, err := service.ConsumeQuote(ctx, quote.ID, userID)
+		if err != nil || consumed.ID != quote.ID {
+			t.Fatalf("esperava consumir a cotação, obteve %+v %v", consumed, err)
+		}
+		if _, err := service.ConsumeQuote(ctx, quote.ID, userID); !errors.Is(err, ErrQuoteNotFound) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrQuoteNotFound, err)
+		}
+	})
+
+	t.Run("deve recusar cotação expirada ou de outro usuário", func(t *testing.T) {
+		expired := &Quote{ID: uuid.New(), UserID: userID, ExpiresAt: time.Now().Add(-time.Second)}
+		other := &Quote{ID: uuid.New(), UserID: uuid.New(), ExpiresAt: time.Now().Add(time.Minute)}
+		_ = store.Save(ctx, expired, 0)
+		_ = store.Save(ctx, other, 0)
+
+		if _, err := service.ConsumeQuote(ctx, expired.ID, userID); !errors.Is(err, ErrQuoteNotFound) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrQuoteNotFound, err)
+		}
+		if _, err := service.ConsumeQuote(ctx, other.ID, userID); !errors.Is(err, ErrQuoteNotFound) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrQuoteNotFound, err)
+		}
+		// A tentativa alheia não descarta a cotação do dono
+		if consumed, err := service.ConsumeQuote(ctx, other.ID, other.UserID); err != nil || consumed.ID != other.ID {
+			t.Errorf("esperava o dono consumir a cotação, obteve %+v %v", consumed, err)
+		}
+	})
+
+	t.Run("deve recusar moedas iguais ou valor em outra moeda", func(t *testing.T) {
+		if _, err := service.CreateQuote(ctx, userID.String(), CreateQuoteRequest{From: "BRL", To: "brl", Amount: money.Money{Amount: 1}}); !errors.Is(err, ErrSameCurrency) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrSameCurrency, err)
+		}
+		if _, err := service.CreateQuote(ctx, userID.String(), CreateQuoteRequest{From: "USD", To: "BRL", Amount: money.New(1, "EUR")}); !errors.Is(err, money.ErrCurrencyMismatch) {
+			t.Errorf("esperava o erro %v, mas obteve %v", money.ErrCurrencyMismatch, err)
+		}
+	})
+}
+
+func TestService_SyncECB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eurofxref.xml")
+	xml := `<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+		<Cube><Cube time="2024-01-05"><Cube currency="USD" rate="1.0921"/><Cube currency="BRL" rate="5.3487"/></Cube></Cube>
+	</gesmes:Envelope>`
+	if err := os.WriteFile(path, []byte(xml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var saved []fxrates.Rate
+	service := NewService(&MockRepository{
+		SaveRatesFunc: func(ctx context.Context, rates []fxrates.Rate, source string, requested time.Time) error {
+			if source != "ecb" {
+				t.Errorf("esperava a fonte ecb, obteve %s", source)
+			}
+			saved = append(saved, rates...)
+			return nil
+		},
+	}, nil, nil, QuoteOptions{})
+
+	imported, err := service.SyncECB(context.Background(), path, nil)
+	if err != nil {
+		t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+	}
+	// EUR, USD e BRL em todos os sentidos
+	if imported != 6 || len(saved) != 6 {
+		t.Errorf("esperava 6 cotações, obteve %d (%d gravadas)", imported, len(saved))
+	}
+
+	if _, err := service.SyncECB(context.Background(), filepath.Join(t.TempDir(), "nada.xml"), nil); err == nil {
+		t.Error("esperava erro para arquivo inexistente")
+	}
+}
@@ -0,0 +1,360 @@
+package imports
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/auth"
+	"github.com/martinsdevv/fincore/pkg/iban"
+	"github.com/martinsdevv/fincore/pkg/money"
+	"github.com/martinsdevv/fincore/pkg/statement"
+	"github.com/rs/zerolog/log"
+)
+
+// maxUploadSize limita o corpo de POST /accounts/{id}/imports
+const maxUploadSize = 5 << 20
+
+type Handler struct {
+	service  Service
+	validate *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(validator.WithRequiredStructEnabled()),
+	}
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("Falha ao escrever resposta JSON")
+	}
+}
+
+func (h *Handler) getUserIDFromContext(r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(auth.UserContextKey).(string)
+	if !ok {
+		log.Error().Msg("UserID não encontrado no contexto, middleware mal configurado")
+		return "", false
+	}
+	return userID, true
+}
+
+// writeServiceError traduz os erros do serviço, incluindo os da conta, para respostas HTTP
+func (h *Handler) writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrProfileNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "import profile not found"})
+	case errors.Is(err, ErrProfileExists):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrProfileRequired):
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrImportNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "import not found"})
+	case errors.Is(err, statement.ErrInvalidProfile), errors.Is(err, statement.ErrInvalidFile),
+		errors.Is(err, statement.ErrUnknownEncoding), errors.Is(err, money.ErrCurrencyMismatch),
+		errors.Is(err, ErrNoAccountForIBAN), errors.Is(err, iban.ErrInvalidIBAN):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, accounts.ErrAmbiguousIBAN):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, accounts.ErrAccountNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "account not found"})
+	case errors.Is(err, accounts.ErrForbidden):
+		h.writeJSON(w, http.StatusForbidden, map[string]string{"error": "you do not have permission for this account"})
+	case errors.Is(err, accounts.ErrAccountArchived):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": "account is archived"})
+	default:
+		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": fallback})
+	}
+}
+
+func (h *Handler) decodeProfileRequest(w http.ResponseWriter, r *http.Request) (ProfileRequest, bool) {
+	var req ProfileRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return req, false
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return req, false
+	}
+	return req, true
+}
+
+func (h *Handler) HandleCreateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	req, ok := h.decodeProfileRequest(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := h.service.CreateProfile(r.Context(), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to create import profile")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, profile)
+}
+
+func (h *Handler) HandleListProfiles(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	profiles, err := h.service.ListProfiles(r.Context(), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve import profiles")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, profiles)
+}
+
+func (h *Handler) HandleGetProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	profile, err := h.service.GetProfile(r.Context(), chi.URLParam(r, "profileID"), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve import profile")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, profile)
+}
+
+func (h *Handler) HandleUpdateProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	req, ok := h.decodeProfileRequest(w, r)
+	if !ok {
+		return
+	}
+
+	profile, err := h.service.UpdateProfile(r.Context(), chi.URLParam(r, "profileID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to update import profile")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, profile)
+}
+
+func (h *Handler) HandleDeleteProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	if err := h.service.DeleteProfile(r.Context(), chi.URLParam(r, "profileID"), userID); err != nil {
+		h.writeServiceError(w, err, "failed to delete import profile")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleImport recebe multipart/form-data com o arquivo em "file" e o formato
+// em "format" (csv, ofx ou qif; sem ele, vale a extensão do arquivo). O CSV
+// precisa do perfil em "profile_id"; o QIF aceita "encoding", "date_format" e
+// "decimal_separator". Com ?dry_run=true responde 200 com a prévia, sem
+// gravar nada.
+func (h *Handler) HandleImport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	dryRun, ok := h.parseDryRun(w, r)
+	if !ok {
+		return
+	}
+	fileName, data, ok := h.readUpload(w, r)
+	if !ok {
+		return
+	}
+
+	format, ok := formatFromRequest(r.FormValue("format"), fileName)
+	if !ok {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "format must be csv, ofx or qif"})
+		return
+	}
+	req := ImportRequest{
+		Format:           format,
+		Encoding:         r.FormValue("encoding"),
+		DateFormat:       r.FormValue("date_format"),
+		DecimalSeparator: r.FormValue("decimal_separator"),
+		FileName:         fileName,
+		Data:             data,
+		DryRun:           dryRun,
+	}
+	if raw := r.FormValue("profile_id"); raw != "" {
+		profileID, err := uuid.Parse(raw)
+		if err != nil {
+			h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid profile_id"})
+			return
+		}
+		req.ProfileID = &profileID
+	}
+	result, err := h.service.Import(r.Context(), chi.URLParam(r, "accountID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to import statement")
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	h.writeJSON(w, status, result)
+}
+
+// HandleImportCAMT053 recebe um camt.053 em "file" e importa cada extrato na
+// conta com o IBAN dele. Responde com uma importação por extrato.
+func (h *Handler) HandleImportCAMT053(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	dryRun, ok := h.parseDryRun(w, r)
+	if !ok {
+		return
+	}
+	fileName, data, ok := h.readUpload(w, r)
+	if !ok {
+		return
+	}
+
+	results, err := h.service.ImportCAMT053(r.Context(), userID, CAMTImportRequest{FileName: fileName, Data: data, DryRun: dryRun})
+	if err != nil {
+		h.writeServiceError(w, err, "failed to import statement")
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	h.writeJSON(w, status, results)
+}
+
+func (h *Handler) parseDryRun(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	raw := r.URL.Query().Get("dry_run")
+	if raw == "" {
+		return false, true
+	}
+	dryRun, err := strconv.ParseBool(raw)
+	if err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "dry_run must be true or false"})
+		return false, false
+	}
+	return dryRun, true
+}
+
+// readUpload lê o arquivo do campo "file" de um multipart/form-data de até 5 MB
+func (h *Handler) readUpload(w http.ResponseWriter, r *http.Request) (string, []byte, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": "file is larger than 5 MB"})
+			return "", nil, false
+		}
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "expected a multipart/form-data body"})
+		return "", nil, false
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "file is required"})
+		return "", nil, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "failed to read file"})
+		return "", nil, false
+	}
+	return header.Filename, data, true
+}
+
+// formatFromRequest usa o formato informado ou, sem ele, a extensão do
+// arquivo; .qfx é o OFX do Quicken e qualquer outra extensão vale como CSV
+func formatFromRequest(value string, fileName string) (Format, bool) {
+	if value != "" {
+		return ParseFormat(value)
+	}
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".ofx", ".qfx":
+		return FormatOFX, true
+	case ".qif":
+		return FormatQIF, true
+	}
+	return FormatCSV, true
+}
+
+func (h *Handler) HandleListImports(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	imports, err := h.service.ListImports(r.Context(), chi.URLParam(r, "accountID"), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve imports")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, imports)
+}
+
+func (h *Handler) HandleGetImport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	imp, err := h.service.GetImport(r.Context(), chi.URLParam(r, "accountID"), chi.URLParam(r, "importID"), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve import")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, imp)
+}
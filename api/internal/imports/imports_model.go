@@ -0,0 +1,192 @@
+package imports
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/pkg/statement"
+)
+
+// Format é o tipo de arquivo importado
+type Format string
+
+const (
+	FormatCSV Format = "csv" // lido com um Profile
+	FormatOFX Format = "ofx" // traz a moeda e o FITID de cada lançamento
+	FormatQIF Format = "qif"
+	// O camt.053 escolhe a conta pelo IBAN e tem endpoint próprio
+	FormatCAMT053 Format = "camt053"
+)
+
+// ParseFormat aceita o formato em qualquer caixa; vazio não é um formato
+func ParseFormat(s string) (Format, bool) {
+	f := Format(strings.ToLower(strings.TrimSpace(s)))
+	switch f {
+	case FormatCSV, FormatOFX, FormatQIF:
+		return f, true
+	}
+	return "", false
+}
+
+type ImportStatus string
+
+const (
+	StatusRunning   ImportStatus = "running"
+	StatusCompleted ImportStatus = "completed" // terminou, mesmo com linhas recusadas
+	StatusFailed    ImportStatus = "failed"    // parou no meio por um erro inesperado
+)
+
+// Profile diz como ler o CSV de um banco; veja statement.CSVProfile
+type Profile struct {
+	ID                uuid.UUID
+	UserID            uuid.UUID
+	Name              string
+	Encoding          string
+	Delimiter         string
+	SkipRows          int
+	DateColumn        string
+	DateFormat        string
+	DescriptionColumn string
+	AmountColumn      *string
+	DebitColumn       *string
+	CreditColumn      *string
+	DecimalSeparator  string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (p *Profile) CSV() statement.CSVProfile {
+	delimiter, _ := utf8.DecodeRuneInString(p.Delimiter)
+	value := func(s *string) string {
+		if s == nil {
+			return ""
+		}
+		return *s
+	}
+	return statement.CSVProfile{
+		Encoding:          p.Encoding,
+		Delimiter:         delimiter,
+		SkipRows:          p.SkipRows,
+		DateColumn:        p.DateColumn,
+		DateFormat:        p.DateFormat,
+		DescriptionColumn: p.DescriptionColumn,
+		AmountColumn:      value(p.AmountColumn),
+		DebitColumn:       value(p.DebitColumn),
+		CreditColumn:      value(p.CreditColumn),
+		DecimalSeparator:  p.DecimalSeparator,
+	}
+}
+
+// ProfileRequest cria ou substitui um perfil. date_format usa DD, MM e YYYY
+// (como "DD/MM/YYYY"); encoding aceita utf-8, windows-1252 e iso-8859-1.
+type ProfileRequest struct {
+	Name              string  `json:"name" validate:"required,max=100"`
+	Encoding          string  `json:"encoding" validate:"omitempty,oneof=utf-8 windows-1252 iso-8859-1"`
+	Delimiter         string  `json:"delimiter" validate:"required"`
+	SkipRows          int     `json:"skip_rows" validate:"min=0"`
+	DateColumn        string  `json:"date_column" validate:"required,max=100"`
+	DateFormat        string  `json:"date_format" validate:"required,max=20"`
+	DescriptionColumn string  `json:"description_column" validate:"required,max=100"`
+	AmountColumn      *string `json:"amount_column" validate:"omitempty,max=100"`
+	DebitColumn       *string `json:"debit_column" validate:"omitempty,max=100"`
+	CreditColumn      *string `json:"credit_column" validate:"omitempty,max=100"`
+	DecimalSeparator  string  `json:"decimal_separator" validate:"required,oneof=, ."`
+}
+
+type ProfileResponse struct {
+	ID                uuid.UUID `json:"id"`
+	Name              string    `json:"name"`
+	Encoding          string    `json:"encoding"`
+	Delimiter         string    `json:"delimiter"`
+	SkipRows          int       `json:"skip_rows"`
+	DateColumn        string    `json:"date_column"`
+	DateFormat        string    `json:"date_format"`
+	DescriptionColumn string    `json:"description_column"`
+	AmountColumn      *string   `json:"amount_column"`
+	DebitColumn       *string   `json:"debit_column"`
+	CreditColumn      *string   `json:"credit_column"`
+	DecimalSeparator  string    `json:"decimal_separator"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Import registra uma importação de extrato. Errors são as linhas recusadas,
+// tanto as que não deu para ler quanto as que a conta não aceitou;
+// SkippedRows são as que já estavam na conta.
+type Import struct {
+	ID           uuid.UUID
+	AccountID    uuid.UUID
+	CreatedBy    uuid.UUID
+	ProfileID    *uuid.UUID
+	Format       Format
+	FileName     string
+	Status       ImportStatus
+	TotalRows    int
+	ImportedRows int
+	FailedRows   int
+	SkippedRows  int
+	Errors       []statement.RowError
+	CreatedAt    time.Time
+	FinishedAt   *time.Time
+}
+
+// Entry liga o identificador que o banco deu a um lançamento ao movimento
+// criado por ele, para o mesmo lançamento não ser importado de novo
+type Entry struct {
+	AccountID  uuid.UUID
+	ExternalID string
+	ImportID   uuid.UUID
+	MovementID uuid.UUID
+	CreatedAt  time.Time
+}
+
+// ImportRequest é o arquivo enviado em POST /accounts/{id}/imports. ProfileID
+// só é usado no CSV; Encoding, DateFormat e DecimalSeparator só no QIF, que
+// não diz como escreve datas e valores. Com DryRun, o arquivo só é lido e
+// validado, sem gravar nada.
+type ImportRequest struct {
+	Format           Format
+	ProfileID        *uuid.UUID
+	Encoding         string
+	DateFormat       string
+	DecimalSeparator string
+	FileName         string
+	Data             []byte
+	DryRun           bool
+}
+
+// CAMTImportRequest é o arquivo enviado em POST /imports/camt053
+type CAMTImportRequest struct {
+	FileName string
+	Data     []byte
+	DryRun   bool
+}
+
+// LineResponse é uma linha do extrato na prévia de um dry run
+type LineResponse struct {
+	Row         int    `json:"row"`
+	Date        string `json:"date"`
+	Amount      int64  `json:"amount"`
+	Description string `json:"description"`
+	ExternalID  string `json:"external_id,omitempty"`
+}
+
+type ImportResponse struct {
+	ID           *uuid.UUID           `json:"id,omitempty"` // vazio no dry run, que não grava nada
+	AccountID    uuid.UUID            `json:"account_id"`
+	ProfileID    *uuid.UUID           `json:"profile_id"`
+	Format       Format               `json:"format"`
+	FileName     string               `json:"file_name"`
+	Status       ImportStatus         `json:"status,omitempty"`
+	DryRun       bool                 `json:"dry_run"`
+	TotalRows    int                  `json:"total_rows"`
+	ImportedRows int                  `json:"imported_rows"`
+	FailedRows   int                  `json:"failed_rows"`
+	SkippedRows  int                  `json:"skipped_rows"`
+	Errors       []statement.RowError `json:"errors"`
+	Lines        []LineResponse       `json:"lines,omitempty"` // só no dry run
+	CreatedAt    *time.Time           `json:"created_at,omitempty"`
+	FinishedAt   *time.Time           `json:"finished_at,omitempty"`
+}
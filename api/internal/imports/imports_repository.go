@@ -0,0 +1,308 @@
+package imports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/martinsdevv/fincore/pkg/database"
+)
+
+type Repository interface {
+	// CreateProfile e UpdateProfile retornam ErrProfileExists se o usuário já
+	// tiver um perfil com o mesmo nome
+	CreateProfile(ctx context.Context, profile *Profile) error
+	GetProfile(ctx context.Context, id uuid.UUID) (*Profile, error)
+	ListProfiles(ctx context.Context, userID uuid.UUID) ([]Profile, error)
+	UpdateProfile(ctx context.Context, profile *Profile) error
+	DeleteProfile(ctx context.Context, id uuid.UUID) error
+
+	CreateImport(ctx context.Context, imp *Import) error
+	// FinishImport grava status, contagens, erros e finished_at
+	FinishImport(ctx context.Context, imp *Import) error
+	// GetImport devolve nil se a importação não existir na conta
+	GetImport(ctx context.Context, accountID uuid.UUID, id uuid.UUID) (*Import, error)
+	// ListImports devolve as limit importações mais recentes da conta
+	ListImports(ctx context.Context, accountID uuid.UUID, limit int) ([]Import, error)
+
+	// ListExternalIDs devolve quais dos ids já foram importados na conta
+	ListExternalIDs(ctx context.Context, accountID uuid.UUID, ids []string) ([]string, error)
+	// CreateEntry retorna ErrDuplicateEntry se o id já foi importado na conta
+	CreateEntry(ctx context.Context, entry *Entry) error
+}
+
+type pgxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgxRepository{db: db}
+}
+
+// conn usa a transação aberta por um database.Transactor, se houver
+func (r *pgxRepository) conn(ctx context.Context) database.DBTX {
+	return database.Conn(ctx, r.db)
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+// Colunas lidas por scanProfile, sempre nesta ordem
+const profileColumns = `id, user_id, name, encoding, delimiter, skip_rows, date_column, date_format,
+	description_column, amount_column, debit_column, credit_column, decimal_separator, created_at, updated_at`
+
+func scanProfile(row pgx.Row, p *Profile) error {
+	return row.Scan(
+		&p.ID,
+		&p.UserID,
+		&p.Name,
+		&p.Encoding,
+		&p.Delimiter,
+		&p.SkipRows,
+		&p.DateColumn,
+		&p.DateFormat,
+		&p.DescriptionColumn,
+		&p.AmountColumn,
+		&p.DebitColumn,
+		&p.CreditColumn,
+		&p.DecimalSeparator,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+}
+
+func (r *pgxRepository) CreateProfile(ctx context.Context, p *Profile) error {
+	query := `
+		INSERT INTO import_profiles (` + profileColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+
+	_, err := r.conn(ctx).Exec(ctx, query,
+		p.ID,
+		p.UserID,
+		p.Name,
+		p.Encoding,
+		p.Delimiter,
+		p.SkipRows,
+		p.DateColumn,
+		p.DateFormat,
+		p.DescriptionColumn,
+		p.AmountColumn,
+		p.DebitColumn,
+		p.CreditColumn,
+		p.DecimalSeparator,
+		p.CreatedAt,
+		p.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return ErrProfileExists
+	}
+	return err
+}
+
+func (r *pgxRepository) GetProfile(ctx context.Context, id uuid.UUID) (*Profile, error) {
+	query := `SELECT ` + profileColumns + ` FROM import_profiles WHERE id = $1`
+
+	var p Profile
+	if err := scanProfile(r.conn(ctx).QueryRow(ctx, query, id), &p); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *pgxRepository) ListProfiles(ctx context.Context, userID uuid.UUID) ([]Profile, error) {
+	query := `SELECT ` + profileColumns + ` FROM import_profiles WHERE user_id = $1 ORDER BY name`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var profiles []Profile
+	for rows.Next() {
+		var p Profile
+		if err := scanProfile(rows, &p); err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, rows.Err()
+}
+
+func (r *pgxRepository) UpdateProfile(ctx context.Context, p *Profile) error {
+	query := `
+		UPDATE import_profiles
+		SET name = $2, encoding = $3, delimiter = $4, skip_rows = $5, date_column = $6, date_format = $7,
+			description_column = $8, amount_column = $9, debit_column = $10, credit_column = $11,
+			decimal_separator = $12, updated_at = $13
+		WHERE id = $1`
+
+	_, err := r.conn(ctx).Exec(ctx, query,
+		p.ID,
+		p.Name,
+		p.Encoding,
+		p.Delimiter,
+		p.SkipRows,
+		p.DateColumn,
+		p.DateFormat,
+		p.DescriptionColumn,
+		p.AmountColumn,
+		p.DebitColumn,
+		p.CreditColumn,
+		p.DecimalSeparator,
+		p.UpdatedAt,
+	)
+	if isUniqueViolation(err) {
+		return ErrProfileExists
+	}
+	return err
+}
+
+func (r *pgxRepository) DeleteProfile(ctx context.Context, id uuid.UUID) error {
+	_, err := r.conn(ctx).Exec(ctx, `DELETE FROM import_profiles WHERE id = $1`, id)
+	return err
+}
+
+// Colunas lidas por scanImport, sempre nesta ordem
+const importColumns = `id, account_id, created_by, profile_id, format, file_name, status, total_rows,
+	imported_rows, failed_rows, skipped_rows, errors, created_at, finished_at`
+
+func scanImport(row pgx.Row, i *Import) error {
+	return row.Scan(
+		&i.ID,
+		&i.AccountID,
+		&i.CreatedBy,
+		&i.ProfileID,
+		&i.Format,
+		&i.FileName,
+		&i.Status,
+		&i.TotalRows,
+		&i.ImportedRows,
+		&i.FailedRows,
+		&i.SkippedRows,
+		&i.Errors,
+		&i.CreatedAt,
+		&i.FinishedAt,
+	)
+}
+
+func (r *pgxRepository) CreateImport(ctx context.Context, i *Import) error {
+	query := `
+		INSERT INTO imports (` + importColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+
+	_, err := r.conn(ctx).Exec(ctx, query,
+		i.ID,
+		i.AccountID,
+		i.CreatedBy,
+		i.ProfileID,
+		i.Format,
+		i.FileName,
+		i.Status,
+		i.TotalRows,
+		i.ImportedRows,
+		i.FailedRows,
+		i.SkippedRows,
+		rowErrors(i),
+		i.CreatedAt,
+		i.FinishedAt,
+	)
+	return err
+}
+
+func (r *pgxRepository) FinishImport(ctx context.Context, i *Import) error {
+	query := `
+		UPDATE imports
+		SET status = $2, total_rows = $3, imported_rows = $4, failed_rows = $5, skipped_rows = $6,
+			errors = $7, finished_at = $8
+		WHERE id = $1`
+
+	_, err := r.conn(ctx).Exec(ctx, query, i.ID, i.Status, i.TotalRows, i.ImportedRows, i.FailedRows, i.SkippedRows, rowErrors(i), i.FinishedAt)
+	return err
+}
+
+func (r *pgxRepository) GetImport(ctx context.Context, accountID uuid.UUID, id uuid.UUID) (*Import, error) {
+	query := `SELECT ` + importColumns + ` FROM imports WHERE id = $2 AND account_id = $1`
+
+	var i Import
+	if err := scanImport(r.conn(ctx).QueryRow(ctx, query, accountID, id), &i); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *pgxRepository) ListImports(ctx context.Context, accountID uuid.UUID, limit int) ([]Import, error) {
+	query := `
+		SELECT ` + importColumns + `
+		FROM imports
+		WHERE account_id = $1
+		ORDER BY created_at DESC, id
+		LIMIT $2`
+
+	rows, err := r.conn(ctx).Query(ctx, query, accountID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var imports []Import
+	for rows.Next() {
+		var i Import
+		if err := scanImport(rows, &i); err != nil {
+			return nil, err
+		}
+		imports = append(imports, i)
+	}
+	return imports, rows.Err()
+}
+
+func (r *pgxRepository) ListExternalIDs(ctx context.Context, accountID uuid.UUID, ids []string) ([]string, error) {
+	query := `SELECT external_id FROM import_entries WHERE account_id = $1 AND external_id = ANY($2)`
+
+	rows, err := r.conn(ctx).Query(ctx, query, accountID, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		found = append(found, id)
+	}
+	return found, rows.Err()
+}
+
+func (r *pgxRepository) CreateEntry(ctx context.Context, e *Entry) error {
+	query := `
+		INSERT INTO import_entries (account_id, external_id, import_id, movement_id, created_at)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	_, err := r.conn(ctx).Exec(ctx, query, e.AccountID, e.ExternalID, e.ImportID, e.MovementID, e.CreatedAt)
+	if isUniqueViolation(err) {
+		return ErrDuplicateEntry
+	}
+	return err
+}
+
+// rowErrors evita gravar null na coluna errors, que é NOT NULL
+func rowErrors(i *Import) interface{} {
+	if i.Errors == nil {
+		return []struct{}{}
+	}
+	return i.Errors
+}
@@ -0,0 +1,15 @@
+package imports
+
+import "github.com/go-chi/chi/v5"
+
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/import-profiles", h.HandleListProfiles)
+	r.Post("/import-profiles", h.HandleCreateProfile)
+	r.Get("/import-profiles/{profileID}", h.HandleGetProfile)
+	r.Put("/import-profiles/{profileID}", h.HandleUpdateProfile)
+	r.Delete("/import-profiles/{profileID}", h.HandleDeleteProfile)
+	r.Post("/imports/camt053", h.HandleImportCAMT053)
+	r.Post("/accounts/{accountID}/imports", h.HandleImport)
+	r.Get("/accounts/{accountID}/imports", h.HandleListImports)
+	r.Get("/accounts/{accountID}/imports/{importID}", h.HandleGetImport)
+}
@@ -0,0 +1,611 @@
+package imports
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/movements"
+	"github.com/martinsdevv/fincore/pkg/database"
+	"github.com/martinsdevv/fincore/pkg/money"
+	"github.com/martinsdevv/fincore/pkg/statement"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrProfileNotFound  = errors.New("import profile not found")
+	ErrProfileExists    = errors.New("an import profile with this name already exists")
+	ErrImportNotFound   = errors.New("import not found")
+	ErrProfileRequired  = errors.New("profile_id is required for csv imports")
+	ErrDuplicateEntry   = errors.New("entry was already imported into this account")
+	ErrNoAccountForIBAN = errors.New("no account with this IBAN")
+)
+
+const (
+	// maxImportRows limita o tamanho de um extrato; cada linha é um lançamento
+	// gravado durante a requisição
+	maxImportRows = 5000
+	// listImportsLimit é quantas importações recentes a listagem mostra
+	listImportsLimit = 50
+	maxDescription   = 255
+
+	// O QIF não diz como escreve datas e valores; sem opção, vale o jeito brasileiro
+	defaultQIFDateFormat       = "DD/MM/YYYY"
+	defaultQIFDecimalSeparator = ","
+)
+
+type Service interface {
+	CreateProfile(ctx context.Context, userID string, req ProfileRequest) (*ProfileResponse, error)
+	ListProfiles(ctx context.Context, userID string) ([]ProfileResponse, error)
+	GetProfile(ctx context.Context, profileID string, userID string) (*ProfileResponse, error)
+	UpdateProfile(ctx context.Context, profileID string, userID string, req ProfileRequest) (*ProfileResponse, error)
+	DeleteProfile(ctx context.Context, profileID string, userID string) error
+
+	// Import lê o extrato e lança cada linha na conta. Linhas ilegíveis ou
+	// recusadas pela conta não impedem as outras e voltam em Errors; linhas
+	// com um FITID já importado na conta são puladas. Com req.DryRun, só
+	// devolve a prévia.
+	Import(ctx context.Context, accountID string, userID string, req ImportRequest) (*ImportResponse, error)
+	// ImportCAMT053 importa cada extrato de um camt.053 na conta com o IBAN
+	// dele, criando uma importação por extrato
+	ImportCAMT053(ctx context.Context, userID string, req CAMTImportRequest) ([]ImportResponse, error)
+	ListImports(ctx context.Context, accountID string, userID string) ([]ImportResponse, error)
+	GetImport(ctx context.Context, accountID string, importID string, userID string) (*ImportResponse, error)
+}
+
+type service struct {
+	repo      Repository
+	accounts  accounts.Service
+	movements movements.Service
+	tx        database.Transactor
+}
+
+func NewService(repo Repository, accountsSvc accounts.Service, movementsSvc movements.Service, tx database.Transactor) Service {
+	return &service{repo: repo, accounts: accountsSvc, movements: movementsSvc, tx: tx}
+}
+
+func (s *service) CreateProfile(ctx context.Context, userIDStr string, req ProfileRequest) (*ProfileResponse, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	profile := &Profile{ID: uuid.New(), UserID: userID, CreatedAt: now}
+	if err := applyProfileRequest(profile, req, now); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateProfile(ctx, profile); err != nil {
+		if !errors.Is(err, ErrProfileExists) {
+			log.Error().Err(err).Str("userID", userIDStr).Msg("Failed to create import profile in repository")
+		}
+		return nil, err
+	}
+	return toProfileResponse(profile), nil
+}
+
+func (s *service) ListProfiles(ctx context.Context, userIDStr string) ([]ProfileResponse, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	profiles, err := s.repo.ListProfiles(ctx, userID)
+	if err != nil {
+		log.Error().Err(err).Str("userID", userIDStr).Msg("Failed to list import profiles from repository")
+		return nil, err
+	}
+
+	responses := make([]ProfileResponse, 0, len(profiles))
+	for i := range profiles {
+		responses = append(responses, *toProfileResponse(&profiles[i]))
+	}
+	return responses, nil
+}
+
+func (s *service) GetProfile(ctx context.Context, profileIDStr string, userIDStr string) (*ProfileResponse, error) {
+	profile, err := s.getProfile(ctx, profileIDStr, userIDStr)
+	if err != nil {
+		return nil, err
+	}
+	return toProfileResponse(profile), nil
+}
+
+func (s *service) UpdateProfile(ctx context.Context, profileIDStr string, userIDStr string, req ProfileRequest) (*ProfileResponse, error) {
+	profile, err := s.getProfile(ctx, profileIDStr, userIDStr)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyProfileRequest(profile, req, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateProfile(ctx, profile); err != nil {
+		if !errors.Is(err, ErrProfileExists) {
+			log.Error().Err(err).Str("profileID", profileIDStr).Msg("Failed to update import profile in repository")
+		}
+		return nil, err
+	}
+	return toProfileResponse(profile), nil
+}
+
+// DeleteProfile mantém o histórico: as importações feitas com o perfil ficam sem ele
+func (s *service) DeleteProfile(ctx context.Context, profileIDStr string, userIDStr string) error {
+	profile, err := s.getProfile(ctx, profileIDStr, userIDStr)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteProfile(ctx, profile.ID); err != nil {
+		log.Error().Err(err).Str("profileID", profileIDStr).Msg("Failed to delete import profile from repository")
+		return err
+	}
+	return nil
+}
+
+func (s *service) Import(ctx context.Context, accountIDStr string, userIDStr string, req ImportRequest) (*ImportResponse, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if account.IsArchived() {
+		return nil, accounts.ErrAccountArchived
+	}
+	if req.Format == "" {
+		req.Format = FormatCSV
+	}
+
+	lines, rowErrors, profileID, err := s.parse(ctx, account, userIDStr, req)
+	if err != nil {
+		return nil, err
+	}
+	file := parsedFile{format: req.Format, profileID: profileID, fileName: req.FileName, lines: lines, rowErrors: rowErrors}
+	return s.importLines(ctx, account, userIDStr, file, req.DryRun)
+}
+
+// ImportCAMT053 confere todas as contas antes de lançar qualquer extrato:
+// um IBAN desconhecido ou uma moeda diferente da conta recusa o arquivo todo
+func (s *service) ImportCAMT053(ctx context.Context, userIDStr string, req CAMTImportRequest) ([]ImportResponse, error) {
+	statements, err := statement.ParseCAMT053(bytes.NewReader(req.Data), maxImportRows)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]*accounts.Account, len(statements))
+	for i, st := range statements {
+		account, err := s.accounts.FindAccountByIBAN(ctx, st.IBAN, userIDStr, accounts.RoleEditor)
+		if err != nil {
+			if errors.Is(err, accounts.ErrAccountNotFound) {
+				return nil, fmt.Errorf("%w: %s", ErrNoAccountForIBAN, st.IBAN)
+			}
+			return nil, err
+		}
+		if account.IsArchived() {
+			return nil, accounts.ErrAccountArchived
+		}
+		if st.Currency != account.Currency {
+			return nil, fmt.Errorf("%w: statement %s in %s for a %s account", money.ErrCurrencyMismatch, st.ID, st.Currency, account.Currency)
+		}
+		targets[i] = account
+	}
+
+	responses := make([]ImportResponse, 0, len(statements))
+	for i, st := range statements {
+		file := parsedFile{format: FormatCAMT053, fileName: req.FileName, lines: st.Lines, rowErrors: st.Errors}
+		resp, err := s.importLines(ctx, targets[i], userIDStr, file, req.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *resp)
+	}
+	return responses, nil
+}
+
+// parsedFile é um arquivo já lido, pronto para ser lançado numa conta
+type parsedFile struct {
+	format    Format
+	profileID *uuid.UUID
+	fileName  string
+	lines     []statement.Line
+	rowErrors []statement.RowError
+}
+
+// importLines pula o que já foi importado, recusa as datas que o lançamento
+// recusaria e lança o resto, registrando a importação. Com dryRun só devolve
+// a prévia.
+func (s *service) importLines(ctx context.Context, account *accounts.Account, userIDStr string, file parsedFile, dryRun bool) (*ImportResponse, error) {
+	lines, skipped, err := s.skipImported(ctx, account.ID, file.lines)
+	if err != nil {
+		return nil, err
+	}
+	rowErrors := file.rowErrors
+
+	// As mesmas regras que o lançamento aplicaria, para a prévia já mostrar
+	// o que vai ser recusado
+	now := time.Now().UTC()
+	valid := lines[:0]
+	for _, line := range lines {
+		switch {
+		case line.Date.After(now):
+			rowErrors = append(rowErrors, statement.RowError{Row: line.Row, Message: movements.ErrFutureMovement.Error()})
+		case line.Date.Before(truncateDay(account.CreatedAt)):
+			rowErrors = append(rowErrors, statement.RowError{Row: line.Row, Message: movements.ErrBeforeOpening.Error()})
+		default:
+			valid = append(valid, line)
+		}
+	}
+	lines = valid
+
+	imp := &Import{
+		ID:          uuid.New(),
+		AccountID:   account.ID,
+		ProfileID:   file.profileID,
+		Format:      file.format,
+		FileName:    truncate(file.fileName, maxDescription),
+		Status:      StatusRunning,
+		TotalRows:   len(lines) + len(rowErrors) + skipped,
+		SkippedRows: skipped,
+		Errors:      rowErrors,
+		CreatedAt:   now,
+	}
+	if imp.CreatedBy, err = uuid.Parse(userIDStr); err != nil {
+		return nil, err
+	}
+
+	if dryRun {
+		imp.FailedRows = len(rowErrors)
+		sortRowErrors(imp.Errors)
+		return toDryRunResponse(imp, lines), nil
+	}
+
+	if err := s.repo.CreateImport(ctx, imp); err != nil {
+		log.Error().Err(err).Str("accountID", account.ID.String()).Msg("Failed to create import in repository")
+		return nil, err
+	}
+
+	postErr := s.postLines(ctx, account, imp, lines, userIDStr)
+
+	finishedAt := time.Now().UTC()
+	imp.FinishedAt = &finishedAt
+	imp.Status = StatusCompleted
+	if postErr != nil {
+		imp.Status = StatusFailed
+	}
+	imp.FailedRows = len(imp.Errors)
+	sortRowErrors(imp.Errors)
+	// O resultado é gravado mesmo se a requisição foi cancelada no meio
+	if err := s.repo.FinishImport(context.WithoutCancel(ctx), imp); err != nil {
+		log.Error().Err(err).Str("importID", imp.ID.String()).Msg("Failed to finish import in repository")
+		return nil, err
+	}
+	if postErr != nil {
+		return nil, postErr
+	}
+
+	return toImportResponse(imp), nil
+}
+
+// parse lê o arquivo no formato pedido e devolve também o perfil usado, que
+// só o CSV tem. O OFX traz a própria moeda, que precisa ser a da conta.
+func (s *service) parse(ctx context.Context, account *accounts.Account, userIDStr string, req ImportRequest) ([]statement.Line, []statement.RowError, *uuid.UUID, error) {
+	switch req.Format {
+	case FormatCSV:
+		if req.ProfileID == nil {
+			return nil, nil, nil, ErrProfileRequired
+		}
+		profile, err := s.getProfile(ctx, req.ProfileID.String(), userIDStr)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		lines, rowErrors, err := statement.ParseCSV(bytes.NewReader(req.Data), profile.CSV(), account.Currency, maxImportRows)
+		return lines, rowErrors, &profile.ID, err
+
+	case FormatOFX:
+		st, err := statement.ParseOFX(bytes.NewReader(req.Data), maxImportRows)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if st.Currency != account.Currency {
+			return nil, nil, nil, fmt.Errorf("%w: statement in %s for a %s account", money.ErrCurrencyMismatch, st.Currency, account.Currency)
+		}
+		return st.Lines, st.Errors, nil, nil
+
+	case FormatQIF:
+		opts := statement.QIFOptions{Encoding: req.Encoding, DateFormat: req.DateFormat, DecimalSeparator: req.DecimalSeparator}
+		if opts.DateFormat == "" {
+			opts.DateFormat = defaultQIFDateFormat
+		}
+		if opts.DecimalSeparator == "" {
+			opts.DecimalSeparator = defaultQIFDecimalSeparator
+		}
+		lines, rowErrors, err := statement.ParseQIF(bytes.NewReader(req.Data), opts, account.Currency, maxImportRows)
+		return lines, rowErrors, nil, err
+	}
+	return nil, nil, nil, fmt.Errorf("%w: unsupported format %q", statement.ErrInvalidFile, req.Format)
+}
+
+// skipImported tira as linhas cujo FITID já foi importado na conta ou se
+// repete no próprio arquivo, e devolve quantas foram tiradas
+func (s *service) skipImported(ctx context.Context, accountID uuid.UUID, lines []statement.Line) ([]statement.Line, int, error) {
+	var ids []string
+	for _, line := range lines {
+		if line.ExternalID != "" {
+			ids = append(ids, line.ExternalID)
+		}
+	}
+	if len(ids) == 0 {
+		return lines, 0, nil
+	}
+
+	imported, err := s.repo.ListExternalIDs(ctx, accountID, ids)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountID.String()).Msg("Failed to list imported entries from repository")
+		return nil, 0, err
+	}
+	seen := make(map[string]bool, len(ids))
+	for _, id := range imported {
+		seen[id] = true
+	}
+
+	kept := lines[:0]
+	skipped := 0
+	for _, line := range lines {
+		if line.ExternalID != "" {
+			if seen[line.ExternalID] {
+				skipped++
+				continue
+			}
+			seen[line.ExternalID] = true
+		}
+		kept = append(kept, line)
+	}
+	return kept, skipped, nil
+}
+
+// postLines lança as linhas em ordem de data, cada uma na própria transação:
+// uma linha que a conta recusa, como por falta de saldo, vira RowError e não
+// desfaz as anteriores. Só um erro inesperado interrompe a importação.
+func (s *service) postLines(ctx context.Context, account *accounts.Account, imp *Import, lines []statement.Line, userIDStr string) error {
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Date.Before(lines[j].Date) })
+
+	for _, line := range lines {
+		// O extrato só tem o dia; no dia da abertura, o lançamento fica no
+		// momento em que a conta foi criada
+		occurredAt := line.Date
+		if occurredAt.Before(account.CreatedAt) {
+			occurredAt = account.CreatedAt
+		}
+		err := s.postLine(ctx, imp, line, occurredAt, userIDStr)
+		switch {
+		case err == nil:
+			imp.ImportedRows++
+		case errors.Is(err, ErrDuplicateEntry):
+			// Outra importação do mesmo arquivo chegou antes
+			imp.SkippedRows++
+		case isRowError(err):
+			imp.Errors = append(imp.Errors, statement.RowError{Row: line.Row, Message: err.Error()})
+		default:
+			log.Error().Err(err).Str("importID", imp.ID.String()).Int("row", line.Row).Msg("Failed to post imported line")
+			imp.Errors = append(imp.Errors, statement.RowError{Row: line.Row, Message: "unexpected error, import stopped"})
+			return err
+		}
+	}
+	return nil
+}
+
+// postLine lança uma linha. Com FITID, o lançamento e o registro do FITID vão
+// na mesma transação, e um FITID já registrado desfaz o lançamento.
+func (s *service) postLine(ctx context.Context, imp *Import, line statement.Line, occurredAt time.Time, userIDStr string) error {
+	post := func(ctx context.Context) error {
+		movement, err := s.movements.PostMovement(ctx, imp.AccountID.String(), userIDStr, movements.KindImported, movements.CreateMovementRequest{
+			Amount:      line.Amount,
+			Description: truncate(line.Description, maxDescription),
+			OccurredAt:  &occurredAt,
+		})
+		if err != nil || line.ExternalID == "" {
+			return err
+		}
+		return s.repo.CreateEntry(ctx, &Entry{
+			AccountID:  imp.AccountID,
+			ExternalID: line.ExternalID,
+			ImportID:   imp.ID,
+			MovementID: movement.ID,
+			CreatedAt:  time.Now().UTC(),
+		})
+	}
+
+	if line.ExternalID == "" {
+		return post(ctx)
+	}
+	return s.tx.WithinTx(ctx, post)
+}
+
+// isRowError diz se o erro é a conta recusando a linha, e não uma falha do sistema
+func isRowError(err error) bool {
+	for _, target := range []error{
+		movements.ErrFutureMovement,
+		movements.ErrBeforeOpening,
+		accounts.ErrInsufficientFunds,
+		accounts.ErrNegativeBalanceNotAllowed,
+		accounts.ErrAccountArchived,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *service) ListImports(ctx context.Context, accountIDStr string, userIDStr string) ([]ImportResponse, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	imports, err := s.repo.ListImports(ctx, account.ID, listImportsLimit)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to list imports from repository")
+		return nil, err
+	}
+
+	responses := make([]ImportResponse, 0, len(imports))
+	for i := range imports {
+		responses = append(responses, *toImportResponse(&imports[i]))
+	}
+	return responses, nil
+}
+
+func (s *service) GetImport(ctx context.Context, accountIDStr string, importIDStr string, userIDStr string) (*ImportResponse, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	importID, err := uuid.Parse(importIDStr)
+	if err != nil {
+		return nil, ErrImportNotFound
+	}
+
+	imp, err := s.repo.GetImport(ctx, account.ID, importID)
+	if err != nil {
+		log.Error().Err(err).Str("importID", importIDStr).Msg("Failed to get import from repository")
+		return nil, err
+	}
+	if imp == nil {
+		return nil, ErrImportNotFound
+	}
+	return toImportResponse(imp), nil
+}
+
+// getProfile busca um perfil do usuário; perfis de outros usuários não existem para ele
+func (s *service) getProfile(ctx context.Context, profileIDStr string, userIDStr string) (*Profile, error) {
+	profileID, err := uuid.Parse(profileIDStr)
+	if err != nil {
+		return nil, ErrProfileNotFound
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := s.repo.GetProfile(ctx, profileID)
+	if err != nil {
+		log.Error().Err(err).Str("profileID", profileIDStr).Msg("Failed to get import profile from repository")
+		return nil, err
+	}
+	if profile == nil || profile.UserID != userID {
+		return nil, ErrProfileNotFound
+	}
+	return profile, nil
+}
+
+// applyProfileRequest copia req para o perfil e valida a combinação de colunas
+func applyProfileRequest(p *Profile, req ProfileRequest, now time.Time) error {
+	p.Name = req.Name
+	p.Encoding = req.Encoding
+	if p.Encoding == "" {
+		p.Encoding = statement.EncodingUTF8
+	}
+	p.Delimiter = req.Delimiter
+	p.SkipRows = req.SkipRows
+	p.DateColumn = req.DateColumn
+	p.DateFormat = req.DateFormat
+	p.DescriptionColumn = req.DescriptionColumn
+	p.AmountColumn = nonEmpty(req.AmountColumn)
+	p.DebitColumn = nonEmpty(req.DebitColumn)
+	p.CreditColumn = nonEmpty(req.CreditColumn)
+	p.DecimalSeparator = req.DecimalSeparator
+	p.UpdatedAt = now
+
+	if utf8.RuneCountInString(p.Delimiter) != 1 {
+		return errors.Join(statement.ErrInvalidProfile, errors.New("delimiter must be a single character"))
+	}
+	return p.CSV().Validate()
+}
+
+func nonEmpty(s *string) *string {
+	if s == nil || *s == "" {
+		return nil
+	}
+	return s
+}
+
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[:max])
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func sortRowErrors(errs []statement.RowError) {
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Row < errs[j].Row })
+}
+
+func toProfileResponse(p *Profile) *ProfileResponse {
+	return &ProfileResponse{
+		ID:                p.ID,
+		Name:              p.Name,
+		Encoding:          p.Encoding,
+		Delimiter:         p.Delimiter,
+		SkipRows:          p.SkipRows,
+		DateColumn:        p.DateColumn,
+		DateFormat:        p.DateFormat,
+		DescriptionColumn: p.DescriptionColumn,
+		AmountColumn:      p.AmountColumn,
+		DebitColumn:       p.DebitColumn,
+		CreditColumn:      p.CreditColumn,
+		DecimalSeparator:  p.DecimalSeparator,
+		CreatedAt:         p.CreatedAt,
+		UpdatedAt:         p.UpdatedAt,
+	}
+}
+
+func toImportResponse(i *Import) *ImportResponse {
+	errs := i.Errors
+	if errs == nil {
+		errs = []statement.RowError{}
+	}
+	return &ImportResponse{
+		ID:           &i.ID,
+		AccountID:    i.AccountID,
+		ProfileID:    i.ProfileID,
+		Format:       i.Format,
+		FileName:     i.FileName,
+		Status:       i.Status,
+		TotalRows:    i.TotalRows,
+		ImportedRows: i.ImportedRows,
+		FailedRows:   i.FailedRows,
+		SkippedRows:  i.SkippedRows,
+		Errors:       errs,
+		CreatedAt:    &i.CreatedAt,
+		FinishedAt:   i.FinishedAt,
+	}
+}
+
+// toDryRunResponse mostra o que seria importado, sem id nem status
+func toDryRunResponse(i *Import, lines []statement.Line) *ImportResponse {
+	resp := toImportResponse(i)
+	resp.ID, resp.Status, resp.CreatedAt = nil, "", nil
+	resp.DryRun = true
+	resp.Lines = make([]LineResponse, 0, len(lines))
+	for _, line := range lines {
+		resp.Lines = append(resp.Lines, LineResponse{
+			Row:         line.Row,
+			Date:        line.Date.Format(time.DateOnly),
+			Amount:      line.Amount,
+			Description: line.Description,
+			ExternalID:  line.ExternalID,
+		})
+	}
+	return resp
+}
@@ -0,0 +1,487 @@
+package imports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/movements"
+	"github.com/martinsdevv/fincore/pkg/money"
+	"github.com/martinsdevv/fincore/pkg/statement"
+)
+
+// MockRepository guarda perfis, importações e FITIDs em memória
+type MockRepository struct {
+	profiles map[uuid.UUID]*Profile
+	imports  map[uuid.UUID]*Import
+	entries  map[string]Entry
+	finished []Import
+
+	CreateEntryFunc func(entry *Entry) error
+}
+
+func newMockRepository(profiles ...*Profile) *MockRepository {
+	m := &MockRepository{profiles: make(map[uuid.UUID]*Profile), imports: make(map[uuid.UUID]*Import), entries: make(map[string]Entry)}
+	for _, p := range profiles {
+		m.profiles[p.ID] = p
+	}
+	return m
+}
+
+func (m *MockRepository) CreateProfile(ctx context.Context, profile *Profile) error {
+	for _, p := range m.profiles {
+		if p.UserID == profile.UserID && p.Name == profile.Name {
+			return ErrProfileExists
+		}
+	}
+	copied := *profile
+	m.profiles[profile.ID] = &copied
+	return nil
+}
+
+func (m *MockRepository) GetProfile(ctx context.Context, id uuid.UUID) (*Profile, error) {
+	if p, ok := m.profiles[id]; ok {
+		copied := *p
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) ListProfiles(ctx context.Context, userID uuid.UUID) ([]Profile, error) {
+	return nil, nil
+}
+
+func (m *MockRepository) UpdateProfile(ctx context.Context, profile *Profile) error {
+	copied := *profile
+	m.profiles[profile.ID] = &copied
+	return nil
+}
+
+func (m *MockRepository) DeleteProfile(ctx context.Context, id uuid.UUID) error {
+	delete(m.profiles, id)
+	return nil
+}
+
+func (m *MockRepository) CreateImport(ctx context.Context, imp *Import) error {
+	copied := *imp
+	m.imports[imp.ID] = &copied
+	return nil
+}
+
+func (m *MockRepository) FinishImport(ctx context.Context, imp *Import) error {
+	m.finished = append(m.finished, *imp)
+	return nil
+}
+
+func (m *MockRepository) GetImport(ctx context.Context, accountID uuid.UUID, id uuid.UUID) (*Import, error) {
+	if i, ok := m.imports[id]; ok && i.AccountID == accountID {
+		return i, nil
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) ListImports(ctx context.Context, accountID uuid.UUID, limit int) ([]Import, error) {
+	return nil, nil
+}
+
+func (m *MockRepository) ListExternalIDs(ctx context.Context, accountID uuid.UUID, ids []string) ([]string, error) {
+	var found []string
+	for _, id := range ids {
+		if e, ok := m.entries[id]; ok && e.AccountID == accountID {
+			found = append(found, id)
+		}
+	}
+	return found, nil
+}
+
+func (m *MockRepository) CreateEntry(ctx context.Context, entry *Entry) error {
+	if m.CreateEntryFunc != nil {
+		if err := m.CreateEntryFunc(entry); err != nil {
+			return err
+		}
+	}
+	if _, ok := m.entries[entry.ExternalID]; ok {
+		return ErrDuplicateEntry
+	}
+	m.entries[entry.ExternalID] = *entry
+	return nil
+}
+
+// MockTransactor desfaz os lançamentos gravados em MockMovements quando fn falha
+type MockTransactor struct {
+	movements *MockMovements
+}
+
+func (m *MockTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if m.movements == nil {
+		return fn(ctx)
+	}
+	posted := len(m.movements.posted)
+	err := fn(ctx)
+	if err != nil {
+		m.movements.posted = m.movements.posted[:posted]
+	}
+	return err
+}
+
+// MockAccounts implementa só a autorização de accounts.Service; a busca por
+// IBAN devolve a conta se o IBAN for o dela
+type MockAccounts struct {
+	accounts.Service
+	account *accounts.Account
+}
+
+func (m *MockAccounts) AuthorizeAccount(ctx context.Context, accountID string, userID string, role accounts.MemberRole) (*accounts.Account, error) {
+	return m.account, nil
+}
+
+func (m *MockAccounts) FindAccountByIBAN(ctx context.Context, iban string, userID string, role accounts.MemberRole) (*accounts.Account, error) {
+	if m.account.IBAN == nil || *m.account.IBAN != iban {
+		return nil, accounts.ErrAccountNotFound
+	}
+	return m.account, nil
+}
+
+// MockMovements grava os lançamentos recebidos; PostMovementFunc pode recusá-los
+type MockMovements struct {
+	movements.Service
+	posted           []movements.CreateMovementRequest
+	PostMovementFunc func(req movements.CreateMovementRequest) error
+}
+
+func (m *MockMovements) PostMovement(ctx context.Context, accountID string, userID string, kind movements.MovementKind, req movements.CreateMovementRequest) (*movements.MovementResponse, error) {
+	if kind != movements.KindImported {
+		return nil, fmt.Errorf("kind inesperado: %s", kind)
+	}
+	if m.PostMovementFunc != nil {
+		if err := m.PostMovementFunc(req); err != nil {
+			return nil, err
+		}
+	}
+	m.posted = append(m.posted, req)
+	return &movements.MovementResponse{ID: uuid.New(), Amount: req.Amount}, nil
+}
+
+func newTestProfile(userID uuid.UUID) *Profile {
+	debit, credit := "Débito", "Crédito"
+	return &Profile{
+		ID:                uuid.New(),
+		UserID:            userID,
+		Name:              "Banco",
+		Encoding:          statement.EncodingUTF8,
+		Delimiter:         ";",
+		DateColumn:        "Data",
+		DateFormat:        "DD/MM/YYYY",
+		DescriptionColumn: "Histórico",
+		DebitColumn:       &debit,
+		CreditColumn:      &credit,
+		DecimalSeparator:  ",",
+	}
+}
+
+func TestService_Import(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	account := &accounts.Account{
+		ID:        uuid.New(),
+		Type:      accounts.AccountTypeChecking,
+		Currency:  "BRL",
+		CreatedAt: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+	}
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format("02/01/2006")
+	statementFile := []byte("Data;Histórico;Débito;Crédito\n" +
+		"05/01/2024;Mercado;150,00;\n" +
+		"02/01/2024;Salário;;3.000,00\n" +
+		"xx/01/2024;Ilegível;1,00;\n" +
+		"01/01/2024;Abertura;;10,00\n" +
+		tomorrow + ";Futuro;5,00;\n" +
+		"03/01/2024;Aluguel;9.000,00;\n")
+
+	t.Run("deve mostrar a prévia sem gravar nada", func(t *testing.T) {
+		profile := newTestProfile(userID)
+		repo := newMockRepository(profile)
+		mockMovements := &MockMovements{}
+		service := NewService(repo, &MockAccounts{account: account}, mockMovements, &MockTransactor{})
+
+		resp, err := service.Import(ctx, account.ID.String(), userID.String(), ImportRequest{ProfileID: &profile.ID, Data: statementFile, DryRun: true})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if !resp.DryRun || resp.ID != nil || len(resp.Lines) != 4 || resp.TotalRows != 6 || resp.FailedRows != 2 {
+			t.Errorf("prévia incorreta: %+v", resp)
+		}
+		if resp.Errors[0].Row != 4 || resp.Errors[1].Row != 6 {
+			t.Errorf("erros fora de ordem: %+v", resp.Errors)
+		}
+		if len(mockMovements.posted) != 0 || len(repo.imports) != 0 {
+			t.Error("o dry run não deveria gravar nada")
+		}
+	})
+
+	t.Run("deve lançar em ordem de data e registrar as linhas recusadas", func(t *testing.T) {
+		profile := newTestProfile(userID)
+		repo := newMockRepository(profile)
+		mockMovements := &MockMovements{
+			PostMovementFunc: func(req movements.CreateMovementRequest) error {
+				if req.Amount < -100000 {
+					return accounts.ErrNegativeBalanceNotAllowed
+				}
+				return nil
+			},
+		}
+		service := NewService(repo, &MockAccounts{account: account}, mockMovements, &MockTransactor{})
+
+		resp, err := service.Import(ctx, account.ID.String(), userID.String(), ImportRequest{ProfileID: &profile.ID, FileName: "extrato.csv", Data: statementFile})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		posted := mockMovements.posted
+		if len(posted) != 3 || posted[1].Description != "Salário" || posted[2].Amount != -15000 {
+			t.Errorf("lançamentos incorretos: %+v", posted)
+		}
+		// No dia da abertura, o lançamento não pode ficar antes da conta existir
+		if len(posted) > 0 && !posted[0].OccurredAt.Equal(account.CreatedAt) {
+			t.Errorf("esperava o primeiro lançamento na abertura da conta, obteve %v", posted[0].OccurredAt)
+		}
+		if resp.Status != StatusCompleted || resp.ImportedRows != 3 || resp.FailedRows != 3 || resp.Errors[2].Row != 7 {
+			t.Errorf("importação incorreta: %+v", resp)
+		}
+		if len(repo.finished) != 1 || repo.finished[0].FinishedAt == nil {
+			t.Errorf("esperava a importação finalizada no repositório: %+v", repo.finished)
+		}
+	})
+
+	t.Run("deve marcar a importação como falha num erro inesperado", func(t *testing.T) {
+		profile := newTestProfile(userID)
+		repo := newMockRepository(profile)
+		dbErr := errors.New("connection reset")
+		mockMovements := &MockMovements{PostMovementFunc: func(req movements.CreateMovementRequest) error { return dbErr }}
+		service := NewService(repo, &MockAccounts{account: account}, mockMovements, &MockTransactor{})
+
+		if _, err := service.Import(ctx, account.ID.String(), userID.String(), ImportRequest{ProfileID: &profile.ID, Data: statementFile}); !errors.Is(err, dbErr) {
+			t.Fatalf("esperava o erro %v, mas obteve %v", dbErr, err)
+		}
+		if len(repo.finished) != 1 || repo.finished[0].Status != StatusFailed {
+			t.Errorf("esperava a importação marcada como falha: %+v", repo.finished)
+		}
+	})
+
+	t.Run("não deve usar o perfil de outro usuário", func(t *testing.T) {
+		profile := newTestProfile(uuid.New())
+		service := NewService(newMockRepository(profile), &MockAccounts{account: account}, &MockMovements{}, &MockTransactor{})
+
+		if _, err := service.Import(ctx, account.ID.String(), userID.String(), ImportRequest{ProfileID: &profile.ID, Data: statementFile}); !errors.Is(err, ErrProfileNotFound) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrProfileNotFound, err)
+		}
+	})
+}
+
+const ofxFile = `<?xml version="1.0" encoding="UTF-8"?>
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><CURDEF>BRL</CURDEF><BANKTRANLIST>
+<STMTTRN><DTPOSTED>20240105</DTPOSTED><TRNAMT>-150.00</TRNAMT><FITID>A1</FITID><NAME>Mercado</NAME></STMTTRN>
+<STMTTRN><DTPOSTED>20240102</DTPOSTED><TRNAMT>3000.00</TRNAMT><FITID>A2</FITID><NAME>Salário</NAME></STMTTRN>
+<STMTTRN><DTPOSTED>20240106</DTPOSTED><TRNAMT>-150.00</TRNAMT><FITID>A1</FITID><NAME>Mercado</NAME></STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>`
+
+func TestService_ImportOFXAndQIF(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	account := &accounts.Account{
+		ID:        uuid.New(),
+		Type:      accounts.AccountTypeChecking,
+		Currency:  "BRL",
+		CreatedAt: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("deve pular FITIDs já importados na conta", func(t *testing.T) {
+		repo := newMockRepository()
+		mockMovements := &MockMovements{}
+		service := NewService(repo, &MockAccounts{account: account}, mockMovements, &MockTransactor{movements: mockMovements})
+		req := ImportRequest{Format: FormatOFX, FileName: "extrato.ofx", Data: []byte(ofxFile)}
+
+		first, err := service.Import(ctx, account.ID.String(), userID.String(), req)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if first.ImportedRows != 2 || first.SkippedRows != 1 || first.TotalRows != 3 || len(repo.entries) != 2 {
+			t.Errorf("primeira importação incorreta: %+v", first)
+		}
+		if first.ProfileID != nil || first.Format != FormatOFX {
+			t.Errorf("esperava uma importação OFX sem perfil: %+v", first)
+		}
+
+		preview, err := service.Import(ctx, account.ID.String(), userID.String(), ImportRequest{Format: FormatOFX, Data: []byte(ofxFile), DryRun: true})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if len(preview.Lines) != 0 || preview.SkippedRows != 3 {
+			t.Errorf("a prévia deveria pular tudo: %+v", preview)
+		}
+
+		second, err := service.Import(ctx, account.ID.String(), userID.String(), req)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if second.ImportedRows != 0 || second.SkippedRows != 3 || len(mockMovements.posted) != 2 {
+			t.Errorf("a segunda importação não deveria lançar nada: %+v", second)
+		}
+	})
+
+	t.Run("deve desfazer o lançamento quando outra importação registra o FITID antes", func(t *testing.T) {
+		repo := newMockRepository()
+		repo.CreateEntryFunc = func(entry *Entry) error {
+			if entry.ExternalID == "A2" {
+				return ErrDuplicateEntry
+			}
+			return nil
+		}
+		mockMovements := &MockMovements{}
+		service := NewService(repo, &MockAccounts{account: account}, mockMovements, &MockTransactor{movements: mockMovements})
+
+		resp, err := service.Import(ctx, account.ID.String(), userID.String(), ImportRequest{Format: FormatOFX, Data: []byte(ofxFile)})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if resp.ImportedRows != 1 || resp.SkippedRows != 2 || len(mockMovements.posted) != 1 || mockMovements.posted[0].Amount != -15000 {
+			t.Errorf("importação incorreta: %+v %+v", resp, mockMovements.posted)
+		}
+	})
+
+	t.Run("deve recusar extrato em outra moeda", func(t *testing.T) {
+		usdAccount := *account
+		usdAccount.Currency = "USD"
+		service := NewService(newMockRepository(), &MockAccounts{account: &usdAccount}, &MockMovements{}, &MockTransactor{})
+
+		_, err := service.Import(ctx, account.ID.String(), userID.String(), ImportRequest{Format: FormatOFX, Data: []byte(ofxFile)})
+		if !errors.Is(err, money.ErrCurrencyMismatch) {
+			t.Errorf("esperava o erro %v, mas obteve %v", money.ErrCurrencyMismatch, err)
+		}
+	})
+
+	t.Run("deve ler QIF com as opções padrão", func(t *testing.T) {
+		mockMovements := &MockMovements{}
+		service := NewService(newMockRepository(), &MockAccounts{account: account}, mockMovements, &MockTransactor{})
+		qif := "!Type:Bank\nD05/01/2024\nT-1.234,56\nPAluguel\n^\nD02/01/2024\nT5.000,00\nPSalário\n^\n"
+
+		resp, err := service.Import(ctx, account.ID.String(), userID.String(), ImportRequest{Format: FormatQIF, Data: []byte(qif)})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if resp.ImportedRows != 2 || mockMovements.posted[1].Amount != -123456 {
+			t.Errorf("importação incorreta: %+v %+v", resp, mockMovements.posted)
+		}
+	})
+
+	t.Run("deve exigir perfil no CSV", func(t *testing.T) {
+		service := NewService(newMockRepository(), &MockAccounts{account: account}, &MockMovements{}, &MockTransactor{})
+
+		if _, err := service.Import(ctx, account.ID.String(), userID.String(), ImportRequest{Data: []byte("Data;Valor\n")}); !errors.Is(err, ErrProfileRequired) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrProfileRequired, err)
+		}
+	})
+}
+
+const camtFile = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02"><BkToCstmrStmt>
+<GrpHdr><MsgId>M1</MsgId><CreDtTm>2024-02-01T06:00:00</CreDtTm></GrpHdr>
+<Stmt><Id>S1</Id><CreDtTm>2024-02-01T06:00:00</CreDtTm>
+<Acct><Id><IBAN>DE89370400440532013000</IBAN></Id><Ccy>EUR</Ccy></Acct>
+<Ntry><Amt Ccy="EUR">250.50</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>BOOK</Sts><BookgDt><Dt>2024-01-05</Dt></BookgDt><AcctSvcrRef>B1</AcctSvcrRef></Ntry>
+<Ntry><Amt Ccy="EUR">4000.00</Amt><CdtDbtInd>CRDT</CdtDbtInd><Sts>BOOK</Sts><BookgDt><Dt>2024-01-02</Dt></BookgDt><AcctSvcrRef>B2</AcctSvcrRef></Ntry>
+<Ntry><Amt Ccy="EUR">9.00</Amt><CdtDbtInd>DBIT</CdtDbtInd><Sts>PDNG</Sts><BookgDt><Dt>2024-01-31</Dt></BookgDt></Ntry>
+</Stmt></BkToCstmrStmt></Document>`
+
+func TestService_ImportCAMT053(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	accountIBAN := "DE89370400440532013000"
+	account := &accounts.Account{
+		ID:        uuid.New(),
+		Type:      accounts.AccountTypeChecking,
+		Currency:  "EUR",
+		IBAN:      &accountIBAN,
+		CreatedAt: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("deve importar os lançamentos contabilizados na conta do IBAN", func(t *testing.T) {
+		repo := newMockRepository()
+		mockMovements := &MockMovements{}
+		service := NewService(repo, &MockAccounts{account: account}, mockMovements, &MockTransactor{movements: mockMovements})
+
+		results, err := service.ImportCAMT053(ctx, userID.String(), CAMTImportRequest{FileName: "camt.xml", Data: []byte(camtFile)})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if len(results) != 1 || results[0].AccountID != account.ID || results[0].Format != FormatCAMT053 || results[0].ImportedRows != 2 {
+			t.Fatalf("importação incorreta: %+v", results)
+		}
+		if len(mockMovements.posted) != 2 || mockMovements.posted[1].Amount != -25050 || len(repo.entries) != 2 {
+			t.Errorf("lançamentos incorretos: %+v", mockMovements.posted)
+		}
+	})
+
+	t.Run("deve recusar o arquivo se nenhuma conta tiver o IBAN", func(t *testing.T) {
+		other := *account
+		other.IBAN = nil
+		mockMovements := &MockMovements{}
+		service := NewService(newMockRepository(), &MockAccounts{account: &other}, mockMovements, &MockTransactor{})
+
+		if _, err := service.ImportCAMT053(ctx, userID.String(), CAMTImportRequest{Data: []byte(camtFile)}); !errors.Is(err, ErrNoAccountForIBAN) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrNoAccountForIBAN, err)
+		}
+		if len(mockMovements.posted) != 0 {
+			t.Error("nada deveria ser lançado")
+		}
+	})
+
+	t.Run("deve recusar extrato em moeda diferente da conta", func(t *testing.T) {
+		brl := *account
+		brl.Currency = "BRL"
+		service := NewService(newMockRepository(), &MockAccounts{account: &brl}, &MockMovements{}, &MockTransactor{})
+
+		if _, err := service.ImportCAMT053(ctx, userID.String(), CAMTImportRequest{Data: []byte(camtFile)}); !errors.Is(err, money.ErrCurrencyMismatch) {
+			t.Errorf("esperava o erro %v, mas obteve %v", money.ErrCurrencyMismatch, err)
+		}
+	})
+}
+
+func TestService_CreateProfile(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	amount, debit := "Valor", "Débito"
+
+	t.Run("deve recusar valor junto com débito e crédito", func(t *testing.T) {
+		service := NewService(newMockRepository(), nil, nil, &MockTransactor{})
+
+		_, err := service.CreateProfile(ctx, userID.String(), ProfileRequest{
+			Name: "Banco", Delimiter: ";", DateColumn: "Data", DateFormat: "DD/MM/YYYY",
+			DescriptionColumn: "Histórico", AmountColumn: &amount, DebitColumn: &debit, DecimalSeparator: ",",
+		})
+		if !errors.Is(err, statement.ErrInvalidProfile) {
+			t.Errorf("esperava o erro %v, mas obteve %v", statement.ErrInvalidProfile, err)
+		}
+	})
+
+	t.Run("deve usar utf-8 por padrão e recusar nome repetido", func(t *testing.T) {
+		service := NewService(newMockRepository(), nil, nil, &MockTransactor{})
+		req := ProfileRequest{
+			Name: "Banco", Delimiter: ",", DateColumn: "date", DateFormat: "YYYY-MM-DD",
+			DescriptionColumn: "title", AmountColumn: &amount, DecimalSeparator: ".",
+		}
+
+		profile, err := service.CreateProfile(ctx, userID.String(), req)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if profile.Encoding != statement.EncodingUTF8 {
+			t.Errorf("esperava utf-8, obteve %q", profile.Encoding)
+		}
+		if _, err := service.CreateProfile(ctx, userID.String(), req); !errors.Is(err, ErrProfileExists) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrProfileExists, err)
+		}
+	})
+}
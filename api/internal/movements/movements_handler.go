@@ -0,0 +1,393 @@
+package movements
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/auth"
+	"github.com/martinsdevv/fincore/internal/categories"
+	"github.com/martinsdevv/fincore/internal/fx"
+	"github.com/martinsdevv/fincore/pkg/money"
+	"github.com/martinsdevv/fincore/pkg/pagination"
+	"github.com/rs/zerolog/log"
+)
+
+type Handler struct {
+	service  Service
+	validate *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	// Regras como gt=0 valem sobre o valor em unidades mínimas
+	validate.RegisterCustomTypeFunc(func(v reflect.Value) interface{} {
+		return v.Interface().(money.Money).Amount
+	}, money.Money{})
+
+	return &Handler{
+		service:  service,
+		validate: validate,
+	}
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("Falha ao escrever resposta JSON")
+	}
+}
+
+func (h *Handler) getUserIDFromContext(r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(auth.UserContextKey).(string)
+	if !ok {
+		log.Error().Msg("UserID não encontrado no contexto, middleware mal configurado")
+		return "", false
+	}
+	return userID, true
+}
+
+// writeServiceError traduz os erros do serviço, incluindo os da conta, para respostas HTTP
+func (h *Handler) writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, accounts.ErrAccountNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "account not found"})
+	case errors.Is(err, accounts.ErrForbidden):
+		h.writeJSON(w, http.StatusForbidden, map[string]string{"error": "you do not have permission for this account"})
+	case errors.Is(err, accounts.ErrAccountArchived):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": "account is archived"})
+	case errors.Is(err, accounts.ErrNegativeBalanceNotAllowed):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "account type does not allow a negative balance"})
+	case errors.Is(err, accounts.ErrInsufficientFunds):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "insufficient funds: credit limit exceeded"})
+	case errors.Is(err, ErrFutureMovement):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "movement cannot occur in the future"})
+	case errors.Is(err, ErrBeforeOpening):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "movement cannot occur before the account was opened"})
+	case errors.Is(err, ErrSameAccount):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "source and target accounts must differ"})
+	case errors.Is(err, ErrQuoteRequired):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "accounts use different currencies: request a quote at /fx/quotes first"})
+	case errors.Is(err, fx.ErrQuoteNotFound):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "quote not found, expired or already used"})
+	case errors.Is(err, fx.ErrQuoteMismatch):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrMovementNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "movement not found"})
+	case errors.Is(err, ErrSplitLines), errors.Is(err, ErrSplitSum), errors.Is(err, ErrSplitWithCategory),
+		errors.Is(err, ErrMovementNotSplittable), errors.Is(err, ErrMovementSplit):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrMovementNotPending), errors.Is(err, ErrHoldExpired):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrAlreadyReversed), errors.Is(err, ErrMovementReconciled):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrMovementNotReversible), errors.Is(err, ErrReversalTooLarge):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrInvalidExpiry):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, categories.ErrCategoryNotFound):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "category not found"})
+	case errors.Is(err, ErrTransferNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "transfer not found"})
+	case errors.Is(err, money.ErrCurrencyMismatch):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, money.ErrInvalidAmount):
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+	default:
+		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": fallback})
+	}
+}
+
+// parseListFilter lê ?from=&to= em RFC 3339, ?category_id= e ?status=
+func parseListFilter(q url.Values) (ListMovementsFilter, error) {
+	var filter ListMovementsFilter
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		raw := q.Get(p.name)
+		if raw == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return filter, errors.New("invalid " + p.name + ": use RFC 3339")
+		}
+		*p.dst = &t
+	}
+	if raw := q.Get("category_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			return filter, errors.New("invalid category_id")
+		}
+		filter.CategoryID = &id
+	}
+	if raw := q.Get("status"); raw != "" {
+		status := MovementStatus(raw)
+		if !status.IsValid() {
+			return filter, errors.New("invalid status: use pending, posted or voided")
+		}
+		filter.Status = &status
+	}
+	return filter, nil
+}
+
+func (h *Handler) HandleCreateMovement(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req CreateMovementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	movement, err := h.service.CreateMovement(r.Context(), chi.URLParam(r, "accountID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to record movement")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, movement)
+}
+
+func (h *Handler) HandleListMovements(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	filter, err := parseListFilter(r.URL.Query())
+	if err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	page, err := pagination.Parse(r.URL.Query(), MovementSortFields, DefaultMovementSort)
+	if err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	movements, err := h.service.ListMovements(r.Context(), chi.URLParam(r, "accountID"), userID, filter, page)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve movements")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, movements)
+}
+
+func (h *Handler) HandleSetMovementCategory(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req SetMovementCategoryRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	movement, err := h.service.SetMovementCategory(r.Context(), chi.URLParam(r, "accountID"), chi.URLParam(r, "movementID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to update movement category")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, movement)
+}
+
+func (h *Handler) HandleSetMovementSplits(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req SetSplitsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	movement, err := h.service.SetMovementSplits(r.Context(), chi.URLParam(r, "accountID"), chi.URLParam(r, "movementID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to split movement")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, movement)
+}
+
+func (h *Handler) HandleCreateHold(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req CreateHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	movement, err := h.service.CreateHold(r.Context(), chi.URLParam(r, "accountID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to place hold")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, movement)
+}
+
+// HandleCaptureHold aceita corpo vazio: captura pelo valor retido
+func (h *Handler) HandleCaptureHold(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req CaptureHoldRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	movement, err := h.service.CaptureHold(r.Context(), chi.URLParam(r, "accountID"), chi.URLParam(r, "movementID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to capture hold")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, movement)
+}
+
+func (h *Handler) HandleVoidHold(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	movement, err := h.service.VoidHold(r.Context(), chi.URLParam(r, "accountID"), chi.URLParam(r, "movementID"), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to void hold")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, movement)
+}
+
+// HandleReverseMovement aceita corpo vazio: estorna o que ainda resta
+func (h *Handler) HandleReverseMovement(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req ReverseMovementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	reversal, err := h.service.ReverseMovement(r.Context(), chi.URLParam(r, "accountID"), chi.URLParam(r, "movementID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to reverse movement")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, reversal)
+}
+
+func (h *Handler) HandleCreateTransfer(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req CreateTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if errors.Is(err, money.ErrInvalidAmount) {
+			h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	transfer, err := h.service.CreateTransfer(r.Context(), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to record transfer")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, transfer)
+}
+
+func (h *Handler) HandleGetTransfer(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	transfer, err := h.service.GetTransfer(r.Context(), chi.URLParam(r, "transferID"), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve transfer")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, transfer)
+}
@@ -0,0 +1,31 @@
+package movements
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RunHoldExpiry anula as retenções vencidas a cada interval, até ctx ser
+// cancelado. Cada retenção muda de status de forma condicional, então várias
+// réplicas podem rodar o job ao mesmo tempo sem liberar o saldo em dobro.
+func RunHoldExpiry(ctx context.Context, svc Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		expired, err := svc.ExpireHolds(ctx, time.Now())
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to expire holds")
+		} else if expired > 0 {
+			log.Info().Int("holds", expired).Msg("Expired holds voided")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package movements
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/pkg/money"
+	"github.com/martinsdevv/fincore/pkg/pagination"
+)
+
+// MovementKind diz a origem de um lançamento
+type MovementKind string
+
+const (
+	KindOpening   MovementKind = "opening" // saldo inicial, gravado junto com a conta
+	KindManual    MovementKind = "manual"
+	KindTransfer  MovementKind = "transfer"  // perna de uma transferência entre contas
+	KindScheduled MovementKind = "scheduled" // ocorrência lançada por um agendamento
+	KindReversal  MovementKind = "reversal"  // estorno, total ou parcial, de outro lançamento
+	KindImported  MovementKind = "imported"  // linha de um extrato importado
+)
+
+// IsReversible diz se lançamentos deste tipo podem ser estornados. Transferências
+// têm duas pernas e saldos iniciais nascem com a conta; um estorno não se estorna.
+func (k MovementKind) IsReversible() bool {
+	return k == KindManual || k == KindScheduled || k == KindImported
+}
+
+// MovementStatus é o ciclo de vida de um lançamento: só os posted entram no saldo
+type MovementStatus string
+
+const (
+	StatusPending MovementStatus = "pending" // retenção: reserva saldo disponível até ser capturada ou anulada
+	StatusPosted  MovementStatus = "posted"
+	StatusVoided  MovementStatus = "voided" // retenção anulada ou expirada
+)
+
+func (s MovementStatus) IsValid() bool {
+	return s == StatusPending || s == StatusPosted || s == StatusVoided
+}
+
+// DefaultHoldTTL é quanto uma retenção dura sem expires_at explícito
+const DefaultHoldTTL = 7 * 24 * time.Hour
+
+// Movement é um lançamento no histórico de uma conta. A soma dos lançamentos
+// posted é sempre igual a accounts.balance; a dos pending, a -accounts.held.
+type Movement struct {
+	ID               uuid.UUID      `json:"id"`
+	AccountID        uuid.UUID      `json:"account_id"`
+	Kind             MovementKind   `json:"kind"`
+	Status           MovementStatus `json:"status"`
+	Amount           int64          `json:"amount"` // Em centavos; negativo para saídas
+	AuthorizedAmount *int64         `json:"authorized_amount"`
+	Description      string         `json:"description"`
+	OccurredAt       time.Time      `json:"occurred_at"`
+	ExpiresAt        *time.Time     `json:"expires_at"`
+	CreatedBy        *uuid.UUID     `json:"created_by"`
+	TransferID       *uuid.UUID     `json:"transfer_id"`
+	CategoryID       *uuid.UUID     `json:"category_id"`
+	ReversalOf       *uuid.UUID     `json:"reversal_of"`   // lançamento estornado, se Kind for reversal
+	ReconciledAt     *time.Time     `json:"reconciled_at"` // quando entrou numa conciliação finalizada
+	CreatedAt        time.Time      `json:"created_at"`
+	Splits           []Split        `json:"splits,omitempty"`
+	// ReversedBy são os estornos deste lançamento, do mais antigo ao mais novo
+	ReversedBy []Reversal `json:"reversed_by,omitempty"`
+}
+
+// Reversal resume um estorno para quem olha o lançamento original
+type Reversal struct {
+	ID         uuid.UUID `json:"id"`
+	Amount     int64     `json:"amount"` // Com o sinal oposto ao do original
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// IsReconciled diz se o lançamento já foi conferido com o extrato numa
+// conciliação finalizada; a partir daí, categoria e divisão ficam travadas
+func (m Movement) IsReconciled() bool {
+	return m.ReconciledAt != nil
+}
+
+// ReversedAmount é quanto do lançamento já foi estornado, em valor absoluto
+func (m Movement) ReversedAmount() int64 {
+	var total int64
+	for _, r := range m.ReversedBy {
+		total -= r.Amount
+	}
+	if m.Amount < 0 {
+		return -total
+	}
+	return total
+}
+
+// Split é uma linha de um lançamento dividido. As linhas somam exatamente o
+// valor do lançamento e, quando existem, substituem a categoria dele.
+type Split struct {
+	ID         uuid.UUID  `json:"id"`
+	MovementID uuid.UUID  `json:"movement_id"`
+	CategoryID *uuid.UUID `json:"category_id"`
+	Amount     int64      `json:"amount"` // Em unidades mínimas, com o sinal do lançamento
+	Memo       string     `json:"memo"`
+	Position   int        `json:"position"`
+}
+
+// CreateMovementRequest registra uma entrada (amount > 0) ou saída (amount < 0).
+// Sem occurred_at, vale o momento da requisição; datas retroativas são aceitas.
+type CreateMovementRequest struct {
+	Amount      int64      `json:"amount" validate:"required"`
+	Description string     `json:"description" validate:"max=255"`
+	OccurredAt  *time.Time `json:"occurred_at"`
+	CategoryID  *uuid.UUID `json:"category_id"`
+	// Splits divide o lançamento; não pode ser usado junto com category_id
+	Splits []SplitRequest `json:"splits" validate:"omitempty,dive"`
+}
+
+type SplitRequest struct {
+	Amount     int64      `json:"amount" validate:"required"`
+	CategoryID *uuid.UUID `json:"category_id"`
+	Memo       string     `json:"memo" validate:"max=255"`
+}
+
+// CreateHoldRequest reserva amount (positivo) do saldo disponível sem mexer no
+// saldo contábil, como a autorização de um cartão. Sem expires_at, a retenção
+// expira depois de DefaultHoldTTL.
+type CreateHoldRequest struct {
+	Amount      int64      `json:"amount" validate:"required,gt=0"`
+	Description string     `json:"description" validate:"max=255"`
+	CategoryID  *uuid.UUID `json:"category_id"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// CaptureHoldRequest lança a retenção. amount é o valor final (positivo), que
+// pode diferir do retido; sem ele, vale o valor retido.
+type CaptureHoldRequest struct {
+	Amount      *int64  `json:"amount" validate:"omitempty,gt=0"`
+	Description *string `json:"description" validate:"omitempty,max=255"`
+}
+
+// ReverseMovementRequest estorna um lançamento efetivado. amount (positivo) é
+// quanto estornar, para reembolsos parciais; sem ele, estorna o que ainda resta.
+type ReverseMovementRequest struct {
+	Amount      *int64  `json:"amount" validate:"omitempty,gt=0"`
+	Description *string `json:"description" validate:"omitempty,max=255"`
+}
+
+// SetSplitsRequest substitui as linhas do lançamento; uma lista vazia desfaz a divisão
+type SetSplitsRequest struct {
+	Splits []SplitRequest `json:"splits" validate:"dive"`
+}
+
+// SetMovementCategoryRequest recategoriza um lançamento; category_id null o deixa sem categoria
+type SetMovementCategoryRequest struct {
+	CategoryID *uuid.UUID `json:"category_id"`
+}
+
+// ListMovementsFilter limita o período por occurred_at; From é inclusivo e To
+// exclusivo. CategoryID filtra pela categoria exata, sem as subcategorias.
+type ListMovementsFilter struct {
+	From       *time.Time
+	To         *time.Time
+	CategoryID *uuid.UUID
+	Status     *MovementStatus
+}
+
+// MovementSortFields são os campos aceitos em ?sort= no histórico
+var MovementSortFields = []pagination.SortField{
+	{Name: "occurred_at", Column: "occurred_at", Kind: pagination.KindTime},
+	{Name: "created_at", Column: "created_at", Kind: pagination.KindTime},
+	{Name: "amount", Column: "amount", Kind: pagination.KindInt},
+}
+
+// DefaultMovementSort mostra os lançamentos mais recentes primeiro
+const DefaultMovementSort = "-occurred_at"
+
+func (m Movement) sortKey(field string) (interface{}, string) {
+	switch field {
+	case "created_at":
+		return m.CreatedAt, m.ID.String()
+	case "amount":
+		return m.Amount, m.ID.String()
+	default:
+		return m.OccurredAt, m.ID.String()
+	}
+}
+
+type MovementResponse struct {
+	ID               uuid.UUID      `json:"id"`
+	AccountID        uuid.UUID      `json:"account_id"`
+	Kind             MovementKind   `json:"kind"`
+	Status           MovementStatus `json:"status"`
+	Amount           int64          `json:"amount"`
+	AuthorizedAmount *int64         `json:"authorized_amount,omitempty"` // valor retido, se o lançamento nasceu como retenção
+	Description      string         `json:"description"`
+	OccurredAt       time.Time      `json:"occurred_at"`
+	ExpiresAt        *time.Time     `json:"expires_at,omitempty"`
+	CreatedBy        *uuid.UUID     `json:"created_by"`
+	TransferID       *uuid.UUID     `json:"transfer_id,omitempty"`
+	CategoryID       *uuid.UUID     `json:"category_id"`
+	Splits           []Split        `json:"splits,omitempty"`
+	ReversalOf       *uuid.UUID     `json:"reversal_of,omitempty"`
+	ReversedBy       []Reversal     `json:"reversed_by,omitempty"`
+	ReversedAmount   int64          `json:"reversed_amount,omitempty"` // soma absoluta dos estornos
+	ReconciledAt     *time.Time     `json:"reconciled_at,omitempty"`
+	CreatedAt        time.Time      `json:"created_at"`
+	BalanceAfter     *int64         `json:"balance_after,omitempty"` // saldo da conta logo após o lançamento, só na criação
+}
+
+// Transfer move dinheiro entre duas contas. Entre moedas diferentes, o valor de
+// destino vem de uma cotação travada em /fx/quotes; Rate, MidRate e Spread
+// registram a conversão aplicada (1, 1 e 0 na mesma moeda).
+type Transfer struct {
+	ID              uuid.UUID
+	SourceAccountID uuid.UUID
+	TargetAccountID uuid.UUID
+	SourceAmount    int64
+	SourceCurrency  string
+	TargetAmount    int64
+	TargetCurrency  string
+	Rate            float64
+	MidRate         float64
+	Spread          float64
+	RateDate        *time.Time
+	QuoteID         *uuid.UUID
+	Description     string
+	OccurredAt      time.Time
+	CreatedBy       *uuid.UUID
+	CreatedAt       time.Time
+}
+
+// CreateTransferRequest debita amount (na moeda da origem) da conta de origem e
+// credita o destino. quote_id é obrigatório quando as moedas diferem e precisa
+// bater com as contas e o valor.
+type CreateTransferRequest struct {
+	SourceAccountID uuid.UUID   `json:"source_account_id" validate:"required"`
+	TargetAccountID uuid.UUID   `json:"target_account_id" validate:"required,nefield=SourceAccountID"`
+	Amount          money.Money `json:"amount" validate:"gt=0"`
+	QuoteID         *uuid.UUID  `json:"quote_id"`
+	Description     string      `json:"description" validate:"max=255"`
+}
+
+type TransferResponse struct {
+	ID              uuid.UUID   `json:"id"`
+	SourceAccountID uuid.UUID   `json:"source_account_id"`
+	TargetAccountID uuid.UUID   `json:"target_account_id"`
+	SourceAmount    money.Money `json:"source_amount"`
+	TargetAmount    money.Money `json:"target_amount"`
+	Rate            float64     `json:"rate"`
+	MidRate         float64     `json:"mid_rate"`
+	Spread          float64     `json:"spread"`
+	RateDate        *string     `json:"rate_date"`
+	QuoteID         *uuid.UUID  `json:"quote_id"`
+	Description     string      `json:"description"`
+	OccurredAt      time.Time   `json:"occurred_at"`
+	CreatedBy       *uuid.UUID  `json:"created_by"`
+	CreatedAt       time.Time   `json:"created_at"`
+}
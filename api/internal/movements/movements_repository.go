@@ -0,0 +1,395 @@
+package movements
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/martinsdevv/fincore/pkg/database"
+	"github.com/martinsdevv/fincore/pkg/pagination"
+)
+
+type Repository interface {
+	CreateMovement(ctx context.Context, movement *Movement) error
+	ListMovements(ctx context.Context, accountID uuid.UUID, filter ListMovementsFilter, page pagination.Params) ([]Movement, error)
+	// GetMovement devolve nil se o lançamento não existir na conta
+	GetMovement(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Movement, error)
+	// SetMovementCategory só grava em lançamento não conciliado; devolve nil se
+	// o lançamento não existir na conta ou se uma conciliação o travou antes
+	SetMovementCategory(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID, categoryID *uuid.UUID) (*Movement, error)
+	// LockMovement é o GetMovement com FOR UPDATE: segura estornos concorrentes
+	// do mesmo lançamento até o fim da transação
+	LockMovement(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Movement, error)
+	// ListReversals devolve os estornos dos lançamentos, do mais antigo ao mais novo
+	ListReversals(ctx context.Context, movementIDs []uuid.UUID) ([]Movement, error)
+
+	// SettleMovement grava status, valor, data e descrição de uma retenção se ela
+	// ainda estiver pending; false se outra requisição (ou a expiração) chegou antes
+	SettleMovement(ctx context.Context, movement *Movement) (bool, error)
+	// ListExpiredHolds devolve até limit retenções pending com expires_at <= now
+	ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]Movement, error)
+
+	// ReplaceSplits troca as linhas do lançamento por splits; com linhas, a
+	// categoria do próprio lançamento é limpa. Trava o lançamento e retorna
+	// ErrMovementReconciled se ele já estiver conciliado; precisa rodar numa
+	// transação para a trava valer até o commit.
+	ReplaceSplits(ctx context.Context, movementID uuid.UUID, splits []Split) error
+	// ListSplits devolve as linhas dos lançamentos, em ordem de lançamento e posição
+	ListSplits(ctx context.Context, movementIDs []uuid.UUID) ([]Split, error)
+
+	CreateTransfer(ctx context.Context, transfer *Transfer) error
+	GetTransfer(ctx context.Context, id uuid.UUID) (*Transfer, error)
+}
+
+type pgxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgxRepository{db: db}
+}
+
+// conn usa a transação aberta por um database.Transactor, se houver
+func (r *pgxRepository) conn(ctx context.Context) database.DBTX {
+	return database.Conn(ctx, r.db)
+}
+
+// Colunas lidas por scanMovement, sempre nesta ordem
+const movementColumns = `id, account_id, kind, status, amount, authorized_amount, description, occurred_at, expires_at,
+	created_by, transfer_id, category_id, reversal_of, reconciled_at, created_at`
+
+func scanMovement(row pgx.Row, m *Movement) error {
+	return row.Scan(
+		&m.ID,
+		&m.AccountID,
+		&m.Kind,
+		&m.Status,
+		&m.Amount,
+		&m.AuthorizedAmount,
+		&m.Description,
+		&m.OccurredAt,
+		&m.ExpiresAt,
+		&m.CreatedBy,
+		&m.TransferID,
+		&m.CategoryID,
+		&m.ReversalOf,
+		&m.ReconciledAt,
+		&m.CreatedAt,
+	)
+}
+
+func (r *pgxRepository) CreateMovement(ctx context.Context, m *Movement) error {
+	query := `
+		INSERT INTO movements (` + movementColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
+
+	_, err := r.conn(ctx).Exec(ctx, query,
+		m.ID,
+		m.AccountID,
+		m.Kind,
+		m.Status,
+		m.Amount,
+		m.AuthorizedAmount,
+		m.Description,
+		m.OccurredAt,
+		m.ExpiresAt,
+		m.CreatedBy,
+		m.TransferID,
+		m.CategoryID,
+		m.ReversalOf,
+		m.ReconciledAt,
+		m.CreatedAt,
+	)
+	return err
+}
+
+func (r *pgxRepository) ListMovements(ctx context.Context, accountID uuid.UUID, filter ListMovementsFilter, page pagination.Params) ([]Movement, error) {
+	conditions := []string{"account_id = $1"}
+	args := []interface{}{accountID}
+
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.From != nil {
+		conditions = append(conditions, "occurred_at >= "+addArg(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "occurred_at < "+addArg(*filter.To))
+	}
+	if filter.CategoryID != nil {
+		// Um lançamento dividido não tem categoria própria: vale a das linhas
+		n := addArg(*filter.CategoryID)
+		conditions = append(conditions, "(category_id = "+n+
+			" OR EXISTS (SELECT 1 FROM movement_splits s WHERE s.movement_id = movements.id AND s.category_id = "+n+"))")
+	}
+	if filter.Status != nil {
+		conditions = append(conditions, "status = "+addArg(*filter.Status))
+	}
+
+	keyset, keysetArgs := page.Where("id", len(args)+1)
+	conditions = append(conditions, keyset)
+	args = append(args, keysetArgs...)
+
+	query := `
+		SELECT ` + movementColumns + `
+		FROM movements
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + page.OrderBy("id") + `
+		LIMIT ` + addArg(page.FetchLimit())
+
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []Movement
+	for rows.Next() {
+		var m Movement
+		if err := scanMovement(rows, &m); err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+
+	return movements, rows.Err()
+}
+
+func (r *pgxRepository) GetMovement(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Movement, error) {
+	return r.getMovement(ctx, `SELECT `+movementColumns+` FROM movements WHERE id = $2 AND account_id = $1`, accountID, movementID)
+}
+
+func (r *pgxRepository) LockMovement(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Movement, error) {
+	return r.getMovement(ctx, `SELECT `+movementColumns+` FROM movements WHERE id = $2 AND account_id = $1 FOR UPDATE`, accountID, movementID)
+}
+
+func (r *pgxRepository) getMovement(ctx context.Context, query string, accountID uuid.UUID, movementID uuid.UUID) (*Movement, error) {
+	var m Movement
+	if err := scanMovement(r.conn(ctx).QueryRow(ctx, query, accountID, movementID), &m); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *pgxRepository) SetMovementCategory(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID, categoryID *uuid.UUID) (*Movement, error) {
+	query := `
+		UPDATE movements
+		SET category_id = $3
+		WHERE id = $2 AND account_id = $1 AND reconciled_at IS NULL
+		RETURNING ` + movementColumns
+
+	var m Movement
+	if err := scanMovement(r.conn(ctx).QueryRow(ctx, query, accountID, movementID, categoryID), &m); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *pgxRepository) SettleMovement(ctx context.Context, m *Movement) (bool, error) {
+	query := `
+		UPDATE movements
+		SET status = $2, amount = $3, occurred_at = $4, description = $5
+		WHERE id = $1 AND status = 'pending'`
+
+	tag, err := r.conn(ctx).Exec(ctx, query, m.ID, m.Status, m.Amount, m.OccurredAt, m.Description)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *pgxRepository) ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]Movement, error) {
+	query := `
+		SELECT ` + movementColumns + `
+		FROM movements
+		WHERE status = 'pending' AND expires_at <= $1
+		ORDER BY expires_at, id
+		LIMIT $2`
+
+	rows, err := r.conn(ctx).Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []Movement
+	for rows.Next() {
+		var m Movement
+		if err := scanMovement(rows, &m); err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+	return movements, rows.Err()
+}
+
+func (r *pgxRepository) ListReversals(ctx context.Context, movementIDs []uuid.UUID) ([]Movement, error) {
+	if len(movementIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT ` + movementColumns + `
+		FROM movements
+		WHERE reversal_of = ANY($1)
+		ORDER BY created_at, id`
+
+	rows, err := r.conn(ctx).Query(ctx, query, movementIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var movements []Movement
+	for rows.Next() {
+		var m Movement
+		if err := scanMovement(rows, &m); err != nil {
+			return nil, err
+		}
+		movements = append(movements, m)
+	}
+	return movements, rows.Err()
+}
+
+func (r *pgxRepository) ReplaceSplits(ctx context.Context, movementID uuid.UUID, splits []Split) error {
+	conn := r.conn(ctx)
+
+	// A trava segura a finalização de uma conciliação até o commit; sem ela,
+	// as linhas podiam mudar logo depois de o lançamento ser conciliado
+	var reconciledAt *time.Time
+	err := conn.QueryRow(ctx, `SELECT reconciled_at FROM movements WHERE id = $1 FOR UPDATE`, movementID).Scan(&reconciledAt)
+	if err != nil {
+		return err
+	}
+	if reconciledAt != nil {
+		return ErrMovementReconciled
+	}
+
+	if _, err := conn.Exec(ctx, `DELETE FROM movement_splits WHERE movement_id = $1`, movementID); err != nil {
+		return err
+	}
+	if len(splits) == 0 {
+		return nil
+	}
+
+	if _, err := conn.Exec(ctx, `UPDATE movements SET category_id = NULL WHERE id = $1`, movementID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO movement_splits (id, movement_id, category_id, amount, memo, position)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+	for _, sp := range splits {
+		if _, err := conn.Exec(ctx, query, sp.ID, movementID, sp.CategoryID, sp.Amount, sp.Memo, sp.Position); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *pgxRepository) ListSplits(ctx context.Context, movementIDs []uuid.UUID) ([]Split, error) {
+	if len(movementIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id, movement_id, category_id, amount, memo, position
+		FROM movement_splits
+		WHERE movement_id = ANY($1)
+		ORDER BY movement_id, position`
+
+	rows, err := r.conn(ctx).Query(ctx, query, movementIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var splits []Split
+	for rows.Next() {
+		var sp Split
+		if err := rows.Scan(&sp.ID, &sp.MovementID, &sp.CategoryID, &sp.Amount, &sp.Memo, &sp.Position); err != nil {
+			return nil, err
+		}
+		splits = append(splits, sp)
+	}
+	return splits, rows.Err()
+}
+
+func (r *pgxRepository) CreateTransfer(ctx context.Context, t *Transfer) error {
+	query := `
+		INSERT INTO transfers (
+			id, source_account_id, target_account_id, source_amount, source_currency,
+			target_amount, target_currency, rate, mid_rate, spread, rate_date, quote_id,
+			description, occurred_at, created_by, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)`
+
+	_, err := r.conn(ctx).Exec(ctx, query,
+		t.ID,
+		t.SourceAccountID,
+		t.TargetAccountID,
+		t.SourceAmount,
+		t.SourceCurrency,
+		t.TargetAmount,
+		t.TargetCurrency,
+		t.Rate,
+		t.MidRate,
+		t.Spread,
+		t.RateDate,
+		t.QuoteID,
+		t.Description,
+		t.OccurredAt,
+		t.CreatedBy,
+		t.CreatedAt,
+	)
+	return err
+}
+
+func (r *pgxRepository) GetTransfer(ctx context.Context, id uuid.UUID) (*Transfer, error) {
+	query := `
+		SELECT id, source_account_id, target_account_id, source_amount, source_currency,
+			target_amount, target_currency, rate::float8, mid_rate::float8, spread::float8,
+			rate_date, quote_id, description, occurred_at, created_by, created_at
+		FROM transfers
+		WHERE id = $1`
+
+	var t Transfer
+	err := r.conn(ctx).QueryRow(ctx, query, id).Scan(
+		&t.ID,
+		&t.SourceAccountID,
+		&t.TargetAccountID,
+		&t.SourceAmount,
+		&t.SourceCurrency,
+		&t.TargetAmount,
+		&t.TargetCurrency,
+		&t.Rate,
+		&t.MidRate,
+		&t.Spread,
+		&t.RateDate,
+		&t.QuoteID,
+		&t.Description,
+		&t.OccurredAt,
+		&t.CreatedBy,
+		&t.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &t, nil
+}
@@ -0,0 +1,16 @@
+package movements
+
+import "github.com/go-chi/chi/v5"
+
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Post("/accounts/{accountID}/movements", h.HandleCreateMovement)
+	r.Get("/accounts/{accountID}/movements", h.HandleListMovements)
+	r.Put("/accounts/{accountID}/movements/{movementID}/category", h.HandleSetMovementCategory)
+	r.Put("/accounts/{accountID}/movements/{movementID}/splits", h.HandleSetMovementSplits)
+	r.Post("/accounts/{accountID}/holds", h.HandleCreateHold)
+	r.Post("/accounts/{accountID}/movements/{movementID}/capture", h.HandleCaptureHold)
+	r.Post("/accounts/{accountID}/movements/{movementID}/void", h.HandleVoidHold)
+	r.Post("/accounts/{accountID}/movements/{movementID}/reverse", h.HandleReverseMovement)
+	r.Post("/transfers", h.HandleCreateTransfer)
+	r.Get("/transfers/{transferID}", h.HandleGetTransfer)
+}
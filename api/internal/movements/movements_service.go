@@ -0,0 +1,954 @@
+package movements
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/categories"
+	"github.com/martinsdevv/fincore/internal/fx"
+	"github.com/martinsdevv/fincore/pkg/database"
+	"github.com/martinsdevv/fincore/pkg/money"
+	"github.com/martinsdevv/fincore/pkg/pagination"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrFutureMovement   = errors.New("movement cannot occur in the future")
+	ErrBeforeOpening    = errors.New("movement cannot occur before the account was opened")
+	ErrSameAccount      = errors.New("source and target accounts must differ")
+	ErrQuoteRequired    = errors.New("a quote is required to transfer between currencies")
+	ErrTransferNotFound = errors.New("transfer not found")
+	ErrMovementNotFound = errors.New("movement not found")
+
+	ErrSplitLines            = errors.New("a split needs at least two non-zero lines with the sign of the movement")
+	ErrSplitSum              = errors.New("split amounts must add up to the movement amount")
+	ErrSplitWithCategory     = errors.New("use either category_id or splits, not both")
+	ErrMovementNotSplittable = errors.New("only posted manual movements can be split")
+	ErrMovementSplit         = errors.New("movement is split: set the category of each line instead")
+
+	ErrMovementNotPending = errors.New("movement is not a pending hold")
+	ErrHoldExpired        = errors.New("hold has expired")
+	ErrInvalidExpiry      = errors.New("expires_at must be in the future")
+
+	ErrMovementNotReversible = errors.New("only posted manual, scheduled and imported movements can be reversed; void pending holds instead")
+	ErrAlreadyReversed       = errors.New("movement was already fully reversed")
+	ErrReversalTooLarge      = errors.New("reversal amount exceeds what is left to reverse")
+
+	ErrMovementReconciled = errors.New("movement is reconciled and can no longer be edited")
+)
+
+// expiryBatchSize limita quantas retenções cada execução do job de expiração anula
+const expiryBatchSize = 500
+
+type Service interface {
+	CreateMovement(ctx context.Context, accountID string, userID string, req CreateMovementRequest) (*MovementResponse, error)
+	// PostMovement é o CreateMovement para lançamentos gerados pelo sistema em
+	// nome do usuário (agendamentos, importações), que guardam a própria origem
+	PostMovement(ctx context.Context, accountID string, userID string, kind MovementKind, req CreateMovementRequest) (*MovementResponse, error)
+	ListMovements(ctx context.Context, accountID string, userID string, filter ListMovementsFilter, page pagination.Params) (*pagination.Page[MovementResponse], error)
+	SetMovementCategory(ctx context.Context, accountID string, movementID string, userID string, req SetMovementCategoryRequest) (*MovementResponse, error)
+	SetMovementSplits(ctx context.Context, accountID string, movementID string, userID string, req SetSplitsRequest) (*MovementResponse, error)
+
+	// CreateHold grava um lançamento pending que reserva saldo disponível sem
+	// mexer no saldo contábil
+	CreateHold(ctx context.Context, accountID string, userID string, req CreateHoldRequest) (*MovementResponse, error)
+	// CaptureHold lança a retenção pelo valor final, que pode diferir do retido
+	CaptureHold(ctx context.Context, accountID string, movementID string, userID string, req CaptureHoldRequest) (*MovementResponse, error)
+	VoidHold(ctx context.Context, accountID string, movementID string, userID string) (*MovementResponse, error)
+	// ExpireHolds anula as retenções vencidas até now e devolve quantas foram anuladas
+	ExpireHolds(ctx context.Context, now time.Time) (int, error)
+
+	// ReverseMovement grava um estorno, total ou parcial, ligado ao lançamento
+	ReverseMovement(ctx context.Context, accountID string, movementID string, userID string, req ReverseMovementRequest) (*MovementResponse, error)
+
+	CreateTransfer(ctx context.Context, userID string, req CreateTransferRequest) (*TransferResponse, error)
+	GetTransfer(ctx context.Context, transferID string, userID string) (*TransferResponse, error)
+}
+
+type service struct {
+	repo       Repository
+	accounts   accounts.Service
+	fx         fx.Service
+	categories categories.Service
+	tx         database.Transactor
+}
+
+func NewService(repo Repository, accountsSvc accounts.Service, fxSvc fx.Service, categoriesSvc categories.Service, tx database.Transactor) Service {
+	return &service{repo: repo, accounts: accountsSvc, fx: fxSvc, categories: categoriesSvc, tx: tx}
+}
+
+func (s *service) CreateMovement(ctx context.Context, accountIDStr string, userIDStr string, req CreateMovementRequest) (*MovementResponse, error) {
+	return s.PostMovement(ctx, accountIDStr, userIDStr, KindManual, req)
+}
+
+// PostMovement grava o lançamento e ajusta o saldo da conta na mesma transação
+func (s *service) PostMovement(ctx context.Context, accountIDStr string, userIDStr string, kind MovementKind, req CreateMovementRequest) (*MovementResponse, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	occurredAt := now
+	if req.OccurredAt != nil {
+		occurredAt = req.OccurredAt.UTC()
+	}
+	// Lançamentos futuros mudariam o saldo atual antes da hora
+	if occurredAt.After(now) {
+		return nil, ErrFutureMovement
+	}
+	if occurredAt.Before(account.CreatedAt) {
+		return nil, ErrBeforeOpening
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+	if req.CategoryID != nil {
+		if len(req.Splits) > 0 {
+			return nil, ErrSplitWithCategory
+		}
+		if _, err := s.categories.ResolveCategory(ctx, *req.CategoryID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	movement := &Movement{
+		ID:          uuid.New(),
+		AccountID:   account.ID,
+		Kind:        kind,
+		Status:      StatusPosted,
+		Amount:      req.Amount,
+		Description: req.Description,
+		OccurredAt:  occurredAt,
+		CreatedBy:   &userID,
+		CategoryID:  req.CategoryID,
+		CreatedAt:   now,
+	}
+
+	var splits []Split
+	if len(req.Splits) > 0 {
+		if splits, err = s.buildSplits(ctx, userID, movement.ID, req.Amount, req.Splits); err != nil {
+			return nil, err
+		}
+	}
+
+	var balanceAfter int64
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		updated, err := s.accounts.AdjustBalance(ctx, account.ID, movement.Amount, movement.OccurredAt)
+		if err != nil {
+			return err
+		}
+		balanceAfter = updated.Balance
+
+		if err := s.repo.CreateMovement(ctx, movement); err != nil {
+			return err
+		}
+		if len(splits) > 0 {
+			return s.repo.ReplaceSplits(ctx, movement.ID, splits)
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, accounts.ErrInsufficientFunds) && !errors.Is(err, accounts.ErrNegativeBalanceNotAllowed) &&
+			!errors.Is(err, accounts.ErrAccountArchived) {
+			log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to record movement")
+		}
+		return nil, err
+	}
+
+	movement.Splits = splits
+	resp := toMovementResponse(movement)
+	resp.BalanceAfter = &balanceAfter
+	return resp, nil
+}
+
+func (s *service) ListMovements(ctx context.Context, accountIDStr string, userIDStr string, filter ListMovementsFilter, page pagination.Params) (*pagination.Page[MovementResponse], error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	movements, err := s.repo.ListMovements(ctx, account.ID, filter, page)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to list movements from repository")
+		return nil, err
+	}
+	if err := s.attachSplits(ctx, movements); err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to list movement splits from repository")
+		return nil, err
+	}
+	if err := s.attachReversals(ctx, movements); err != nil {
+		log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to list movement reversals from repository")
+		return nil, err
+	}
+
+	result := pagination.NewPage(movements, page, func(m Movement) (interface{}, string) {
+		return m.sortKey(page.Sort.Name)
+	})
+	responses := pagination.Map(result, func(m Movement) MovementResponse {
+		return *toMovementResponse(&m)
+	})
+
+	return &responses, nil
+}
+
+// SetMovementCategory troca a categoria de um lançamento já gravado; o valor
+// e a data não mudam
+func (s *service) SetMovementCategory(ctx context.Context, accountIDStr string, movementIDStr string, userIDStr string, req SetMovementCategoryRequest) (*MovementResponse, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	movementID, err := uuid.Parse(movementIDStr)
+	if err != nil {
+		return nil, ErrMovementNotFound
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+	if req.CategoryID != nil {
+		if _, err := s.categories.ResolveCategory(ctx, *req.CategoryID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Num lançamento dividido a categoria dele seria ignorada pelos relatórios
+	current, err := s.getMovement(ctx, account.ID, movementID)
+	if err != nil {
+		return nil, err
+	}
+	if current.IsReconciled() {
+		return nil, ErrMovementReconciled
+	}
+	if len(current.Splits) > 0 {
+		return nil, ErrMovementSplit
+	}
+
+	movement, err := s.repo.SetMovementCategory(ctx, account.ID, movementID, req.CategoryID)
+	if err != nil {
+		log.Error().Err(err).Str("movementID", movementIDStr).Msg("Failed to set movement category")
+		return nil, err
+	}
+	// O lançamento existia acima: sem linha atualizada, foi conciliado no meio
+	if movement == nil {
+		return nil, ErrMovementReconciled
+	}
+
+	return toMovementResponse(movement), nil
+}
+
+// SetMovementSplits divide um lançamento manual em linhas, substituindo as
+// anteriores; uma lista vazia desfaz a divisão e deixa o lançamento sem categoria
+func (s *service) SetMovementSplits(ctx context.Context, accountIDStr string, movementIDStr string, userIDStr string, req SetSplitsRequest) (*MovementResponse, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	movementID, err := uuid.Parse(movementIDStr)
+	if err != nil {
+		return nil, ErrMovementNotFound
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	movement, err := s.getMovement(ctx, account.ID, movementID)
+	if err != nil {
+		return nil, err
+	}
+	// Retenções ainda podem mudar de valor na captura
+	if movement.Kind != KindManual || movement.Status != StatusPosted {
+		return nil, ErrMovementNotSplittable
+	}
+	if movement.IsReconciled() {
+		return nil, ErrMovementReconciled
+	}
+
+	var splits []Split
+	if len(req.Splits) > 0 {
+		if splits, err = s.buildSplits(ctx, userID, movement.ID, movement.Amount, req.Splits); err != nil {
+			return nil, err
+		}
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		return s.repo.ReplaceSplits(ctx, movement.ID, splits)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrMovementReconciled) {
+			log.Error().Err(err).Str("movementID", movementIDStr).Msg("Failed to replace movement splits")
+		}
+		return nil, err
+	}
+
+	if len(splits) > 0 || len(movement.Splits) > 0 {
+		movement.CategoryID = nil
+	}
+	movement.Splits = splits
+	return toMovementResponse(movement), nil
+}
+
+func (s *service) CreateHold(ctx context.Context, accountIDStr string, userIDStr string, req CreateHoldRequest) (*MovementResponse, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+	if req.CategoryID != nil {
+		if _, err := s.categories.ResolveCategory(ctx, *req.CategoryID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	expiresAt := now.Add(DefaultHoldTTL)
+	if req.ExpiresAt != nil {
+		expiresAt = req.ExpiresAt.UTC()
+	}
+	if !expiresAt.After(now) {
+		return nil, ErrInvalidExpiry
+	}
+
+	authorized := -req.Amount
+	movement := &Movement{
+		ID:               uuid.New(),
+		AccountID:        account.ID,
+		Kind:             KindManual,
+		Status:           StatusPending,
+		Amount:           authorized,
+		AuthorizedAmount: &authorized,
+		Description:      req.Description,
+		OccurredAt:       now,
+		ExpiresAt:        &expiresAt,
+		CreatedBy:        &userID,
+		CategoryID:       req.CategoryID,
+		CreatedAt:        now,
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.accounts.AdjustHeld(ctx, account.ID, req.Amount); err != nil {
+			return err
+		}
+		return s.repo.CreateMovement(ctx, movement)
+	})
+	if err != nil {
+		if !errors.Is(err, accounts.ErrInsufficientFunds) && !errors.Is(err, accounts.ErrNegativeBalanceNotAllowed) &&
+			!errors.Is(err, accounts.ErrAccountArchived) {
+			log.Error().Err(err).Str("accountID", accountIDStr).Msg("Failed to place hold")
+		}
+		return nil, err
+	}
+
+	return toMovementResponse(movement), nil
+}
+
+// CaptureHold libera a retenção e lança o valor final na mesma transação, com
+// occurred_at no momento da captura: é quando o saldo contábil muda
+func (s *service) CaptureHold(ctx context.Context, accountIDStr string, movementIDStr string, userIDStr string, req CaptureHoldRequest) (*MovementResponse, error) {
+	movement, err := s.getHold(ctx, accountIDStr, movementIDStr, userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	if movement.ExpiresAt != nil && !movement.ExpiresAt.After(now) {
+		return nil, ErrHoldExpired
+	}
+
+	held := -movement.Amount
+	if req.Amount != nil {
+		movement.Amount = -*req.Amount
+	}
+	if req.Description != nil {
+		movement.Description = *req.Description
+	}
+	movement.Status = StatusPosted
+	movement.OccurredAt = now
+
+	var balanceAfter int64
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		ok, err := s.repo.SettleMovement(ctx, movement)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrMovementNotPending
+		}
+		if _, err := s.accounts.AdjustHeld(ctx, movement.AccountID, -held); err != nil {
+			return err
+		}
+		updated, err := s.accounts.AdjustBalance(ctx, movement.AccountID, movement.Amount, movement.OccurredAt)
+		if err != nil {
+			return err
+		}
+		balanceAfter = updated.Balance
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrMovementNotPending) && !errors.Is(err, accounts.ErrInsufficientFunds) &&
+			!errors.Is(err, accounts.ErrNegativeBalanceNotAllowed) && !errors.Is(err, accounts.ErrAccountArchived) {
+			log.Error().Err(err).Str("movementID", movementIDStr).Msg("Failed to capture hold")
+		}
+		return nil, err
+	}
+
+	resp := toMovementResponse(movement)
+	resp.BalanceAfter = &balanceAfter
+	return resp, nil
+}
+
+func (s *service) VoidHold(ctx context.Context, accountIDStr string, movementIDStr string, userIDStr string) (*MovementResponse, error) {
+	movement, err := s.getHold(ctx, accountIDStr, movementIDStr, userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.void(ctx, movement); err != nil {
+		if !errors.Is(err, ErrMovementNotPending) {
+			log.Error().Err(err).Str("movementID", movementIDStr).Msg("Failed to void hold")
+		}
+		return nil, err
+	}
+	return toMovementResponse(movement), nil
+}
+
+// ExpireHolds pode rodar em várias réplicas: cada retenção só é anulada por
+// quem mudar o status dela primeiro
+func (s *service) ExpireHolds(ctx context.Context, now time.Time) (int, error) {
+	holds, err := s.repo.ListExpiredHolds(ctx, now, expiryBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for i := range holds {
+		if err := s.void(ctx, &holds[i]); err != nil {
+			if errors.Is(err, ErrMovementNotPending) {
+				continue
+			}
+			return expired, err
+		}
+		expired++
+	}
+	return expired, nil
+}
+
+// void anula a retenção e devolve o valor retido ao saldo disponível
+func (s *service) void(ctx context.Context, movement *Movement) error {
+	held := -movement.Amount
+	movement.Status = StatusVoided
+
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		ok, err := s.repo.SettleMovement(ctx, movement)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrMovementNotPending
+		}
+		_, err = s.accounts.AdjustHeld(ctx, movement.AccountID, -held)
+		return err
+	})
+}
+
+// getHold busca uma retenção pending da conta, exigindo editor
+func (s *service) getHold(ctx context.Context, accountIDStr string, movementIDStr string, userIDStr string) (*Movement, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	movementID, err := uuid.Parse(movementIDStr)
+	if err != nil {
+		return nil, ErrMovementNotFound
+	}
+
+	movement, err := s.getMovement(ctx, account.ID, movementID)
+	if err != nil {
+		return nil, err
+	}
+	if movement.Status != StatusPending {
+		return nil, ErrMovementNotPending
+	}
+	return movement, nil
+}
+
+// ReverseMovement grava o estorno com occurred_at no momento do pedido, sem
+// mexer no original. O original fica travado na transação, então dois estornos
+// simultâneos não passam juntos do valor dele.
+func (s *service) ReverseMovement(ctx context.Context, accountIDStr string, movementIDStr string, userIDStr string, req ReverseMovementRequest) (*MovementResponse, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	movementID, err := uuid.Parse(movementIDStr)
+	if err != nil {
+		return nil, ErrMovementNotFound
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	var reversal *Movement
+	var balanceAfter int64
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		original, err := s.repo.LockMovement(ctx, account.ID, movementID)
+		if err != nil {
+			return err
+		}
+		if original == nil {
+			return ErrMovementNotFound
+		}
+		if !original.Kind.IsReversible() || original.Status != StatusPosted {
+			return ErrMovementNotReversible
+		}
+
+		movements := []Movement{*original}
+		if err := s.attachSplits(ctx, movements); err != nil {
+			return err
+		}
+		if err := s.attachReversals(ctx, movements); err != nil {
+			return err
+		}
+		original = &movements[0]
+
+		reversal, err = buildReversal(original, req, userID, now)
+		if err != nil {
+			return err
+		}
+
+		updated, err := s.accounts.AdjustBalance(ctx, account.ID, reversal.Amount, reversal.OccurredAt)
+		if err != nil {
+			return err
+		}
+		balanceAfter = updated.Balance
+
+		if err := s.repo.CreateMovement(ctx, reversal); err != nil {
+			return err
+		}
+		if len(reversal.Splits) > 0 {
+			return s.repo.ReplaceSplits(ctx, reversal.ID, reversal.Splits)
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, ErrMovementNotFound) && !errors.Is(err, ErrMovementNotReversible) &&
+			!errors.Is(err, ErrAlreadyReversed) && !errors.Is(err, ErrReversalTooLarge) &&
+			!errors.Is(err, accounts.ErrInsufficientFunds) && !errors.Is(err, accounts.ErrNegativeBalanceNotAllowed) &&
+			!errors.Is(err, accounts.ErrAccountArchived) {
+			log.Error().Err(err).Str("movementID", movementIDStr).Msg("Failed to reverse movement")
+		}
+		return nil, err
+	}
+
+	resp := toMovementResponse(reversal)
+	resp.BalanceAfter = &balanceAfter
+	return resp, nil
+}
+
+// buildReversal monta o estorno de original. Um estorno de um lançamento
+// dividido também é dividido, para os relatórios por categoria se anularem: o
+// total repete as linhas com o sinal trocado; um parcial rateia o valor entre
+// elas na proporção de cada uma. Sem divisão, herda a categoria do original.
+func buildReversal(original *Movement, req ReverseMovementRequest, userID uuid.UUID, now time.Time) (*Movement, error) {
+	total := original.Amount
+	if total < 0 {
+		total = -total
+	}
+	remaining := total - original.ReversedAmount()
+	if remaining <= 0 {
+		return nil, ErrAlreadyReversed
+	}
+
+	amount := remaining
+	if req.Amount != nil {
+		if *req.Amount > remaining {
+			return nil, ErrReversalTooLarge
+		}
+		amount = *req.Amount
+	}
+	if original.Amount > 0 {
+		amount = -amount
+	}
+
+	description := original.Description
+	if req.Description != nil {
+		description = *req.Description
+	}
+
+	reversal := &Movement{
+		ID:          uuid.New(),
+		AccountID:   original.AccountID,
+		Kind:        KindReversal,
+		Status:      StatusPosted,
+		Amount:      amount,
+		Description: description,
+		OccurredAt:  now,
+		CreatedBy:   &userID,
+		CategoryID:  original.CategoryID,
+		ReversalOf:  &original.ID,
+		CreatedAt:   now,
+	}
+	if len(original.Splits) == 0 {
+		return reversal, nil
+	}
+
+	parts := make([]int64, len(original.Splits))
+	if amount == -original.Amount {
+		for i, sp := range original.Splits {
+			parts[i] = -sp.Amount
+		}
+	} else {
+		weights := make([]int, len(original.Splits))
+		for i, sp := range original.Splits {
+			weights[i] = int(sp.Amount)
+			if weights[i] < 0 {
+				weights[i] = -weights[i]
+			}
+		}
+		allocated, err := money.Money{Amount: amount}.Allocate(weights...)
+		if err != nil {
+			return nil, err
+		}
+		for i, part := range allocated {
+			parts[i] = part.Amount
+		}
+	}
+
+	for i, sp := range original.Splits {
+		// Um estorno pequeno pode não chegar a todas as linhas
+		if parts[i] == 0 {
+			continue
+		}
+		reversal.Splits = append(reversal.Splits, Split{
+			ID:         uuid.New(),
+			MovementID: reversal.ID,
+			CategoryID: sp.CategoryID,
+			Amount:     parts[i],
+			Memo:       sp.Memo,
+			Position:   len(reversal.Splits),
+		})
+	}
+	// Uma linha só não é divisão: a categoria dela vai para o estorno
+	if len(reversal.Splits) == 1 {
+		reversal.CategoryID = reversal.Splits[0].CategoryID
+		reversal.Splits = nil
+	}
+	return reversal, nil
+}
+
+// buildSplits valida as linhas contra o valor do lançamento: pelo menos duas,
+// nenhuma zerada ou com sinal trocado, somando exatamente amount
+func (s *service) buildSplits(ctx context.Context, userID uuid.UUID, movementID uuid.UUID, amount int64, lines []SplitRequest) ([]Split, error) {
+	if len(lines) < 2 {
+		return nil, ErrSplitLines
+	}
+
+	total := money.New(0, "")
+	resolved := make(map[uuid.UUID]bool)
+	splits := make([]Split, 0, len(lines))
+	for i, line := range lines {
+		if line.Amount == 0 || (line.Amount < 0) != (amount < 0) {
+			return nil, ErrSplitLines
+		}
+		var err error
+		if total, err = total.Add(money.New(line.Amount, "")); err != nil {
+			return nil, ErrSplitSum
+		}
+		if line.CategoryID != nil && !resolved[*line.CategoryID] {
+			if _, err := s.categories.ResolveCategory(ctx, *line.CategoryID, userID); err != nil {
+				return nil, err
+			}
+			resolved[*line.CategoryID] = true
+		}
+
+		splits = append(splits, Split{
+			ID:         uuid.New(),
+			MovementID: movementID,
+			CategoryID: line.CategoryID,
+			Amount:     line.Amount,
+			Memo:       line.Memo,
+			Position:   i,
+		})
+	}
+	if total.Amount != amount {
+		return nil, fmt.Errorf("%w: lines add up to %d, movement is %d", ErrSplitSum, total.Amount, amount)
+	}
+	return splits, nil
+}
+
+// getMovement busca o lançamento da conta com as linhas de divisão
+func (s *service) getMovement(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Movement, error) {
+	movement, err := s.repo.GetMovement(ctx, accountID, movementID)
+	if err != nil {
+		log.Error().Err(err).Str("movementID", movementID.String()).Msg("Failed to get movement from repository")
+		return nil, err
+	}
+	if movement == nil {
+		return nil, ErrMovementNotFound
+	}
+
+	movements := []Movement{*movement}
+	if err := s.attachSplits(ctx, movements); err != nil {
+		return nil, err
+	}
+	if err := s.attachReversals(ctx, movements); err != nil {
+		return nil, err
+	}
+	return &movements[0], nil
+}
+
+// attachSplits preenche Splits dos lançamentos divididos com uma única consulta
+func (s *service) attachSplits(ctx context.Context, movements []Movement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(movements))
+	index := make(map[uuid.UUID]int, len(movements))
+	for i, m := range movements {
+		ids[i] = m.ID
+		index[m.ID] = i
+	}
+
+	splits, err := s.repo.ListSplits(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, sp := range splits {
+		i := index[sp.MovementID]
+		movements[i].Splits = append(movements[i].Splits, sp)
+	}
+	return nil
+}
+
+// attachReversals preenche ReversedBy dos lançamentos estornados com uma única consulta
+func (s *service) attachReversals(ctx context.Context, movements []Movement) error {
+	if len(movements) == 0 {
+		return nil
+	}
+	ids := make([]uuid.UUID, len(movements))
+	index := make(map[uuid.UUID]int, len(movements))
+	for i, m := range movements {
+		ids[i] = m.ID
+		index[m.ID] = i
+	}
+
+	reversals, err := s.repo.ListReversals(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, r := range reversals {
+		i := index[*r.ReversalOf]
+		movements[i].ReversedBy = append(movements[i].ReversedBy, Reversal{ID: r.ID, Amount: r.Amount, OccurredAt: r.OccurredAt})
+	}
+	return nil
+}
+
+// CreateTransfer debita a origem e credita o destino na mesma transação. Entre
+// moedas diferentes, consome a cotação travada do usuário: ela precisa ser para o
+// mesmo par e valor, e não pode ser reaproveitada mesmo que a transferência falhe.
+func (s *service) CreateTransfer(ctx context.Context, userIDStr string, req CreateTransferRequest) (*TransferResponse, error) {
+	if req.SourceAccountID == req.TargetAccountID {
+		return nil, ErrSameAccount
+	}
+	source, err := s.accounts.AuthorizeAccount(ctx, req.SourceAccountID.String(), userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	target, err := s.accounts.AuthorizeAccount(ctx, req.TargetAccountID.String(), userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := req.Amount
+	if amount.Currency == "" {
+		amount = money.New(amount.Amount, source.Currency)
+	}
+	if amount.Currency != source.Currency {
+		return nil, fmt.Errorf("%w: amount in %s for a %s account", money.ErrCurrencyMismatch, amount.Currency, source.Currency)
+	}
+	if amount.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be positive", money.ErrInvalidAmount)
+	}
+
+	now := time.Now().UTC()
+	transfer := &Transfer{
+		ID:              uuid.New(),
+		SourceAccountID: source.ID,
+		TargetAccountID: target.ID,
+		SourceAmount:    amount.Amount,
+		SourceCurrency:  source.Currency,
+		TargetAmount:    amount.Amount,
+		TargetCurrency:  target.Currency,
+		Rate:            1,
+		MidRate:         1,
+		Description:     req.Description,
+		OccurredAt:      now,
+		CreatedBy:       &userID,
+		CreatedAt:       now,
+	}
+
+	switch {
+	case source.Currency == target.Currency && req.QuoteID != nil:
+		return nil, fmt.Errorf("%w: accounts share the same currency", fx.ErrQuoteMismatch)
+	case source.Currency != target.Currency:
+		if req.QuoteID == nil {
+			return nil, ErrQuoteRequired
+		}
+		quote, err := s.fx.ConsumeQuote(ctx, *req.QuoteID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if quote.Amount != amount || quote.Result.Currency != target.Currency {
+			return nil, fmt.Errorf("%w: quote is for %s to %s", fx.ErrQuoteMismatch, quote.Amount, quote.Result.Currency)
+		}
+		rateDate, err := time.Parse(time.DateOnly, quote.RateDate)
+		if err != nil {
+			return nil, err
+		}
+
+		transfer.TargetAmount = quote.Result.Amount
+		transfer.Rate = quote.Rate
+		transfer.MidRate = quote.MidRate
+		transfer.Spread = quote.Spread
+		transfer.RateDate = &rateDate
+		transfer.QuoteID = &quote.ID
+	}
+	// Valores muito pequenos podem virar zero na conversão
+	if transfer.TargetAmount <= 0 {
+		return nil, fmt.Errorf("%w: converted amount is zero", money.ErrInvalidAmount)
+	}
+
+	legs := []*Movement{
+		{AccountID: source.ID, Amount: -transfer.SourceAmount},
+		{AccountID: target.ID, Amount: transfer.TargetAmount},
+	}
+	// Ajusta os saldos sempre na mesma ordem de conta para que transferências
+	// simultâneas em sentidos opostos não travem uma à outra
+	if target.ID.String() < source.ID.String() {
+		legs[0], legs[1] = legs[1], legs[0]
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateTransfer(ctx, transfer); err != nil {
+			return err
+		}
+		for _, leg := range legs {
+			if _, err := s.accounts.AdjustBalance(ctx, leg.AccountID, leg.Amount, transfer.OccurredAt); err != nil {
+				return err
+			}
+
+			leg.ID = uuid.New()
+			leg.Kind = KindTransfer
+			leg.Status = StatusPosted
+			leg.Description = transfer.Description
+			leg.OccurredAt = transfer.OccurredAt
+			leg.CreatedBy = transfer.CreatedBy
+			leg.TransferID = &transfer.ID
+			leg.CreatedAt = transfer.CreatedAt
+			if err := s.repo.CreateMovement(ctx, leg); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, accounts.ErrInsufficientFunds) && !errors.Is(err, accounts.ErrNegativeBalanceNotAllowed) &&
+			!errors.Is(err, accounts.ErrAccountArchived) {
+			log.Error().Err(err).Str("transferID", transfer.ID.String()).Msg("Failed to record transfer")
+		}
+		return nil, err
+	}
+
+	return toTransferResponse(transfer), nil
+}
+
+// GetTransfer exige acesso de leitura a pelo menos uma das contas
+func (s *service) GetTransfer(ctx context.Context, transferIDStr string, userIDStr string) (*TransferResponse, error) {
+	transferID, err := uuid.Parse(transferIDStr)
+	if err != nil {
+		return nil, ErrTransferNotFound
+	}
+	transfer, err := s.repo.GetTransfer(ctx, transferID)
+	if err != nil {
+		log.Error().Err(err).Str("transferID", transferIDStr).Msg("Failed to get transfer from repository")
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, ErrTransferNotFound
+	}
+
+	for _, accountID := range []uuid.UUID{transfer.SourceAccountID, transfer.TargetAccountID} {
+		_, err := s.accounts.AuthorizeAccount(ctx, accountID.String(), userIDStr, accounts.RoleViewer)
+		if err == nil {
+			return toTransferResponse(transfer), nil
+		}
+		if !errors.Is(err, accounts.ErrForbidden) && !errors.Is(err, accounts.ErrAccountNotFound) {
+			return nil, err
+		}
+	}
+	// Quem não vê nenhuma das contas não fica sabendo que a transferência existe
+	return nil, ErrTransferNotFound
+}
+
+func toTransferResponse(t *Transfer) *TransferResponse {
+	resp := &TransferResponse{
+		ID:              t.ID,
+		SourceAccountID: t.SourceAccountID,
+		TargetAccountID: t.TargetAccountID,
+		SourceAmount:    money.New(t.SourceAmount, t.SourceCurrency),
+		TargetAmount:    money.New(t.TargetAmount, t.TargetCurrency),
+		Rate:            t.Rate,
+		MidRate:         t.MidRate,
+		Spread:          t.Spread,
+		QuoteID:         t.QuoteID,
+		Description:     t.Description,
+		OccurredAt:      t.OccurredAt,
+		CreatedBy:       t.CreatedBy,
+		CreatedAt:       t.CreatedAt,
+	}
+	if t.RateDate != nil {
+		date := t.RateDate.Format(time.DateOnly)
+		resp.RateDate = &date
+	}
+	return resp
+}
+
+func toMovementResponse(m *Movement) *MovementResponse {
+	return &MovementResponse{
+		ID:               m.ID,
+		AccountID:        m.AccountID,
+		Kind:             m.Kind,
+		Status:           m.Status,
+		Amount:           m.Amount,
+		AuthorizedAmount: m.AuthorizedAmount,
+		Description:      m.Description,
+		OccurredAt:       m.OccurredAt,
+		ExpiresAt:        m.ExpiresAt,
+		CreatedBy:        m.CreatedBy,
+		TransferID:       m.TransferID,
+		CategoryID:       m.CategoryID,
+		Splits:           m.Splits,
+		ReversalOf:       m.ReversalOf,
+		ReversedBy:       m.ReversedBy,
+		ReversedAmount:   m.ReversedAmount(),
+		ReconciledAt:     m.ReconciledAt,
+		CreatedAt:        m.CreatedAt,
+	}
+}
@@ -0,0 +1,901 @@
+package movements
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/categories"
+	"github.com/martinsdevv/fincore/internal/fx"
+	"github.com/martinsdevv/fincore/pkg/money"
+	"github.com/martinsdevv/fincore/pkg/pagination"
+)
+
+// MockRepository é a simulação da nossa interface Repository
+type MockRepository struct {
+	CreateMovementFunc func(ctx context.Context, movement *Movement) error
+	ListMovementsFunc  func(ctx context.Context, accountID uuid.UUID, filter ListMovementsFilter, page pagination.Params) ([]Movement, error)
+	CreateTransferFunc func(ctx context.Context, transfer *Transfer) error
+	GetTransferFunc    func(ctx context.Context, id uuid.UUID) (*Transfer, error)
+
+	GetMovementFunc         func(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Movement, error)
+	SetMovementCategoryFunc func(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID, categoryID *uuid.UUID) (*Movement, error)
+	ReplaceSplitsFunc       func(ctx context.Context, movementID uuid.UUID, splits []Split) error
+	ListSplitsFunc          func(ctx context.Context, movementIDs []uuid.UUID) ([]Split, error)
+
+	LockMovementFunc  func(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Movement, error)
+	ListReversalsFunc func(ctx context.Context, movementIDs []uuid.UUID) ([]Movement, error)
+
+	SettleMovementFunc   func(ctx context.Context, movement *Movement) (bool, error)
+	ListExpiredHoldsFunc func(ctx context.Context, now time.Time, limit int) ([]Movement, error)
+}
+
+func (m *MockRepository) CreateMovement(ctx context.Context, movement *Movement) error {
+	if m.CreateMovementFunc != nil {
+		return m.CreateMovementFunc(ctx, movement)
+	}
+	return nil
+}
+
+func (m *MockRepository) CreateTransfer(ctx context.Context, transfer *Transfer) error {
+	if m.CreateTransferFunc != nil {
+		return m.CreateTransferFunc(ctx, transfer)
+	}
+	return nil
+}
+
+func (m *MockRepository) GetTransfer(ctx context.Context, id uuid.UUID) (*Transfer, error) {
+	if m.GetTransferFunc != nil {
+		return m.GetTransferFunc(ctx, id)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) ListMovements(ctx context.Context, accountID uuid.UUID, filter ListMovementsFilter, page pagination.Params) ([]Movement, error) {
+	if m.ListMovementsFunc != nil {
+		return m.ListMovementsFunc(ctx, accountID, filter, page)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) GetMovement(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Movement, error) {
+	return m.GetMovementFunc(ctx, accountID, movementID)
+}
+
+func (m *MockRepository) ReplaceSplits(ctx context.Context, movementID uuid.UUID, splits []Split) error {
+	if m.ReplaceSplitsFunc != nil {
+		return m.ReplaceSplitsFunc(ctx, movementID, splits)
+	}
+	return nil
+}
+
+func (m *MockRepository) ListSplits(ctx context.Context, movementIDs []uuid.UUID) ([]Split, error) {
+	if m.ListSplitsFunc != nil {
+		return m.ListSplitsFunc(ctx, movementIDs)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) SetMovementCategory(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID, categoryID *uuid.UUID) (*Movement, error) {
+	return m.SetMovementCategoryFunc(ctx, accountID, movementID, categoryID)
+}
+
+func (m *MockRepository) LockMovement(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Movement, error) {
+	return m.LockMovementFunc(ctx, accountID, movementID)
+}
+
+func (m *MockRepository) ListReversals(ctx context.Context, movementIDs []uuid.UUID) ([]Movement, error) {
+	if m.ListReversalsFunc != nil {
+		return m.ListReversalsFunc(ctx, movementIDs)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) SettleMovement(ctx context.Context, movement *Movement) (bool, error) {
+	return m.SettleMovementFunc(ctx, movement)
+}
+
+func (m *MockRepository) ListExpiredHolds(ctx context.Context, now time.Time, limit int) ([]Movement, error) {
+	if m.ListExpiredHoldsFunc != nil {
+		return m.ListExpiredHoldsFunc(ctx, now, limit)
+	}
+	return nil, nil
+}
+
+// MockAccounts implementa só o que o módulo usa de accounts.Service; o resto
+// vem da interface embutida e entra em pânico se for chamado
+type MockAccounts struct {
+	accounts.Service
+	AuthorizeAccountFunc func(ctx context.Context, accountID string, userID string, role accounts.MemberRole) (*accounts.Account, error)
+	AdjustBalanceFunc    func(ctx context.Context, accountID uuid.UUID, delta int64, occurredAt time.Time) (*accounts.Account, error)
+	AdjustHeldFunc       func(ctx context.Context, accountID uuid.UUID, delta int64) (*accounts.Account, error)
+}
+
+func (m *MockAccounts) AuthorizeAccount(ctx context.Context, accountID string, userID string, role accounts.MemberRole) (*accounts.Account, error) {
+	return m.AuthorizeAccountFunc(ctx, accountID, userID, role)
+}
+
+func (m *MockAccounts) AdjustBalance(ctx context.Context, accountID uuid.UUID, delta int64, occurredAt time.Time) (*accounts.Account, error) {
+	return m.AdjustBalanceFunc(ctx, accountID, delta, occurredAt)
+}
+
+func (m *MockAccounts) AdjustHeld(ctx context.Context, accountID uuid.UUID, delta int64) (*accounts.Account, error) {
+	return m.AdjustHeldFunc(ctx, accountID, delta)
+}
+
+// MockFX implementa só o consumo de cotações de fx.Service
+type MockFX struct {
+	fx.Service
+	ConsumeQuoteFunc func(ctx context.Context, quoteID uuid.UUID, userID uuid.UUID) (*fx.Quote, error)
+}
+
+func (m *MockFX) ConsumeQuote(ctx context.Context, quoteID uuid.UUID, userID uuid.UUID) (*fx.Quote, error) {
+	return m.ConsumeQuoteFunc(ctx, quoteID, userID)
+}
+
+// MockCategories conhece só as categorias de known
+type MockCategories struct {
+	categories.Service
+	known map[uuid.UUID]*categories.Category
+}
+
+func (m *MockCategories) ResolveCategory(ctx context.Context, categoryID uuid.UUID, userID uuid.UUID) (*categories.Category, error) {
+	c, ok := m.known[categoryID]
+	if !ok || !c.VisibleTo(userID) {
+		return nil, categories.ErrCategoryNotFound
+	}
+	return c, nil
+}
+
+// MockTransactor roda fn direto e conta as transações abertas
+type MockTransactor struct {
+	Calls int
+}
+
+func (m *MockTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.Calls++
+	return fn(ctx)
+}
+
+func newTestAccount() *accounts.Account {
+	return &accounts.Account{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Type:      accounts.AccountTypeChecking,
+		Balance:   1000,
+		Currency:  "BRL",
+		CreatedAt: time.Now().Add(-30 * 24 * time.Hour),
+	}
+}
+
+func TestService_CreateMovement(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	t.Run("deve gravar o lançamento e ajustar o saldo na mesma transação", func(t *testing.T) {
+		account := newTestAccount()
+		occurredAt := time.Now().Add(-48 * time.Hour).UTC()
+
+		mockAccounts := &MockAccounts{
+			AuthorizeAccountFunc: func(ctx context.Context, accountID string, uid string, role accounts.MemberRole) (*accounts.Account, error) {
+				if role != accounts.RoleEditor {
+					t.Errorf("esperava exigir editor, exigiu %s", role)
+				}
+				return account, nil
+			},
+			AdjustBalanceFunc: func(ctx context.Context, id uuid.UUID, delta int64, at time.Time) (*accounts.Account, error) {
+				if delta != -250 || !at.Equal(occurredAt) {
+					t.Errorf("ajuste incorreto: %d em %v", delta, at)
+				}
+				updated := *account
+				updated.Balance += delta
+				return &updated, nil
+			},
+		}
+		var created *Movement
+		mockRepo := &MockRepository{
+			CreateMovementFunc: func(ctx context.Context, movement *Movement) error {
+				created = movement
+				return nil
+			},
+		}
+		tx := &MockTransactor{}
+		service := NewService(mockRepo, mockAccounts, nil, nil, tx)
+
+		req := CreateMovementRequest{Amount: -250, Description: "Padaria", OccurredAt: &occurredAt}
+		resp, err := service.CreateMovement(ctx, account.ID.String(), userID.String(), req)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if tx.Calls != 1 {
+			t.Errorf("esperava 1 transação, obteve %d", tx.Calls)
+		}
+		if created == nil || created.Kind != KindManual || *created.CreatedBy != userID {
+			t.Errorf("lançamento gravado incorreto: %+v", created)
+		}
+		if resp.BalanceAfter == nil || *resp.BalanceAfter != 750 {
+			t.Errorf("esperava saldo 750 após o lançamento, obteve %v", resp.BalanceAfter)
+		}
+	})
+
+	t.Run("deve recusar lançamento futuro ou anterior à abertura", func(t *testing.T) {
+		account := newTestAccount()
+		mockAccounts := &MockAccounts{
+			AuthorizeAccountFunc: func(ctx context.Context, accountID string, uid string, role accounts.MemberRole) (*accounts.Account, error) {
+				return account, nil
+			},
+		}
+		service := NewService(&MockRepository{}, mockAccounts, nil, nil, &MockTransactor{})
+
+		future := time.Now().Add(time.Hour)
+		if _, err := service.CreateMovement(ctx, account.ID.String(), userID.String(), CreateMovementRequest{Amount: 10, OccurredAt: &future}); !errors.Is(err, ErrFutureMovement) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrFutureMovement, err)
+		}
+
+		early := account.CreatedAt.Add(-time.Hour)
+		if _, err := service.CreateMovement(ctx, account.ID.String(), userID.String(), CreateMovementRequest{Amount: 10, OccurredAt: &early}); !errors.Is(err, ErrBeforeOpening) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrBeforeOpening, err)
+		}
+	})
+
+	t.Run("não deve gravar o lançamento se o saldo não comportar", func(t *testing.T) {
+		account := newTestAccount()
+		mockAccounts := &MockAccounts{
+			AuthorizeAccountFunc: func(ctx context.Context, accountID string, uid string, role accounts.MemberRole) (*accounts.Account, error) {
+				return account, nil
+			},
+			AdjustBalanceFunc: func(ctx context.Context, id uuid.UUID, delta int64, at time.Time) (*accounts.Account, error) {
+				return nil, accounts.ErrNegativeBalanceNotAllowed
+			},
+		}
+		mockRepo := &MockRepository{
+			CreateMovementFunc: func(ctx context.Context, movement *Movement) error {
+				t.Error("o lançamento não deveria ser gravado")
+				return nil
+			},
+		}
+		service := NewService(mockRepo, mockAccounts, nil, nil, &MockTransactor{})
+
+		_, err := service.CreateMovement(ctx, account.ID.String(), userID.String(), CreateMovementRequest{Amount: -5000})
+		if !errors.Is(err, accounts.ErrNegativeBalanceNotAllowed) {
+			t.Errorf("esperava o erro %v, mas obteve %v", accounts.ErrNegativeBalanceNotAllowed, err)
+		}
+	})
+
+	t.Run("deve aceitar só categorias do sistema ou do próprio usuário", func(t *testing.T) {
+		account := newTestAccount()
+		otherUser := uuid.New()
+		system := &categories.Category{ID: uuid.New(), Kind: categories.KindExpense}
+		foreign := &categories.Category{ID: uuid.New(), UserID: &otherUser, Kind: categories.KindExpense}
+
+		mockAccounts := &MockAccounts{
+			AuthorizeAccountFunc: func(ctx context.Context, accountID string, uid string, role accounts.MemberRole) (*accounts.Account, error) {
+				return account, nil
+			},
+			AdjustBalanceFunc: func(ctx context.Context, id uuid.UUID, delta int64, at time.Time) (*accounts.Account, error) {
+				return account, nil
+			},
+		}
+		var created *Movement
+		mockRepo := &MockRepository{
+			CreateMovementFunc: func(ctx context.Context, movement *Movement) error {
+				created = movement
+				return nil
+			},
+		}
+		mockCategories := &MockCategories{known: map[uuid.UUID]*categories.Category{system.ID: system, foreign.ID: foreign}}
+		service := NewService(mockRepo, mockAccounts, nil, mockCategories, &MockTransactor{})
+
+		_, err := service.CreateMovement(ctx, account.ID.String(), userID.String(), CreateMovementRequest{Amount: -10, CategoryID: &foreign.ID})
+		if !errors.Is(err, categories.ErrCategoryNotFound) {
+			t.Errorf("esperava o erro %v, mas obteve %v", categories.ErrCategoryNotFound, err)
+		}
+
+		resp, err := service.CreateMovement(ctx, account.ID.String(), userID.String(), CreateMovementRequest{Amount: -10, CategoryID: &system.ID})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if created == nil || *created.CategoryID != system.ID || *resp.CategoryID != system.ID {
+			t.Errorf("categoria não gravada: %+v", created)
+		}
+	})
+}
+
+func TestService_SetMovementSplits(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	account := newTestAccount()
+	groceries := &categories.Category{ID: uuid.New(), Kind: categories.KindExpense}
+	household := &categories.Category{ID: uuid.New(), UserID: &userID, Kind: categories.KindExpense}
+
+	mockAccounts := &MockAccounts{
+		AuthorizeAccountFunc: func(ctx context.Context, accountID string, uid string, role accounts.MemberRole) (*accounts.Account, error) {
+			return account, nil
+		},
+	}
+	mockCategories := &MockCategories{known: map[uuid.UUID]*categories.Category{groceries.ID: groceries, household.ID: household}}
+
+	movement := &Movement{ID: uuid.New(), AccountID: account.ID, Kind: KindManual, Status: StatusPosted, Amount: -10000, CategoryID: &groceries.ID}
+	newRepo := func(replaced *[]Split) *MockRepository {
+		return &MockRepository{
+			GetMovementFunc: func(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Movement, error) {
+				copied := *movement
+				return &copied, nil
+			},
+			ReplaceSplitsFunc: func(ctx context.Context, movementID uuid.UUID, splits []Split) error {
+				*replaced = splits
+				return nil
+			},
+		}
+	}
+
+	t.Run("deve dividir o lançamento em linhas que somam o valor", func(t *testing.T) {
+		var replaced []Split
+		tx := &MockTransactor{}
+		service := NewService(newRepo(&replaced), mockAccounts, nil, mockCategories, tx)
+
+		resp, err := service.SetMovementSplits(ctx, account.ID.String(), movement.ID.String(), userID.String(), SetSplitsRequest{Splits: []SplitRequest{
+			{Amount: -7000, CategoryID: &groceries.ID, Memo: "Comida"},
+			{Amount: -3000, CategoryID: &household.ID, Memo: "Limpeza"},
+		}})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if tx.Calls != 1 || len(replaced) != 2 || replaced[1].Position != 1 || replaced[1].MovementID != movement.ID {
+			t.Errorf("linhas gravadas incorretas: %+v", replaced)
+		}
+		if resp.CategoryID != nil || len(resp.Splits) != 2 {
+			t.Errorf("resposta incorreta: %+v", resp)
+		}
+	})
+
+	t.Run("deve recusar linhas que não somam o valor ou com sinal trocado", func(t *testing.T) {
+		var replaced []Split
+		service := NewService(newRepo(&replaced), mockAccounts, nil, mockCategories, &MockTransactor{})
+
+		cases := []struct {
+			lines []SplitRequest
+			err   error
+		}{
+			{[]SplitRequest{{Amount: -7000}, {Amount: -2999}}, ErrSplitSum},
+			{[]SplitRequest{{Amount: -11000}, {Amount: 1000}}, ErrSplitLines},
+			{[]SplitRequest{{Amount: -10000}}, ErrSplitLines},
+		}
+		for _, c := range cases {
+			_, err := service.SetMovementSplits(ctx, account.ID.String(), movement.ID.String(), userID.String(), SetSplitsRequest{Splits: c.lines})
+			if !errors.Is(err, c.err) {
+				t.Errorf("%+v: esperava o erro %v, mas obteve %v", c.lines, c.err, err)
+			}
+		}
+		if replaced != nil {
+			t.Error("nenhuma linha deveria ser gravada")
+		}
+	})
+
+	t.Run("deve travar a categoria e a divisão de lançamento conciliado", func(t *testing.T) {
+		reconciledAt := time.Now()
+		movement.ReconciledAt = &reconciledAt
+		defer func() { movement.ReconciledAt = nil }()
+
+		var replaced []Split
+		repo := newRepo(&replaced)
+		repo.SetMovementCategoryFunc = func(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID, categoryID *uuid.UUID) (*Movement, error) {
+			t.Error("a categoria não deveria ser gravada")
+			return nil, nil
+		}
+		service := NewService(repo, mockAccounts, nil, mockCategories, &MockTransactor{})
+
+		_, err := service.SetMovementSplits(ctx, account.ID.String(), movement.ID.String(), userID.String(), SetSplitsRequest{Splits: []SplitRequest{
+			{Amount: -7000}, {Amount: -3000},
+		}})
+		if !errors.Is(err, ErrMovementReconciled) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrMovementReconciled, err)
+		}
+		_, err = service.SetMovementCategory(ctx, account.ID.String(), movement.ID.String(), userID.String(), SetMovementCategoryRequest{CategoryID: &household.ID})
+		if !errors.Is(err, ErrMovementReconciled) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrMovementReconciled, err)
+		}
+		if replaced != nil {
+			t.Error("nenhuma linha deveria ser gravada")
+		}
+	})
+
+	t.Run("deve recusar a edição se a conciliação fechar entre a leitura e a gravação", func(t *testing.T) {
+		var replaced []Split
+		repo := newRepo(&replaced)
+		repo.SetMovementCategoryFunc = func(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID, categoryID *uuid.UUID) (*Movement, error) {
+			return nil, nil
+		}
+		repo.ReplaceSplitsFunc = func(ctx context.Context, movementID uuid.UUID, splits []Split) error {
+			return ErrMovementReconciled
+		}
+		service := NewService(repo, mockAccounts, nil, mockCategories, &MockTransactor{})
+
+		_, err := service.SetMovementCategory(ctx, account.ID.String(), movement.ID.String(), userID.String(), SetMovementCategoryRequest{CategoryID: &household.ID})
+		if !errors.Is(err, ErrMovementReconciled) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrMovementReconciled, err)
+		}
+		_, err = service.SetMovementSplits(ctx, account.ID.String(), movement.ID.String(), userID.String(), SetSplitsRequest{Splits: []SplitRequest{
+			{Amount: -7000}, {Amount: -3000},
+		}})
+		if !errors.Is(err, ErrMovementReconciled) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrMovementReconciled, err)
+		}
+	})
+}
+
+func TestService_Holds(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	// newHarness simula conta e lançamentos em memória: o repositório só
+	// liquida o que ainda está pending, como o UPDATE condicional
+	newHarness := func(account *accounts.Account) (Service, map[uuid.UUID]*Movement) {
+		stored := map[uuid.UUID]*Movement{}
+		mockRepo := &MockRepository{
+			CreateMovementFunc: func(ctx context.Context, movement *Movement) error {
+				copied := *movement
+				stored[movement.ID] = &copied
+				return nil
+			},
+			GetMovementFunc: func(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Movement, error) {
+				m, ok := stored[movementID]
+				if !ok || m.AccountID != accountID {
+					return nil, nil
+				}
+				copied := *m
+				return &copied, nil
+			},
+			SettleMovementFunc: func(ctx context.Context, movement *Movement) (bool, error) {
+				m, ok := stored[movement.ID]
+				if !ok || m.Status != StatusPending {
+					return false, nil
+				}
+				copied := *movement
+				stored[movement.ID] = &copied
+				return true, nil
+			},
+			ListExpiredHoldsFunc: func(ctx context.Context, now time.Time, limit int) ([]Movement, error) {
+				var expired []Movement
+				for _, m := range stored {
+					if m.Status == StatusPending && !m.ExpiresAt.After(now) {
+						expired = append(expired, *m)
+					}
+				}
+				return expired, nil
+			},
+		}
+		mockAccounts := &MockAccounts{
+			AuthorizeAccountFunc: func(ctx context.Context, accountID string, uid string, role accounts.MemberRole) (*accounts.Account, error) {
+				return account, nil
+			},
+			AdjustHeldFunc: func(ctx context.Context, id uuid.UUID, delta int64) (*accounts.Account, error) {
+				if delta > 0 && account.AvailableBalance() < delta {
+					return nil, accounts.ErrNegativeBalanceNotAllowed
+				}
+				account.Held += delta
+				return account, nil
+			},
+			AdjustBalanceFunc: func(ctx context.Context, id uuid.UUID, delta int64, at time.Time) (*accounts.Account, error) {
+				account.Balance += delta
+				return account, nil
+			},
+		}
+		return NewService(mockRepo, mockAccounts, nil, nil, &MockTransactor{}), stored
+	}
+
+	t.Run("deve reservar o saldo disponível sem mexer no saldo contábil", func(t *testing.T) {
+		account := newTestAccount()
+		service, _ := newHarness(account)
+
+		hold, err := service.CreateHold(ctx, account.ID.String(), userID.String(), CreateHoldRequest{Amount: 400, Description: "Hotel"})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if hold.Status != StatusPending || hold.ExpiresAt == nil {
+			t.Errorf("retenção incorreta: %+v", hold)
+		}
+		if account.Balance != 1000 || account.Held != 400 || account.AvailableBalance() != 600 {
+			t.Errorf("saldos incorretos: contábil %d, retido %d", account.Balance, account.Held)
+		}
+
+		if _, err := service.CreateHold(ctx, account.ID.String(), userID.String(), CreateHoldRequest{Amount: 700}); !errors.Is(err, accounts.ErrNegativeBalanceNotAllowed) {
+			t.Errorf("esperava o erro %v, mas obteve %v", accounts.ErrNegativeBalanceNotAllowed, err)
+		}
+	})
+
+	t.Run("deve capturar por outro valor uma única vez", func(t *testing.T) {
+		account := newTestAccount()
+		service, _ := newHarness(account)
+		hold, _ := service.CreateHold(ctx, account.ID.String(), userID.String(), CreateHoldRequest{Amount: 400})
+
+		final := int64(450)
+		captured, err := service.CaptureHold(ctx, account.ID.String(), hold.ID.String(), userID.String(), CaptureHoldRequest{Amount: &final})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if captured.Status != StatusPosted || captured.Amount != -450 || *captured.BalanceAfter != 550 {
+			t.Errorf("captura incorreta: %+v", captured)
+		}
+		if account.Held != 0 || account.Balance != 550 {
+			t.Errorf("saldos incorretos: contábil %d, retido %d", account.Balance, account.Held)
+		}
+
+		if _, err := service.CaptureHold(ctx, account.ID.String(), hold.ID.String(), userID.String(), CaptureHoldRequest{}); !errors.Is(err, ErrMovementNotPending) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrMovementNotPending, err)
+		}
+	})
+
+	t.Run("deve liberar a retenção ao anular ou expirar", func(t *testing.T) {
+		account := newTestAccount()
+		service, stored := newHarness(account)
+		voided, _ := service.CreateHold(ctx, account.ID.String(), userID.String(), CreateHoldRequest{Amount: 100})
+		stale, _ := service.CreateHold(ctx, account.ID.String(), userID.String(), CreateHoldRequest{Amount: 200})
+
+		if _, err := service.VoidHold(ctx, account.ID.String(), voided.ID.String(), userID.String()); err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if account.Held != 200 {
+			t.Errorf("esperava 200 retidos, obteve %d", account.Held)
+		}
+
+		expired, err := service.ExpireHolds(ctx, time.Now().Add(DefaultHoldTTL+time.Minute))
+		if err != nil || expired != 1 {
+			t.Fatalf("esperava anular 1 retenção, anulou %d (%v)", expired, err)
+		}
+		if account.Held != 0 || account.Balance != 1000 || stored[stale.ID].Status != StatusVoided {
+			t.Errorf("saldos incorretos: contábil %d, retido %d", account.Balance, account.Held)
+		}
+
+		if _, err := service.CaptureHold(ctx, account.ID.String(), stale.ID.String(), userID.String(), CaptureHoldRequest{}); !errors.Is(err, ErrMovementNotPending) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrMovementNotPending, err)
+		}
+	})
+}
+
+func TestService_ReverseMovement(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	groceries := uuid.New()
+	household := uuid.New()
+
+	// newHarness guarda os lançamentos em memória; os estornos gravados voltam
+	// em ListReversals, como no banco
+	newHarness := func(account *accounts.Account, original *Movement, splits []Split) (Service, *[]Movement) {
+		var created []Movement
+		mockRepo := &MockRepository{
+			LockMovementFunc: func(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Movement, error) {
+				if movementID != original.ID {
+					return nil, nil
+				}
+				copied := *original
+				return &copied, nil
+			},
+			ListSplitsFunc: func(ctx context.Context, movementIDs []uuid.UUID) ([]Split, error) {
+				return splits, nil
+			},
+			ListReversalsFunc: func(ctx context.Context, movementIDs []uuid.UUID) ([]Movement, error) {
+				return created, nil
+			},
+			CreateMovementFunc: func(ctx context.Context, movement *Movement) error {
+				created = append(created, *movement)
+				return nil
+			},
+		}
+		mockAccounts := &MockAccounts{
+			AuthorizeAccountFunc: func(ctx context.Context, accountID string, uid string, role accounts.MemberRole) (*accounts.Account, error) {
+				if role != accounts.RoleEditor {
+					t.Errorf("esperava exigir editor, exigiu %s", role)
+				}
+				return account, nil
+			},
+			AdjustBalanceFunc: func(ctx context.Context, id uuid.UUID, delta int64, at time.Time) (*accounts.Account, error) {
+				account.Balance += delta
+				return account, nil
+			},
+		}
+		return NewService(mockRepo, mockAccounts, nil, nil, &MockTransactor{}), &created
+	}
+
+	t.Run("deve estornar em parcelas sem passar do valor original", func(t *testing.T) {
+		account := newTestAccount()
+		original := &Movement{ID: uuid.New(), AccountID: account.ID, Kind: KindManual, Status: StatusPosted, Amount: -300, Description: "Loja", CategoryID: &groceries}
+		service, created := newHarness(account, original, nil)
+
+		partial := int64(100)
+		refund, err := service.ReverseMovement(ctx, account.ID.String(), original.ID.String(), userID.String(), ReverseMovementRequest{Amount: &partial})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if refund.Kind != KindReversal || refund.Amount != 100 || *refund.ReversalOf != original.ID || *refund.CategoryID != groceries {
+			t.Errorf("estorno incorreto: %+v", refund)
+		}
+		if account.Balance != 1100 || *refund.BalanceAfter != 1100 {
+			t.Errorf("esperava saldo 1100, obteve %d", account.Balance)
+		}
+
+		tooMuch := int64(201)
+		if _, err := service.ReverseMovement(ctx, account.ID.String(), original.ID.String(), userID.String(), ReverseMovementRequest{Amount: &tooMuch}); !errors.Is(err, ErrReversalTooLarge) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrReversalTooLarge, err)
+		}
+
+		rest, err := service.ReverseMovement(ctx, account.ID.String(), original.ID.String(), userID.String(), ReverseMovementRequest{})
+		if err != nil || rest.Amount != 200 {
+			t.Fatalf("esperava estornar os 200 restantes, obteve %+v (%v)", rest, err)
+		}
+		if _, err := service.ReverseMovement(ctx, account.ID.String(), original.ID.String(), userID.String(), ReverseMovementRequest{}); !errors.Is(err, ErrAlreadyReversed) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrAlreadyReversed, err)
+		}
+		if len(*created) != 2 || account.Balance != 1300 {
+			t.Errorf("esperava 2 estornos e saldo 1300, obteve %d e %d", len(*created), account.Balance)
+		}
+	})
+
+	t.Run("deve repetir as linhas de um lançamento dividido no estorno total", func(t *testing.T) {
+		account := newTestAccount()
+		original := &Movement{ID: uuid.New(), AccountID: account.ID, Kind: KindManual, Status: StatusPosted, Amount: -100}
+		splits := []Split{
+			{ID: uuid.New(), MovementID: original.ID, CategoryID: &groceries, Amount: -70, Position: 0},
+			{ID: uuid.New(), MovementID: original.ID, CategoryID: &household, Amount: -30, Position: 1},
+		}
+		service, _ := newHarness(account, original, splits)
+
+		reversal, err := service.ReverseMovement(ctx, account.ID.String(), original.ID.String(), userID.String(), ReverseMovementRequest{})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if len(reversal.Splits) != 2 || reversal.Splits[0].Amount != 70 || reversal.Splits[1].MovementID != reversal.ID {
+			t.Errorf("linhas do estorno incorretas: %+v", reversal.Splits)
+		}
+	})
+
+	t.Run("deve ratear o estorno parcial entre as linhas de um lançamento dividido", func(t *testing.T) {
+		account := newTestAccount()
+		original := &Movement{ID: uuid.New(), AccountID: account.ID, Kind: KindManual, Status: StatusPosted, Amount: -100}
+		splits := []Split{
+			{ID: uuid.New(), MovementID: original.ID, CategoryID: &groceries, Amount: -70, Position: 0, Memo: "Feira"},
+			{ID: uuid.New(), MovementID: original.ID, CategoryID: &household, Amount: -30, Position: 1},
+		}
+		service, _ := newHarness(account, original, splits)
+
+		partial := int64(25)
+		refund, err := service.ReverseMovement(ctx, account.ID.String(), original.ID.String(), userID.String(), ReverseMovementRequest{Amount: &partial})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		// 25 * 70/100 = 17,5 e 25 * 30/100 = 7,5: o centavo que sobra vai para a primeira linha
+		if len(refund.Splits) != 2 || refund.Splits[0].Amount != 18 || refund.Splits[1].Amount != 7 ||
+			*refund.Splits[0].CategoryID != groceries || refund.Splits[0].Memo != "Feira" {
+			t.Errorf("linhas do estorno incorretas: %+v", refund.Splits)
+		}
+
+		// O restante também é rateado, não sai sem categoria
+		rest, err := service.ReverseMovement(ctx, account.ID.String(), original.ID.String(), userID.String(), ReverseMovementRequest{})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if rest.Amount != 75 || len(rest.Splits) != 2 || rest.Splits[0].Amount+rest.Splits[1].Amount != 75 {
+			t.Errorf("linhas do estorno restante incorretas: %+v", rest.Splits)
+		}
+
+		// Um centavo cabe numa linha só: vira a categoria do estorno
+		service, _ = newHarness(newTestAccount(), original, splits)
+		cent := int64(1)
+		refund, err = service.ReverseMovement(ctx, account.ID.String(), original.ID.String(), userID.String(), ReverseMovementRequest{Amount: &cent})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if len(refund.Splits) != 0 || refund.CategoryID == nil || *refund.CategoryID != groceries {
+			t.Errorf("estorno de um centavo incorreto: %+v", refund)
+		}
+	})
+
+	t.Run("deve recusar transferências, retenções e estornos", func(t *testing.T) {
+		account := newTestAccount()
+		for _, original := range []*Movement{
+			{ID: uuid.New(), AccountID: account.ID, Kind: KindTransfer, Status: StatusPosted, Amount: -100},
+			{ID: uuid.New(), AccountID: account.ID, Kind: KindManual, Status: StatusPending, Amount: -100},
+			{ID: uuid.New(), AccountID: account.ID, Kind: KindReversal, Status: StatusPosted, Amount: 100},
+		} {
+			service, _ := newHarness(account, original, nil)
+			if _, err := service.ReverseMovement(ctx, account.ID.String(), original.ID.String(), userID.String(), ReverseMovementRequest{}); !errors.Is(err, ErrMovementNotReversible) {
+				t.Errorf("%s/%s: esperava o erro %v, mas obteve %v", original.Kind, original.Status, ErrMovementNotReversible, err)
+			}
+		}
+		if account.Balance != 1000 {
+			t.Errorf("o saldo não deveria mudar, obteve %d", account.Balance)
+		}
+	})
+}
+
+func TestService_CreateTransfer(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	// accountsFor devolve as contas pelo ID e registra os ajustes de saldo
+	accountsFor := func(adjusted map[uuid.UUID]int64, list ...*accounts.Account) *MockAccounts {
+		byID := make(map[string]*accounts.Account)
+		for _, acc := range list {
+			byID[acc.ID.String()] = acc
+		}
+		return &MockAccounts{
+			AuthorizeAccountFunc: func(ctx context.Context, accountID string, uid string, role accounts.MemberRole) (*accounts.Account, error) {
+				if acc, ok := byID[accountID]; ok {
+					return acc, nil
+				}
+				return nil, accounts.ErrAccountNotFound
+			},
+			AdjustBalanceFunc: func(ctx context.Context, id uuid.UUID, delta int64, at time.Time) (*accounts.Account, error) {
+				adjusted[id] += delta
+				return byID[id.String()], nil
+			},
+		}
+	}
+
+	t.Run("deve transferir na mesma moeda sem cotação", func(t *testing.T) {
+		source, target := newTestAccount(), newTestAccount()
+		adjusted := make(map[uuid.UUID]int64)
+		var legs []*Movement
+		var transfer *Transfer
+		service := NewService(&MockRepository{
+			CreateTransferFunc: func(ctx context.Context, tr *Transfer) error {
+				transfer = tr
+				return nil
+			},
+			CreateMovementFunc: func(ctx context.Context, m *Movement) error {
+				legs = append(legs, m)
+				return nil
+			},
+		}, accountsFor(adjusted, source, target), nil, nil, &MockTransactor{})
+
+		resp, err := service.CreateTransfer(ctx, userID.String(), CreateTransferRequest{
+			SourceAccountID: source.ID,
+			TargetAccountID: target.ID,
+			Amount:          money.Money{Amount: 300},
+		})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if adjusted[source.ID] != -300 || adjusted[target.ID] != 300 {
+			t.Errorf("ajustes incorretos: %v", adjusted)
+		}
+		if len(legs) != 2 || legs[0].Kind != KindTransfer || *legs[0].TransferID != transfer.ID || *legs[1].TransferID != transfer.ID {
+			t.Errorf("lançamentos incorretos: %+v", legs)
+		}
+		if resp.Rate != 1 || resp.TargetAmount != money.New(300, "BRL") || resp.QuoteID != nil {
+			t.Errorf("transferência incorreta: %+v", resp)
+		}
+	})
+
+	t.Run("deve converter com a cotação travada", func(t *testing.T) {
+		source, target := newTestAccount(), newTestAccount()
+		source.Currency = "USD"
+		quote := &fx.Quote{
+			ID:       uuid.New(),
+			UserID:   userID,
+			Amount:   money.New(10000, "USD"),
+			Result:   money.New(49500, "BRL"),
+			MidRate:  5,
+			Spread:   0.01,
+			Rate:     4.95,
+			RateDate: "2024-01-05",
+		}
+		adjusted := make(map[uuid.UUID]int64)
+		var transfer *Transfer
+		service := NewService(&MockRepository{
+			CreateTransferFunc: func(ctx context.Context, tr *Transfer) error {
+				transfer = tr
+				return nil
+			},
+		}, accountsFor(adjusted, source, target), &MockFX{
+			ConsumeQuoteFunc: func(ctx context.Context, quoteID uuid.UUID, uid uuid.UUID) (*fx.Quote, error) {
+				if quoteID != quote.ID || uid != userID {
+					t.Errorf("cotação consumida incorreta: %s por %s", quoteID, uid)
+				}
+				return quote, nil
+			},
+		}, nil, &MockTransactor{})
+
+		_, err := service.CreateTransfer(ctx, userID.String(), CreateTransferRequest{
+			SourceAccountID: source.ID,
+			TargetAccountID: target.ID,
+			Amount:          money.New(10000, "USD"),
+			QuoteID:         &quote.ID,
+		})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if adjusted[source.ID] != -10000 || adjusted[target.ID] != 49500 {
+			t.Errorf("ajustes incorretos: %v", adjusted)
+		}
+		if transfer.Rate != 4.95 || transfer.MidRate != 5 || transfer.Spread != 0.01 || *transfer.QuoteID != quote.ID || transfer.TargetCurrency != "BRL" {
+			t.Errorf("conversão não registrada: %+v", transfer)
+		}
+	})
+
+	t.Run("deve exigir cotação compatível entre moedas", func(t *testing.T) {
+		source, target := newTestAccount(), newTestAccount()
+		source.Currency = "USD"
+		quote := &fx.Quote{ID: uuid.New(), UserID: userID, Amount: money.New(500, "USD"), Result: money.New(2500, "BRL")}
+		fxSvc := &MockFX{
+			ConsumeQuoteFunc: func(ctx context.Context, quoteID uuid.UUID, uid uuid.UUID) (*fx.Quote, error) {
+				return quote, nil
+			},
+		}
+		service := NewService(&MockRepository{}, accountsFor(map[uuid.UUID]int64{}, source, target), fxSvc, nil, &MockTransactor{})
+		req := CreateTransferRequest{SourceAccountID: source.ID, TargetAccountID: target.ID, Amount: money.Money{Amount: 10000}}
+
+		if _, err := service.CreateTransfer(ctx, userID.String(), req); !errors.Is(err, ErrQuoteRequired) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrQuoteRequired, err)
+		}
+
+		req.QuoteID = &quote.ID
+		if _, err := service.CreateTransfer(ctx, userID.String(), req); !errors.Is(err, fx.ErrQuoteMismatch) {
+			t.Errorf("esperava o erro %v, mas obteve %v", fx.ErrQuoteMismatch, err)
+		}
+
+		fxSvc.ConsumeQuoteFunc = func(ctx context.Context, quoteID uuid.UUID, uid uuid.UUID) (*fx.Quote, error) {
+			return nil, fx.ErrQuoteNotFound
+		}
+		if _, err := service.CreateTransfer(ctx, userID.String(), req); !errors.Is(err, fx.ErrQuoteNotFound) {
+			t.Errorf("esperava o erro %v, mas obteve %v", fx.ErrQuoteNotFound, err)
+		}
+	})
+
+	t.Run("deve recusar cotação na mesma moeda e valor em outra moeda", func(t *testing.T) {
+		source, target := newTestAccount(), newTestAccount()
+		service := NewService(&MockRepository{}, accountsFor(map[uuid.UUID]int64{}, source, target), nil, nil, &MockTransactor{})
+		quoteID := uuid.New()
+
+		req := CreateTransferRequest{SourceAccountID: source.ID, TargetAccountID: target.ID, Amount: money.Money{Amount: 100}, QuoteID: &quoteID}
+		if _, err := service.CreateTransfer(ctx, userID.String(), req); !errors.Is(err, fx.ErrQuoteMismatch) {
+			t.Errorf("esperava o erro %v, mas obteve %v", fx.ErrQuoteMismatch, err)
+		}
+
+		req = CreateTransferRequest{SourceAccountID: source.ID, TargetAccountID: target.ID, Amount: money.New(100, "USD")}
+		if _, err := service.CreateTransfer(ctx, userID.String(), req); !errors.Is(err, money.ErrCurrencyMismatch) {
+			t.Errorf("esperava o erro %v, mas obteve %v", money.ErrCurrencyMismatch, err)
+		}
+	})
+}
+
+func TestService_GetTransfer(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	transfer := &Transfer{ID: uuid.New(), SourceAccountID: uuid.New(), TargetAccountID: uuid.New(), SourceCurrency: "BRL", TargetCurrency: "BRL"}
+	mockRepo := &MockRepository{
+		GetTransferFunc: func(ctx context.Context, id uuid.UUID) (*Transfer, error) {
+			return transfer, nil
+		},
+	}
+
+	t.Run("deve mostrar a quem vê uma das contas", func(t *testing.T) {
+		service := NewService(mockRepo, &MockAccounts{
+			AuthorizeAccountFunc: func(ctx context.Context, accountID string, uid string, role accounts.MemberRole) (*accounts.Account, error) {
+				if accountID == transfer.TargetAccountID.String() {
+					return &accounts.Account{}, nil
+				}
+				return nil, accounts.ErrForbidden
+			},
+		}, nil, nil, &MockTransactor{})
+
+		if _, err := service.GetTransfer(ctx, transfer.ID.String(), userID.String()); err != nil {
+			t.Errorf("esperava nenhum erro, mas obteve %v", err)
+		}
+	})
+
+	t.Run("deve esconder de quem não vê nenhuma das contas", func(t *testing.T) {
+		service := NewService(mockRepo, &MockAccounts{
+			AuthorizeAccountFunc: func(ctx context.Context, accountID string, uid string, role accounts.MemberRole) (*accounts.Account, error) {
+				return nil, accounts.ErrForbidden
+			},
+		}, nil, nil, &MockTransactor{})
+
+		if _, err := service.GetTransfer(ctx, transfer.ID.String(), userID.String()); !errors.Is(err, ErrTransferNotFound) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrTransferNotFound, err)
+		}
+	})
+}
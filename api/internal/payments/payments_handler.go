@@ -0,0 +1,100 @@
+package payments
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/auth"
+	"github.com/martinsdevv/fincore/pkg/pain"
+	"github.com/rs/zerolog/log"
+)
+
+type Handler struct {
+	service  Service
+	validate *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(validator.WithRequiredStructEnabled()),
+	}
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("Falha ao escrever resposta JSON")
+	}
+}
+
+func (h *Handler) getUserIDFromContext(r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(auth.UserContextKey).(string)
+	if !ok {
+		log.Error().Msg("UserID não encontrado no contexto, middleware mal configurado")
+		return "", false
+	}
+	return userID, true
+}
+
+// writeServiceError traduz os erros do serviço, incluindo os da conta, para respostas HTTP
+func (h *Handler) writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, pain.ErrInvalidBatch), errors.Is(err, ErrPastExecutionDate):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrAccountWithoutIBAN):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, accounts.ErrAccountNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "account not found"})
+	case errors.Is(err, accounts.ErrForbidden):
+		h.writeJSON(w, http.StatusForbidden, map[string]string{"error": "you do not have permission for this account"})
+	case errors.Is(err, accounts.ErrAccountArchived):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": "account is archived"})
+	default:
+		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": fallback})
+	}
+}
+
+// HandleExportPAIN001 devolve o XML como anexo; o resumo do lote vai nos
+// cabeçalhos X-Message-Id, X-Payment-Count e X-Control-Sum
+func (h *Handler) HandleExportPAIN001(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req ExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	export, err := h.service.ExportPAIN001(r.Context(), chi.URLParam(r, "accountID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to export payments")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+export.FileName+`"`)
+	w.Header().Set("X-Message-Id", export.MessageID)
+	w.Header().Set("X-Payment-Count", strconv.Itoa(export.Payments))
+	w.Header().Set("X-Control-Sum", strconv.FormatInt(export.ControlSum, 10))
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(export.Data); err != nil {
+		log.Error().Err(err).Msg("Falha ao escrever o arquivo pain.001")
+	}
+}
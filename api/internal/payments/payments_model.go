@@ -0,0 +1,32 @@
+package payments
+
+// PaymentRequest é uma transferência do lote. O valor está na moeda da conta
+// de origem, em unidades mínimas.
+type PaymentRequest struct {
+	CreditorName string `json:"creditor_name" validate:"required,max=70"`
+	CreditorIBAN string `json:"creditor_iban" validate:"required,max=42"`
+	CreditorBIC  string `json:"creditor_bic" validate:"omitempty,max=11"`
+	Amount       int64  `json:"amount" validate:"required,gt=0"`
+	Reference    string `json:"reference" validate:"max=140"`    // texto livre que o recebedor vê
+	EndToEndID   string `json:"end_to_end_id" validate:"max=35"` // volta no extrato do recebedor
+}
+
+// ExportRequest monta um arquivo pain.001 saindo da conta da URL. Sem
+// execution_date, vale o dia da requisição.
+type ExportRequest struct {
+	DebtorName    string           `json:"debtor_name" validate:"required,max=70"`
+	DebtorBIC     string           `json:"debtor_bic" validate:"omitempty,max=11"`
+	ExecutionDate string           `json:"execution_date" validate:"omitempty,datetime=2006-01-02"`
+	Payments      []PaymentRequest `json:"payments" validate:"required,min=1,max=1000,dive"`
+}
+
+// Export é o arquivo gerado. Nada é lançado no razão: os lançamentos entram
+// quando o extrato do banco for importado.
+type Export struct {
+	MessageID  string
+	FileName   string
+	Data       []byte
+	Payments   int
+	ControlSum int64 // soma dos pagamentos, em unidades mínimas
+	Currency   string
+}
@@ -0,0 +1,7 @@
+package payments
+
+import "github.com/go-chi/chi/v5"
+
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Post("/accounts/{accountID}/payments/pain001", h.HandleExportPAIN001)
+}
@@ -0,0 +1,122 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/pkg/pain"
+)
+
+// MockAccounts implementa só a autorização de accounts.Service
+type MockAccounts struct {
+	accounts.Service
+	account *accounts.Account
+	role    accounts.MemberRole
+}
+
+func (m *MockAccounts) AuthorizeAccount(ctx context.Context, accountID string, userID string, role accounts.MemberRole) (*accounts.Account, error) {
+	m.role = role
+	return m.account, nil
+}
+
+func TestService_ExportPAIN001(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	newAccount := func() *accounts.Account {
+		accountIBAN := "DE89370400440532013000"
+		return &accounts.Account{ID: uuid.New(), UserID: userID, Currency: "EUR", IBAN: &accountIBAN}
+	}
+	newRequest := func() ExportRequest {
+		return ExportRequest{
+			DebtorName: "Martins Comércio Ltda",
+			Payments: []PaymentRequest{
+				{CreditorName: "Papelaria Müller GmbH", CreditorIBAN: "DE02 1203 0000 0000 2020 51", Amount: 25050, Reference: "Rechnung 2024-001", EndToEndID: "INV-2024-001"},
+				{CreditorName: "Office & Co", CreditorIBAN: "GB82WEST12345698765432", CreditorBIC: "nwbkgb2l", Amount: 1999},
+			},
+		}
+	}
+
+	t.Run("deve gerar o lote a partir do IBAN da conta", func(t *testing.T) {
+		mockAccounts := &MockAccounts{account: newAccount()}
+		service := NewService(mockAccounts)
+
+		export, err := service.ExportPAIN001(ctx, mockAccounts.account.ID.String(), userID.String(), newRequest())
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if mockAccounts.role != accounts.RoleEditor {
+			t.Errorf("esperava exigir o papel editor, mas exigiu %s", mockAccounts.role)
+		}
+		if export.Payments != 2 || export.ControlSum != 27049 || export.Currency != "EUR" || len(export.MessageID) != 32 {
+			t.Errorf("resumo incorreto: %+v", export)
+		}
+		if !strings.HasSuffix(export.FileName, ".xml") {
+			t.Errorf("nome de arquivo inesperado: %s", export.FileName)
+		}
+		out := string(export.Data)
+		for _, want := range []string{
+			`<IBAN>DE89370400440532013000</IBAN>`,
+			`<IBAN>DE02120300000000202051</IBAN>`,
+			`<BIC>NWBKGB2L</BIC>`,
+			`<CtrlSum>270.49</CtrlSum>`,
+			`<ReqdExctnDt>` + time.Now().UTC().Format(time.DateOnly) + `</ReqdExctnDt>`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("esperava %s no XML:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("deve recusar conta sem IBAN", func(t *testing.T) {
+		account := newAccount()
+		account.IBAN = nil
+		service := NewService(&MockAccounts{account: account})
+
+		_, err := service.ExportPAIN001(ctx, account.ID.String(), userID.String(), newRequest())
+		if !errors.Is(err, ErrAccountWithoutIBAN) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrAccountWithoutIBAN, err)
+		}
+	})
+
+	t.Run("deve recusar conta arquivada", func(t *testing.T) {
+		account := newAccount()
+		archivedAt := time.Now()
+		account.ArchivedAt = &archivedAt
+		service := NewService(&MockAccounts{account: account})
+
+		_, err := service.ExportPAIN001(ctx, account.ID.String(), userID.String(), newRequest())
+		if !errors.Is(err, accounts.ErrAccountArchived) {
+			t.Errorf("esperava o erro %v, mas obteve %v", accounts.ErrAccountArchived, err)
+		}
+	})
+
+	t.Run("deve recusar data de execução no passado", func(t *testing.T) {
+		account := newAccount()
+		service := NewService(&MockAccounts{account: account})
+		req := newRequest()
+		req.ExecutionDate = time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+
+		_, err := service.ExportPAIN001(ctx, account.ID.String(), userID.String(), req)
+		if !errors.Is(err, ErrPastExecutionDate) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrPastExecutionDate, err)
+		}
+	})
+
+	t.Run("deve recusar IBAN de recebedor inválido", func(t *testing.T) {
+		account := newAccount()
+		service := NewService(&MockAccounts{account: account})
+		req := newRequest()
+		req.Payments[1].CreditorIBAN = "GB82WEST12345698765433"
+
+		_, err := service.ExportPAIN001(ctx, account.ID.String(), userID.String(), req)
+		if !errors.Is(err, pain.ErrInvalidBatch) {
+			t.Errorf("esperava o erro %v, mas obteve %v", pain.ErrInvalidBatch, err)
+		}
+	})
+}
@@ -0,0 +1,233 @@
+package reconciliations
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/auth"
+	"github.com/martinsdevv/fincore/internal/movements"
+	"github.com/rs/zerolog/log"
+)
+
+type Handler struct {
+	service  Service
+	validate *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(validator.WithRequiredStructEnabled()),
+	}
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("Falha ao escrever resposta JSON")
+	}
+}
+
+func (h *Handler) getUserIDFromContext(r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(auth.UserContextKey).(string)
+	if !ok {
+		log.Error().Msg("UserID não encontrado no contexto, middleware mal configurado")
+		return "", false
+	}
+	return userID, true
+}
+
+// writeServiceError traduz os erros do serviço, incluindo os da conta, para respostas HTTP
+func (h *Handler) writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrReconciliationNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "reconciliation not found"})
+	case errors.Is(err, ErrLineNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "statement line not found"})
+	case errors.Is(err, movements.ErrMovementNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "movement not found"})
+	case errors.Is(err, ErrOpenReconciliation), errors.Is(err, ErrReconciliationFinalized),
+		errors.Is(err, ErrLineMatched), errors.Is(err, ErrLineNotMatched), errors.Is(err, ErrMovementMatched):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrInvalidPeriod), errors.Is(err, ErrPeriodOverlap), errors.Is(err, ErrLineOutsidePeriod),
+		errors.Is(err, ErrOpeningBalanceMismatch), errors.Is(err, ErrMovementNotPosted), errors.Is(err, ErrSignMismatch),
+		errors.Is(err, ErrUnbalanced):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, accounts.ErrAccountNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "account not found"})
+	case errors.Is(err, accounts.ErrForbidden):
+		h.writeJSON(w, http.StatusForbidden, map[string]string{"error": "you do not have permission for this account"})
+	case errors.Is(err, accounts.ErrAccountArchived):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": "account is archived"})
+	default:
+		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": fallback})
+	}
+}
+
+func (h *Handler) HandleStartReconciliation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req StartReconciliationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	reconciliation, err := h.service.StartReconciliation(r.Context(), chi.URLParam(r, "accountID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to start reconciliation")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, reconciliation)
+}
+
+func (h *Handler) HandleListReconciliations(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	reconciliations, err := h.service.ListReconciliations(r.Context(), chi.URLParam(r, "accountID"), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve reconciliations")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, reconciliations)
+}
+
+func (h *Handler) HandleGetReconciliation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	reconciliation, err := h.service.GetReconciliation(r.Context(), chi.URLParam(r, "accountID"), chi.URLParam(r, "reconciliationID"), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve reconciliation")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, reconciliation)
+}
+
+func (h *Handler) HandleDeleteReconciliation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	if err := h.service.DeleteReconciliation(r.Context(), chi.URLParam(r, "accountID"), chi.URLParam(r, "reconciliationID"), userID); err != nil {
+		h.writeServiceError(w, err, "failed to delete reconciliation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleAutoMatch aceita um corpo vazio: valem as tolerâncias da conciliação
+func (h *Handler) HandleAutoMatch(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req AutoMatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	reconciliation, err := h.service.AutoMatch(r.Context(), chi.URLParam(r, "accountID"), chi.URLParam(r, "reconciliationID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to match statement lines")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, reconciliation)
+}
+
+func (h *Handler) HandleMatchLine(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req MatchLineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	reconciliation, err := h.service.MatchLine(r.Context(), chi.URLParam(r, "accountID"), chi.URLParam(r, "reconciliationID"), chi.URLParam(r, "lineID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to match statement line")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, reconciliation)
+}
+
+func (h *Handler) HandleUnmatchLine(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	reconciliation, err := h.service.UnmatchLine(r.Context(), chi.URLParam(r, "accountID"), chi.URLParam(r, "reconciliationID"), chi.URLParam(r, "lineID"), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to unmatch statement line")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, reconciliation)
+}
+
+func (h *Handler) HandleFinalizeReconciliation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	reconciliation, err := h.service.FinalizeReconciliation(r.Context(), chi.URLParam(r, "accountID"), chi.URLParam(r, "reconciliationID"), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to finalize reconciliation")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, reconciliation)
+}
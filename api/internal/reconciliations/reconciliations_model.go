@@ -0,0 +1,151 @@
+package reconciliations
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReconciliationStatus string
+
+const (
+	StatusOpen      ReconciliationStatus = "open"
+	StatusFinalized ReconciliationStatus = "finalized" // os lançamentos casados ficam travados
+)
+
+// MatchKind diz como uma linha do extrato foi casada com um lançamento
+type MatchKind string
+
+const (
+	MatchAuto   MatchKind = "auto"
+	MatchManual MatchKind = "manual"
+)
+
+// defaultDateTolerance é quantos dias a data de um lançamento pode se afastar
+// da linha do extrato no auto-match, quando a conciliação não diz outro valor
+const defaultDateTolerance = 3
+
+// Reconciliation confere a conta com um extrato de PeriodStart a PeriodEnd.
+// A diferença é EndingBalance menos OpeningBalance e os lançamentos casados;
+// só com diferença zero a conciliação pode ser finalizada.
+type Reconciliation struct {
+	ID                uuid.UUID
+	AccountID         uuid.UUID
+	CreatedBy         uuid.UUID
+	PeriodStart       time.Time
+	PeriodEnd         time.Time
+	OpeningBalance    int64
+	EndingBalance     int64
+	AmountTolerance   int64 // diferença de valor aceita no auto-match, em unidades mínimas
+	DateToleranceDays int
+	Status            ReconciliationStatus
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	FinalizedAt       *time.Time
+}
+
+func (r *Reconciliation) IsFinalized() bool {
+	return r.Status == StatusFinalized
+}
+
+// Line é um lançamento do extrato. Movement é o lançamento da conta casado com
+// ela, lido junto com a linha.
+type Line struct {
+	ID               uuid.UUID
+	ReconciliationID uuid.UUID
+	Position         int
+	Date             time.Time
+	Amount           int64
+	Reference        string
+	Description      string
+	MovementID       *uuid.UUID
+	Match            *MatchKind
+	Movement         *Candidate
+}
+
+// Candidate é um lançamento da conta visto pela conciliação. ExternalID é o
+// identificador do banco, quando o lançamento veio de um extrato importado.
+type Candidate struct {
+	ID          uuid.UUID
+	Status      string
+	Amount      int64
+	Description string
+	OccurredAt  time.Time
+	ExternalID  *string
+}
+
+// Summary resume as linhas de uma conciliação
+type Summary struct {
+	Lines        int
+	MatchedLines int
+	MatchedTotal int64 // soma dos lançamentos casados
+}
+
+// StartReconciliationRequest usa datas no formato AAAA-MM-DD. opening_balance
+// só vale na primeira conciliação da conta; nas outras, o saldo inicial é o
+// final da anterior. Sem date_tolerance_days, vale 3 dias.
+type StartReconciliationRequest struct {
+	PeriodStart       string        `json:"period_start" validate:"required,datetime=2006-01-02"`
+	PeriodEnd         string        `json:"period_end" validate:"required,datetime=2006-01-02"`
+	OpeningBalance    *int64        `json:"opening_balance"`
+	EndingBalance     *int64        `json:"ending_balance" validate:"required"`
+	AmountTolerance   int64         `json:"amount_tolerance" validate:"min=0"`
+	DateToleranceDays *int          `json:"date_tolerance_days" validate:"omitempty,min=0,max=31"`
+	Lines             []LineRequest `json:"lines" validate:"max=5000,dive"`
+}
+
+type LineRequest struct {
+	Date        string `json:"date" validate:"required,datetime=2006-01-02"`
+	Amount      int64  `json:"amount" validate:"required"` // Em unidades mínimas; negativo para saídas
+	Reference   string `json:"reference" validate:"max=255"`
+	Description string `json:"description" validate:"max=255"`
+}
+
+// AutoMatchRequest troca as tolerâncias da conciliação só nesta rodada
+type AutoMatchRequest struct {
+	AmountTolerance   *int64 `json:"amount_tolerance" validate:"omitempty,min=0"`
+	DateToleranceDays *int   `json:"date_tolerance_days" validate:"omitempty,min=0,max=31"`
+}
+
+type MatchLineRequest struct {
+	MovementID uuid.UUID `json:"movement_id" validate:"required"`
+}
+
+type ReconciliationResponse struct {
+	ID                uuid.UUID            `json:"id"`
+	AccountID         uuid.UUID            `json:"account_id"`
+	PeriodStart       string               `json:"period_start"`
+	PeriodEnd         string               `json:"period_end"`
+	OpeningBalance    int64                `json:"opening_balance"`
+	EndingBalance     int64                `json:"ending_balance"`
+	ClearedBalance    int64                `json:"cleared_balance"` // saldo inicial mais os lançamentos casados
+	Difference        int64                `json:"difference"`      // ending_balance - cleared_balance; precisa ser zero para finalizar
+	AmountTolerance   int64                `json:"amount_tolerance"`
+	DateToleranceDays int                  `json:"date_tolerance_days"`
+	Status            ReconciliationStatus `json:"status"`
+	TotalLines        int                  `json:"total_lines"`
+	MatchedLines      int                  `json:"matched_lines"`
+	Lines             []LineResponse       `json:"lines,omitempty"` // só no detalhe
+	CreatedBy         uuid.UUID            `json:"created_by"`
+	CreatedAt         time.Time            `json:"created_at"`
+	UpdatedAt         time.Time            `json:"updated_at"`
+	FinalizedAt       *time.Time           `json:"finalized_at"`
+}
+
+type LineResponse struct {
+	ID          uuid.UUID          `json:"id"`
+	Date        string             `json:"date"`
+	Amount      int64              `json:"amount"`
+	Reference   string             `json:"reference"`
+	Description string             `json:"description"`
+	Match       *MatchKind         `json:"match"`
+	Movement    *MovementReference `json:"movement"`
+}
+
+// MovementReference resume o lançamento casado com uma linha
+type MovementReference struct {
+	ID          uuid.UUID `json:"id"`
+	Amount      int64     `json:"amount"`
+	Description string    `json:"description"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
@@ -0,0 +1,347 @@
+package reconciliations
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/martinsdevv/fincore/pkg/database"
+)
+
+type Repository interface {
+	// CreateReconciliation grava a conciliação com as linhas do extrato;
+	// retorna ErrOpenReconciliation se a conta já tiver uma em andamento
+	CreateReconciliation(ctx context.Context, reconciliation *Reconciliation, lines []Line) error
+	// GetReconciliation e LockReconciliation devolvem nil se a conciliação não
+	// existir na conta. LockReconciliation segura matches e a finalização
+	// concorrentes até o fim da transação.
+	GetReconciliation(ctx context.Context, accountID uuid.UUID, id uuid.UUID) (*Reconciliation, error)
+	LockReconciliation(ctx context.Context, accountID uuid.UUID, id uuid.UUID) (*Reconciliation, error)
+	// ListReconciliations devolve as conciliações da conta, do período mais recente ao mais antigo
+	ListReconciliations(ctx context.Context, accountID uuid.UUID) ([]Reconciliation, error)
+	// LastFinalized devolve a conciliação finalizada de período mais recente, ou nil
+	LastFinalized(ctx context.Context, accountID uuid.UUID) (*Reconciliation, error)
+	// Summaries resume as linhas de cada conciliação com uma única consulta
+	Summaries(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]Summary, error)
+	// FinalizeReconciliation grava o status e marca os lançamentos casados
+	// como conciliados em reconciliation.FinalizedAt
+	FinalizeReconciliation(ctx context.Context, reconciliation *Reconciliation) error
+	DeleteReconciliation(ctx context.Context, id uuid.UUID) error
+
+	// ListLines devolve as linhas em ordem, com o lançamento casado a cada uma
+	ListLines(ctx context.Context, reconciliationID uuid.UUID) ([]Line, error)
+	// SetLineMatch casa a linha com o lançamento, ou desfaz o match com nil;
+	// retorna ErrMovementMatched se o lançamento já estiver em outra linha
+	SetLineMatch(ctx context.Context, lineID uuid.UUID, movementID *uuid.UUID, match *MatchKind) error
+
+	// ListCandidates devolve os lançamentos posted da conta com occurred_at em
+	// [from, to) que ainda não foram casados com nenhuma linha
+	ListCandidates(ctx context.Context, accountID uuid.UUID, from time.Time, to time.Time) ([]Candidate, error)
+	// GetCandidate devolve o lançamento da conta em qualquer status, ou nil
+	GetCandidate(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Candidate, error)
+	// BalanceBefore soma os lançamentos posted da conta com occurred_at antes de before
+	BalanceBefore(ctx context.Context, accountID uuid.UUID, before time.Time) (int64, error)
+}
+
+type pgxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgxRepository{db: db}
+}
+
+// conn usa a transação aberta por um database.Transactor, se houver
+func (r *pgxRepository) conn(ctx context.Context) database.DBTX {
+	return database.Conn(ctx, r.db)
+}
+
+// Colunas lidas por scanReconciliation, sempre nesta ordem
+const reconciliationColumns = `id, account_id, created_by, period_start, period_end, opening_balance, ending_balance,
+	amount_tolerance, date_tolerance_days, status, created_at, updated_at, finalized_at`
+
+func scanReconciliation(row pgx.Row, rec *Reconciliation) error {
+	return row.Scan(
+		&rec.ID,
+		&rec.AccountID,
+		&rec.CreatedBy,
+		&rec.PeriodStart,
+		&rec.PeriodEnd,
+		&rec.OpeningBalance,
+		&rec.EndingBalance,
+		&rec.AmountTolerance,
+		&rec.DateToleranceDays,
+		&rec.Status,
+		&rec.CreatedAt,
+		&rec.UpdatedAt,
+		&rec.FinalizedAt,
+	)
+}
+
+func (r *pgxRepository) CreateReconciliation(ctx context.Context, rec *Reconciliation, lines []Line) error {
+	conn := r.conn(ctx)
+	query := `
+		INSERT INTO reconciliations (` + reconciliationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)`
+
+	_, err := conn.Exec(ctx, query,
+		rec.ID,
+		rec.AccountID,
+		rec.CreatedBy,
+		rec.PeriodStart,
+		rec.PeriodEnd,
+		rec.OpeningBalance,
+		rec.EndingBalance,
+		rec.AmountTolerance,
+		rec.DateToleranceDays,
+		rec.Status,
+		rec.CreatedAt,
+		rec.UpdatedAt,
+		rec.FinalizedAt,
+	)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_reconciliations_open" {
+		return ErrOpenReconciliation
+	}
+	if err != nil {
+		return err
+	}
+
+	lineQuery := `
+		INSERT INTO reconciliation_lines (id, reconciliation_id, position, occurred_on, amount, reference, description)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+	for _, l := range lines {
+		if _, err := conn.Exec(ctx, lineQuery, l.ID, rec.ID, l.Position, l.Date, l.Amount, l.Reference, l.Description); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *pgxRepository) GetReconciliation(ctx context.Context, accountID uuid.UUID, id uuid.UUID) (*Reconciliation, error) {
+	return r.getReconciliation(ctx, `SELECT `+reconciliationColumns+` FROM reconciliations WHERE id = $2 AND account_id = $1`, accountID, id)
+}
+
+func (r *pgxRepository) LockReconciliation(ctx context.Context, accountID uuid.UUID, id uuid.UUID) (*Reconciliation, error) {
+	return r.getReconciliation(ctx, `SELECT `+reconciliationColumns+` FROM reconciliations WHERE id = $2 AND account_id = $1 FOR UPDATE`, accountID, id)
+}
+
+func (r *pgxRepository) LastFinalized(ctx context.Context, accountID uuid.UUID) (*Reconciliation, error) {
+	query := `
+		SELECT ` + reconciliationColumns + `
+		FROM reconciliations
+		WHERE account_id = $1 AND status = 'finalized'
+		ORDER BY period_end DESC, finalized_at DESC
+		LIMIT 1`
+
+	var rec Reconciliation
+	if err := scanReconciliation(r.conn(ctx).QueryRow(ctx, query, accountID), &rec); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (r *pgxRepository) getReconciliation(ctx context.Context, query string, accountID uuid.UUID, id uuid.UUID) (*Reconciliation, error) {
+	var rec Reconciliation
+	if err := scanReconciliation(r.conn(ctx).QueryRow(ctx, query, accountID, id), &rec); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (r *pgxRepository) ListReconciliations(ctx context.Context, accountID uuid.UUID) ([]Reconciliation, error) {
+	query := `
+		SELECT ` + reconciliationColumns + `
+		FROM reconciliations
+		WHERE account_id = $1
+		ORDER BY period_end DESC, created_at DESC`
+
+	rows, err := r.conn(ctx).Query(ctx, query, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reconciliations []Reconciliation
+	for rows.Next() {
+		var rec Reconciliation
+		if err := scanReconciliation(rows, &rec); err != nil {
+			return nil, err
+		}
+		reconciliations = append(reconciliations, rec)
+	}
+	return reconciliations, rows.Err()
+}
+
+func (r *pgxRepository) Summaries(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]Summary, error) {
+	summaries := make(map[uuid.UUID]Summary, len(ids))
+	if len(ids) == 0 {
+		return summaries, nil
+	}
+
+	query := `
+		SELECT l.reconciliation_id, COUNT(*), COUNT(l.movement_id), COALESCE(SUM(m.amount), 0)::BIGINT
+		FROM reconciliation_lines l
+		LEFT JOIN movements m ON m.id = l.movement_id
+		WHERE l.reconciliation_id = ANY($1)
+		GROUP BY l.reconciliation_id`
+
+	rows, err := r.conn(ctx).Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var s Summary
+		if err := rows.Scan(&id, &s.Lines, &s.MatchedLines, &s.MatchedTotal); err != nil {
+			return nil, err
+		}
+		summaries[id] = s
+	}
+	return summaries, rows.Err()
+}
+
+func (r *pgxRepository) FinalizeReconciliation(ctx context.Context, rec *Reconciliation) error {
+	conn := r.conn(ctx)
+	query := `UPDATE reconciliations SET status = $2, finalized_at = $3, updated_at = $4 WHERE id = $1`
+	if _, err := conn.Exec(ctx, query, rec.ID, rec.Status, rec.FinalizedAt, rec.UpdatedAt); err != nil {
+		return err
+	}
+
+	query = `
+		UPDATE movements SET reconciled_at = $2
+		WHERE id IN (SELECT movement_id FROM reconciliation_lines WHERE reconciliation_id = $1 AND movement_id IS NOT NULL)`
+	_, err := conn.Exec(ctx, query, rec.ID, rec.FinalizedAt)
+	return err
+}
+
+func (r *pgxRepository) DeleteReconciliation(ctx context.Context, id uuid.UUID) error {
+	_, err := r.conn(ctx).Exec(ctx, `DELETE FROM reconciliations WHERE id = $1`, id)
+	return err
+}
+
+func (r *pgxRepository) ListLines(ctx context.Context, reconciliationID uuid.UUID) ([]Line, error) {
+	query := `
+		SELECT l.id, l.reconciliation_id, l.position, l.occurred_on, l.amount, l.reference, l.description, l.movement_id, l.match,
+			m.status, m.amount, m.description, m.occurred_at, e.external_id
+		FROM reconciliation_lines l
+		LEFT JOIN movements m ON m.id = l.movement_id
+		LEFT JOIN import_entries e ON e.movement_id = l.movement_id
+		WHERE l.reconciliation_id = $1
+		ORDER BY l.position`
+
+	rows, err := r.conn(ctx).Query(ctx, query, reconciliationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lines []Line
+	for rows.Next() {
+		var l Line
+		var status, description *string
+		var amount *int64
+		var occurredAt *time.Time
+		var externalID *string
+		err := rows.Scan(
+			&l.ID, &l.ReconciliationID, &l.Position, &l.Date, &l.Amount, &l.Reference, &l.Description, &l.MovementID, &l.Match,
+			&status, &amount, &description, &occurredAt, &externalID,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if l.MovementID != nil {
+			l.Movement = &Candidate{
+				ID:          *l.MovementID,
+				Status:      *status,
+				Amount:      *amount,
+				Description: *description,
+				OccurredAt:  *occurredAt,
+				ExternalID:  externalID,
+			}
+		}
+		lines = append(lines, l)
+	}
+	return lines, rows.Err()
+}
+
+func (r *pgxRepository) SetLineMatch(ctx context.Context, lineID uuid.UUID, movementID *uuid.UUID, match *MatchKind) error {
+	query := `UPDATE reconciliation_lines SET movement_id = $2, match = $3 WHERE id = $1`
+
+	_, err := r.conn(ctx).Exec(ctx, query, lineID, movementID, match)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrMovementMatched
+	}
+	return err
+}
+
+// Colunas lidas por scanCandidate, de movements m e import_entries e
+const candidateColumns = `m.id, m.status, m.amount, m.description, m.occurred_at, e.external_id`
+
+func scanCandidate(row pgx.Row, c *Candidate) error {
+	return row.Scan(&c.ID, &c.Status, &c.Amount, &c.Description, &c.OccurredAt, &c.ExternalID)
+}
+
+func (r *pgxRepository) ListCandidates(ctx context.Context, accountID uuid.UUID, from time.Time, to time.Time) ([]Candidate, error) {
+	query := `
+		SELECT ` + candidateColumns + `
+		FROM movements m
+		LEFT JOIN import_entries e ON e.movement_id = m.id
+		WHERE m.account_id = $1 AND m.status = 'posted' AND m.occurred_at >= $2 AND m.occurred_at < $3
+			AND NOT EXISTS (SELECT 1 FROM reconciliation_lines l WHERE l.movement_id = m.id)
+		ORDER BY m.occurred_at, m.id`
+
+	rows, err := r.conn(ctx).Query(ctx, query, accountID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candidates []Candidate
+	for rows.Next() {
+		var c Candidate
+		if err := scanCandidate(rows, &c); err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates, rows.Err()
+}
+
+func (r *pgxRepository) GetCandidate(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Candidate, error) {
+	query := `
+		SELECT ` + candidateColumns + `
+		FROM movements m
+		LEFT JOIN import_entries e ON e.movement_id = m.id
+		WHERE m.id = $2 AND m.account_id = $1`
+
+	var c Candidate
+	if err := scanCandidate(r.conn(ctx).QueryRow(ctx, query, accountID, movementID), &c); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *pgxRepository) BalanceBefore(ctx context.Context, accountID uuid.UUID, before time.Time) (int64, error) {
+	query := `SELECT COALESCE(SUM(amount), 0)::BIGINT FROM movements WHERE account_id = $1 AND status = 'posted' AND occurred_at < $2`
+
+	var balance int64
+	err := r.conn(ctx).QueryRow(ctx, query, accountID, before).Scan(&balance)
+	return balance, err
+}
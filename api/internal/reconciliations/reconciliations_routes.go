@@ -0,0 +1,14 @@
+package reconciliations
+
+import "github.com/go-chi/chi/v5"
+
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Post("/accounts/{accountID}/reconciliations", h.HandleStartReconciliation)
+	r.Get("/accounts/{accountID}/reconciliations", h.HandleListReconciliations)
+	r.Get("/accounts/{accountID}/reconciliations/{reconciliationID}", h.HandleGetReconciliation)
+	r.Delete("/accounts/{accountID}/reconciliations/{reconciliationID}", h.HandleDeleteReconciliation)
+	r.Post("/accounts/{accountID}/reconciliations/{reconciliationID}/auto-match", h.HandleAutoMatch)
+	r.Put("/accounts/{accountID}/reconciliations/{reconciliationID}/lines/{lineID}/match", h.HandleMatchLine)
+	r.Delete("/accounts/{accountID}/reconciliations/{reconciliationID}/lines/{lineID}/match", h.HandleUnmatchLine)
+	r.Post("/accounts/{accountID}/reconciliations/{reconciliationID}/finalize", h.HandleFinalizeReconciliation)
+}
@@ -0,0 +1,544 @@
+package reconciliations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/movements"
+	"github.com/martinsdevv/fincore/pkg/database"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrReconciliationNotFound  = errors.New("reconciliation not found")
+	ErrLineNotFound            = errors.New("statement line not found")
+	ErrOpenReconciliation      = errors.New("account already has an open reconciliation")
+	ErrReconciliationFinalized = errors.New("reconciliation is finalized")
+	ErrInvalidPeriod           = errors.New("period_end cannot be before period_start")
+	ErrPeriodOverlap           = errors.New("period must start after the end of the last finalized reconciliation")
+	ErrLineOutsidePeriod       = errors.New("statement line is outside the reconciliation period")
+	ErrOpeningBalanceMismatch  = errors.New("opening_balance must be the ending balance of the last finalized reconciliation")
+	ErrLineMatched             = errors.New("statement line is already matched; unmatch it first")
+	ErrLineNotMatched          = errors.New("statement line is not matched")
+	ErrMovementMatched         = errors.New("movement is already matched to a statement line")
+	ErrMovementNotPosted       = errors.New("only posted movements can be reconciled")
+	ErrSignMismatch            = errors.New("movement and statement line must both be inflows or outflows")
+	ErrUnbalanced              = errors.New("reconciliation difference must be zero to finalize")
+)
+
+type Service interface {
+	// StartReconciliation abre a conciliação do período com as linhas do
+	// extrato; a conta só tem uma aberta por vez
+	StartReconciliation(ctx context.Context, accountID string, userID string, req StartReconciliationRequest) (*ReconciliationResponse, error)
+	ListReconciliations(ctx context.Context, accountID string, userID string) ([]ReconciliationResponse, error)
+	GetReconciliation(ctx context.Context, accountID string, reconciliationID string, userID string) (*ReconciliationResponse, error)
+	// DeleteReconciliation descarta uma conciliação aberta e os matches dela
+	DeleteReconciliation(ctx context.Context, accountID string, reconciliationID string, userID string) error
+	// AutoMatch casa as linhas ainda sem lançamento pelo valor, data e referência
+	AutoMatch(ctx context.Context, accountID string, reconciliationID string, userID string, req AutoMatchRequest) (*ReconciliationResponse, error)
+	MatchLine(ctx context.Context, accountID string, reconciliationID string, lineID string, userID string, req MatchLineRequest) (*ReconciliationResponse, error)
+	UnmatchLine(ctx context.Context, accountID string, reconciliationID string, lineID string, userID string) (*ReconciliationResponse, error)
+	// FinalizeReconciliation fecha a conciliação se a diferença for zero e
+	// trava os lançamentos casados
+	FinalizeReconciliation(ctx context.Context, accountID string, reconciliationID string, userID string) (*ReconciliationResponse, error)
+}
+
+type service struct {
+	repo     Repository
+	accounts accounts.Service
+	tx       database.Transactor
+}
+
+func NewService(repo Repository, accountsSvc accounts.Service, tx database.Transactor) Service {
+	return &service{repo: repo, accounts: accountsSvc, tx: tx}
+}
+
+func (s *service) StartReconciliation(ctx context.Context, accountIDStr string, userIDStr string, req StartReconciliationRequest) (*ReconciliationResponse, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if account.IsArchived() {
+		return nil, accounts.ErrAccountArchived
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	periodStart, err := time.Parse(time.DateOnly, req.PeriodStart)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidPeriod, err)
+	}
+	periodEnd, err := time.Parse(time.DateOnly, req.PeriodEnd)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidPeriod, err)
+	}
+	if periodEnd.Before(periodStart) {
+		return nil, ErrInvalidPeriod
+	}
+
+	now := time.Now().UTC()
+	reconciliation := &Reconciliation{
+		ID:                uuid.New(),
+		AccountID:         account.ID,
+		CreatedBy:         userID,
+		PeriodStart:       periodStart,
+		PeriodEnd:         periodEnd,
+		EndingBalance:     *req.EndingBalance,
+		AmountTolerance:   req.AmountTolerance,
+		DateToleranceDays: defaultDateTolerance,
+		Status:            StatusOpen,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+	if req.DateToleranceDays != nil {
+		reconciliation.DateToleranceDays = *req.DateToleranceDays
+	}
+
+	lines := make([]Line, len(req.Lines))
+	for i, l := range req.Lines {
+		date, err := time.Parse(time.DateOnly, l.Date)
+		if err != nil || date.Before(periodStart) || date.After(periodEnd) {
+			return nil, fmt.Errorf("%w: line %d", ErrLineOutsidePeriod, i+1)
+		}
+		lines[i] = Line{
+			ID:               uuid.New(),
+			ReconciliationID: reconciliation.ID,
+			Position:         i,
+			Date:             date,
+			Amount:           l.Amount,
+			Reference:        strings.TrimSpace(l.Reference),
+			Description:      strings.TrimSpace(l.Description),
+		}
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		// O saldo inicial continua de onde a última conciliação parou
+		last, err := s.repo.LastFinalized(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+		switch {
+		case last != nil:
+			if !periodStart.After(last.PeriodEnd) {
+				return ErrPeriodOverlap
+			}
+			if req.OpeningBalance != nil && *req.OpeningBalance != last.EndingBalance {
+				return ErrOpeningBalanceMismatch
+			}
+			reconciliation.OpeningBalance = last.EndingBalance
+		case req.OpeningBalance != nil:
+			reconciliation.OpeningBalance = *req.OpeningBalance
+		default:
+			if reconciliation.OpeningBalance, err = s.repo.BalanceBefore(ctx, account.ID, periodStart); err != nil {
+				return err
+			}
+		}
+		return s.repo.CreateReconciliation(ctx, reconciliation, lines)
+	})
+	if err != nil {
+		if !errors.Is(err, ErrPeriodOverlap) && !errors.Is(err, ErrOpeningBalanceMismatch) && !errors.Is(err, ErrOpenReconciliation) {
+			log.Error().Err(err).Str("accountID", account.ID.String()).Msg("Failed to create reconciliation in repository")
+		}
+		return nil, err
+	}
+
+	return toReconciliationResponse(reconciliation, lines), nil
+}
+
+func (s *service) ListReconciliations(ctx context.Context, accountIDStr string, userIDStr string) ([]ReconciliationResponse, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	reconciliations, err := s.repo.ListReconciliations(ctx, account.ID)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", account.ID.String()).Msg("Failed to list reconciliations from repository")
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(reconciliations))
+	for i, rec := range reconciliations {
+		ids[i] = rec.ID
+	}
+	summaries, err := s.repo.Summaries(ctx, ids)
+	if err != nil {
+		log.Error().Err(err).Str("accountID", account.ID.String()).Msg("Failed to summarize reconciliations")
+		return nil, err
+	}
+
+	resp := make([]ReconciliationResponse, 0, len(reconciliations))
+	for i := range reconciliations {
+		r := toReconciliationResponse(&reconciliations[i], nil)
+		applySummary(r, summaries[reconciliations[i].ID])
+		resp = append(resp, *r)
+	}
+	return resp, nil
+}
+
+func (s *service) GetReconciliation(ctx context.Context, accountIDStr string, reconciliationIDStr string, userIDStr string) (*ReconciliationResponse, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	reconciliationID, err := uuid.Parse(reconciliationIDStr)
+	if err != nil {
+		return nil, ErrReconciliationNotFound
+	}
+
+	reconciliation, err := s.repo.GetReconciliation(ctx, account.ID, reconciliationID)
+	if err != nil {
+		log.Error().Err(err).Str("reconciliationID", reconciliationIDStr).Msg("Failed to get reconciliation from repository")
+		return nil, err
+	}
+	if reconciliation == nil {
+		return nil, ErrReconciliationNotFound
+	}
+	return s.detail(ctx, reconciliation)
+}
+
+func (s *service) DeleteReconciliation(ctx context.Context, accountIDStr string, reconciliationIDStr string, userIDStr string) error {
+	_, err := s.withOpen(ctx, accountIDStr, reconciliationIDStr, userIDStr, func(ctx context.Context, rec *Reconciliation, lines []Line) error {
+		return s.repo.DeleteReconciliation(ctx, rec.ID)
+	})
+	return err
+}
+
+func (s *service) AutoMatch(ctx context.Context, accountIDStr string, reconciliationIDStr string, userIDStr string, req AutoMatchRequest) (*ReconciliationResponse, error) {
+	return s.update(ctx, accountIDStr, reconciliationIDStr, userIDStr, func(ctx context.Context, rec *Reconciliation, lines []Line) error {
+		tol := tolerance{amount: rec.AmountTolerance, days: rec.DateToleranceDays}
+		if req.AmountTolerance != nil {
+			tol.amount = *req.AmountTolerance
+		}
+		if req.DateToleranceDays != nil {
+			tol.days = *req.DateToleranceDays
+		}
+
+		var from, to time.Time
+		for _, l := range lines {
+			if l.MovementID != nil {
+				continue
+			}
+			if from.IsZero() || l.Date.Before(from) {
+				from = l.Date
+			}
+			if l.Date.After(to) {
+				to = l.Date
+			}
+		}
+		if from.IsZero() {
+			return nil
+		}
+		candidates, err := s.repo.ListCandidates(ctx, rec.AccountID, from.AddDate(0, 0, -tol.days), to.AddDate(0, 0, tol.days+1))
+		if err != nil {
+			return err
+		}
+
+		match := MatchAuto
+		for lineID, movementID := range autoMatch(lines, candidates, tol) {
+			movementID := movementID
+			if err := s.repo.SetLineMatch(ctx, lineID, &movementID, &match); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *service) MatchLine(ctx context.Context, accountIDStr string, reconciliationIDStr string, lineIDStr string, userIDStr string, req MatchLineRequest) (*ReconciliationResponse, error) {
+	return s.update(ctx, accountIDStr, reconciliationIDStr, userIDStr, func(ctx context.Context, rec *Reconciliation, lines []Line) error {
+		line, err := findLine(lines, lineIDStr)
+		if err != nil {
+			return err
+		}
+		if line.MovementID != nil {
+			return ErrLineMatched
+		}
+
+		// À mão vale qualquer data e valor: as tolerâncias são só do auto-match
+		movement, err := s.repo.GetCandidate(ctx, rec.AccountID, req.MovementID)
+		if err != nil {
+			return err
+		}
+		if movement == nil {
+			return movements.ErrMovementNotFound
+		}
+		if movements.MovementStatus(movement.Status) != movements.StatusPosted {
+			return ErrMovementNotPosted
+		}
+		if (movement.Amount < 0) != (line.Amount < 0) {
+			return ErrSignMismatch
+		}
+
+		match := MatchManual
+		return s.repo.SetLineMatch(ctx, line.ID, &movement.ID, &match)
+	})
+}
+
+func (s *service) UnmatchLine(ctx context.Context, accountIDStr string, reconciliationIDStr string, lineIDStr string, userIDStr string) (*ReconciliationResponse, error) {
+	return s.update(ctx, accountIDStr, reconciliationIDStr, userIDStr, func(ctx context.Context, rec *Reconciliation, lines []Line) error {
+		line, err := findLine(lines, lineIDStr)
+		if err != nil {
+			return err
+		}
+		if line.MovementID == nil {
+			return ErrLineNotMatched
+		}
+		return s.repo.SetLineMatch(ctx, line.ID, nil, nil)
+	})
+}
+
+func (s *service) FinalizeReconciliation(ctx context.Context, accountIDStr string, reconciliationIDStr string, userIDStr string) (*ReconciliationResponse, error) {
+	return s.update(ctx, accountIDStr, reconciliationIDStr, userIDStr, func(ctx context.Context, rec *Reconciliation, lines []Line) error {
+		if difference := rec.EndingBalance - rec.OpeningBalance - summarize(lines).MatchedTotal; difference != 0 {
+			return fmt.Errorf("%w: difference is %d", ErrUnbalanced, difference)
+		}
+
+		now := time.Now().UTC()
+		rec.Status = StatusFinalized
+		rec.FinalizedAt = &now
+		rec.UpdatedAt = now
+		return s.repo.FinalizeReconciliation(ctx, rec)
+	})
+}
+
+// update roda fn com withOpen e devolve o detalhe como ficou
+func (s *service) update(ctx context.Context, accountIDStr string, reconciliationIDStr string, userIDStr string, fn func(ctx context.Context, rec *Reconciliation, lines []Line) error) (*ReconciliationResponse, error) {
+	reconciliation, err := s.withOpen(ctx, accountIDStr, reconciliationIDStr, userIDStr, fn)
+	if err != nil {
+		return nil, err
+	}
+	return s.detail(ctx, reconciliation)
+}
+
+// withOpen roda fn numa transação com a conciliação travada e as linhas dela.
+// Conciliações finalizadas não mudam mais.
+func (s *service) withOpen(ctx context.Context, accountIDStr string, reconciliationIDStr string, userIDStr string, fn func(ctx context.Context, rec *Reconciliation, lines []Line) error) (*Reconciliation, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, accountIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	reconciliationID, err := uuid.Parse(reconciliationIDStr)
+	if err != nil {
+		return nil, ErrReconciliationNotFound
+	}
+
+	var reconciliation *Reconciliation
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		locked, err := s.repo.LockReconciliation(ctx, account.ID, reconciliationID)
+		if err != nil {
+			return err
+		}
+		if locked == nil {
+			return ErrReconciliationNotFound
+		}
+		if locked.IsFinalized() {
+			return ErrReconciliationFinalized
+		}
+		lines, err := s.repo.ListLines(ctx, locked.ID)
+		if err != nil {
+			return err
+		}
+		reconciliation = locked
+		return fn(ctx, locked, lines)
+	})
+	if err != nil {
+		if !isExpected(err) {
+			log.Error().Err(err).Str("reconciliationID", reconciliationIDStr).Msg("Failed to update reconciliation")
+		}
+		return nil, err
+	}
+	return reconciliation, nil
+}
+
+// detail monta a resposta com as linhas e os lançamentos casados
+func (s *service) detail(ctx context.Context, reconciliation *Reconciliation) (*ReconciliationResponse, error) {
+	lines, err := s.repo.ListLines(ctx, reconciliation.ID)
+	if err != nil {
+		log.Error().Err(err).Str("reconciliationID", reconciliation.ID.String()).Msg("Failed to list reconciliation lines")
+		return nil, err
+	}
+	return toReconciliationResponse(reconciliation, lines), nil
+}
+
+func isExpected(err error) bool {
+	for _, target := range []error{
+		ErrReconciliationNotFound, ErrReconciliationFinalized, ErrLineNotFound, ErrLineMatched, ErrLineNotMatched,
+		ErrMovementMatched, ErrMovementNotPosted, ErrSignMismatch, ErrUnbalanced, movements.ErrMovementNotFound,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func findLine(lines []Line, lineIDStr string) (*Line, error) {
+	lineID, err := uuid.Parse(lineIDStr)
+	if err != nil {
+		return nil, ErrLineNotFound
+	}
+	for i := range lines {
+		if lines[i].ID == lineID {
+			return &lines[i], nil
+		}
+	}
+	return nil, ErrLineNotFound
+}
+
+// tolerance é o quanto valor (em unidades mínimas) e data (em dias) de um
+// lançamento podem se afastar da linha no auto-match
+type tolerance struct {
+	amount int64
+	days   int
+}
+
+// autoMatch escolhe, para cada linha sem lançamento, um candidato com o mesmo
+// sinal dentro das tolerâncias. Os pares com a referência batendo vêm
+// primeiro, depois os de menor diferença de valor e de data; cada linha e
+// cada lançamento entram em um par só. Devolve o lançamento de cada linha casada.
+func autoMatch(lines []Line, candidates []Candidate, tol tolerance) map[uuid.UUID]uuid.UUID {
+	type pair struct {
+		line, candidate int
+		reference       bool
+		amountDiff      int64
+		dayDiff         int
+	}
+
+	var pairs []pair
+	for i, l := range lines {
+		if l.MovementID != nil {
+			continue
+		}
+		for j, c := range candidates {
+			if (c.Amount < 0) != (l.Amount < 0) {
+				continue
+			}
+			p := pair{line: i, candidate: j, amountDiff: abs(c.Amount - l.Amount), dayDiff: daysBetween(l.Date, c.OccurredAt)}
+			if p.amountDiff > tol.amount || p.dayDiff > tol.days {
+				continue
+			}
+			p.reference = referenceMatches(l.Reference, c)
+			pairs = append(pairs, p)
+		}
+	}
+
+	sort.SliceStable(pairs, func(a, b int) bool {
+		pa, pb := pairs[a], pairs[b]
+		if pa.reference != pb.reference {
+			return pa.reference
+		}
+		if pa.amountDiff != pb.amountDiff {
+			return pa.amountDiff < pb.amountDiff
+		}
+		return pa.dayDiff < pb.dayDiff
+	})
+
+	matches := make(map[uuid.UUID]uuid.UUID)
+	usedCandidates := make(map[int]bool)
+	for _, p := range pairs {
+		lineID := lines[p.line].ID
+		if _, done := matches[lineID]; done || usedCandidates[p.candidate] {
+			continue
+		}
+		matches[lineID] = candidates[p.candidate].ID
+		usedCandidates[p.candidate] = true
+	}
+	return matches
+}
+
+// referenceMatches compara a referência da linha com o identificador do banco
+// do lançamento importado ou, sem ele, com a descrição
+func referenceMatches(reference string, c Candidate) bool {
+	if reference == "" {
+		return false
+	}
+	if c.ExternalID != nil && strings.EqualFold(*c.ExternalID, reference) {
+		return true
+	}
+	return strings.Contains(strings.ToLower(c.Description), strings.ToLower(reference))
+}
+
+// daysBetween conta os dias de calendário (UTC) entre a data da linha e o lançamento
+func daysBetween(date time.Time, occurredAt time.Time) int {
+	day := occurredAt.UTC().Truncate(24 * time.Hour)
+	diff := int(day.Sub(date).Hours() / 24)
+	if diff < 0 {
+		return -diff
+	}
+	return diff
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func summarize(lines []Line) Summary {
+	summary := Summary{Lines: len(lines)}
+	for _, l := range lines {
+		if l.Movement != nil {
+			summary.MatchedLines++
+			summary.MatchedTotal += l.Movement.Amount
+		}
+	}
+	return summary
+}
+
+func applySummary(resp *ReconciliationResponse, summary Summary) {
+	resp.TotalLines = summary.Lines
+	resp.MatchedLines = summary.MatchedLines
+	resp.ClearedBalance = resp.OpeningBalance + summary.MatchedTotal
+	resp.Difference = resp.EndingBalance - resp.ClearedBalance
+}
+
+// toReconciliationResponse inclui as linhas quando recebe alguma; os totais
+// saem delas
+func toReconciliationResponse(rec *Reconciliation, lines []Line) *ReconciliationResponse {
+	resp := &ReconciliationResponse{
+		ID:                rec.ID,
+		AccountID:         rec.AccountID,
+		PeriodStart:       rec.PeriodStart.Format(time.DateOnly),
+		PeriodEnd:         rec.PeriodEnd.Format(time.DateOnly),
+		OpeningBalance:    rec.OpeningBalance,
+		EndingBalance:     rec.EndingBalance,
+		AmountTolerance:   rec.AmountTolerance,
+		DateToleranceDays: rec.DateToleranceDays,
+		Status:            rec.Status,
+		CreatedBy:         rec.CreatedBy,
+		CreatedAt:         rec.CreatedAt,
+		UpdatedAt:         rec.UpdatedAt,
+		FinalizedAt:       rec.FinalizedAt,
+	}
+	applySummary(resp, summarize(lines))
+
+	for _, l := range lines {
+		line := LineResponse{
+			ID:          l.ID,
+			Date:        l.Date.Format(time.DateOnly),
+			Amount:      l.Amount,
+			Reference:   l.Reference,
+			Description: l.Description,
+			Match:       l.Match,
+		}
+		if l.Movement != nil {
+			line.Movement = &MovementReference{
+				ID:          l.Movement.ID,
+				Amount:      l.Movement.Amount,
+				Description: l.Movement.Description,
+				OccurredAt:  l.Movement.OccurredAt,
+			}
+		}
+		resp.Lines = append(resp.Lines, line)
+	}
+	return resp
+}
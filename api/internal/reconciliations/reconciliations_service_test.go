@@ -0,0 +1,411 @@
+package reconciliations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/movements"
+)
+
+// MockRepository guarda conciliações, linhas e lançamentos em memória. Os
+// lançamentos já casados numa linha não voltam como candidatos, como no NOT
+// EXISTS da consulta.
+type MockRepository struct {
+	reconciliations map[uuid.UUID]*Reconciliation
+	lines           map[uuid.UUID][]Line
+	movements       map[uuid.UUID]*Candidate
+	reconciled      map[uuid.UUID]time.Time
+	balanceBefore   int64
+}
+
+func newMockRepository(candidates ...Candidate) *MockRepository {
+	m := &MockRepository{
+		reconciliations: make(map[uuid.UUID]*Reconciliation),
+		lines:           make(map[uuid.UUID][]Line),
+		movements:       make(map[uuid.UUID]*Candidate),
+		reconciled:      make(map[uuid.UUID]time.Time),
+	}
+	for i := range candidates {
+		m.movements[candidates[i].ID] = &candidates[i]
+	}
+	return m
+}
+
+func (m *MockRepository) CreateReconciliation(ctx context.Context, reconciliation *Reconciliation, lines []Line) error {
+	for _, r := range m.reconciliations {
+		if r.AccountID == reconciliation.AccountID && r.Status == StatusOpen {
+			return ErrOpenReconciliation
+		}
+	}
+	copied := *reconciliation
+	m.reconciliations[reconciliation.ID] = &copied
+	m.lines[reconciliation.ID] = append([]Line(nil), lines...)
+	return nil
+}
+
+func (m *MockRepository) GetReconciliation(ctx context.Context, accountID uuid.UUID, id uuid.UUID) (*Reconciliation, error) {
+	r, ok := m.reconciliations[id]
+	if !ok || r.AccountID != accountID {
+		return nil, nil
+	}
+	copied := *r
+	return &copied, nil
+}
+
+func (m *MockRepository) LockReconciliation(ctx context.Context, accountID uuid.UUID, id uuid.UUID) (*Reconciliation, error) {
+	return m.GetReconciliation(ctx, accountID, id)
+}
+
+func (m *MockRepository) ListReconciliations(ctx context.Context, accountID uuid.UUID) ([]Reconciliation, error) {
+	var list []Reconciliation
+	for _, r := range m.reconciliations {
+		if r.AccountID == accountID {
+			list = append(list, *r)
+		}
+	}
+	return list, nil
+}
+
+func (m *MockRepository) LastFinalized(ctx context.Context, accountID uuid.UUID) (*Reconciliation, error) {
+	var last *Reconciliation
+	for _, r := range m.reconciliations {
+		if r.AccountID == accountID && r.IsFinalized() && (last == nil || r.PeriodEnd.After(last.PeriodEnd)) {
+			copied := *r
+			last = &copied
+		}
+	}
+	return last, nil
+}
+
+func (m *MockRepository) Summaries(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]Summary, error) {
+	summaries := make(map[uuid.UUID]Summary)
+	for _, id := range ids {
+		lines, _ := m.ListLines(ctx, id)
+		summaries[id] = summarize(lines)
+	}
+	return summaries, nil
+}
+
+func (m *MockRepository) FinalizeReconciliation(ctx context.Context, reconciliation *Reconciliation) error {
+	copied := *reconciliation
+	m.reconciliations[reconciliation.ID] = &copied
+	for _, l := range m.lines[reconciliation.ID] {
+		if l.MovementID != nil {
+			m.reconciled[*l.MovementID] = *reconciliation.FinalizedAt
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) DeleteReconciliation(ctx context.Context, id uuid.UUID) error {
+	delete(m.reconciliations, id)
+	delete(m.lines, id)
+	return nil
+}
+
+func (m *MockRepository) ListLines(ctx context.Context, reconciliationID uuid.UUID) ([]Line, error) {
+	lines := append([]Line(nil), m.lines[reconciliationID]...)
+	for i := range lines {
+		if lines[i].MovementID != nil {
+			copied := *m.movements[*lines[i].MovementID]
+			lines[i].Movement = &copied
+		}
+	}
+	return lines, nil
+}
+
+func (m *MockRepository) matched(movementID uuid.UUID) bool {
+	for _, lines := range m.lines {
+		for _, l := range lines {
+			if l.MovementID != nil && *l.MovementID == movementID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (m *MockRepository) SetLineMatch(ctx context.Context, lineID uuid.UUID, movementID *uuid.UUID, match *MatchKind) error {
+	if movementID != nil && m.matched(*movementID) {
+		return ErrMovementMatched
+	}
+	for _, lines := range m.lines {
+		for i := range lines {
+			if lines[i].ID == lineID {
+				lines[i].MovementID = movementID
+				lines[i].Match = match
+			}
+		}
+	}
+	return nil
+}
+
+func (m *MockRepository) ListCandidates(ctx context.Context, accountID uuid.UUID, from time.Time, to time.Time) ([]Candidate, error) {
+	var candidates []Candidate
+	for _, c := range m.movements {
+		if c.Status == "posted" && !c.OccurredAt.Before(from) && c.OccurredAt.Before(to) && !m.matched(c.ID) {
+			candidates = append(candidates, *c)
+		}
+	}
+	return candidates, nil
+}
+
+func (m *MockRepository) GetCandidate(ctx context.Context, accountID uuid.UUID, movementID uuid.UUID) (*Candidate, error) {
+	c, ok := m.movements[movementID]
+	if !ok {
+		return nil, nil
+	}
+	copied := *c
+	return &copied, nil
+}
+
+func (m *MockRepository) BalanceBefore(ctx context.Context, accountID uuid.UUID, before time.Time) (int64, error) {
+	return m.balanceBefore, nil
+}
+
+// MockAccounts implementa só a autorização de accounts.Service
+type MockAccounts struct {
+	accounts.Service
+	account *accounts.Account
+}
+
+func (m *MockAccounts) AuthorizeAccount(ctx context.Context, accountID string, userID string, role accounts.MemberRole) (*accounts.Account, error) {
+	return m.account, nil
+}
+
+type MockTransactor struct{}
+
+func (m *MockTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func day(s string) time.Time {
+	t, _ := time.Parse(time.DateOnly, s)
+	return t
+}
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func TestService_StartReconciliation(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	account := &accounts.Account{ID: uuid.New(), UserID: userID, Currency: "BRL"}
+
+	t.Run("deve usar o saldo contábil antes do período na primeira conciliação", func(t *testing.T) {
+		repo := newMockRepository()
+		repo.balanceBefore = 50000
+		service := NewService(repo, &MockAccounts{account: account}, &MockTransactor{})
+
+		resp, err := service.StartReconciliation(ctx, account.ID.String(), userID.String(), StartReconciliationRequest{
+			PeriodStart:   "2024-03-01",
+			PeriodEnd:     "2024-03-31",
+			EndingBalance: int64Ptr(42000),
+			Lines:         []LineRequest{{Date: "2024-03-05", Amount: -8000, Reference: "NF 123"}},
+		})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if resp.OpeningBalance != 50000 || resp.Difference != -8000 || resp.DateToleranceDays != defaultDateTolerance || len(resp.Lines) != 1 {
+			t.Errorf("conciliação incorreta: %+v", resp)
+		}
+
+		_, err = service.StartReconciliation(ctx, account.ID.String(), userID.String(), StartReconciliationRequest{
+			PeriodStart: "2024-04-01", PeriodEnd: "2024-04-30", EndingBalance: int64Ptr(0),
+		})
+		if !errors.Is(err, ErrOpenReconciliation) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrOpenReconciliation, err)
+		}
+	})
+
+	t.Run("deve continuar do saldo final da última conciliação finalizada", func(t *testing.T) {
+		repo := newMockRepository()
+		previous := &Reconciliation{ID: uuid.New(), AccountID: account.ID, PeriodStart: day("2024-02-01"), PeriodEnd: day("2024-02-29"), EndingBalance: 31000, Status: StatusFinalized}
+		repo.reconciliations[previous.ID] = previous
+		service := NewService(repo, &MockAccounts{account: account}, &MockTransactor{})
+
+		cases := []struct {
+			req StartReconciliationRequest
+			err error
+		}{
+			{StartReconciliationRequest{PeriodStart: "2024-02-20", PeriodEnd: "2024-03-31", EndingBalance: int64Ptr(0)}, ErrPeriodOverlap},
+			{StartReconciliationRequest{PeriodStart: "2024-03-01", PeriodEnd: "2024-03-31", EndingBalance: int64Ptr(0), OpeningBalance: int64Ptr(1)}, ErrOpeningBalanceMismatch},
+			{StartReconciliationRequest{PeriodStart: "2024-03-31", PeriodEnd: "2024-03-01", EndingBalance: int64Ptr(0)}, ErrInvalidPeriod},
+			{StartReconciliationRequest{PeriodStart: "2024-03-01", PeriodEnd: "2024-03-31", EndingBalance: int64Ptr(0), Lines: []LineRequest{{Date: "2024-04-01", Amount: 1}}}, ErrLineOutsidePeriod},
+		}
+		for _, c := range cases {
+			_, err := service.StartReconciliation(ctx, account.ID.String(), userID.String(), c.req)
+			if !errors.Is(err, c.err) {
+				t.Errorf("%+v: esperava o erro %v, mas obteve %v", c.req, c.err, err)
+			}
+		}
+
+		resp, err := service.StartReconciliation(ctx, account.ID.String(), userID.String(), StartReconciliationRequest{
+			PeriodStart: "2024-03-01", PeriodEnd: "2024-03-31", EndingBalance: int64Ptr(31000),
+		})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if resp.OpeningBalance != 31000 || resp.Difference != 0 {
+			t.Errorf("esperava saldo inicial 31000 e diferença 0, mas obteve %+v", resp)
+		}
+	})
+}
+
+func TestService_Matching(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	account := &accounts.Account{ID: uuid.New(), UserID: userID, Currency: "BRL"}
+	at := func(date string) time.Time { return day(date).Add(15 * time.Hour) }
+	fitid := "20240305001"
+
+	// Dois lançamentos de -8000 perto da linha: só um tem a referência
+	rent := Candidate{ID: uuid.New(), Status: "posted", Amount: -8000, Description: "Aluguel", OccurredAt: at("2024-03-05")}
+	invoice := Candidate{ID: uuid.New(), Status: "posted", Amount: -8000, Description: "Fornecedor", OccurredAt: at("2024-03-07"), ExternalID: &fitid}
+	salary := Candidate{ID: uuid.New(), Status: "posted", Amount: 150000, Description: "Salário", OccurredAt: at("2024-03-02")}
+	fee := Candidate{ID: uuid.New(), Status: "posted", Amount: -1050, Description: "Tarifa", OccurredAt: at("2024-03-10")}
+	hold := Candidate{ID: uuid.New(), Status: "pending", Amount: -2000, Description: "Cartão", OccurredAt: at("2024-03-11")}
+
+	start := func(t *testing.T) (Service, *MockRepository, *ReconciliationResponse) {
+		repo := newMockRepository(rent, invoice, salary, fee, hold)
+		service := NewService(repo, &MockAccounts{account: account}, &MockTransactor{})
+		resp, err := service.StartReconciliation(ctx, account.ID.String(), userID.String(), StartReconciliationRequest{
+			PeriodStart:    "2024-03-01",
+			PeriodEnd:      "2024-03-31",
+			OpeningBalance: int64Ptr(10000),
+			EndingBalance:  int64Ptr(10000 + 150000 - 8000 - 8000 - 1000),
+			Lines: []LineRequest{
+				{Date: "2024-03-06", Amount: -8000, Reference: fitid},
+				{Date: "2024-03-06", Amount: -8000},
+				{Date: "2024-03-02", Amount: 150000},
+				{Date: "2024-03-10", Amount: -1000, Description: "Tarifa do mês"},
+			},
+		})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		return service, repo, resp
+	}
+
+	t.Run("deve casar pela referência antes do valor e da data", func(t *testing.T) {
+		service, _, started := start(t)
+
+		resp, err := service.AutoMatch(ctx, account.ID.String(), started.ID.String(), userID.String(), AutoMatchRequest{})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		want := []*uuid.UUID{&invoice.ID, &rent.ID, &salary.ID, nil}
+		for i, line := range resp.Lines {
+			switch {
+			case want[i] == nil && line.Movement != nil:
+				t.Errorf("linha %d: esperava nenhum lançamento, mas obteve %s", i, line.Movement.Description)
+			case want[i] != nil && (line.Movement == nil || line.Movement.ID != *want[i] || *line.Match != MatchAuto):
+				t.Errorf("linha %d: esperava o lançamento %s, mas obteve %+v", i, *want[i], line.Movement)
+			}
+		}
+		if resp.MatchedLines != 3 || resp.Difference != -1000 {
+			t.Errorf("totais incorretos: %+v", resp)
+		}
+
+		// A tarifa difere em 50 centavos: entra só com tolerância de valor
+		resp, err = service.AutoMatch(ctx, account.ID.String(), started.ID.String(), userID.String(), AutoMatchRequest{AmountTolerance: int64Ptr(50)})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if resp.Lines[3].Movement == nil || resp.Lines[3].Movement.ID != fee.ID {
+			t.Errorf("esperava casar a tarifa com tolerância, mas obteve %+v", resp.Lines[3])
+		}
+	})
+
+	t.Run("deve casar e desfazer à mão", func(t *testing.T) {
+		service, _, started := start(t)
+		feeLine := started.Lines[3].ID.String()
+		match := func(lineID string, movementID uuid.UUID) error {
+			_, err := service.MatchLine(ctx, account.ID.String(), started.ID.String(), lineID, userID.String(), MatchLineRequest{MovementID: movementID})
+			return err
+		}
+
+		cases := []struct {
+			movementID uuid.UUID
+			err        error
+		}{
+			{hold.ID, ErrMovementNotPosted},
+			{salary.ID, ErrSignMismatch},
+			{uuid.New(), movements.ErrMovementNotFound},
+		}
+		for _, c := range cases {
+			if err := match(feeLine, c.movementID); !errors.Is(err, c.err) {
+				t.Errorf("esperava o erro %v, mas obteve %v", c.err, err)
+			}
+		}
+
+		if err := match(feeLine, fee.ID); err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if err := match(feeLine, rent.ID); !errors.Is(err, ErrLineMatched) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrLineMatched, err)
+		}
+		if err := match(started.Lines[0].ID.String(), fee.ID); !errors.Is(err, ErrMovementMatched) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrMovementMatched, err)
+		}
+
+		resp, err := service.UnmatchLine(ctx, account.ID.String(), started.ID.String(), feeLine, userID.String())
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if resp.Lines[3].Movement != nil || resp.MatchedLines != 0 {
+			t.Errorf("esperava a linha sem lançamento, mas obteve %+v", resp.Lines[3])
+		}
+		_, err = service.UnmatchLine(ctx, account.ID.String(), started.ID.String(), feeLine, userID.String())
+		if !errors.Is(err, ErrLineNotMatched) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrLineNotMatched, err)
+		}
+	})
+
+	t.Run("deve finalizar só com diferença zero e travar os lançamentos", func(t *testing.T) {
+		service, repo, started := start(t)
+		if _, err := service.AutoMatch(ctx, account.ID.String(), started.ID.String(), userID.String(), AutoMatchRequest{}); err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+
+		_, err := service.FinalizeReconciliation(ctx, account.ID.String(), started.ID.String(), userID.String())
+		if !errors.Is(err, ErrUnbalanced) {
+			t.Fatalf("esperava o erro %v, mas obteve %v", ErrUnbalanced, err)
+		}
+		if len(repo.reconciled) != 0 {
+			t.Errorf("nenhum lançamento deveria ser travado: %+v", repo.reconciled)
+		}
+
+		// O saldo final foi informado errado por 50 centavos; corrigido, a tarifa
+		// casada à mão zera a diferença
+		repo.reconciliations[started.ID].EndingBalance -= 50
+		_, err = service.MatchLine(ctx, account.ID.String(), started.ID.String(), started.Lines[3].ID.String(), userID.String(), MatchLineRequest{MovementID: fee.ID})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		resp, err := service.FinalizeReconciliation(ctx, account.ID.String(), started.ID.String(), userID.String())
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if resp.Status != StatusFinalized || resp.FinalizedAt == nil || resp.Difference != 0 {
+			t.Errorf("conciliação não finalizada: %+v", resp)
+		}
+		if len(repo.reconciled) != 4 {
+			t.Errorf("esperava 4 lançamentos travados, mas obteve %d", len(repo.reconciled))
+		}
+
+		_, err = service.UnmatchLine(ctx, account.ID.String(), started.ID.String(), started.Lines[3].ID.String(), userID.String())
+		if !errors.Is(err, ErrReconciliationFinalized) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrReconciliationFinalized, err)
+		}
+		err = service.DeleteReconciliation(ctx, account.ID.String(), started.ID.String(), userID.String())
+		if !errors.Is(err, ErrReconciliationFinalized) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrReconciliationFinalized, err)
+		}
+	})
+}
@@ -0,0 +1,153 @@
+package reports
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/auth"
+	"github.com/rs/zerolog/log"
+)
+
+type Handler struct {
+	service Service
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("Falha ao escrever resposta JSON")
+	}
+}
+
+func (h *Handler) getUserIDFromContext(r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(auth.UserContextKey).(string)
+	if !ok {
+		log.Error().Msg("UserID não encontrado no contexto, middleware mal configurado")
+		return "", false
+	}
+	return userID, true
+}
+
+// HandleGetFXRevaluation responde GET /reports/fx-revaluation?date=2024-01-31 com
+// os ajustes cambiais gravados para as contas do usuário no dia; um dia ainda
+// não reavaliado é calculado pelo POST
+func (h *Handler) HandleGetFXRevaluation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	raw := r.URL.Query().Get("date")
+	if raw == "" {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "date is required"})
+		return
+	}
+	date, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid date: expected YYYY-MM-DD"})
+		return
+	}
+
+	report, err := h.service.GetFXRevaluation(r.Context(), userID, date)
+	if err != nil {
+		log.Error().Err(err).Msg("Falha ao gerar relatório de reavaliação cambial")
+		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to retrieve fx revaluation"})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, report)
+}
+
+// HandlePostFXRevaluation responde POST /reports/fx-revaluation?date=2024-01-31:
+// reavalia na hora as contas do usuário ainda sem reavaliação no dia (fechado)
+// e devolve o relatório do dia
+func (h *Handler) HandlePostFXRevaluation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	raw := r.URL.Query().Get("date")
+	if raw == "" {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "date is required"})
+		return
+	}
+	date, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid date: expected YYYY-MM-DD"})
+		return
+	}
+
+	report, err := h.service.RevalueForUser(r.Context(), userID, date)
+	if err != nil {
+		if errors.Is(err, ErrDayNotClosed) {
+			h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "date must be a closed day"})
+			return
+		}
+		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to revalue accounts"})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, report)
+}
+
+// HandleGetCategoryReport responde GET /reports/categories?from=2024-01-01&to=2024-01-31
+// (dias inclusivos) com os totais por categoria; account_id limita a uma conta
+func (h *Handler) HandleGetCategoryReport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	q := r.URL.Query()
+	var filter CategoryReportFilter
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		raw := q.Get(p.name)
+		if raw == "" {
+			h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": p.name + " is required"})
+			return
+		}
+		date, err := time.Parse(time.DateOnly, raw)
+		if err != nil {
+			h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid " + p.name + ": expected YYYY-MM-DD"})
+			return
+		}
+		*p.dst = date
+	}
+	if raw := q.Get("account_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid account_id"})
+			return
+		}
+		filter.AccountID = &id
+	}
+
+	report, err := h.service.GetCategoryReport(r.Context(), userID, filter)
+	if err != nil {
+		if errors.Is(err, ErrInvalidPeriod) {
+			h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "from must not be after to"})
+			return
+		}
+		log.Error().Err(err).Msg("Falha ao gerar relatório por categoria")
+		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to retrieve category report"})
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,32 @@
+package reports
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// RunFXRevaluation reavalia o dia anterior (UTC) a cada interval, até ctx ser
+// cancelado. Contas já reavaliadas no dia são puladas, então várias réplicas
+// podem rodar o job ao mesmo tempo e uma execução perdida é refeita na próxima.
+func RunFXRevaluation(ctx context.Context, svc Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		date := time.Now().UTC().AddDate(0, 0, -1)
+		created, err := svc.RevalueAll(ctx, date)
+		if err != nil {
+			log.Error().Err(err).Time("date", date).Msg("Failed to run FX revaluation")
+		} else {
+			log.Info().Int("revaluations", created).Time("date", date).Msg("FX revaluation finished")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package reports
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/pkg/money"
+)
+
+// Revaluation é a reavaliação cambial de uma conta no fim do dia Date. Balance
+// está na moeda da conta; RevaluedAmount, CarryingAmount e Adjustment estão na
+// moeda funcional. Adjustment = RevaluedAmount - CarryingAmount.
+type Revaluation struct {
+	ID                 uuid.UUID
+	AccountID          uuid.UUID
+	AccountName        string // preenchido só na listagem
+	Date               time.Time
+	Currency           string
+	FunctionalCurrency string
+	Balance            int64
+	Rate               float64
+	RateDate           time.Time
+	RevaluedAmount     int64
+	CarryingAmount     int64
+	Adjustment         int64
+	CreatedAt          time.Time
+}
+
+// RevaluationAccount é uma conta em moeda estrangeira a reavaliar
+type RevaluationAccount struct {
+	ID       uuid.UUID
+	Currency string
+}
+
+// DailyAmount é a soma dos movimentos de uma conta num dia (UTC)
+type DailyAmount struct {
+	Day    time.Time
+	Amount int64
+}
+
+type RevaluationResponse struct {
+	AccountID      uuid.UUID   `json:"account_id"`
+	AccountName    string      `json:"account_name"`
+	Balance        money.Money `json:"balance"`
+	Rate           float64     `json:"rate"`
+	RateDate       string      `json:"rate_date"`
+	RevaluedAmount money.Money `json:"revalued_amount"`
+	CarryingAmount money.Money `json:"carrying_amount"`
+	Adjustment     money.Money `json:"adjustment"`
+}
+
+// FXRevaluationReport lista os ajustes cambiais do dia na moeda funcional
+type FXRevaluationReport struct {
+	Date               string                `json:"date"`
+	FunctionalCurrency string                `json:"functional_currency"`
+	Adjustments        []RevaluationResponse `json:"adjustments"`
+	TotalRevalued      money.Money           `json:"total_revalued"`
+	TotalAdjustment    money.Money           `json:"total_adjustment"`
+}
+
+// CategorySum é o total de uma categoria numa moeda. Lançamentos divididos
+// entram pelas suas linhas; CategoryID nil agrupa o que está sem categoria.
+type CategorySum struct {
+	CategoryID *uuid.UUID
+	Name       *string
+	ParentID   *uuid.UUID
+	Kind       *string
+	Currency   string
+	Amount     int64
+	Lines      int
+}
+
+// CategoryReportFilter define o período (dias UTC, ambos inclusivos) e, se
+// AccountID vier, limita a uma conta
+type CategoryReportFilter struct {
+	From      time.Time
+	To        time.Time
+	AccountID *uuid.UUID
+}
+
+type CategoryTotal struct {
+	CategoryID *uuid.UUID  `json:"category_id"`
+	Name       string      `json:"name"`
+	ParentID   *uuid.UUID  `json:"parent_id"`
+	Kind       string      `json:"kind,omitempty"`
+	Total      money.Money `json:"total"`
+	Lines      int         `json:"lines"`
+}
+
+// CategoryReport soma os lançamentos do período por categoria, em cada moeda
+type CategoryReport struct {
+	From       string          `json:"from"`
+	To         string          `json:"to"`
+	Categories []CategoryTotal `json:"categories"`
+}
@@ -0,0 +1,301 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/martinsdevv/fincore/pkg/database"
+)
+
+type Repository interface {
+	// ListRevaluationAccounts devolve as contas ativas fora da moeda funcional
+	// abertas até before e ainda sem reavaliação no dia date; com userID, só
+	// as contas de que o usuário é membro
+	ListRevaluationAccounts(ctx context.Context, functional string, date time.Time, before time.Time, userID *uuid.UUID) ([]RevaluationAccount, error)
+	// LatestRevaluation devolve a última reavaliação da conta anterior a date
+	LatestRevaluation(ctx context.Context, accountID uuid.UUID, date time.Time) (*Revaluation, error)
+	// ListRevaluationDatesAfter devolve, em ordem, os dias reavaliados da conta depois de date
+	ListRevaluationDatesAfter(ctx context.Context, accountID uuid.UUID, date time.Time) ([]time.Time, error)
+	// BalanceBefore soma os movimentos da conta com occurred_at < before
+	BalanceBefore(ctx context.Context, accountID uuid.UUID, before time.Time) (int64, error)
+	// DailyAmountsSince soma, por dia, os movimentos com occurred_at < before que
+	// ainda não entraram em prev: os posteriores a prevBefore e os retroativos
+	// gravados depois de prev. Sem prev, todos.
+	DailyAmountsSince(ctx context.Context, accountID uuid.UUID, prev *Revaluation, prevBefore time.Time, before time.Time) ([]DailyAmount, error)
+	// CreateRevaluation grava a reavaliação; devolve false se a conta já tinha uma no dia
+	CreateRevaluation(ctx context.Context, rev *Revaluation) (bool, error)
+	// UpdateRevaluation refaz os valores da reavaliação da conta no dia rev.Date
+	UpdateRevaluation(ctx context.Context, rev *Revaluation) error
+	// ListRevaluations devolve as reavaliações do dia das contas de que o usuário é membro
+	ListRevaluations(ctx context.Context, userID uuid.UUID, date time.Time) ([]Revaluation, error)
+
+	// SumByCategory soma por categoria e moeda os lançamentos das contas do
+	// usuário com from <= occurred_at < before, trocando os divididos pelas linhas
+	SumByCategory(ctx context.Context, userID uuid.UUID, from time.Time, before time.Time, accountID *uuid.UUID) ([]CategorySum, error)
+}
+
+type pgxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgxRepository{db: db}
+}
+
+// conn usa a transação aberta por um database.Transactor, se houver
+func (r *pgxRepository) conn(ctx context.Context) database.DBTX {
+	return database.Conn(ctx, r.db)
+}
+
+// Colunas lidas por scanRevaluation, sempre nesta ordem
+const revaluationColumns = `v.id, v.account_id, v.rev_date, v.currency, v.functional_currency, v.balance,
+	v.rate::float8, v.rate_date, v.revalued_amount, v.carrying_amount, v.adjustment, v.created_at`
+
+func scanRevaluation(row pgx.Row, v *Revaluation, extra ...interface{}) error {
+	dest := append([]interface{}{
+		&v.ID,
+		&v.AccountID,
+		&v.Date,
+		&v.Currency,
+		&v.FunctionalCurrency,
+		&v.Balance,
+		&v.Rate,
+		&v.RateDate,
+		&v.RevaluedAmount,
+		&v.CarryingAmount,
+		&v.Adjustment,
+		&v.CreatedAt,
+	}, extra...)
+	return row.Scan(dest...)
+}
+
+func (r *pgxRepository) ListRevaluationAccounts(ctx context.Context, functional string, date time.Time, before time.Time, userID *uuid.UUID) ([]RevaluationAccount, error) {
+	query := `
+		SELECT a.id, a.currency
+		FROM accounts a
+		WHERE a.currency <> $1 AND a.created_at < $3 AND a.archived_at IS NULL
+		  AND ($4::uuid IS NULL OR EXISTS (
+			SELECT 1 FROM account_members am WHERE am.account_id = a.id AND am.user_id = $4
+		  ))
+		  AND NOT EXISTS (
+			SELECT 1 FROM fx_revaluations v WHERE v.account_id = a.id AND v.rev_date = $2
+		  )
+		ORDER BY a.id`
+
+	rows, err := r.conn(ctx).Query(ctx, query, functional, date, before, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []RevaluationAccount
+	for rows.Next() {
+		var a RevaluationAccount
+		if err := rows.Scan(&a.ID, &a.Currency); err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, a)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *pgxRepository) LatestRevaluation(ctx context.Context, accountID uuid.UUID, date time.Time) (*Revaluation, error) {
+	query := `
+		SELECT ` + revaluationColumns + `
+		FROM fx_revaluations v
+		WHERE v.account_id = $1 AND v.rev_date < $2
+		ORDER BY v.rev_date DESC
+		LIMIT 1`
+
+	var v Revaluation
+	if err := scanRevaluation(r.conn(ctx).QueryRow(ctx, query, accountID, date), &v); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *pgxRepository) ListRevaluationDatesAfter(ctx context.Context, accountID uuid.UUID, date time.Time) ([]time.Time, error) {
+	query := `SELECT rev_date FROM fx_revaluations WHERE account_id = $1 AND rev_date > $2 ORDER BY rev_date`
+
+	rows, err := r.conn(ctx).Query(ctx, query, accountID, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dates []time.Time
+	for rows.Next() {
+		var d time.Time
+		if err := rows.Scan(&d); err != nil {
+			return nil, err
+		}
+		dates = append(dates, d)
+	}
+	return dates, rows.Err()
+}
+
+func (r *pgxRepository) BalanceBefore(ctx context.Context, accountID uuid.UUID, before time.Time) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(amount), 0)::BIGINT
+		FROM movements
+		WHERE account_id = $1 AND status = 'posted' AND occurred_at < $2`
+
+	var balance int64
+	err := r.conn(ctx).QueryRow(ctx, query, accountID, before).Scan(&balance)
+	return balance, err
+}
+
+func (r *pgxRepository) DailyAmountsSince(ctx context.Context, accountID uuid.UUID, prev *Revaluation, prevBefore time.Time, before time.Time) ([]DailyAmount, error) {
+	var prevCreatedAt *time.Time
+	var since *time.Time
+	if prev != nil {
+		prevCreatedAt, since = &prev.CreatedAt, &prevBefore
+	}
+
+	query := `
+		SELECT (occurred_at AT TIME ZONE 'UTC')::date AS day, SUM(amount)::BIGINT
+		FROM movements
+		WHERE account_id = $1 AND status = 'posted' AND occurred_at < $2
+		  AND ($3::timestamptz IS NULL OR occurred_at >= $3 OR created_at > $4)
+		GROUP BY day
+		ORDER BY day`
+
+	rows, err := r.conn(ctx).Query(ctx, query, accountID, before, since, prevCreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var amounts []DailyAmount
+	for rows.Next() {
+		var d DailyAmount
+		if err := rows.Scan(&d.Day, &d.Amount); err != nil {
+			return nil, err
+		}
+		amounts = append(amounts, d)
+	}
+	return amounts, rows.Err()
+}
+
+func (r *pgxRepository) CreateRevaluation(ctx context.Context, v *Revaluation) (bool, error) {
+	query := `
+		INSERT INTO fx_revaluations (
+			id, account_id, rev_date, currency, functional_currency, balance, rate,
+			rate_date, revalued_amount, carrying_amount, adjustment, created_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (account_id, rev_date) DO NOTHING`
+
+	tag, err := r.conn(ctx).Exec(ctx, query,
+		v.ID,
+		v.AccountID,
+		v.Date,
+		v.Currency,
+		v.FunctionalCurrency,
+		v.Balance,
+		v.Rate,
+		v.RateDate,
+		v.RevaluedAmount,
+		v.CarryingAmount,
+		v.Adjustment,
+		v.CreatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *pgxRepository) UpdateRevaluation(ctx context.Context, v *Revaluation) error {
+	query := `
+		UPDATE fx_revaluations
+		SET currency = $3, functional_currency = $4, balance = $5, rate = $6, rate_date = $7,
+			revalued_amount = $8, carrying_amount = $9, adjustment = $10, created_at = $11
+		WHERE account_id = $1 AND rev_date = $2`
+
+	_, err := r.conn(ctx).Exec(ctx, query,
+		v.AccountID,
+		v.Date,
+		v.Currency,
+		v.FunctionalCurrency,
+		v.Balance,
+		v.Rate,
+		v.RateDate,
+		v.RevaluedAmount,
+		v.CarryingAmount,
+		v.Adjustment,
+		v.CreatedAt,
+	)
+	return err
+}
+
+func (r *pgxRepository) ListRevaluations(ctx context.Context, userID uuid.UUID, date time.Time) ([]Revaluation, error) {
+	query := `
+		SELECT ` + revaluationColumns + `, a.name
+		FROM fx_revaluations v
+		JOIN accounts a ON a.id = v.account_id
+		JOIN account_members am ON am.account_id = a.id AND am.user_id = $1
+		WHERE v.rev_date = $2
+		ORDER BY a.name, a.id`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID, date)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revaluations []Revaluation
+	for rows.Next() {
+		var v Revaluation
+		if err := scanRevaluation(rows, &v, &v.AccountName); err != nil {
+			return nil, err
+		}
+		revaluations = append(revaluations, v)
+	}
+	return revaluations, rows.Err()
+}
+
+func (r *pgxRepository) SumByCategory(ctx context.Context, userID uuid.UUID, from time.Time, before time.Time, accountID *uuid.UUID) ([]CategorySum, error) {
+	// Saldos iniciais e transferências não são receita nem despesa
+	query := `
+		WITH lines AS (
+			SELECT m.account_id,
+				CASE WHEN s.id IS NULL THEN m.category_id ELSE s.category_id END AS category_id,
+				COALESCE(s.amount, m.amount) AS amount
+			FROM movements m
+			LEFT JOIN movement_splits s ON s.movement_id = m.id
+			WHERE m.kind NOT IN ('opening', 'transfer') AND m.status = 'posted'
+			  AND m.occurred_at >= $2 AND m.occurred_at < $3
+			  AND ($4::uuid IS NULL OR m.account_id = $4)
+		)
+		SELECT l.category_id, c.name, c.parent_id, c.kind, a.currency, SUM(l.amount)::BIGINT, COUNT(*)
+		FROM lines l
+		JOIN accounts a ON a.id = l.account_id
+		JOIN account_members am ON am.account_id = a.id AND am.user_id = $1
+		LEFT JOIN categories c ON c.id = l.category_id
+		GROUP BY l.category_id, c.name, c.parent_id, c.kind, a.currency
+		ORDER BY a.currency, SUM(l.amount), c.name`
+
+	rows, err := r.conn(ctx).Query(ctx, query, userID, from, before, accountID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sums []CategorySum
+	for rows.Next() {
+		var cs CategorySum
+		if err := rows.Scan(&cs.CategoryID, &cs.Name, &cs.ParentID, &cs.Kind, &cs.Currency, &cs.Amount, &cs.Lines); err != nil {
+			return nil, err
+		}
+		sums = append(sums, cs)
+	}
+	return sums, rows.Err()
+}
@@ -0,0 +1,9 @@
+package reports
+
+import "github.com/go-chi/chi/v5"
+
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/reports/fx-revaluation", h.HandleGetFXRevaluation)
+	r.Post("/reports/fx-revaluation", h.HandlePostFXRevaluation)
+	r.Get("/reports/categories", h.HandleGetCategoryReport)
+}
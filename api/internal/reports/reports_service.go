@@ -0,0 +1,292 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/fx"
+	"github.com/martinsdevv/fincore/pkg/external/fxrates"
+	"github.com/martinsdevv/fincore/pkg/money"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrDayNotClosed  = errors.New("revaluation date must be a closed day")
+	ErrInvalidPeriod = errors.New("from must not be after to")
+)
+
+type Service interface {
+	// RevalueAll reavalia, no fim do dia date (UTC), todas as contas fora da moeda
+	// funcional que ainda não foram reavaliadas nesse dia. Devolve quantas
+	// reavaliações foram gravadas.
+	RevalueAll(ctx context.Context, date time.Time) (int, error)
+	// RevalueForUser faz o mesmo na hora, só com as contas do usuário, e
+	// devolve o relatório do dia
+	RevalueForUser(ctx context.Context, userID string, date time.Time) (*FXRevaluationReport, error)
+	GetFXRevaluation(ctx context.Context, userID string, date time.Time) (*FXRevaluationReport, error)
+	GetCategoryReport(ctx context.Context, userID string, filter CategoryReportFilter) (*CategoryReport, error)
+}
+
+type service struct {
+	repo       Repository
+	fx         fx.Service
+	functional string
+}
+
+func NewService(repo Repository, fxSvc fx.Service, functionalCurrency string) Service {
+	return &service{repo: repo, fx: fxSvc, functional: functionalCurrency}
+}
+
+func (s *service) RevalueAll(ctx context.Context, date time.Time) (int, error) {
+	return s.revalueDay(ctx, date, nil)
+}
+
+func (s *service) RevalueForUser(ctx context.Context, userIDStr string, date time.Time) (*FXRevaluationReport, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.revalueDay(ctx, date, &userID); err != nil {
+		if !errors.Is(err, ErrDayNotClosed) {
+			log.Error().Err(err).Str("userID", userIDStr).Time("date", date).Msg("Failed to revalue accounts")
+		}
+		return nil, err
+	}
+	return s.GetFXRevaluation(ctx, userIDStr, date)
+}
+
+func (s *service) revalueDay(ctx context.Context, date time.Time, userID *uuid.UUID) (int, error) {
+	day := fxrates.Day(date)
+	if !day.Before(fxrates.Day(time.Now())) {
+		return 0, ErrDayNotClosed
+	}
+	before := day.AddDate(0, 0, 1)
+
+	accounts, err := s.repo.ListRevaluationAccounts(ctx, s.functional, day, before, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	rates := make(rateCache)
+	created := 0
+	for _, account := range accounts {
+		revs, err := s.revalueFrom(ctx, rates, account, day)
+		if err != nil {
+			// Sem cotação a conta fica para a próxima execução; o resto segue
+			if errors.Is(err, fxrates.ErrRateUnavailable) {
+				log.Warn().Err(err).Str("account_id", account.ID.String()).Msg("Skipping FX revaluation")
+				continue
+			}
+			return created, err
+		}
+
+		ok, err := s.repo.CreateRevaluation(ctx, revs[0])
+		if err != nil {
+			return created, err
+		}
+		if !ok {
+			continue
+		}
+		created++
+		for _, rev := range revs[1:] {
+			if err := s.repo.UpdateRevaluation(ctx, rev); err != nil {
+				return created, err
+			}
+		}
+	}
+	return created, nil
+}
+
+// revalueFrom reavalia a conta no dia day e refaz, em ordem, as reavaliações
+// que ela já tem depois dele: cada uma parte do valor reavaliado da anterior,
+// então um dia preenchido para trás muda o custo histórico das seguintes.
+// Tudo é calculado antes de gravar, para uma cotação faltando não deixar a
+// sequência pela metade.
+func (s *service) revalueFrom(ctx context.Context, rates rateCache, account RevaluationAccount, day time.Time) ([]*Revaluation, error) {
+	prev, err := s.repo.LatestRevaluation(ctx, account.ID, day)
+	if err != nil {
+		return nil, err
+	}
+	later, err := s.repo.ListRevaluationDatesAfter(ctx, account.ID, day)
+	if err != nil {
+		return nil, err
+	}
+
+	revs := make([]*Revaluation, 0, len(later)+1)
+	for _, d := range append([]time.Time{day}, later...) {
+		rev, err := s.revalue(ctx, rates, account, prev, fxrates.Day(d))
+		if err != nil {
+			return nil, err
+		}
+		revs = append(revs, rev)
+		prev = rev
+	}
+	return revs, nil
+}
+
+// revalue compara o saldo da conta convertido pela cotação do dia (revalued) com
+// o custo histórico na moeda funcional (carrying): o valor reavaliado anterior
+// (prev) mais cada movimento novo convertido pela cotação do dia em que ocorreu
+func (s *service) revalue(ctx context.Context, rates rateCache, account RevaluationAccount, prev *Revaluation, day time.Time) (*Revaluation, error) {
+	carrying := money.New(0, s.functional)
+	var prevBefore time.Time
+	if prev != nil {
+		carrying = money.New(prev.RevaluedAmount, s.functional)
+		prevBefore = prev.Date.AddDate(0, 0, 1)
+	}
+
+	daily, err := s.repo.DailyAmountsSince(ctx, account.ID, prev, prevBefore, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range daily {
+		rate, err := s.rate(ctx, rates, account.Currency, d.Day)
+		if err != nil {
+			return nil, err
+		}
+		converted, err := money.New(d.Amount, account.Currency).Convert(rate.Value, s.functional)
+		if err != nil {
+			return nil, err
+		}
+		if carrying, err = carrying.Add(converted); err != nil {
+			return nil, err
+		}
+	}
+
+	balance, err := s.repo.BalanceBefore(ctx, account.ID, day.AddDate(0, 0, 1))
+	if err != nil {
+		return nil, err
+	}
+	rate, err := s.rate(ctx, rates, account.Currency, day)
+	if err != nil {
+		return nil, err
+	}
+	revalued, err := money.New(balance, account.Currency).Convert(rate.Value, s.functional)
+	if err != nil {
+		return nil, err
+	}
+	adjustment, err := revalued.Sub(carrying)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Revaluation{
+		ID:                 uuid.New(),
+		AccountID:          account.ID,
+		Date:               day,
+		Currency:           account.Currency,
+		FunctionalCurrency: s.functional,
+		Balance:            balance,
+		Rate:               rate.Value,
+		RateDate:           rate.Date,
+		RevaluedAmount:     revalued.Amount,
+		CarryingAmount:     carrying.Amount,
+		Adjustment:         adjustment.Amount,
+		CreatedAt:          time.Now(),
+	}, nil
+}
+
+// rateCache evita buscar de novo a mesma cotação para cada conta da execução
+type rateCache map[string]*fxrates.Rate
+
+func (s *service) rate(ctx context.Context, cache rateCache, currency string, day time.Time) (*fxrates.Rate, error) {
+	key := currency + ":" + day.Format(time.DateOnly)
+	if rate, ok := cache[key]; ok {
+		return rate, nil
+	}
+	rate, err := s.fx.Rate(ctx, currency, s.functional, day)
+	if err != nil {
+		return nil, err
+	}
+	cache[key] = rate
+	return rate, nil
+}
+
+func (s *service) GetFXRevaluation(ctx context.Context, userIDStr string, date time.Time) (*FXRevaluationReport, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	day := fxrates.Day(date)
+	revaluations, err := s.repo.ListRevaluations(ctx, userID, day)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &FXRevaluationReport{
+		Date:               day.Format(time.DateOnly),
+		FunctionalCurrency: s.functional,
+		Adjustments:        make([]RevaluationResponse, 0, len(revaluations)),
+		TotalRevalued:      money.New(0, s.functional),
+		TotalAdjustment:    money.New(0, s.functional),
+	}
+	for _, v := range revaluations {
+		// O relatório soma numa só moeda; reavaliações feitas com outra moeda
+		// funcional ficam de fora do total
+		if v.FunctionalCurrency != s.functional {
+			continue
+		}
+		item := RevaluationResponse{
+			AccountID:      v.AccountID,
+			AccountName:    v.AccountName,
+			Balance:        money.New(v.Balance, v.Currency),
+			Rate:           v.Rate,
+			RateDate:       v.RateDate.Format(time.DateOnly),
+			RevaluedAmount: money.New(v.RevaluedAmount, v.FunctionalCurrency),
+			CarryingAmount: money.New(v.CarryingAmount, v.FunctionalCurrency),
+			Adjustment:     money.New(v.Adjustment, v.FunctionalCurrency),
+		}
+		if report.TotalRevalued, err = report.TotalRevalued.Add(item.RevaluedAmount); err != nil {
+			return nil, fmt.Errorf("summing revaluations: %w", err)
+		}
+		if report.TotalAdjustment, err = report.TotalAdjustment.Add(item.Adjustment); err != nil {
+			return nil, fmt.Errorf("summing revaluations: %w", err)
+		}
+		report.Adjustments = append(report.Adjustments, item)
+	}
+	return report, nil
+}
+
+func (s *service) GetCategoryReport(ctx context.Context, userIDStr string, filter CategoryReportFilter) (*CategoryReport, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := fxrates.Day(filter.From), fxrates.Day(filter.To)
+	if from.After(to) {
+		return nil, ErrInvalidPeriod
+	}
+
+	sums, err := s.repo.SumByCategory(ctx, userID, from, to.AddDate(0, 0, 1), filter.AccountID)
+	if err != nil {
+		log.Error().Err(err).Str("userID", userIDStr).Msg("Failed to sum movements by category")
+		return nil, err
+	}
+
+	report := &CategoryReport{
+		From:       from.Format(time.DateOnly),
+		To:         to.Format(time.DateOnly),
+		Categories: make([]CategoryTotal, 0, len(sums)),
+	}
+	for _, cs := range sums {
+		total := CategoryTotal{
+			CategoryID: cs.CategoryID,
+			ParentID:   cs.ParentID,
+			Total:      money.New(cs.Amount, cs.Currency),
+			Lines:      cs.Lines,
+		}
+		if cs.Name != nil {
+			total.Name = *cs.Name
+		}
+		if cs.Kind != nil {
+			total.Kind = *cs.Kind
+		}
+		report.Categories = append(report.Categories, total)
+	}
+	return report, nil
+}
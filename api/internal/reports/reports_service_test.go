@@ -0,0 +1,312 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/fx"
+	"github.com/martinsdevv/fincore/pkg/external/fxrates"
+)
+
+type MockRepository struct {
+	ListRevaluationAccountsFunc   func(ctx context.Context, functional string, date time.Time, before time.Time, userID *uuid.UUID) ([]RevaluationAccount, error)
+	LatestRevaluationFunc         func(ctx context.Context, accountID uuid.UUID, date time.Time) (*Revaluation, error)
+	ListRevaluationDatesAfterFunc func(ctx context.Context, accountID uuid.UUID, date time.Time) ([]time.Time, error)
+	BalanceBeforeFunc             func(ctx context.Context, accountID uuid.UUID, before time.Time) (int64, error)
+	DailyAmountsSinceFunc         func(ctx context.Context, accountID uuid.UUID, prev *Revaluation, prevBefore time.Time, before time.Time) ([]DailyAmount, error)
+	CreateRevaluationFunc         func(ctx context.Context, rev *Revaluation) (bool, error)
+	UpdateRevaluationFunc         func(ctx context.Context, rev *Revaluation) error
+	ListRevaluationsFunc          func(ctx context.Context, userID uuid.UUID, date time.Time) ([]Revaluation, error)
+	SumByCategoryFunc             func(ctx context.Context, userID uuid.UUID, from time.Time, before time.Time, accountID *uuid.UUID) ([]CategorySum, error)
+}
+
+func (m *MockRepository) ListRevaluationAccounts(ctx context.Context, functional string, date time.Time, before time.Time, userID *uuid.UUID) ([]RevaluationAccount, error) {
+	return m.ListRevaluationAccountsFunc(ctx, functional, date, before, userID)
+}
+
+func (m *MockRepository) LatestRevaluation(ctx context.Context, accountID uuid.UUID, date time.Time) (*Revaluation, error) {
+	if m.LatestRevaluationFunc != nil {
+		return m.LatestRevaluationFunc(ctx, accountID, date)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) ListRevaluationDatesAfter(ctx context.Context, accountID uuid.UUID, date time.Time) ([]time.Time, error) {
+	if m.ListRevaluationDatesAfterFunc != nil {
+		return m.ListRevaluationDatesAfterFunc(ctx, accountID, date)
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) BalanceBefore(ctx context.Context, accountID uuid.UUID, before time.Time) (int64, error) {
+	return m.BalanceBeforeFunc(ctx, accountID, before)
+}
+
+func (m *MockRepository) DailyAmountsSince(ctx context.Context, accountID uuid.UUID, prev *Revaluation, prevBefore time.Time, before time.Time) ([]DailyAmount, error) {
+	return m.DailyAmountsSinceFunc(ctx, accountID, prev, prevBefore, before)
+}
+
+func (m *MockRepository) CreateRevaluation(ctx context.Context, rev *Revaluation) (bool, error) {
+	if m.CreateRevaluationFunc != nil {
+		return m.CreateRevaluationFunc(ctx, rev)
+	}
+	return true, nil
+}
+
+func (m *MockRepository) UpdateRevaluation(ctx context.Context, rev *Revaluation) error {
+	if m.UpdateRevaluationFunc != nil {
+		return m.UpdateRevaluationFunc(ctx, rev)
+	}
+	return nil
+}
+
+func (m *MockRepository) ListRevaluations(ctx context.Context, userID uuid.UUID, date time.Time) ([]Revaluation, error) {
+	return m.ListRevaluationsFunc(ctx, userID, date)
+}
+
+func (m *MockRepository) SumByCategory(ctx context.Context, userID uuid.UUID, from time.Time, before time.Time, accountID *uuid.UUID) ([]CategorySum, error) {
+	return m.SumByCategoryFunc(ctx, userID, from, before, accountID)
+}
+
+// MockFX implementa só as cotações de fx.Service: table[moeda][dia] em BRL
+type MockFX struct {
+	fx.Service
+	table map[string]map[string]float64
+}
+
+func (m *MockFX) Rate(ctx context.Context, from string, to string, date time.Time) (*fxrates.Rate, error) {
+	value, ok := m.table[from][date.Format(time.DateOnly)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s to %s", fxrates.ErrRateUnavailable, from, to)
+	}
+	return &fxrates.Rate{Base: from, Quote: to, Value: value, Date: date}, nil
+}
+
+func day(s string) time.Time {
+	d, _ := time.Parse(time.DateOnly, s)
+	return d
+}
+
+func TestService_RevalueAll(t *testing.T) {
+	ctx := context.Background()
+	usd := RevaluationAccount{ID: uuid.New(), Currency: "USD"}
+	eur := RevaluationAccount{ID: uuid.New(), Currency: "EUR"}
+	rates := &MockFX{table: map[string]map[string]float64{
+		"USD": {"2024-01-01": 5.0, "2024-01-02": 5.2},
+	}}
+
+	t.Run("deve reavaliar o saldo e somar os movimentos novos pelo custo histórico", func(t *testing.T) {
+		prev := &Revaluation{AccountID: usd.ID, Date: day("2024-01-01"), RevaluedAmount: 50000, CreatedAt: time.Now()}
+		var saved []*Revaluation
+
+		repo := &MockRepository{
+			ListRevaluationAccountsFunc: func(ctx context.Context, functional string, date time.Time, before time.Time, userID *uuid.UUID) ([]RevaluationAccount, error) {
+				if functional != "BRL" || !date.Equal(day("2024-01-02")) || !before.Equal(day("2024-01-03")) || userID != nil {
+					t.Errorf("parâmetros incorretos: %s %s %s %v", functional, date, before, userID)
+				}
+				return []RevaluationAccount{usd, eur}, nil
+			},
+			LatestRevaluationFunc: func(ctx context.Context, accountID uuid.UUID, date time.Time) (*Revaluation, error) {
+				if accountID == usd.ID {
+					return prev, nil
+				}
+				return nil, nil
+			},
+			DailyAmountsSinceFunc: func(ctx context.Context, accountID uuid.UUID, p *Revaluation, prevBefore time.Time, before time.Time) ([]DailyAmount, error) {
+				if accountID == usd.ID && (p != prev || !prevBefore.Equal(day("2024-01-02"))) {
+					t.Errorf("reavaliação anterior incorreta: %+v %s", p, prevBefore)
+				}
+				// +10,00 USD no dia 2
+				return []DailyAmount{{Day: day("2024-01-02"), Amount: 1000}}, nil
+			},
+			BalanceBeforeFunc: func(ctx context.Context, accountID uuid.UUID, before time.Time) (int64, error) {
+				return 11000, nil
+			},
+			CreateRevaluationFunc: func(ctx context.Context, rev *Revaluation) (bool, error) {
+				saved = append(saved, rev)
+				return true, nil
+			},
+		}
+		service := NewService(repo, rates, "BRL")
+
+		created, err := service.RevalueAll(ctx, day("2024-01-02"))
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		// A conta em EUR não tem cotação e fica para depois
+		if created != 1 || len(saved) != 1 {
+			t.Fatalf("esperava 1 reavaliação, obteve %d", created)
+		}
+
+		// 110 USD * 5.2 = 572,00; custo = 500,00 + 10 * 5.2 = 552,00
+		rev := saved[0]
+		if rev.RevaluedAmount != 57200 || rev.CarryingAmount != 55200 || rev.Adjustment != 2000 {
+			t.Errorf("reavaliação incorreta: %+v", rev)
+		}
+		if rev.Balance != 11000 || rev.Rate != 5.2 || rev.FunctionalCurrency != "BRL" || !rev.Date.Equal(day("2024-01-02")) {
+			t.Errorf("reavaliação incorreta: %+v", rev)
+		}
+	})
+
+	t.Run("deve refazer as reavaliações seguintes ao preencher um dia para trás", func(t *testing.T) {
+		var created *Revaluation
+		var updated []*Revaluation
+
+		repo := &MockRepository{
+			ListRevaluationAccountsFunc: func(ctx context.Context, functional string, date time.Time, before time.Time, userID *uuid.UUID) ([]RevaluationAccount, error) {
+				return []RevaluationAccount{usd}, nil
+			},
+			ListRevaluationDatesAfterFunc: func(ctx context.Context, accountID uuid.UUID, date time.Time) ([]time.Time, error) {
+				return []time.Time{day("2024-01-02")}, nil
+			},
+			DailyAmountsSinceFunc: func(ctx context.Context, accountID uuid.UUID, p *Revaluation, prevBefore time.Time, before time.Time) ([]DailyAmount, error) {
+				// 100 USD no dia 1, nada no dia 2
+				if p == nil {
+					return []DailyAmount{{Day: day("2024-01-01"), Amount: 10000}}, nil
+				}
+				return nil, nil
+			},
+			BalanceBeforeFunc: func(ctx context.Context, accountID uuid.UUID, before time.Time) (int64, error) {
+				return 10000, nil
+			},
+			CreateRevaluationFunc: func(ctx context.Context, rev *Revaluation) (bool, error) {
+				created = rev
+				return true, nil
+			},
+			UpdateRevaluationFunc: func(ctx context.Context, rev *Revaluation) error {
+				updated = append(updated, rev)
+				return nil
+			},
+		}
+		service := NewService(repo, rates, "BRL")
+
+		if _, err := service.RevalueAll(ctx, day("2024-01-01")); err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if created == nil || created.RevaluedAmount != 50000 || created.Adjustment != 0 {
+			t.Fatalf("reavaliação do dia 1 incorreta: %+v", created)
+		}
+		// O dia 2 passa a partir dos 500,00 do dia 1: 100 * 5.2 - 500 = 20,00
+		if len(updated) != 1 || !updated[0].Date.Equal(day("2024-01-02")) || updated[0].CarryingAmount != 50000 || updated[0].Adjustment != 2000 {
+			t.Errorf("reavaliação do dia 2 incorreta: %+v", updated)
+		}
+	})
+
+	t.Run("deve recusar um dia ainda aberto", func(t *testing.T) {
+		service := NewService(&MockRepository{}, rates, "BRL")
+		if _, err := service.RevalueAll(ctx, time.Now()); !errors.Is(err, ErrDayNotClosed) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrDayNotClosed, err)
+		}
+	})
+}
+
+func TestService_RevalueForUser(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	usd := RevaluationAccount{ID: uuid.New(), Currency: "USD"}
+	rates := &MockFX{table: map[string]map[string]float64{"USD": {"2024-01-02": 5.2}}}
+
+	var saved []Revaluation
+	repo := &MockRepository{
+		ListRevaluationAccountsFunc: func(ctx context.Context, functional string, date time.Time, before time.Time, uid *uuid.UUID) ([]RevaluationAccount, error) {
+			if uid == nil || *uid != userID {
+				t.Errorf("esperava só as contas do usuário, obteve %v", uid)
+			}
+			return []RevaluationAccount{usd}, nil
+		},
+		DailyAmountsSinceFunc: func(ctx context.Context, accountID uuid.UUID, p *Revaluation, prevBefore time.Time, before time.Time) ([]DailyAmount, error) {
+			return []DailyAmount{{Day: day("2024-01-02"), Amount: 1000}}, nil
+		},
+		BalanceBeforeFunc: func(ctx context.Context, accountID uuid.UUID, before time.Time) (int64, error) {
+			return 1000, nil
+		},
+		CreateRevaluationFunc: func(ctx context.Context, rev *Revaluation) (bool, error) {
+			rev.AccountName = "Conta USD"
+			saved = append(saved, *rev)
+			return true, nil
+		},
+		ListRevaluationsFunc: func(ctx context.Context, uid uuid.UUID, date time.Time) ([]Revaluation, error) {
+			return saved, nil
+		},
+	}
+	service := NewService(repo, rates, "BRL")
+
+	report, err := service.RevalueForUser(ctx, userID.String(), day("2024-01-02"))
+	if err != nil {
+		t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+	}
+	if len(report.Adjustments) != 1 || report.TotalRevalued.Amount != 5200 || report.Date != "2024-01-02" {
+		t.Errorf("relatório incorreto: %+v", report)
+	}
+
+	if _, err := service.RevalueForUser(ctx, userID.String(), time.Now()); !errors.Is(err, ErrDayNotClosed) {
+		t.Errorf("esperava o erro %v, mas obteve %v", ErrDayNotClosed, err)
+	}
+}
+
+func TestService_GetFXRevaluation(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	repo := &MockRepository{
+		ListRevaluationsFunc: func(ctx context.Context, uid uuid.UUID, date time.Time) ([]Revaluation, error) {
+			if uid != userID || !date.Equal(day("2024-01-02")) {
+				t.Errorf("parâmetros incorretos: %s %s", uid, date)
+			}
+			return []Revaluation{
+				{AccountName: "Conta USD", Currency: "USD", FunctionalCurrency: "BRL", Balance: 11000, Rate: 5.2, RateDate: date, RevaluedAmount: 57200, CarryingAmount: 55200, Adjustment: 2000},
+				{AccountName: "Conta EUR", Currency: "EUR", FunctionalCurrency: "BRL", Balance: 1000, Rate: 5.5, RateDate: date, RevaluedAmount: 5500, CarryingAmount: 6000, Adjustment: -500},
+			}, nil
+		},
+	}
+	service := NewService(repo, &MockFX{}, "BRL")
+
+	report, err := service.GetFXRevaluation(ctx, userID.String(), day("2024-01-02"))
+	if err != nil {
+		t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+	}
+	if len(report.Adjustments) != 2 || report.Adjustments[1].Adjustment.Amount != -500 || report.Adjustments[0].Balance.Currency != "USD" {
+		t.Errorf("ajustes incorretos: %+v", report.Adjustments)
+	}
+	if report.TotalAdjustment.Amount != 1500 || report.TotalRevalued.Amount != 62700 || report.TotalAdjustment.Currency != "BRL" {
+		t.Errorf("totais incorretos: %+v %+v", report.TotalRevalued, report.TotalAdjustment)
+	}
+}
+
+func TestService_GetCategoryReport(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	categoryID := uuid.New()
+	name, kind := "Supermercado", "expense"
+
+	repo := &MockRepository{
+		SumByCategoryFunc: func(ctx context.Context, uid uuid.UUID, from time.Time, before time.Time, accountID *uuid.UUID) ([]CategorySum, error) {
+			// O último dia entra inteiro no período
+			if !from.Equal(day("2024-01-01")) || !before.Equal(day("2024-02-01")) {
+				t.Errorf("período incorreto: %s a %s", from, before)
+			}
+			return []CategorySum{
+				{CategoryID: &categoryID, Name: &name, Kind: &kind, Currency: "BRL", Amount: -7000, Lines: 2},
+				{Currency: "BRL", Amount: -3000, Lines: 1},
+			}, nil
+		},
+	}
+	service := NewService(repo, &MockFX{}, "BRL")
+
+	report, err := service.GetCategoryReport(ctx, userID.String(), CategoryReportFilter{From: day("2024-01-01"), To: day("2024-01-31")})
+	if err != nil {
+		t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+	}
+	if len(report.Categories) != 2 || report.Categories[0].Name != "Supermercado" || report.Categories[0].Total.Amount != -7000 ||
+		report.Categories[1].CategoryID != nil || report.Categories[1].Total.Currency != "BRL" {
+		t.Errorf("relatório incorreto: %+v", report.Categories)
+	}
+
+	if _, err := service.GetCategoryReport(ctx, userID.String(), CategoryReportFilter{From: day("2024-02-01"), To: day("2024-01-31")}); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidPeriod, err)
+	}
+}
@@ -0,0 +1,267 @@
+package schedules
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-playground/validator/v10"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/auth"
+	"github.com/martinsdevv/fincore/internal/categories"
+	"github.com/martinsdevv/fincore/pkg/recurrence"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultPreviewCount = 10
+	maxPreviewCount     = 100
+)
+
+type Handler struct {
+	service  Service
+	validate *validator.Validate
+}
+
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service:  service,
+		validate: validator.New(validator.WithRequiredStructEnabled()),
+	}
+}
+
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("Falha ao escrever resposta JSON")
+	}
+}
+
+func (h *Handler) getUserIDFromContext(r *http.Request) (string, bool) {
+	userID, ok := r.Context().Value(auth.UserContextKey).(string)
+	if !ok {
+		log.Error().Msg("UserID não encontrado no contexto, middleware mal configurado")
+		return "", false
+	}
+	return userID, true
+}
+
+// writeServiceError traduz os erros do serviço, incluindo os da conta, para respostas HTTP
+func (h *Handler) writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	switch {
+	case errors.Is(err, ErrScheduleNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "schedule not found"})
+	case errors.Is(err, ErrOccurrenceNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "schedule has no upcoming occurrence on this date"})
+	case errors.Is(err, ErrOccurrenceDecided):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": "occurrence was already posted or skipped"})
+	case errors.Is(err, ErrScheduleFinished), errors.Is(err, ErrScheduleNotActive), errors.Is(err, ErrScheduleNotPaused):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrScheduleChanged):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": "schedule was modified by another request, try again"})
+	case errors.Is(err, recurrence.ErrInvalidRule), errors.Is(err, ErrStartBeforeOpening):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+	case errors.Is(err, categories.ErrCategoryNotFound):
+		h.writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": "category not found"})
+	case errors.Is(err, accounts.ErrAccountNotFound):
+		h.writeJSON(w, http.StatusNotFound, map[string]string{"error": "account not found"})
+	case errors.Is(err, accounts.ErrForbidden):
+		h.writeJSON(w, http.StatusForbidden, map[string]string{"error": "you do not have permission for this account"})
+	case errors.Is(err, accounts.ErrAccountArchived):
+		h.writeJSON(w, http.StatusConflict, map[string]string{"error": "account is archived"})
+	default:
+		h.writeJSON(w, http.StatusInternalServerError, map[string]string{"error": fallback})
+	}
+}
+
+func (h *Handler) HandleCreateSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req CreateScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	schedule, err := h.service.CreateSchedule(r.Context(), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to create schedule")
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, schedule)
+}
+
+func (h *Handler) HandleListSchedules(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	schedules, err := h.service.ListSchedules(r.Context(), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve schedules")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, schedules)
+}
+
+func (h *Handler) HandleGetSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	schedule, err := h.service.GetSchedule(r.Context(), chi.URLParam(r, "scheduleID"), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to retrieve schedule")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, schedule)
+}
+
+func (h *Handler) HandleUpdateSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req UpdateScheduleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	schedule, err := h.service.UpdateSchedule(r.Context(), chi.URLParam(r, "scheduleID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to update schedule")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, schedule)
+}
+
+func (h *Handler) HandleDeleteSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	if err := h.service.DeleteSchedule(r.Context(), chi.URLParam(r, "scheduleID"), userID); err != nil {
+		h.writeServiceError(w, err, "failed to delete schedule")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandlePreviewSchedule responde GET /schedules/{id}/preview?count=N com as
+// próximas N ocorrências (10 por padrão, até 100)
+func (h *Handler) HandlePreviewSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	count := defaultPreviewCount
+	if raw := r.URL.Query().Get("count"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 || n > maxPreviewCount {
+			h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "count must be between 1 and 100"})
+			return
+		}
+		count = n
+	}
+
+	occurrences, err := h.service.PreviewSchedule(r.Context(), chi.URLParam(r, "scheduleID"), userID, count)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to preview schedule")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, occurrences)
+}
+
+func (h *Handler) HandleSkipOccurrence(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	var req SkipOccurrenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body"})
+		return
+	}
+
+	if err := h.validate.Struct(req); err != nil {
+		h.writeJSON(w, http.StatusBadRequest, map[string]string{"error": "validation failed: " + err.Error()})
+		return
+	}
+
+	occurrence, err := h.service.SkipOccurrence(r.Context(), chi.URLParam(r, "scheduleID"), userID, req)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to skip occurrence")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, occurrence)
+}
+
+func (h *Handler) HandlePauseSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	schedule, err := h.service.PauseSchedule(r.Context(), chi.URLParam(r, "scheduleID"), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to pause schedule")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, schedule)
+}
+
+func (h *Handler) HandleResumeSchedule(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromContext(r)
+	if !ok {
+		h.writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid token"})
+		return
+	}
+
+	schedule, err := h.service.ResumeSchedule(r.Context(), chi.URLParam(r, "scheduleID"), userID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to resume schedule")
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, schedule)
+}
@@ -0,0 +1,58 @@
+package schedules
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	schedulerLockKey = "schedules:scheduler"
+	// schedulerLockTTL libera a trava se a réplica que a tem morrer no meio da
+	// execução. Uma execução mais longa que isso não lança nada em dobro: cada
+	// ocorrência avança next_index de forma condicional, na mesma transação.
+	schedulerLockTTL = 5 * time.Minute
+)
+
+// RunScheduler lança as ocorrências vencidas a cada interval, até ctx ser
+// cancelado. A cada execução só a réplica que pegar a trava no Redis trabalha.
+func RunScheduler(ctx context.Context, svc Service, locker Locker, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		runScheduler(ctx, svc, locker)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func runScheduler(ctx context.Context, svc Service, locker Locker) {
+	token, ok, err := locker.TryLock(ctx, schedulerLockKey, schedulerLockTTL)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to acquire scheduler lock")
+		return
+	}
+	if !ok {
+		return
+	}
+	defer func() {
+		if err := locker.Unlock(context.WithoutCancel(ctx), schedulerLockKey, token); err != nil {
+			log.Error().Err(err).Msg("Failed to release scheduler lock")
+		}
+	}()
+
+	posted, err := svc.PostDue(ctx, time.Now())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to post scheduled movements")
+		return
+	}
+	if posted > 0 {
+		log.Info().Int("movements", posted).Msg("Scheduled movements posted")
+	}
+}
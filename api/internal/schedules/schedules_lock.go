@@ -0,0 +1,46 @@
+package schedules
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+)
+
+// Locker é uma trava com prazo compartilhada entre as réplicas da API
+type Locker interface {
+	// TryLock tenta pegar a trava key por ttl; ok false se outra réplica a tem.
+	// O token devolvido é o que libera a trava em Unlock.
+	TryLock(ctx context.Context, key string, ttl time.Duration) (token string, ok bool, err error)
+	Unlock(ctx context.Context, key string, token string) error
+}
+
+type redisLocker struct {
+	client *redis.Client
+}
+
+func NewRedisLocker(client *redis.Client) Locker {
+	return &redisLocker{client: client}
+}
+
+// unlockScript só apaga a trava se ela ainda for de quem a pegou: depois do
+// ttl, outra réplica pode tê-la
+var unlockScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+
+func (l *redisLocker) TryLock(ctx context.Context, key string, ttl time.Duration) (string, bool, error) {
+	token := uuid.NewString()
+	ok, err := l.client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return "", false, err
+	}
+	return token, ok, nil
+}
+
+func (l *redisLocker) Unlock(ctx context.Context, key string, token string) error {
+	return unlockScript.Run(ctx, l.client, []string{key}, token).Err()
+}
@@ -0,0 +1,130 @@
+package schedules
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/pkg/recurrence"
+)
+
+type ScheduleStatus string
+
+const (
+	StatusActive   ScheduleStatus = "active"
+	StatusPaused   ScheduleStatus = "paused"
+	StatusFinished ScheduleStatus = "finished" // a regra não tem mais ocorrências
+)
+
+type OccurrenceStatus string
+
+const (
+	OccurrencePosted  OccurrenceStatus = "posted"
+	OccurrenceSkipped OccurrenceStatus = "skipped"
+)
+
+// Schedule lança Amount na conta a cada ocorrência da regra. NextIndex é a
+// próxima ocorrência a processar e NextDate a data dela já ajustada; NextDate
+// nil indica que o agendamento terminou.
+type Schedule struct {
+	ID          uuid.UUID
+	AccountID   uuid.UUID
+	CreatedBy   uuid.UUID
+	Amount      int64
+	Description string
+	CategoryID  *uuid.UUID
+	Frequency   recurrence.Frequency
+	Interval    int
+	StartDate   time.Time
+	EndDate     *time.Time
+	Count       *int
+	Adjustment  recurrence.Adjustment
+	Status      ScheduleStatus
+	NextIndex   int
+	NextDate    *time.Time
+	LastError   *string // por que o agendamento foi pausado pelo scheduler
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (s *Schedule) Rule() recurrence.Rule {
+	rule := recurrence.Rule{
+		Frequency:  s.Frequency,
+		Interval:   s.Interval,
+		Start:      s.StartDate,
+		Until:      s.EndDate,
+		Adjustment: s.Adjustment,
+	}
+	if s.Count != nil {
+		rule.Count = *s.Count
+	}
+	return rule
+}
+
+// Occurrence registra uma ocorrência já decidida: lançada ou pulada
+type Occurrence struct {
+	ScheduleID  uuid.UUID
+	Index       int
+	NominalDate time.Time
+	Date        time.Time
+	Status      OccurrenceStatus
+	MovementID  *uuid.UUID
+	CreatedAt   time.Time
+}
+
+// CreateScheduleRequest usa datas no formato AAAA-MM-DD. end_date e count são
+// alternativos; sem nenhum dos dois o agendamento não termina.
+type CreateScheduleRequest struct {
+	AccountID   uuid.UUID             `json:"account_id" validate:"required"`
+	Amount      int64                 `json:"amount" validate:"required"`
+	Description string                `json:"description" validate:"max=255"`
+	CategoryID  *uuid.UUID            `json:"category_id"`
+	Frequency   recurrence.Frequency  `json:"frequency" validate:"required,oneof=daily weekly monthly yearly"`
+	Interval    int                   `json:"interval" validate:"omitempty,min=1,max=366"`
+	StartDate   string                `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate     *string               `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	Count       *int                  `json:"count" validate:"omitempty,min=1"`
+	Adjustment  recurrence.Adjustment `json:"adjustment" validate:"omitempty,oneof=none following preceding modified_following"`
+}
+
+// UpdateScheduleRequest só altera os campos enviados e vale para as próximas
+// ocorrências. A regra não muda depois de criada: para isso, crie outro agendamento.
+type UpdateScheduleRequest struct {
+	Amount      *int64     `json:"amount"`
+	Description *string    `json:"description" validate:"omitempty,max=255"`
+	CategoryID  *uuid.UUID `json:"category_id"`
+}
+
+// SkipOccurrenceRequest pula a ocorrência que cai em date (a data já ajustada,
+// como aparece no preview)
+type SkipOccurrenceRequest struct {
+	Date string `json:"date" validate:"required,datetime=2006-01-02"`
+}
+
+type ScheduleResponse struct {
+	ID          uuid.UUID             `json:"id"`
+	AccountID   uuid.UUID             `json:"account_id"`
+	Amount      int64                 `json:"amount"`
+	Description string                `json:"description"`
+	CategoryID  *uuid.UUID            `json:"category_id"`
+	Frequency   recurrence.Frequency  `json:"frequency"`
+	Interval    int                   `json:"interval"`
+	StartDate   string                `json:"start_date"`
+	EndDate     *string               `json:"end_date"`
+	Count       *int                  `json:"count"`
+	Adjustment  recurrence.Adjustment `json:"adjustment"`
+	Status      ScheduleStatus        `json:"status"`
+	NextDate    *string               `json:"next_date"`
+	LastError   *string               `json:"last_error,omitempty"`
+	CreatedBy   uuid.UUID             `json:"created_by"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+// OccurrenceResponse é uma ocorrência futura no preview. Skipped marca as que
+// o usuário já pulou e não serão lançadas.
+type OccurrenceResponse struct {
+	Index       int    `json:"index"`
+	NominalDate string `json:"nominal_date"`
+	Date        string `json:"date"`
+	Skipped     bool   `json:"skipped"`
+}
@@ -0,0 +1,216 @@
+package schedules
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/martinsdevv/fincore/pkg/database"
+)
+
+type Repository interface {
+	CreateSchedule(ctx context.Context, schedule *Schedule) error
+	GetSchedule(ctx context.Context, id uuid.UUID) (*Schedule, error)
+	// ListSchedules devolve os agendamentos das contas de que o usuário é membro
+	ListSchedules(ctx context.Context, userID uuid.UUID) ([]Schedule, error)
+	// UpdateSchedule grava só o que o usuário pode editar: valor, descrição e categoria
+	UpdateSchedule(ctx context.Context, schedule *Schedule) error
+	// SetScheduleState grava status, próxima ocorrência e last_error se o
+	// agendamento ainda estiver em fromIndex/fromStatus; false se outra
+	// requisição ou réplica chegou antes
+	SetScheduleState(ctx context.Context, schedule *Schedule, fromIndex int, fromStatus ScheduleStatus) (bool, error)
+	DeleteSchedule(ctx context.Context, id uuid.UUID) error
+	// ListDueSchedules devolve até limit agendamentos ativos com ocorrência até today
+	ListDueSchedules(ctx context.Context, today time.Time, limit int) ([]Schedule, error)
+	// CreateOccurrence retorna ErrOccurrenceDecided se a ocorrência já foi lançada ou pulada
+	CreateOccurrence(ctx context.Context, occurrence *Occurrence) error
+	// ListSkipped devolve os índices pulados a partir de from
+	ListSkipped(ctx context.Context, scheduleID uuid.UUID, from int) (map[int]bool, error)
+}
+
+type pgxRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRepository(db *pgxpool.Pool) Repository {
+	return &pgxRepository{db: db}
+}
+
+// conn usa a transação aberta por um database.Transactor, se houver
+func (r *pgxRepository) conn(ctx context.Context) database.DBTX {
+	return database.Conn(ctx, r.db)
+}
+
+// Colunas lidas por scanSchedule, sempre nesta ordem
+const scheduleColumns = `id, account_id, created_by, amount, description, category_id, frequency, repeat_interval,
+	start_date, end_date, occurrence_count, adjustment, status, next_index, next_date, last_error, created_at, updated_at`
+
+func scanSchedule(row pgx.Row, s *Schedule) error {
+	return row.Scan(
+		&s.ID,
+		&s.AccountID,
+		&s.CreatedBy,
+		&s.Amount,
+		&s.Description,
+		&s.CategoryID,
+		&s.Frequency,
+		&s.Interval,
+		&s.StartDate,
+		&s.EndDate,
+		&s.Count,
+		&s.Adjustment,
+		&s.Status,
+		&s.NextIndex,
+		&s.NextDate,
+		&s.LastError,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+}
+
+func (r *pgxRepository) CreateSchedule(ctx context.Context, s *Schedule) error {
+	query := `
+		INSERT INTO schedules (` + scheduleColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`
+
+	_, err := r.conn(ctx).Exec(ctx, query,
+		s.ID,
+		s.AccountID,
+		s.CreatedBy,
+		s.Amount,
+		s.Description,
+		s.CategoryID,
+		s.Frequency,
+		s.Interval,
+		s.StartDate,
+		s.EndDate,
+		s.Count,
+		s.Adjustment,
+		s.Status,
+		s.NextIndex,
+		s.NextDate,
+		s.LastError,
+		s.CreatedAt,
+		s.UpdatedAt,
+	)
+	return err
+}
+
+func (r *pgxRepository) GetSchedule(ctx context.Context, id uuid.UUID) (*Schedule, error) {
+	query := `SELECT ` + scheduleColumns + ` FROM schedules WHERE id = $1`
+
+	var s Schedule
+	if err := scanSchedule(r.conn(ctx).QueryRow(ctx, query, id), &s); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *pgxRepository) querySchedules(ctx context.Context, query string, args ...interface{}) ([]Schedule, error) {
+	rows, err := r.conn(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var schedules []Schedule
+	for rows.Next() {
+		var s Schedule
+		if err := scanSchedule(rows, &s); err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+	return schedules, rows.Err()
+}
+
+func (r *pgxRepository) ListSchedules(ctx context.Context, userID uuid.UUID) ([]Schedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM schedules
+		WHERE account_id IN (SELECT account_id FROM account_members WHERE user_id = $1)
+		ORDER BY next_date NULLS LAST, created_at, id`
+
+	return r.querySchedules(ctx, query, userID)
+}
+
+func (r *pgxRepository) UpdateSchedule(ctx context.Context, s *Schedule) error {
+	query := `UPDATE schedules SET amount = $2, description = $3, category_id = $4, updated_at = $5 WHERE id = $1`
+
+	_, err := r.conn(ctx).Exec(ctx, query, s.ID, s.Amount, s.Description, s.CategoryID, s.UpdatedAt)
+	return err
+}
+
+func (r *pgxRepository) SetScheduleState(ctx context.Context, s *Schedule, fromIndex int, fromStatus ScheduleStatus) (bool, error) {
+	query := `
+		UPDATE schedules
+		SET status = $4, next_index = $5, next_date = $6, last_error = $7, updated_at = $8
+		WHERE id = $1 AND next_index = $2 AND status = $3`
+
+	tag, err := r.conn(ctx).Exec(ctx, query,
+		s.ID, fromIndex, fromStatus,
+		s.Status, s.NextIndex, s.NextDate, s.LastError, s.UpdatedAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *pgxRepository) DeleteSchedule(ctx context.Context, id uuid.UUID) error {
+	_, err := r.conn(ctx).Exec(ctx, `DELETE FROM schedules WHERE id = $1`, id)
+	return err
+}
+
+func (r *pgxRepository) ListDueSchedules(ctx context.Context, today time.Time, limit int) ([]Schedule, error) {
+	query := `
+		SELECT ` + scheduleColumns + `
+		FROM schedules
+		WHERE status = 'active' AND next_date <= $1
+		ORDER BY next_date, id
+		LIMIT $2`
+
+	return r.querySchedules(ctx, query, today, limit)
+}
+
+func (r *pgxRepository) CreateOccurrence(ctx context.Context, o *Occurrence) error {
+	query := `
+		INSERT INTO schedule_occurrences (schedule_id, occurrence_index, nominal_date, occurrence_date, status, movement_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+	_, err := r.conn(ctx).Exec(ctx, query, o.ScheduleID, o.Index, o.NominalDate, o.Date, o.Status, o.MovementID, o.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return ErrOccurrenceDecided
+	}
+	return err
+}
+
+func (r *pgxRepository) ListSkipped(ctx context.Context, scheduleID uuid.UUID, from int) (map[int]bool, error) {
+	query := `
+		SELECT occurrence_index FROM schedule_occurrences
+		WHERE schedule_id = $1 AND occurrence_index >= $2 AND status = 'skipped'`
+
+	rows, err := r.conn(ctx).Query(ctx, query, scheduleID, from)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	skipped := make(map[int]bool)
+	for rows.Next() {
+		var index int
+		if err := rows.Scan(&index); err != nil {
+			return nil, err
+		}
+		skipped[index] = true
+	}
+	return skipped, rows.Err()
+}
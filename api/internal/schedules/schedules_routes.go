@@ -0,0 +1,15 @@
+package schedules
+
+import "github.com/go-chi/chi/v5"
+
+func (h *Handler) RegisterRoutes(r chi.Router) {
+	r.Get("/schedules", h.HandleListSchedules)
+	r.Post("/schedules", h.HandleCreateSchedule)
+	r.Get("/schedules/{scheduleID}", h.HandleGetSchedule)
+	r.Patch("/schedules/{scheduleID}", h.HandleUpdateSchedule)
+	r.Delete("/schedules/{scheduleID}", h.HandleDeleteSchedule)
+	r.Get("/schedules/{scheduleID}/preview", h.HandlePreviewSchedule)
+	r.Post("/schedules/{scheduleID}/skip", h.HandleSkipOccurrence)
+	r.Post("/schedules/{scheduleID}/pause", h.HandlePauseSchedule)
+	r.Post("/schedules/{scheduleID}/resume", h.HandleResumeSchedule)
+}
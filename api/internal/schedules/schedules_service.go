@@ -0,0 +1,542 @@
+package schedules
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/categories"
+	"github.com/martinsdevv/fincore/internal/movements"
+	"github.com/martinsdevv/fincore/pkg/database"
+	"github.com/martinsdevv/fincore/pkg/recurrence"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	ErrScheduleNotFound    = errors.New("schedule not found")
+	ErrScheduleFinished    = errors.New("schedule has no more occurrences")
+	ErrScheduleNotActive   = errors.New("schedule is not active")
+	ErrScheduleNotPaused   = errors.New("schedule is not paused")
+	ErrScheduleChanged     = errors.New("schedule was modified by another request")
+	ErrStartBeforeOpening  = errors.New("start date cannot be before the account was opened")
+	ErrOccurrenceNotFound  = errors.New("schedule has no upcoming occurrence on this date")
+	ErrOccurrenceDecided   = errors.New("occurrence was already posted or skipped")
+	errConcurrentRun       = errors.New("schedule advanced by another run")
+	errRuleWithoutNextDate = errors.New("schedule rule has no occurrence at next_index")
+)
+
+const (
+	// dueBatchSize limita quantos agendamentos cada execução do scheduler processa
+	dueBatchSize = 500
+	// skipSearchLimit é até quantas ocorrências à frente um skip procura a data
+	skipSearchLimit = 1000
+)
+
+type Service interface {
+	CreateSchedule(ctx context.Context, userID string, req CreateScheduleRequest) (*ScheduleResponse, error)
+	ListSchedules(ctx context.Context, userID string) ([]ScheduleResponse, error)
+	GetSchedule(ctx context.Context, scheduleID string, userID string) (*ScheduleResponse, error)
+	UpdateSchedule(ctx context.Context, scheduleID string, userID string, req UpdateScheduleRequest) (*ScheduleResponse, error)
+	DeleteSchedule(ctx context.Context, scheduleID string, userID string) error
+	// PreviewSchedule devolve as próximas count ocorrências, inclusive as puladas
+	PreviewSchedule(ctx context.Context, scheduleID string, userID string, count int) ([]OccurrenceResponse, error)
+	SkipOccurrence(ctx context.Context, scheduleID string, userID string, req SkipOccurrenceRequest) (*OccurrenceResponse, error)
+	PauseSchedule(ctx context.Context, scheduleID string, userID string) (*ScheduleResponse, error)
+	// ResumeSchedule reativa o agendamento a partir de hoje; as ocorrências que
+	// venceram durante a pausa não são lançadas
+	ResumeSchedule(ctx context.Context, scheduleID string, userID string) (*ScheduleResponse, error)
+	// PostDue lança as ocorrências vencidas até o dia de now (UTC) e devolve
+	// quantos lançamentos foram gravados
+	PostDue(ctx context.Context, now time.Time) (int, error)
+}
+
+type service struct {
+	repo       Repository
+	accounts   accounts.Service
+	categories categories.Service
+	movements  movements.Service
+	tx         database.Transactor
+}
+
+func NewService(repo Repository, accountsSvc accounts.Service, categoriesSvc categories.Service, movementsSvc movements.Service, tx database.Transactor) Service {
+	return &service{repo: repo, accounts: accountsSvc, categories: categoriesSvc, movements: movementsSvc, tx: tx}
+}
+
+func (s *service) CreateSchedule(ctx context.Context, userIDStr string, req CreateScheduleRequest) (*ScheduleResponse, error) {
+	account, err := s.accounts.AuthorizeAccount(ctx, req.AccountID.String(), userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if account.IsArchived() {
+		return nil, accounts.ErrAccountArchived
+	}
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+	if req.CategoryID != nil {
+		if _, err := s.categories.ResolveCategory(ctx, *req.CategoryID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now().UTC()
+	schedule := &Schedule{
+		ID:          uuid.New(),
+		AccountID:   account.ID,
+		CreatedBy:   userID,
+		Amount:      req.Amount,
+		Description: req.Description,
+		CategoryID:  req.CategoryID,
+		Frequency:   req.Frequency,
+		Interval:    req.Interval,
+		Count:       req.Count,
+		Adjustment:  req.Adjustment,
+		Status:      StatusActive,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if schedule.Interval == 0 {
+		schedule.Interval = 1
+	}
+	if schedule.Adjustment == "" {
+		schedule.Adjustment = recurrence.NoAdjustment
+	}
+	if schedule.StartDate, err = time.Parse(time.DateOnly, req.StartDate); err != nil {
+		return nil, errors.Join(recurrence.ErrInvalidRule, err)
+	}
+	if req.EndDate != nil {
+		endDate, err := time.Parse(time.DateOnly, *req.EndDate)
+		if err != nil {
+			return nil, errors.Join(recurrence.ErrInvalidRule, err)
+		}
+		schedule.EndDate = &endDate
+	}
+	if err := schedule.Rule().Validate(); err != nil {
+		return nil, err
+	}
+	// Ocorrências passadas são lançadas retroativamente, mas não antes da conta existir
+	if schedule.StartDate.Before(truncateDay(account.CreatedAt)) {
+		return nil, ErrStartBeforeOpening
+	}
+	setNext(schedule, schedule.Rule(), 0)
+
+	if err := s.repo.CreateSchedule(ctx, schedule); err != nil {
+		log.Error().Err(err).Str("accountID", account.ID.String()).Msg("Failed to create schedule in repository")
+		return nil, err
+	}
+	return toScheduleResponse(schedule), nil
+}
+
+func (s *service) ListSchedules(ctx context.Context, userIDStr string) ([]ScheduleResponse, error) {
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules, err := s.repo.ListSchedules(ctx, userID)
+	if err != nil {
+		log.Error().Err(err).Str("userID", userIDStr).Msg("Failed to list schedules from repository")
+		return nil, err
+	}
+
+	responses := make([]ScheduleResponse, 0, len(schedules))
+	for i := range schedules {
+		responses = append(responses, *toScheduleResponse(&schedules[i]))
+	}
+	return responses, nil
+}
+
+func (s *service) GetSchedule(ctx context.Context, scheduleIDStr string, userIDStr string) (*ScheduleResponse, error) {
+	schedule, err := s.getSchedule(ctx, scheduleIDStr, userIDStr, accounts.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	return toScheduleResponse(schedule), nil
+}
+
+func (s *service) UpdateSchedule(ctx context.Context, scheduleIDStr string, userIDStr string, req UpdateScheduleRequest) (*ScheduleResponse, error) {
+	schedule, err := s.getSchedule(ctx, scheduleIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Amount != nil {
+		if *req.Amount == 0 {
+			return nil, errors.Join(recurrence.ErrInvalidRule, errors.New("amount must not be zero"))
+		}
+		schedule.Amount = *req.Amount
+	}
+	if req.Description != nil {
+		schedule.Description = *req.Description
+	}
+	if req.CategoryID != nil {
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := s.categories.ResolveCategory(ctx, *req.CategoryID, userID); err != nil {
+			return nil, err
+		}
+		schedule.CategoryID = req.CategoryID
+	}
+	schedule.UpdatedAt = time.Now().UTC()
+
+	if err := s.repo.UpdateSchedule(ctx, schedule); err != nil {
+		log.Error().Err(err).Str("scheduleID", scheduleIDStr).Msg("Failed to update schedule in repository")
+		return nil, err
+	}
+	return toScheduleResponse(schedule), nil
+}
+
+// DeleteSchedule remove o agendamento; os lançamentos que ele já gerou ficam
+func (s *service) DeleteSchedule(ctx context.Context, scheduleIDStr string, userIDStr string) error {
+	schedule, err := s.getSchedule(ctx, scheduleIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteSchedule(ctx, schedule.ID); err != nil {
+		log.Error().Err(err).Str("scheduleID", scheduleIDStr).Msg("Failed to delete schedule from repository")
+		return err
+	}
+	return nil
+}
+
+func (s *service) PreviewSchedule(ctx context.Context, scheduleIDStr string, userIDStr string, count int) ([]OccurrenceResponse, error) {
+	schedule, err := s.getSchedule(ctx, scheduleIDStr, userIDStr, accounts.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	previews := make([]OccurrenceResponse, 0, count)
+	if schedule.Status == StatusFinished {
+		return previews, nil
+	}
+
+	skipped, err := s.repo.ListSkipped(ctx, schedule.ID, schedule.NextIndex)
+	if err != nil {
+		return nil, err
+	}
+	for _, occ := range schedule.Rule().List(schedule.NextIndex, count) {
+		preview := toOccurrenceResponse(occ)
+		preview.Skipped = skipped[occ.Index]
+		previews = append(previews, preview)
+	}
+	return previews, nil
+}
+
+func (s *service) SkipOccurrence(ctx context.Context, scheduleIDStr string, userIDStr string, req SkipOccurrenceRequest) (*OccurrenceResponse, error) {
+	schedule, err := s.getSchedule(ctx, scheduleIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status == StatusFinished {
+		return nil, ErrScheduleFinished
+	}
+
+	date, err := time.Parse(time.DateOnly, req.Date)
+	if err != nil {
+		return nil, ErrOccurrenceNotFound
+	}
+	occ, ok := schedule.Rule().Find(date, schedule.NextIndex, skipSearchLimit)
+	if !ok {
+		return nil, ErrOccurrenceNotFound
+	}
+
+	// Se o scheduler lançar a ocorrência antes, a chave primária recusa o skip
+	err = s.repo.CreateOccurrence(ctx, &Occurrence{
+		ScheduleID:  schedule.ID,
+		Index:       occ.Index,
+		NominalDate: occ.Nominal,
+		Date:        occ.Date,
+		Status:      OccurrenceSkipped,
+		CreatedAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		if !errors.Is(err, ErrOccurrenceDecided) {
+			log.Error().Err(err).Str("scheduleID", scheduleIDStr).Msg("Failed to skip schedule occurrence")
+		}
+		return nil, err
+	}
+
+	resp := toOccurrenceResponse(occ)
+	resp.Skipped = true
+	return &resp, nil
+}
+
+func (s *service) PauseSchedule(ctx context.Context, scheduleIDStr string, userIDStr string) (*ScheduleResponse, error) {
+	schedule, err := s.getSchedule(ctx, scheduleIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != StatusActive {
+		return nil, ErrScheduleNotActive
+	}
+
+	schedule.Status = StatusPaused
+	if err := s.setState(ctx, schedule, schedule.NextIndex, StatusActive); err != nil {
+		return nil, err
+	}
+	return toScheduleResponse(schedule), nil
+}
+
+func (s *service) ResumeSchedule(ctx context.Context, scheduleIDStr string, userIDStr string) (*ScheduleResponse, error) {
+	schedule, err := s.getSchedule(ctx, scheduleIDStr, userIDStr, accounts.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+	if schedule.Status != StatusPaused {
+		return nil, ErrScheduleNotPaused
+	}
+
+	from := schedule.NextIndex
+	rule := schedule.Rule()
+	today := truncateDay(time.Now())
+	index := from
+	for {
+		occ, ok := rule.At(index)
+		if !ok || !occ.Date.Before(today) {
+			break
+		}
+		index++
+	}
+
+	schedule.Status = StatusActive
+	schedule.LastError = nil
+	setNext(schedule, rule, index)
+	if err := s.setState(ctx, schedule, from, StatusPaused); err != nil {
+		return nil, err
+	}
+	return toScheduleResponse(schedule), nil
+}
+
+func (s *service) setState(ctx context.Context, schedule *Schedule, fromIndex int, fromStatus ScheduleStatus) error {
+	schedule.UpdatedAt = time.Now().UTC()
+	ok, err := s.repo.SetScheduleState(ctx, schedule, fromIndex, fromStatus)
+	if err != nil {
+		log.Error().Err(err).Str("scheduleID", schedule.ID.String()).Msg("Failed to update schedule state")
+		return err
+	}
+	if !ok {
+		return ErrScheduleChanged
+	}
+	return nil
+}
+
+func (s *service) PostDue(ctx context.Context, now time.Time) (int, error) {
+	today := truncateDay(now)
+	schedules, err := s.repo.ListDueSchedules(ctx, today, dueBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for i := range schedules {
+		n, err := s.postSchedule(ctx, &schedules[i], today)
+		posted += n
+		if err != nil {
+			// Um agendamento com problema não impede os outros
+			log.Error().Err(err).Str("scheduleID", schedules[i].ID.String()).Msg("Failed to post schedule occurrences")
+		}
+	}
+	return posted, nil
+}
+
+// postSchedule lança, em ordem, as ocorrências vencidas do agendamento. Cada
+// ocorrência avança next_index na mesma transação do lançamento, condicionada
+// ao valor lido: se outra réplica chegou antes, a atualização não encontra a
+// linha e nada é gravado.
+func (s *service) postSchedule(ctx context.Context, schedule *Schedule, today time.Time) (int, error) {
+	rule := schedule.Rule()
+	skipped, err := s.repo.ListSkipped(ctx, schedule.ID, schedule.NextIndex)
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for schedule.Status == StatusActive && schedule.NextDate != nil && !schedule.NextDate.After(today) {
+		occ, ok := rule.At(schedule.NextIndex)
+		if !ok {
+			return posted, errRuleWithoutNextDate
+		}
+
+		next := *schedule
+		setNext(&next, rule, occ.Index+1)
+		next.UpdatedAt = time.Now().UTC()
+
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			ok, err := s.repo.SetScheduleState(ctx, &next, occ.Index, StatusActive)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errConcurrentRun
+			}
+			if skipped[occ.Index] {
+				return nil
+			}
+			return s.postOccurrence(ctx, schedule, occ)
+		})
+		switch {
+		case err == nil:
+			if !skipped[occ.Index] {
+				posted++
+			}
+			*schedule = next
+		case errors.Is(err, errConcurrentRun), errors.Is(err, ErrOccurrenceDecided):
+			// Outra réplica ou um skip simultâneo; a próxima execução relê o estado
+			return posted, nil
+		case isPostingError(err):
+			return posted, s.pauseOnError(ctx, schedule, err)
+		default:
+			return posted, err
+		}
+	}
+	return posted, nil
+}
+
+func (s *service) postOccurrence(ctx context.Context, schedule *Schedule, occ recurrence.Occurrence) error {
+	accountID, userID := schedule.AccountID.String(), schedule.CreatedBy.String()
+	account, err := s.accounts.AuthorizeAccount(ctx, accountID, userID, accounts.RoleEditor)
+	if err != nil {
+		return err
+	}
+	// A ocorrência do dia da abertura não pode ficar antes do saldo inicial
+	occurredAt := occ.Date
+	if occurredAt.Before(account.CreatedAt) {
+		occurredAt = account.CreatedAt
+	}
+
+	movement, err := s.movements.PostMovement(ctx, accountID, userID, movements.KindScheduled, movements.CreateMovementRequest{
+		Amount:      schedule.Amount,
+		Description: schedule.Description,
+		OccurredAt:  &occurredAt,
+		CategoryID:  schedule.CategoryID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.repo.CreateOccurrence(ctx, &Occurrence{
+		ScheduleID:  schedule.ID,
+		Index:       occ.Index,
+		NominalDate: occ.Nominal,
+		Date:        occ.Date,
+		Status:      OccurrencePosted,
+		MovementID:  &movement.ID,
+		CreatedAt:   time.Now().UTC(),
+	})
+}
+
+// isPostingError diz se o lançamento foi recusado por uma regra da conta; esses
+// erros não se resolvem sozinhos, então o agendamento é pausado
+func isPostingError(err error) bool {
+	return errors.Is(err, accounts.ErrInsufficientFunds) ||
+		errors.Is(err, accounts.ErrNegativeBalanceNotAllowed) ||
+		errors.Is(err, accounts.ErrAccountArchived) ||
+		errors.Is(err, accounts.ErrAccountNotFound) ||
+		errors.Is(err, accounts.ErrForbidden) ||
+		errors.Is(err, categories.ErrCategoryNotFound)
+}
+
+func (s *service) pauseOnError(ctx context.Context, schedule *Schedule, cause error) error {
+	log.Warn().Err(cause).Str("scheduleID", schedule.ID.String()).Msg("Pausing schedule after a rejected occurrence")
+
+	message := cause.Error()
+	schedule.Status = StatusPaused
+	schedule.LastError = &message
+	err := s.setState(ctx, schedule, schedule.NextIndex, StatusActive)
+	if errors.Is(err, ErrScheduleChanged) {
+		return nil
+	}
+	return err
+}
+
+// getSchedule busca o agendamento e exige o papel role na conta dele. Quem não
+// é membro da conta não vê o agendamento.
+func (s *service) getSchedule(ctx context.Context, scheduleIDStr string, userIDStr string, role accounts.MemberRole) (*Schedule, error) {
+	scheduleID, err := uuid.Parse(scheduleIDStr)
+	if err != nil {
+		return nil, ErrScheduleNotFound
+	}
+
+	schedule, err := s.repo.GetSchedule(ctx, scheduleID)
+	if err != nil {
+		log.Error().Err(err).Str("scheduleID", scheduleIDStr).Msg("Failed to get schedule from repository")
+		return nil, err
+	}
+	if schedule == nil {
+		return nil, ErrScheduleNotFound
+	}
+
+	if _, err := s.accounts.AuthorizeAccount(ctx, schedule.AccountID.String(), userIDStr, accounts.RoleViewer); err != nil {
+		if errors.Is(err, accounts.ErrForbidden) || errors.Is(err, accounts.ErrAccountNotFound) {
+			return nil, ErrScheduleNotFound
+		}
+		return nil, err
+	}
+	if role != accounts.RoleViewer {
+		if _, err := s.accounts.AuthorizeAccount(ctx, schedule.AccountID.String(), userIDStr, role); err != nil {
+			return nil, err
+		}
+	}
+	return schedule, nil
+}
+
+// setNext aponta o agendamento para a ocorrência index, ou o encerra se a regra acabou
+func setNext(schedule *Schedule, rule recurrence.Rule, index int) {
+	schedule.NextIndex = index
+	occ, ok := rule.At(index)
+	if !ok {
+		schedule.NextDate = nil
+		schedule.Status = StatusFinished
+		return
+	}
+	schedule.NextDate = &occ.Date
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func toScheduleResponse(s *Schedule) *ScheduleResponse {
+	resp := &ScheduleResponse{
+		ID:          s.ID,
+		AccountID:   s.AccountID,
+		Amount:      s.Amount,
+		Description: s.Description,
+		CategoryID:  s.CategoryID,
+		Frequency:   s.Frequency,
+		Interval:    s.Interval,
+		StartDate:   s.StartDate.Format(time.DateOnly),
+		Count:       s.Count,
+		Adjustment:  s.Adjustment,
+		Status:      s.Status,
+		LastError:   s.LastError,
+		CreatedBy:   s.CreatedBy,
+		CreatedAt:   s.CreatedAt,
+		UpdatedAt:   s.UpdatedAt,
+	}
+	if s.EndDate != nil {
+		endDate := s.EndDate.Format(time.DateOnly)
+		resp.EndDate = &endDate
+	}
+	if s.NextDate != nil {
+		nextDate := s.NextDate.Format(time.DateOnly)
+		resp.NextDate = &nextDate
+	}
+	return resp
+}
+
+func toOccurrenceResponse(occ recurrence.Occurrence) OccurrenceResponse {
+	return OccurrenceResponse{
+		Index:       occ.Index,
+		NominalDate: occ.Nominal.Format(time.DateOnly),
+		Date:        occ.Date.Format(time.DateOnly),
+	}
+}
@@ -0,0 +1,336 @@
+package schedules
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/martinsdevv/fincore/internal/accounts"
+	"github.com/martinsdevv/fincore/internal/movements"
+	"github.com/martinsdevv/fincore/pkg/recurrence"
+)
+
+// MockRepository guarda os agendamentos e as ocorrências em memória
+type MockRepository struct {
+	schedules   map[uuid.UUID]*Schedule
+	occurrences map[uuid.UUID]map[int]Occurrence
+
+	SetScheduleStateFunc func(ctx context.Context, schedule *Schedule, fromIndex int, fromStatus ScheduleStatus) (bool, error)
+}
+
+func newMockRepository(list ...*Schedule) *MockRepository {
+	m := &MockRepository{schedules: make(map[uuid.UUID]*Schedule), occurrences: make(map[uuid.UUID]map[int]Occurrence)}
+	for _, s := range list {
+		m.schedules[s.ID] = s
+	}
+	return m
+}
+
+func (m *MockRepository) CreateSchedule(ctx context.Context, schedule *Schedule) error {
+	copied := *schedule
+	m.schedules[schedule.ID] = &copied
+	return nil
+}
+
+func (m *MockRepository) GetSchedule(ctx context.Context, id uuid.UUID) (*Schedule, error) {
+	if s, ok := m.schedules[id]; ok {
+		copied := *s
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *MockRepository) ListSchedules(ctx context.Context, userID uuid.UUID) ([]Schedule, error) {
+	return nil, nil
+}
+
+func (m *MockRepository) UpdateSchedule(ctx context.Context, schedule *Schedule) error {
+	return nil
+}
+
+func (m *MockRepository) SetScheduleState(ctx context.Context, schedule *Schedule, fromIndex int, fromStatus ScheduleStatus) (bool, error) {
+	if m.SetScheduleStateFunc != nil {
+		return m.SetScheduleStateFunc(ctx, schedule, fromIndex, fromStatus)
+	}
+	stored := m.schedules[schedule.ID]
+	if stored == nil || stored.NextIndex != fromIndex || stored.Status != fromStatus {
+		return false, nil
+	}
+	copied := *schedule
+	m.schedules[schedule.ID] = &copied
+	return true, nil
+}
+
+func (m *MockRepository) DeleteSchedule(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+func (m *MockRepository) ListDueSchedules(ctx context.Context, today time.Time, limit int) ([]Schedule, error) {
+	var due []Schedule
+	for _, s := range m.schedules {
+		if s.Status == StatusActive && s.NextDate != nil && !s.NextDate.After(today) {
+			due = append(due, *s)
+		}
+	}
+	return due, nil
+}
+
+func (m *MockRepository) CreateOccurrence(ctx context.Context, occurrence *Occurrence) error {
+	if m.occurrences[occurrence.ScheduleID] == nil {
+		m.occurrences[occurrence.ScheduleID] = make(map[int]Occurrence)
+	}
+	if _, ok := m.occurrences[occurrence.ScheduleID][occurrence.Index]; ok {
+		return ErrOccurrenceDecided
+	}
+	m.occurrences[occurrence.ScheduleID][occurrence.Index] = *occurrence
+	return nil
+}
+
+func (m *MockRepository) ListSkipped(ctx context.Context, scheduleID uuid.UUID, from int) (map[int]bool, error) {
+	skipped := make(map[int]bool)
+	for index, o := range m.occurrences[scheduleID] {
+		if index >= from && o.Status == OccurrenceSkipped {
+			skipped[index] = true
+		}
+	}
+	return skipped, nil
+}
+
+type MockAccounts struct {
+	accounts.Service
+	account *accounts.Account
+}
+
+func (m *MockAccounts) AuthorizeAccount(ctx context.Context, accountID string, userID string, role accounts.MemberRole) (*accounts.Account, error) {
+	if accountID != m.account.ID.String() {
+		return nil, accounts.ErrAccountNotFound
+	}
+	return m.account, nil
+}
+
+// MockMovements guarda os lançamentos feitos por PostMovement
+type MockMovements struct {
+	movements.Service
+	posted []movements.CreateMovementRequest
+	err    error
+}
+
+func (m *MockMovements) PostMovement(ctx context.Context, accountID string, userID string, kind movements.MovementKind, req movements.CreateMovementRequest) (*movements.MovementResponse, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	if kind != movements.KindScheduled {
+		return nil, errors.New("kind inesperado: " + string(kind))
+	}
+	m.posted = append(m.posted, req)
+	return &movements.MovementResponse{ID: uuid.New(), Amount: req.Amount}, nil
+}
+
+// MockTransactor desfaz as mudanças nos agendamentos do repo quando fn falha
+type MockTransactor struct {
+	repo *MockRepository
+}
+
+func (m *MockTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if m.repo == nil {
+		return fn(ctx)
+	}
+	saved := make(map[uuid.UUID]Schedule, len(m.repo.schedules))
+	for id, s := range m.repo.schedules {
+		saved[id] = *s
+	}
+	err := fn(ctx)
+	if err != nil {
+		for id, s := range saved {
+			copied := s
+			m.repo.schedules[id] = &copied
+		}
+	}
+	return err
+}
+
+func date(s string) time.Time {
+	d, _ := time.Parse(time.DateOnly, s)
+	return d
+}
+
+func newSchedule(accountID uuid.UUID, userID uuid.UUID, start string, count int) *Schedule {
+	s := &Schedule{
+		ID:         uuid.New(),
+		AccountID:  accountID,
+		CreatedBy:  userID,
+		Amount:     -150000,
+		Frequency:  recurrence.Monthly,
+		Interval:   1,
+		StartDate:  date(start),
+		Count:      &count,
+		Adjustment: recurrence.Following,
+		Status:     StatusActive,
+	}
+	setNext(s, s.Rule(), 0)
+	return s
+}
+
+func TestService_PostDue(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	// Conta aberta no meio do dia da primeira ocorrência
+	account := &accounts.Account{ID: uuid.New(), CreatedAt: date("2024-01-05").Add(10 * time.Hour)}
+
+	t.Run("deve lançar as ocorrências vencidas uma vez, respeitando os skips", func(t *testing.T) {
+		schedule := newSchedule(account.ID, userID, "2024-01-05", 4)
+		repo := newMockRepository(schedule)
+		repo.occurrences[schedule.ID] = map[int]Occurrence{1: {ScheduleID: schedule.ID, Index: 1, Status: OccurrenceSkipped}}
+		movementsSvc := &MockMovements{}
+		service := NewService(repo, &MockAccounts{account: account}, nil, movementsSvc, &MockTransactor{})
+
+		// 05/01, 05/02 (pulada), 05/03 e 05/04 (sexta, ainda não venceu em 01/04)
+		posted, err := service.PostDue(ctx, date("2024-04-01").Add(3*time.Hour))
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if posted != 2 || len(movementsSvc.posted) != 2 {
+			t.Fatalf("esperava 2 lançamentos, obteve %d", posted)
+		}
+		if first := movementsSvc.posted[0]; !first.OccurredAt.Equal(account.CreatedAt) || first.Amount != -150000 {
+			t.Errorf("primeiro lançamento incorreto: %+v", first)
+		}
+		if second := movementsSvc.posted[1]; !second.OccurredAt.Equal(date("2024-03-05")) {
+			t.Errorf("segundo lançamento incorreto: %s", second.OccurredAt)
+		}
+
+		stored := repo.schedules[schedule.ID]
+		if stored.NextIndex != 3 || !stored.NextDate.Equal(date("2024-04-05")) || stored.Status != StatusActive {
+			t.Errorf("estado incorreto: %+v", stored)
+		}
+
+		// Outra execução no mesmo dia não lança nada de novo
+		if posted, _ := service.PostDue(ctx, date("2024-04-01")); posted != 0 {
+			t.Errorf("esperava nenhum lançamento, obteve %d", posted)
+		}
+
+		// A última ocorrência encerra o agendamento
+		if _, err := service.PostDue(ctx, date("2024-04-05")); err != nil || repo.schedules[schedule.ID].Status != StatusFinished {
+			t.Errorf("esperava o agendamento encerrado, obteve %+v (%v)", repo.schedules[schedule.ID], err)
+		}
+	})
+
+	t.Run("não deve lançar se outra réplica avançou o agendamento", func(t *testing.T) {
+		schedule := newSchedule(account.ID, userID, "2024-01-05", 2)
+		repo := newMockRepository(schedule)
+		repo.SetScheduleStateFunc = func(ctx context.Context, s *Schedule, fromIndex int, fromStatus ScheduleStatus) (bool, error) {
+			return false, nil
+		}
+		movementsSvc := &MockMovements{}
+		service := NewService(repo, &MockAccounts{account: account}, nil, movementsSvc, &MockTransactor{})
+
+		if posted, err := service.PostDue(ctx, date("2024-03-01")); err != nil || posted != 0 || len(movementsSvc.posted) != 0 {
+			t.Errorf("esperava nenhum lançamento, obteve %d (%v)", posted, err)
+		}
+	})
+
+	t.Run("deve pausar o agendamento quando a conta recusar o lançamento", func(t *testing.T) {
+		schedule := newSchedule(account.ID, userID, "2024-01-05", 2)
+		repo := newMockRepository(schedule)
+		service := NewService(repo, &MockAccounts{account: account}, nil, &MockMovements{err: accounts.ErrInsufficientFunds}, &MockTransactor{repo: repo})
+
+		if _, err := service.PostDue(ctx, date("2024-03-01")); err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		stored := repo.schedules[schedule.ID]
+		if stored.Status != StatusPaused || stored.NextIndex != 0 || stored.LastError == nil {
+			t.Errorf("esperava o agendamento pausado na primeira ocorrência, obteve %+v", stored)
+		}
+	})
+}
+
+func TestService_CreateSchedule(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	account := &accounts.Account{ID: uuid.New(), CreatedAt: date("2024-01-05").Add(10 * time.Hour)}
+	repo := newMockRepository()
+	service := NewService(repo, &MockAccounts{account: account}, nil, &MockMovements{}, &MockTransactor{})
+
+	resp, err := service.CreateSchedule(ctx, userID.String(), CreateScheduleRequest{
+		AccountID: account.ID, Amount: -1000, Frequency: recurrence.Monthly, StartDate: "2024-06-01", Adjustment: recurrence.Following,
+	})
+	if err != nil {
+		t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+	}
+	// 01/06/2024 é sábado
+	if resp.Interval != 1 || resp.NextDate == nil || *resp.NextDate != "2024-06-03" || resp.Status != StatusActive {
+		t.Errorf("agendamento incorreto: %+v", resp)
+	}
+
+	_, err = service.CreateSchedule(ctx, userID.String(), CreateScheduleRequest{
+		AccountID: account.ID, Amount: -1000, Frequency: recurrence.Monthly, StartDate: "2024-01-04",
+	})
+	if !errors.Is(err, ErrStartBeforeOpening) {
+		t.Errorf("esperava o erro %v, mas obteve %v", ErrStartBeforeOpening, err)
+	}
+
+	endDate, count := "2024-12-31", 3
+	_, err = service.CreateSchedule(ctx, userID.String(), CreateScheduleRequest{
+		AccountID: account.ID, Amount: -1000, Frequency: recurrence.Monthly, StartDate: "2024-06-01", EndDate: &endDate, Count: &count,
+	})
+	if !errors.Is(err, recurrence.ErrInvalidRule) {
+		t.Errorf("esperava o erro %v, mas obteve %v", recurrence.ErrInvalidRule, err)
+	}
+}
+
+func TestService_SkipAndResume(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	account := &accounts.Account{ID: uuid.New(), CreatedAt: date("2020-01-01")}
+
+	t.Run("deve pular a ocorrência pela data ajustada", func(t *testing.T) {
+		schedule := newSchedule(account.ID, userID, "2024-06-01", 0)
+		repo := newMockRepository(schedule)
+		service := NewService(repo, &MockAccounts{account: account}, nil, &MockMovements{}, &MockTransactor{})
+
+		occ, err := service.SkipOccurrence(ctx, schedule.ID.String(), userID.String(), SkipOccurrenceRequest{Date: "2024-06-03"})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if occ.Index != 0 || occ.NominalDate != "2024-06-01" || !occ.Skipped {
+			t.Errorf("ocorrência incorreta: %+v", occ)
+		}
+
+		if _, err := service.SkipOccurrence(ctx, schedule.ID.String(), userID.String(), SkipOccurrenceRequest{Date: "2024-06-03"}); !errors.Is(err, ErrOccurrenceDecided) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrOccurrenceDecided, err)
+		}
+		if _, err := service.SkipOccurrence(ctx, schedule.ID.String(), userID.String(), SkipOccurrenceRequest{Date: "2024-06-01"}); !errors.Is(err, ErrOccurrenceNotFound) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrOccurrenceNotFound, err)
+		}
+
+		preview, err := service.PreviewSchedule(ctx, schedule.ID.String(), userID.String(), 2)
+		if err != nil || len(preview) != 2 || !preview[0].Skipped || preview[1].Skipped || preview[1].Date != "2024-07-01" {
+			t.Errorf("preview incorreto: %+v (%v)", preview, err)
+		}
+	})
+
+	t.Run("deve retomar a partir de hoje sem lançar o que venceu na pausa", func(t *testing.T) {
+		schedule := newSchedule(account.ID, userID, "2024-01-05", 0)
+		schedule.Status = StatusPaused
+		repo := newMockRepository(schedule)
+		service := NewService(repo, &MockAccounts{account: account}, nil, &MockMovements{}, &MockTransactor{})
+
+		resp, err := service.ResumeSchedule(ctx, schedule.ID.String(), userID.String())
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		today := truncateDay(time.Now()).Format(time.DateOnly)
+		if resp.Status != StatusActive || resp.NextDate == nil || *resp.NextDate < today {
+			t.Errorf("agendamento incorreto: %+v", resp)
+		}
+
+		if _, err := service.PauseSchedule(ctx, schedule.ID.String(), userID.String()); err != nil {
+			t.Errorf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if _, err := service.PauseSchedule(ctx, schedule.ID.String(), userID.String()); !errors.Is(err, ErrScheduleNotActive) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrScheduleNotActive, err)
+		}
+	})
+}
@@ -0,0 +1,2 @@
+-- Versão usada no controle de concorrência otimista (ETag / If-Match)
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
@@ -0,0 +1,7 @@
+-- Normaliza os tipos livres que existiam antes da lista fechada
+UPDATE accounts SET type = lower(trim(type));
+UPDATE accounts SET type = 'checking'
+WHERE type NOT IN ('checking', 'savings', 'cash', 'credit_card', 'investment', 'loan', 'asset', 'liability');
+
+ALTER TABLE accounts ADD CONSTRAINT accounts_type_check
+    CHECK (type IN ('checking', 'savings', 'cash', 'credit_card', 'investment', 'loan', 'asset', 'liability'));
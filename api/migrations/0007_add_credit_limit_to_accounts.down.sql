@@ -0,0 +1,4 @@
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_balance_within_limit;
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_credit_limit_check;
+ALTER TABLE accounts DROP COLUMN IF EXISTS overdraft_allowed;
+ALTER TABLE accounts DROP COLUMN IF EXISTS credit_limit;
@@ -0,0 +1,18 @@
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS credit_limit BIGINT NOT NULL DEFAULT 0; -- Em centavos
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS overdraft_allowed BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE accounts ADD CONSTRAINT accounts_credit_limit_check CHECK (credit_limit >= 0);
+
+-- Contas que já estavam negativas ganham um limite igual à dívida atual
+UPDATE accounts SET credit_limit = -balance
+WHERE balance < 0 AND type IN ('credit_card', 'loan', 'liability');
+
+-- Última linha de defesa para qualquer operação que mexa no saldo:
+-- só os tipos de passivo e o cheque especial podem ficar negativos, e até o limite
+ALTER TABLE accounts ADD CONSTRAINT accounts_balance_within_limit CHECK (
+    balance + CASE
+        WHEN type IN ('credit_card', 'loan', 'liability') THEN credit_limit
+        WHEN type = 'checking' AND overdraft_allowed THEN credit_limit
+        ELSE 0
+    END >= 0
+);
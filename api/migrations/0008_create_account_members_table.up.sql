@@ -0,0 +1,17 @@
+CREATE TABLE account_members (
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    user_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role       VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'editor', 'viewer')),
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (account_id, user_id)
+);
+
+CREATE INDEX idx_account_members_user_id ON account_members(user_id);
+
+-- Quem criou cada conta existente vira o primeiro owner
+INSERT INTO account_members (account_id, user_id, role, created_at, updated_at)
+SELECT id, user_id, 'owner', created_at, created_at FROM accounts;
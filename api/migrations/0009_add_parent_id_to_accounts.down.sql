@@ -0,0 +1,2 @@
+ALTER TABLE accounts DROP CONSTRAINT IF EXISTS accounts_parent_not_self;
+ALTER TABLE accounts DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,6 @@
+ALTER TABLE accounts ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES accounts(id) ON DELETE RESTRICT;
+
+-- Ciclos maiores são barrados pela aplicação; aqui só o caso trivial
+ALTER TABLE accounts ADD CONSTRAINT accounts_parent_not_self CHECK (parent_id <> id);
+
+CREATE INDEX idx_accounts_parent_id ON accounts(parent_id);
@@ -0,0 +1,18 @@
+CREATE TABLE movements (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    account_id  UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    kind        VARCHAR(20) NOT NULL CHECK (kind IN ('opening', 'manual')),
+    amount      BIGINT NOT NULL, -- Em centavos; negativo para saídas
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    occurred_at TIMESTAMPTZ NOT NULL, -- Data do fato, pode ser retroativa
+    created_by  UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_movements_account_occurred ON movements(account_id, occurred_at, id);
+
+-- O saldo atual de cada conta existente vira o lançamento de abertura,
+-- mantendo accounts.balance = soma dos movimentos
+INSERT INTO movements (account_id, kind, amount, description, occurred_at, created_by, created_at)
+SELECT id, 'opening', balance, 'Saldo inicial', created_at, user_id, created_at FROM accounts;
@@ -0,0 +1,10 @@
+-- balance é a soma dos movimentos da conta com occurred_at < as_of
+CREATE TABLE balance_snapshots (
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    as_of      TIMESTAMPTZ NOT NULL,
+    balance    BIGINT NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (account_id, as_of)
+);
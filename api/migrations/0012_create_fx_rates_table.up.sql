@@ -0,0 +1,12 @@
+-- rate é quanto vale 1 unidade de base em quote no dia rate_date
+CREATE TABLE fx_rates (
+    base       VARCHAR(10) NOT NULL,
+    quote      VARCHAR(10) NOT NULL,
+    rate_date  DATE NOT NULL,
+    rate       NUMERIC(24, 12) NOT NULL CHECK (rate > 0),
+    source     VARCHAR(50) NOT NULL,
+
+    fetched_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (base, quote, rate_date)
+);
@@ -0,0 +1,34 @@
+-- Transferência entre duas contas do usuário. Gera um lançamento de saída na
+-- origem e um de entrada no destino, ambos apontando para ela.
+-- rate é quanto vale 1 unidade da moeda de origem na de destino, já com o
+-- spread; mid_rate é a cotação de mercado da qual ele foi descontado.
+CREATE TABLE transfers (
+    id                UUID PRIMARY KEY,
+    source_account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    target_account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    source_amount     BIGINT NOT NULL CHECK (source_amount > 0),
+    source_currency   VARCHAR(10) NOT NULL,
+    target_amount     BIGINT NOT NULL CHECK (target_amount > 0),
+    target_currency   VARCHAR(10) NOT NULL,
+    rate              NUMERIC(24, 12) NOT NULL DEFAULT 1,
+    mid_rate          NUMERIC(24, 12) NOT NULL DEFAULT 1,
+    spread            NUMERIC(10, 6) NOT NULL DEFAULT 0,
+    rate_date         DATE,
+    quote_id          UUID UNIQUE,
+    description       VARCHAR(255) NOT NULL DEFAULT '',
+    occurred_at       TIMESTAMPTZ NOT NULL,
+    created_by        UUID REFERENCES users(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT transfers_distinct_accounts CHECK (source_account_id <> target_account_id)
+);
+
+CREATE INDEX idx_transfers_source ON transfers(source_account_id);
+CREATE INDEX idx_transfers_target ON transfers(target_account_id);
+
+ALTER TABLE movements ADD COLUMN transfer_id UUID REFERENCES transfers(id) ON DELETE CASCADE;
+CREATE INDEX idx_movements_transfer ON movements(transfer_id) WHERE transfer_id IS NOT NULL;
+
+ALTER TABLE movements DROP CONSTRAINT movements_kind_check;
+ALTER TABLE movements ADD CONSTRAINT movements_kind_check CHECK (kind IN ('opening', 'manual', 'transfer'));
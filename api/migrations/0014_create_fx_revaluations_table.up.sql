@@ -0,0 +1,24 @@
+-- Reavaliação cambial de uma conta em moeda estrangeira no fim do dia rev_date.
+-- balance está na moeda da conta; os demais valores na moeda funcional.
+-- carrying_amount é o valor contábil antes do ajuste (reavaliação anterior mais
+-- os movimentos do período, cada um na cotação do seu dia); adjustment é o
+-- ganho (positivo) ou perda (negativo) cambial não realizado.
+CREATE TABLE fx_revaluations (
+    id                  UUID PRIMARY KEY,
+    account_id          UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    rev_date            DATE NOT NULL,
+    currency            VARCHAR(10) NOT NULL,
+    functional_currency VARCHAR(10) NOT NULL,
+    balance             BIGINT NOT NULL,
+    rate                NUMERIC(24, 12) NOT NULL,
+    rate_date           DATE NOT NULL,
+    revalued_amount     BIGINT NOT NULL,
+    carrying_amount     BIGINT NOT NULL,
+    adjustment          BIGINT NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (account_id, rev_date)
+);
+
+CREATE INDEX idx_fx_revaluations_date ON fx_revaluations(rev_date);
@@ -0,0 +1,3 @@
+ALTER TABLE movements DROP COLUMN IF EXISTS category_id;
+
+DROP TABLE IF EXISTS categories;
@@ -0,0 +1,55 @@
+-- Categorias dos lançamentos. user_id NULL marca as categorias padrão do
+-- sistema, visíveis para todos e somente leitura; as demais pertencem ao usuário.
+-- Subcategorias têm o mesmo kind do pai.
+CREATE TABLE categories (
+    id        UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id   UUID REFERENCES users(id) ON DELETE CASCADE,
+    parent_id UUID REFERENCES categories(id) ON DELETE RESTRICT,
+    name      VARCHAR(100) NOT NULL,
+    kind      VARCHAR(10) NOT NULL CHECK (kind IN ('income', 'expense')),
+    icon      VARCHAR(50) NOT NULL DEFAULT '',
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT categories_parent_not_self CHECK (parent_id <> id)
+);
+
+-- Nomes únicos entre irmãos do mesmo dono, sem diferenciar maiúsculas
+CREATE UNIQUE INDEX idx_categories_unique_name ON categories (
+    COALESCE(user_id, '00000000-0000-0000-0000-000000000000'),
+    COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'),
+    LOWER(name)
+);
+CREATE INDEX idx_categories_user_id ON categories(user_id);
+CREATE INDEX idx_categories_parent_id ON categories(parent_id);
+
+INSERT INTO categories (name, kind, icon) VALUES
+    ('Alimentação', 'expense', 'utensils'),
+    ('Moradia', 'expense', 'home'),
+    ('Transporte', 'expense', 'car'),
+    ('Saúde', 'expense', 'heart-pulse'),
+    ('Educação', 'expense', 'graduation-cap'),
+    ('Lazer', 'expense', 'gamepad'),
+    ('Compras', 'expense', 'shopping-bag'),
+    ('Contas e serviços', 'expense', 'receipt'),
+    ('Impostos e taxas', 'expense', 'landmark'),
+    ('Outras despesas', 'expense', 'circle-minus'),
+    ('Salário', 'income', 'briefcase'),
+    ('Investimentos', 'income', 'chart-line'),
+    ('Outras receitas', 'income', 'circle-plus');
+
+INSERT INTO categories (parent_id, name, kind, icon)
+SELECT p.id, c.name, 'expense', c.icon
+FROM (VALUES
+    ('Alimentação', 'Supermercado', 'shopping-cart'),
+    ('Alimentação', 'Restaurantes', 'utensils'),
+    ('Moradia', 'Aluguel', 'key'),
+    ('Moradia', 'Energia e água', 'bolt'),
+    ('Transporte', 'Combustível', 'gas-pump'),
+    ('Transporte', 'Transporte público', 'bus')
+) AS c(parent, name, icon)
+JOIN categories p ON p.name = c.parent AND p.user_id IS NULL AND p.parent_id IS NULL;
+
+ALTER TABLE movements ADD COLUMN category_id UUID REFERENCES categories(id) ON DELETE RESTRICT;
+CREATE INDEX idx_movements_category ON movements(category_id) WHERE category_id IS NOT NULL;
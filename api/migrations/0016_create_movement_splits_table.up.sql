@@ -0,0 +1,15 @@
+-- Divisão de um lançamento em linhas com categoria e memo próprios. A soma das
+-- linhas é igual a movements.amount (garantido pela aplicação); quando há
+-- linhas, elas substituem a categoria do lançamento nos relatórios.
+CREATE TABLE movement_splits (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    movement_id UUID NOT NULL REFERENCES movements(id) ON DELETE CASCADE,
+    category_id UUID REFERENCES categories(id) ON DELETE RESTRICT,
+    amount      BIGINT NOT NULL CHECK (amount <> 0), -- Em unidades mínimas, com o sinal do lançamento
+    memo        VARCHAR(255) NOT NULL DEFAULT '',
+    position    INT NOT NULL,
+
+    UNIQUE (movement_id, position)
+);
+
+CREATE INDEX idx_movement_splits_category ON movement_splits(category_id) WHERE category_id IS NOT NULL;
@@ -0,0 +1,7 @@
+-- Mantém os lançamentos, para accounts.balance continuar batendo com a soma
+UPDATE movements SET kind = 'manual' WHERE kind = 'scheduled';
+ALTER TABLE movements DROP CONSTRAINT movements_kind_check;
+ALTER TABLE movements ADD CONSTRAINT movements_kind_check CHECK (kind IN ('opening', 'manual', 'transfer'));
+
+DROP TABLE IF EXISTS schedule_occurrences;
+DROP TABLE IF EXISTS schedules;
@@ -0,0 +1,46 @@
+-- Lançamento recorrente de uma conta. A regra (frequency, repeat_interval, start_date,
+-- end_date ou occurrence_count, adjustment) gera as ocorrências numeradas a
+-- partir de 0; next_index é a próxima a processar e next_date a data dela já
+-- ajustada para dia útil (NULL quando o agendamento terminou).
+CREATE TABLE schedules (
+    id               UUID PRIMARY KEY,
+    account_id       UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    created_by       UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount           BIGINT NOT NULL CHECK (amount <> 0), -- Em unidades mínimas; negativo para saídas
+    description      VARCHAR(255) NOT NULL DEFAULT '',
+    category_id      UUID REFERENCES categories(id) ON DELETE SET NULL,
+    frequency        VARCHAR(10) NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly', 'yearly')),
+    repeat_interval  INT NOT NULL DEFAULT 1 CHECK (repeat_interval >= 1),
+    start_date       DATE NOT NULL,
+    end_date         DATE,
+    occurrence_count INT CHECK (occurrence_count > 0),
+    adjustment       VARCHAR(20) NOT NULL DEFAULT 'none' CHECK (adjustment IN ('none', 'following', 'preceding', 'modified_following')),
+    status           VARCHAR(10) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'paused', 'finished')),
+    next_index       INT NOT NULL DEFAULT 0,
+    next_date        DATE,
+    last_error       TEXT,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_schedules_account ON schedules(account_id);
+CREATE INDEX idx_schedules_due ON schedules(next_date) WHERE status = 'active';
+
+-- Ocorrências já decididas: lançadas (com o lançamento gerado) ou puladas pelo
+-- usuário. A chave primária impede lançar a mesma ocorrência duas vezes.
+CREATE TABLE schedule_occurrences (
+    schedule_id      UUID NOT NULL REFERENCES schedules(id) ON DELETE CASCADE,
+    occurrence_index INT NOT NULL,
+    nominal_date     DATE NOT NULL,
+    occurrence_date  DATE NOT NULL,
+    status           VARCHAR(10) NOT NULL CHECK (status IN ('posted', 'skipped')),
+    movement_id      UUID REFERENCES movements(id) ON DELETE SET NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (schedule_id, occurrence_index)
+);
+
+ALTER TABLE movements DROP CONSTRAINT movements_kind_check;
+ALTER TABLE movements ADD CONSTRAINT movements_kind_check CHECK (kind IN ('opening', 'manual', 'transfer', 'scheduled'));
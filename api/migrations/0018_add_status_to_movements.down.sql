@@ -0,0 +1,17 @@
+ALTER TABLE accounts DROP CONSTRAINT accounts_balance_within_limit;
+ALTER TABLE accounts ADD CONSTRAINT accounts_balance_within_limit CHECK (
+    balance + CASE
+        WHEN type IN ('credit_card', 'loan', 'liability') THEN credit_limit
+        WHEN type = 'checking' AND overdraft_allowed THEN credit_limit
+        ELSE 0
+    END >= 0
+);
+ALTER TABLE accounts DROP COLUMN IF EXISTS held;
+
+-- Sem a coluna status, retenções e anulados pareceriam lançamentos efetivados
+-- e quebrariam accounts.balance = soma dos lançamentos
+DELETE FROM movements WHERE status <> 'posted';
+DROP INDEX IF EXISTS idx_movements_pending_expiry;
+ALTER TABLE movements DROP COLUMN IF EXISTS expires_at;
+ALTER TABLE movements DROP COLUMN IF EXISTS authorized_amount;
+ALTER TABLE movements DROP COLUMN IF EXISTS status;
@@ -0,0 +1,25 @@
+-- Ciclo de vida dos lançamentos. Só os posted entram no saldo contábil
+-- (accounts.balance = soma dos lançamentos posted). Um pending é uma retenção,
+-- como a autorização de um cartão: reserva saldo disponível em accounts.held até
+-- ser capturado (vira posted, com o valor final) ou anulado (voided), pelo
+-- usuário ou por expirar em expires_at.
+ALTER TABLE movements ADD COLUMN status VARCHAR(10) NOT NULL DEFAULT 'posted'
+    CHECK (status IN ('pending', 'posted', 'voided'));
+ALTER TABLE movements ADD COLUMN authorized_amount BIGINT; -- Valor retido, quando o lançamento nasceu pending
+ALTER TABLE movements ADD COLUMN expires_at TIMESTAMPTZ;
+
+CREATE INDEX idx_movements_pending_expiry ON movements(expires_at) WHERE status = 'pending';
+
+-- Soma dos valores retidos pelos lançamentos pending, em unidades mínimas
+ALTER TABLE accounts ADD COLUMN held BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE accounts ADD CONSTRAINT accounts_held_check CHECK (held >= 0);
+
+-- As retenções contam como já gastas no limite da conta
+ALTER TABLE accounts DROP CONSTRAINT accounts_balance_within_limit;
+ALTER TABLE accounts ADD CONSTRAINT accounts_balance_within_limit CHECK (
+    balance - held + CASE
+        WHEN type IN ('credit_card', 'loan', 'liability') THEN credit_limit
+        WHEN type = 'checking' AND overdraft_allowed THEN credit_limit
+        ELSE 0
+    END >= 0
+);
@@ -0,0 +1,8 @@
+ALTER TABLE movements DROP CONSTRAINT IF EXISTS movements_reversal_check;
+-- Mantém os estornos, para accounts.balance continuar batendo com a soma
+UPDATE movements SET kind = 'manual' WHERE kind = 'reversal';
+ALTER TABLE movements DROP CONSTRAINT movements_kind_check;
+ALTER TABLE movements ADD CONSTRAINT movements_kind_check CHECK (kind IN ('opening', 'manual', 'transfer', 'scheduled'));
+
+DROP INDEX IF EXISTS idx_movements_reversal_of;
+ALTER TABLE movements DROP COLUMN IF EXISTS reversal_of;
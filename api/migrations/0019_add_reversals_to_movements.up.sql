@@ -0,0 +1,11 @@
+-- Estornos. Lançamentos efetivados nunca são editados: um engano é corrigido
+-- por um lançamento kind = 'reversal' com o sinal oposto, ligado ao original
+-- por reversal_of. Vários estornos parciais (reembolsos) podem apontar para o
+-- mesmo original, desde que juntos não passem do valor dele.
+ALTER TABLE movements ADD COLUMN reversal_of UUID REFERENCES movements(id);
+
+ALTER TABLE movements DROP CONSTRAINT movements_kind_check;
+ALTER TABLE movements ADD CONSTRAINT movements_kind_check CHECK (kind IN ('opening', 'manual', 'transfer', 'scheduled', 'reversal'));
+ALTER TABLE movements ADD CONSTRAINT movements_reversal_check CHECK ((kind = 'reversal') = (reversal_of IS NOT NULL));
+
+CREATE INDEX idx_movements_reversal_of ON movements(reversal_of) WHERE reversal_of IS NOT NULL;
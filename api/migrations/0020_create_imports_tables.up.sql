@@ -0,0 +1,47 @@
+-- Perfil de leitura do CSV de um banco, salvo pelo usuário. As colunas são
+-- nomes do cabeçalho; o valor vem de amount_column (com sinal) ou do par
+-- debit_column/credit_column.
+CREATE TABLE import_profiles (
+    id                 UUID PRIMARY KEY,
+    user_id            UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name               VARCHAR(100) NOT NULL,
+    encoding           VARCHAR(20) NOT NULL DEFAULT 'utf-8',
+    delimiter          VARCHAR(1) NOT NULL DEFAULT ';',
+    skip_rows          INT NOT NULL DEFAULT 0 CHECK (skip_rows >= 0),
+    date_column        VARCHAR(100) NOT NULL,
+    date_format        VARCHAR(20) NOT NULL,
+    description_column VARCHAR(100) NOT NULL,
+    amount_column      VARCHAR(100),
+    debit_column       VARCHAR(100),
+    credit_column      VARCHAR(100),
+    decimal_separator  VARCHAR(1) NOT NULL DEFAULT ',' CHECK (decimal_separator IN (',', '.')),
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    UNIQUE (user_id, name)
+);
+
+-- Uma importação de extrato numa conta. As linhas que não puderam ser lidas
+-- ou lançadas ficam em errors, como [{"row": 3, "message": "..."}].
+CREATE TABLE imports (
+    id            UUID PRIMARY KEY,
+    account_id    UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    created_by    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    profile_id    UUID REFERENCES import_profiles(id) ON DELETE SET NULL,
+    format        VARCHAR(10) NOT NULL CHECK (format IN ('csv')),
+    file_name     VARCHAR(255) NOT NULL DEFAULT '',
+    status        VARCHAR(10) NOT NULL CHECK (status IN ('running', 'completed', 'failed')),
+    total_rows    INT NOT NULL DEFAULT 0,
+    imported_rows INT NOT NULL DEFAULT 0,
+    failed_rows   INT NOT NULL DEFAULT 0,
+    errors        JSONB NOT NULL DEFAULT '[]',
+
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_imports_account ON imports(account_id, created_at DESC);
+
+ALTER TABLE movements DROP CONSTRAINT movements_kind_check;
+ALTER TABLE movements ADD CONSTRAINT movements_kind_check CHECK (kind IN ('opening', 'manual', 'transfer', 'scheduled', 'reversal', 'imported'));
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS import_entries;
+
+ALTER TABLE imports DROP COLUMN IF EXISTS skipped_rows;
+
+-- O histórico de OFX e QIF se perde; os lançamentos continuam na conta
+DELETE FROM imports WHERE format <> 'csv';
+ALTER TABLE imports DROP CONSTRAINT imports_format_check;
+ALTER TABLE imports ADD CONSTRAINT imports_format_check CHECK (format IN ('csv'));
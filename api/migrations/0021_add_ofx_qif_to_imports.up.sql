@@ -0,0 +1,18 @@
+ALTER TABLE imports DROP CONSTRAINT imports_format_check;
+ALTER TABLE imports ADD CONSTRAINT imports_format_check CHECK (format IN ('csv', 'ofx', 'qif'));
+
+-- Lançamentos que já estavam na conta, como os de um OFX enviado de novo
+ALTER TABLE imports ADD COLUMN skipped_rows INT NOT NULL DEFAULT 0;
+
+-- Identificador que o banco dá a cada lançamento (o FITID do OFX). A chave
+-- garante que o mesmo lançamento não entra duas vezes na conta, mesmo com
+-- duas importações do mesmo arquivo ao mesmo tempo.
+CREATE TABLE import_entries (
+    account_id  UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    external_id VARCHAR(255) NOT NULL,
+    import_id   UUID NOT NULL REFERENCES imports(id) ON DELETE CASCADE,
+    movement_id UUID NOT NULL REFERENCES movements(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (account_id, external_id)
+);
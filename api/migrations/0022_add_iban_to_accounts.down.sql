@@ -0,0 +1,6 @@
+DELETE FROM imports WHERE format = 'camt053';
+ALTER TABLE imports DROP CONSTRAINT imports_format_check;
+ALTER TABLE imports ADD CONSTRAINT imports_format_check CHECK (format IN ('csv', 'ofx', 'qif'));
+
+DROP INDEX IF EXISTS idx_accounts_user_iban;
+ALTER TABLE accounts DROP COLUMN IF EXISTS iban;
@@ -0,0 +1,10 @@
+-- IBAN da conta no banco, já normalizado (sem espaços, maiúsculo). É por ele
+-- que os extratos CAMT.053 encontram a conta e que a exportação PAIN.001
+-- identifica o pagador.
+ALTER TABLE accounts ADD COLUMN iban VARCHAR(34);
+
+-- Um dono não tem duas contas ativas com o mesmo IBAN; arquivadas não contam
+CREATE UNIQUE INDEX idx_accounts_user_iban ON accounts(user_id, iban) WHERE iban IS NOT NULL AND archived_at IS NULL;
+
+ALTER TABLE imports DROP CONSTRAINT imports_format_check;
+ALTER TABLE imports ADD CONSTRAINT imports_format_check CHECK (format IN ('csv', 'ofx', 'qif', 'camt053'));
@@ -0,0 +1,3 @@
+ALTER TABLE movements DROP COLUMN IF EXISTS reconciled_at;
+DROP TABLE IF EXISTS reconciliation_lines;
+DROP TABLE IF EXISTS reconciliations;
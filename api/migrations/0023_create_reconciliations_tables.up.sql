@@ -0,0 +1,50 @@
+-- Conciliação de uma conta com um extrato do banco. O saldo inicial é o final
+-- da conciliação anterior (ou, na primeira, o informado ou o saldo contábil
+-- antes de period_start). Só é finalizada quando ending_balance é igual ao
+-- saldo inicial mais os lançamentos casados com as linhas do extrato.
+CREATE TABLE reconciliations (
+    id                  UUID PRIMARY KEY,
+    account_id          UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    created_by          UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    period_start        DATE NOT NULL,
+    period_end          DATE NOT NULL,
+    opening_balance     BIGINT NOT NULL, -- Em unidades mínimas
+    ending_balance      BIGINT NOT NULL, -- Saldo final do extrato
+    amount_tolerance    BIGINT NOT NULL DEFAULT 0 CHECK (amount_tolerance >= 0),
+    date_tolerance_days INT NOT NULL DEFAULT 3 CHECK (date_tolerance_days >= 0),
+    status              VARCHAR(10) NOT NULL CHECK (status IN ('open', 'finalized')),
+
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finalized_at TIMESTAMPTZ,
+
+    CHECK (period_end >= period_start)
+);
+
+CREATE INDEX idx_reconciliations_account ON reconciliations(account_id, period_end DESC);
+-- Uma conta tem no máximo uma conciliação em andamento
+CREATE UNIQUE INDEX idx_reconciliations_open ON reconciliations(account_id) WHERE status = 'open';
+
+-- Linhas do extrato. movement_id é o lançamento da conta casado com a linha,
+-- pelo auto-match ou à mão; o índice único impede que um lançamento seja
+-- casado duas vezes, mesmo em conciliações diferentes.
+CREATE TABLE reconciliation_lines (
+    id                UUID PRIMARY KEY,
+    reconciliation_id UUID NOT NULL REFERENCES reconciliations(id) ON DELETE CASCADE,
+    position          INT NOT NULL,
+    occurred_on       DATE NOT NULL,
+    amount            BIGINT NOT NULL CHECK (amount <> 0), -- Em unidades mínimas; negativo para saídas
+    reference         VARCHAR(255) NOT NULL DEFAULT '',
+    description       VARCHAR(255) NOT NULL DEFAULT '',
+    movement_id       UUID REFERENCES movements(id),
+    match             VARCHAR(10) CHECK (match IN ('auto', 'manual')),
+
+    UNIQUE (reconciliation_id, position),
+    CHECK ((movement_id IS NULL) = (match IS NULL))
+);
+
+CREATE UNIQUE INDEX idx_reconciliation_lines_movement ON reconciliation_lines(movement_id) WHERE movement_id IS NOT NULL;
+
+-- Quando o lançamento entrou numa conciliação finalizada. A partir daí,
+-- categoria e divisão não mudam mais; correções são feitas por estorno.
+ALTER TABLE movements ADD COLUMN reconciled_at TIMESTAMPTZ;
@@ -0,0 +1,15 @@
+-- Pares de lançamentos que o detector de duplicados apontou e o usuário
+-- marcou como distintos; o par não volta a aparecer. first_movement_id é
+-- sempre o menor dos dois, para cada par ter uma só linha.
+CREATE TABLE duplicate_dismissals (
+    account_id         UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    first_movement_id  UUID NOT NULL REFERENCES movements(id) ON DELETE CASCADE,
+    second_movement_id UUID NOT NULL REFERENCES movements(id) ON DELETE CASCADE,
+    dismissed_by       UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (first_movement_id, second_movement_id),
+    CHECK (first_movement_id < second_movement_id)
+);
+
+CREATE INDEX idx_duplicate_dismissals_account ON duplicate_dismissals(account_id);
@@ -0,0 +1,3 @@
+-- Marcada quando o contador de assinaturas de uma passkey não avança; a
+-- partir daí a passkey não serve mais para login
+ALTER TABLE webauthn_credentials ADD COLUMN clone_warning BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,12 @@
+-- Dia pedido ao provedor que não tinha cotação própria (fim de semana,
+-- feriado): guarda qual cotação o provedor entregou no lugar, para o
+-- histórico só cair para um dia anterior quando a fonte disse isso
+CREATE TABLE fx_rate_requests (
+    base           VARCHAR(10) NOT NULL,
+    quote          VARCHAR(10) NOT NULL,
+    requested_date DATE NOT NULL,
+    rate_date      DATE NOT NULL,
+
+    PRIMARY KEY (base, quote, requested_date),
+    FOREIGN KEY (base, quote, rate_date) REFERENCES fx_rates (base, quote, rate_date) ON DELETE CASCADE
+);
@@ -0,0 +1,45 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Canal Redis onde os eventos de notificação são publicados
+const DefaultChannel = "fincore:events"
+
+type Event struct {
+	Type       string      `json:"type"`
+	UserID     string      `json:"user_id"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Payload    interface{} `json:"payload"`
+}
+
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+type redisPublisher struct {
+	client  *redis.Client
+	channel string
+}
+
+// NewRedisPublisher publica os eventos como JSON num canal pub/sub do Redis
+func NewRedisPublisher(client *redis.Client, channel string) Publisher {
+	return &redisPublisher{client: client, channel: channel}
+}
+
+func (p *redisPublisher) Publish(ctx context.Context, event Event) error {
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return p.client.Publish(ctx, p.channel, data).Err()
+}
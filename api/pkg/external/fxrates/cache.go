@@ -0,0 +1,102 @@
+package fxrates
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+type cachedProvider struct {
+	next   RateProvider
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewCachedProvider guarda no Redis, por ttl, cada cotação obtida de next. Só as
+// moedas que faltam no cache são pedidas a next; pedidos sem symbols sempre vão
+// a next, porque o cache não sabe quais moedas existem.
+func NewCachedProvider(next RateProvider, client *redis.Client, ttl time.Duration) RateProvider {
+	return &cachedProvider{next: next, client: client, ttl: ttl}
+}
+
+func (p *cachedProvider) Name() string {
+	return p.next.Name()
+}
+
+func (p *cachedProvider) key(base string, quote string, date time.Time) string {
+	day := "latest"
+	if !date.IsZero() {
+		day = Day(date).Format(time.DateOnly)
+	}
+	return "fxrates:" + p.next.Name() + ":" + base + ":" + quote + ":" + day
+}
+
+func (p *cachedProvider) Rates(ctx context.Context, base string, symbols []string, date time.Time) ([]Rate, error) {
+	base = strings.ToUpper(base)
+	symbols = NormalizeSymbols(symbols)
+	if len(symbols) == 0 {
+		rates, err := p.next.Rates(ctx, base, nil, date)
+		if err != nil {
+			return nil, err
+		}
+		p.store(ctx, rates, date)
+		return rates, nil
+	}
+
+	keys := make([]string, len(symbols))
+	for i, quote := range symbols {
+		keys[i] = p.key(base, quote, date)
+	}
+	// Falha no Redis não impede a consulta: tudo vira miss
+	cached, err := p.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		cached = make([]interface{}, len(keys))
+	}
+
+	found := make(map[string]Rate, len(symbols))
+	var missing []string
+	for i, quote := range symbols {
+		var rate Rate
+		if raw, ok := cached[i].(string); ok && json.Unmarshal([]byte(raw), &rate) == nil {
+			found[quote] = rate
+			continue
+		}
+		missing = append(missing, quote)
+	}
+
+	if len(missing) > 0 {
+		fetched, err := p.next.Rates(ctx, base, missing, date)
+		if err != nil {
+			return nil, err
+		}
+		p.store(ctx, fetched, date)
+		for _, rate := range fetched {
+			found[rate.Quote] = rate
+		}
+	}
+
+	rates := make([]Rate, 0, len(symbols))
+	for _, quote := range symbols {
+		if rate, ok := found[quote]; ok {
+			rates = append(rates, rate)
+		}
+	}
+	return rates, nil
+}
+
+// store grava as cotações sob a data pedida (não a efetiva), que é como serão
+// procuradas depois. Erros de gravação são ignorados.
+func (p *cachedProvider) store(ctx context.Context, rates []Rate, date time.Time) {
+	pipe := p.client.Pipeline()
+	for _, rate := range rates {
+		data, err := json.Marshal(rate)
+		if err != nil {
+			continue
+		}
+		pipe.Set(ctx, p.key(rate.Base, rate.Quote, date), data, p.ttl)
+	}
+	_, _ = pipe.Exec(ctx)
+}
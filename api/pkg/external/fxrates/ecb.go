@@ -0,0 +1,156 @@
+package fxrates
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Arquivos de referência publicados pelo Banco Central Europeu, todos com EUR como base
+const (
+	ECBDailyURL  = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+	ECB90DaysURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist-90d.xml"
+	ECBHistURL   = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist.xml"
+)
+
+// ecbEnvelope segue o formato gesmes do BCE:
+//
+//	<Cube><Cube time="2024-01-05"><Cube currency="USD" rate="1.0921"/>...</Cube></Cube>
+type ecbEnvelope struct {
+	Cube struct {
+		Days []struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string  `xml:"currency,attr"`
+				Rate     float64 `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube"`
+	} `xml:"Cube"`
+}
+
+// ParseECB lê um arquivo de cotações de referência do BCE (diário ou histórico)
+// e devolve as cotações de EUR para cada moeda, em ordem de data
+func ParseECB(r io.Reader) ([]Rate, error) {
+	var envelope ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, fmt.Errorf("invalid ECB file: %w", err)
+	}
+
+	var rates []Rate
+	for _, day := range envelope.Cube.Days {
+		date, err := time.Parse(time.DateOnly, day.Time)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ECB file: date %q", day.Time)
+		}
+		for _, r := range day.Rates {
+			if r.Currency == "" || r.Rate <= 0 {
+				return nil, fmt.Errorf("invalid ECB file: rate %q=%v on %s", r.Currency, r.Rate, day.Time)
+			}
+			rates = append(rates, Rate{Base: "EUR", Quote: strings.ToUpper(r.Currency), Value: r.Rate, Date: date})
+		}
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("invalid ECB file: no rates found")
+	}
+
+	sort.SliceStable(rates, func(i, j int) bool { return rates[i].Date.Before(rates[j].Date) })
+	return rates, nil
+}
+
+// LoadECB lê o arquivo do BCE de uma URL http(s) ou de um caminho local. client
+// nil usa um cliente com timeout de 1 minuto (o histórico completo tem alguns MB).
+func LoadECB(ctx context.Context, source string, client *http.Client) ([]Rate, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		f, err := os.Open(source)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ParseECB(f)
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d from %s", ErrProvider, resp.StatusCode, source)
+	}
+	return ParseECB(resp.Body)
+}
+
+// CrossRates parte das cotações de EUR e devolve, para cada dia, as cotações
+// entre todos os pares de currencies (vazio = todas as moedas do dia), passando
+// por EUR. As cotações diretas de EUR para moedas fora de currencies são mantidas.
+func CrossRates(eurRates []Rate, currencies []string) []Rate {
+	byDate := make(map[time.Time][]Rate)
+	var dates []time.Time
+	for _, r := range eurRates {
+		if _, ok := byDate[r.Date]; !ok {
+			dates = append(dates, r.Date)
+		}
+		byDate[r.Date] = append(byDate[r.Date], r)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+
+	wanted := NormalizeSymbols(currencies)
+	var result []Rate
+	for _, date := range dates {
+		table := map[string]float64{"EUR": 1}
+		for _, r := range byDate[date] {
+			table[r.Quote] = r.Value
+		}
+
+		set := wanted
+		if len(set) == 0 {
+			set = make([]string, 0, len(table))
+			for currency := range table {
+				set = append(set, currency)
+			}
+			sort.Strings(set)
+		}
+		inSet := make(map[string]bool, len(set))
+		var available []string
+		for _, currency := range set {
+			if _, ok := table[currency]; ok {
+				available = append(available, currency)
+				inSet[currency] = true
+			}
+		}
+
+		for _, base := range available {
+			others := make([]string, 0, len(available)-1)
+			for _, quote := range available {
+				if quote != base {
+					others = append(others, quote)
+				}
+			}
+			// Todas as moedas estão na tabela, então não há erro possível
+			rates, _ := crossRates(table, base, others, date)
+			result = append(result, rates...)
+		}
+
+		// Cotações diretas que o produto acima não cobriu
+		for _, r := range byDate[date] {
+			if !inSet["EUR"] || !inSet[r.Quote] {
+				result = append(result, r)
+			}
+		}
+	}
+	return result
+}
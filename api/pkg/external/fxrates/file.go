@@ -0,0 +1,92 @@
+package fxrates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+type fileProvider struct {
+	base  string
+	dates []time.Time // em ordem crescente
+	rates map[time.Time]map[string]float64
+}
+
+// rateFile é o formato do arquivo lido por NewFileProvider:
+//
+//	{"base": "EUR", "rates": {"2024-01-02": {"USD": 1.0956, "BRL": 5.3689}}}
+//
+// Cada dia diz quanto vale 1 unidade de base em cada moeda.
+type rateFile struct {
+	Base  string                        `json:"base"`
+	Rates map[string]map[string]float64 `json:"rates"`
+}
+
+// NewFileProvider carrega cotações históricas de um arquivo JSON, para
+// desenvolvimento e ambientes sem acesso à API externa. Pares sem a moeda base do
+// arquivo saem pela base (cotação cruzada).
+func NewFileProvider(path string) (RateProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseRateFile(data)
+}
+
+func parseRateFile(data []byte) (*fileProvider, error) {
+	var file rateFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid rates file: %w", err)
+	}
+	if file.Base == "" {
+		return nil, fmt.Errorf("invalid rates file: base is required")
+	}
+
+	p := &fileProvider{
+		base:  strings.ToUpper(file.Base),
+		rates: make(map[time.Time]map[string]float64, len(file.Rates)),
+	}
+	for day, values := range file.Rates {
+		date, err := time.Parse(time.DateOnly, day)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rates file: date %q", day)
+		}
+		table := make(map[string]float64, len(values)+1)
+		for currency, value := range values {
+			table[strings.ToUpper(currency)] = value
+		}
+		table[p.base] = 1
+
+		p.rates[date] = table
+		p.dates = append(p.dates, date)
+	}
+	sort.Slice(p.dates, func(i, j int) bool { return p.dates[i].Before(p.dates[j]) })
+	return p, nil
+}
+
+func (p *fileProvider) Name() string {
+	return "file"
+}
+
+func (p *fileProvider) Rates(ctx context.Context, base string, symbols []string, date time.Time) ([]Rate, error) {
+	if len(p.dates) == 0 {
+		return nil, fmt.Errorf("%w: no rates loaded", ErrRateUnavailable)
+	}
+
+	// Último dia com cotação até a data pedida
+	day := p.dates[len(p.dates)-1]
+	if !date.IsZero() {
+		date = Day(date)
+		i := sort.Search(len(p.dates), func(i int) bool { return p.dates[i].After(date) })
+		if i == 0 {
+			return nil, fmt.Errorf("%w: no rates on or before %s", ErrRateUnavailable, date.Format(time.DateOnly))
+		}
+		day = p.dates[i-1]
+	}
+
+	return crossRates(p.rates[day], strings.ToUpper(base), NormalizeSymbols(symbols), day)
+}
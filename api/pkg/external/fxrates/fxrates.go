@@ -0,0 +1,88 @@
+// Package fxrates busca cotações de câmbio diárias em fontes externas (uma API no
+// estilo exchangerate.host ou um arquivo local) e as guarda em cache no Redis.
+package fxrates
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrRateUnavailable = errors.New("exchange rate unavailable")
+	ErrProvider        = errors.New("exchange rate provider error")
+)
+
+// Rate diz quanto vale 1 unidade de Base em Quote no dia Date
+type Rate struct {
+	Base  string    `json:"base"`
+	Quote string    `json:"quote"`
+	Value float64   `json:"value"`
+	Date  time.Time `json:"date"`
+}
+
+// RateProvider devolve as cotações de base para cada moeda de symbols (todas as
+// conhecidas, se vazio). date zero pede as mais recentes; em dias sem cotação
+// (fins de semana, feriados) vale a do último dia útil anterior, e Rate.Date
+// traz o dia efetivo. Moedas sem cotação resultam em ErrRateUnavailable.
+type RateProvider interface {
+	Name() string
+	Rates(ctx context.Context, base string, symbols []string, date time.Time) ([]Rate, error)
+}
+
+// Day normaliza o instante para a meia-noite UTC do mesmo dia
+func Day(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// NormalizeSymbols passa as moedas para maiúsculas, remove repetidas e vazias,
+// e devolve em ordem alfabética
+func NormalizeSymbols(symbols []string) []string {
+	seen := make(map[string]bool, len(symbols))
+	result := make([]string, 0, len(symbols))
+	for _, s := range symbols {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		result = append(result, s)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// crossRates calcula as cotações de base a partir de uma tabela em que cada moeda
+// vale table[moeda] unidades por 1 unidade da moeda de referência
+func crossRates(table map[string]float64, base string, symbols []string, date time.Time) ([]Rate, error) {
+	baseValue, ok := table[base]
+	if !ok || baseValue <= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrRateUnavailable, base)
+	}
+
+	if len(symbols) == 0 {
+		for currency := range table {
+			if currency != base {
+				symbols = append(symbols, currency)
+			}
+		}
+		sort.Strings(symbols)
+	}
+
+	rates := make([]Rate, 0, len(symbols))
+	for _, quote := range symbols {
+		value, ok := table[quote]
+		if !ok || value <= 0 {
+			return nil, fmt.Errorf("%w: %s to %s", ErrRateUnavailable, base, quote)
+		}
+		rates = append(rates, Rate{Base: base, Quote: quote, Value: value / baseValue, Date: date})
+	}
+	return rates, nil
+}
@@ -0,0 +1,192 @@
+package fxrates
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, _ := time.Parse(time.DateOnly, s)
+	return d
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestHTTPProvider(t *testing.T) {
+	var gotPath, gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery = r.URL.Path, r.URL.RawQuery
+		switch r.URL.Query().Get("base") {
+		case "USD":
+			_, _ = w.Write([]byte(`{"success":true,"base":"USD","date":"2024-01-05","rates":{"BRL":4.9,"EUR":0.91}}`))
+		case "XXX":
+			_, _ = w.Write([]byte(`{"success":false,"error":{"code":201,"info":"invalid base currency"}}`))
+		default:
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProvider(server.URL+"/", "secret", server.Client())
+	ctx := context.Background()
+
+	t.Run("deve buscar as cotações da data pedida", func(t *testing.T) {
+		rates, err := provider.Rates(ctx, "usd", []string{"eur", "BRL", "eur"}, date("2024-01-06"))
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if gotPath != "/2024-01-06" || gotQuery != "access_key=secret&base=USD&symbols=BRL%2CEUR" {
+			t.Errorf("requisição incorreta: %s?%s", gotPath, gotQuery)
+		}
+		if len(rates) != 2 || rates[0].Quote != "BRL" || !near(rates[0].Value, 4.9) || !rates[0].Date.Equal(date("2024-01-05")) {
+			t.Errorf("cotações incorretas: %+v", rates)
+		}
+	})
+
+	t.Run("deve usar /latest sem data", func(t *testing.T) {
+		if _, err := provider.Rates(ctx, "USD", nil, time.Time{}); err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if gotPath != "/latest" {
+			t.Errorf("esperava /latest, obteve %s", gotPath)
+		}
+	})
+
+	t.Run("deve falhar para moeda sem cotação", func(t *testing.T) {
+		if _, err := provider.Rates(ctx, "USD", []string{"JPY"}, time.Time{}); !errors.Is(err, ErrRateUnavailable) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrRateUnavailable, err)
+		}
+	})
+
+	t.Run("deve repassar erros da API", func(t *testing.T) {
+		for _, base := range []string{"XXX", "GBP"} {
+			if _, err := provider.Rates(ctx, base, nil, time.Time{}); !errors.Is(err, ErrProvider) {
+				t.Errorf("%s: esperava o erro %v, mas obteve %v", base, ErrProvider, err)
+			}
+		}
+	})
+}
+
+func TestFileProvider(t *testing.T) {
+	provider, err := parseRateFile([]byte(`{
+		"base": "EUR",
+		"rates": {
+			"2024-01-05": {"USD": 1.1, "BRL": 5.5},
+			"2024-01-02": {"USD": 1.0, "BRL": 5.0}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+	}
+	ctx := context.Background()
+
+	t.Run("deve usar o último dia útil até a data", func(t *testing.T) {
+		rates, err := provider.Rates(ctx, "EUR", []string{"BRL"}, date("2024-01-04"))
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if !near(rates[0].Value, 5.0) || !rates[0].Date.Equal(date("2024-01-02")) {
+			t.Errorf("cotação incorreta: %+v", rates[0])
+		}
+	})
+
+	t.Run("deve calcular cotações cruzadas", func(t *testing.T) {
+		rates, err := provider.Rates(ctx, "USD", nil, time.Time{})
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		// 1 USD = 5.5/1.1 BRL = 1/1.1 EUR
+		if len(rates) != 2 || rates[0].Quote != "BRL" || !near(rates[0].Value, 5) || !near(rates[1].Value, 1/1.1) {
+			t.Errorf("cotações incorretas: %+v", rates)
+		}
+	})
+
+	t.Run("deve falhar antes da primeira data ou para moeda desconhecida", func(t *testing.T) {
+		if _, err := provider.Rates(ctx, "EUR", []string{"USD"}, date("2023-12-31")); !errors.Is(err, ErrRateUnavailable) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrRateUnavailable, err)
+		}
+		if _, err := provider.Rates(ctx, "EUR", []string{"JPY"}, time.Time{}); !errors.Is(err, ErrRateUnavailable) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrRateUnavailable, err)
+		}
+	})
+}
+
+const ecbSample = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender><gesmes:name>European Central Bank</gesmes:name></gesmes:Sender>
+	<Cube>
+		<Cube time="2024-01-05">
+			<Cube currency="USD" rate="1.0921"/>
+			<Cube currency="BRL" rate="5.3487"/>
+			<Cube currency="JPY" rate="158.59"/>
+		</Cube>
+		<Cube time="2024-01-04">
+			<Cube currency="USD" rate="1.0953"/>
+			<Cube currency="BRL" rate="5.3628"/>
+			<Cube currency="JPY" rate="158.04"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestParseECB(t *testing.T) {
+	rates, err := ParseECB(strings.NewReader(ecbSample))
+	if err != nil {
+		t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+	}
+	if len(rates) != 6 || !rates[0].Date.Equal(date("2024-01-04")) || rates[0].Base != "EUR" || rates[0].Quote != "USD" || !near(rates[0].Value, 1.0953) {
+		t.Errorf("cotações incorretas: %+v", rates)
+	}
+
+	for _, invalid := range []string{`<Envelope/>`, `<Envelope><Cube><Cube time="ontem"><Cube currency="USD" rate="1"/></Cube></Cube></Envelope>`, `não é xml`} {
+		if _, err := ParseECB(strings.NewReader(invalid)); err == nil {
+			t.Errorf("%s: esperava erro", invalid)
+		}
+	}
+}
+
+func TestCrossRates(t *testing.T) {
+	eur, _ := ParseECB(strings.NewReader(ecbSample))
+
+	find := func(rates []Rate, base, quote, day string) *Rate {
+		for i, r := range rates {
+			if r.Base == base && r.Quote == quote && r.Date.Equal(date(day)) {
+				return &rates[i]
+			}
+		}
+		return nil
+	}
+
+	t.Run("deve derivar todos os pares passando por EUR", func(t *testing.T) {
+		rates := CrossRates(eur, nil)
+		// 4 moedas (com EUR) → 12 pares por dia
+		if len(rates) != 24 {
+			t.Errorf("esperava 24 cotações, obteve %d", len(rates))
+		}
+		if r := find(rates, "USD", "BRL", "2024-01-05"); r == nil || !near(r.Value, 5.3487/1.0921) {
+			t.Errorf("cotação cruzada incorreta: %+v", r)
+		}
+		if r := find(rates, "BRL", "EUR", "2024-01-04"); r == nil || !near(r.Value, 1/5.3628) {
+			t.Errorf("cotação inversa incorreta: %+v", r)
+		}
+	})
+
+	t.Run("deve limitar os pares às moedas pedidas e manter as cotações de EUR", func(t *testing.T) {
+		rates := CrossRates(eur, []string{"usd", "BRL"})
+		// USD↔BRL nos dois sentidos + EUR→(USD, BRL, JPY), por dia
+		if len(rates) != 10 {
+			t.Errorf("esperava 10 cotações, obteve %d: %+v", len(rates), rates)
+		}
+		if find(rates, "EUR", "JPY", "2024-01-05") == nil || find(rates, "JPY", "USD", "2024-01-05") != nil {
+			t.Errorf("pares incorretos: %+v", rates)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package fxrates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type httpProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewHTTPProvider consulta uma API no formato do exchangerate.host:
+// GET {baseURL}/latest ou {baseURL}/2006-01-02, com base, symbols e access_key
+// (opcional) na query. client nil usa um cliente com timeout de 10s.
+func NewHTTPProvider(baseURL string, apiKey string, client *http.Client) RateProvider {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &httpProvider{baseURL: strings.TrimRight(baseURL, "/"), apiKey: apiKey, client: client}
+}
+
+func (p *httpProvider) Name() string {
+	return "http"
+}
+
+type httpRatesResponse struct {
+	Success *bool              `json:"success"`
+	Base    string             `json:"base"`
+	Date    string             `json:"date"`
+	Rates   map[string]float64 `json:"rates"`
+	Error   *struct {
+		Code int    `json:"code"`
+		Info string `json:"info"`
+	} `json:"error"`
+}
+
+func (p *httpProvider) Rates(ctx context.Context, base string, symbols []string, date time.Time) ([]Rate, error) {
+	base = strings.ToUpper(base)
+	symbols = NormalizeSymbols(symbols)
+
+	path := "/latest"
+	if !date.IsZero() {
+		path = "/" + Day(date).Format(time.DateOnly)
+	}
+	query := url.Values{}
+	query.Set("base", base)
+	if len(symbols) > 0 {
+		query.Set("symbols", strings.Join(symbols, ","))
+	}
+	if p.apiKey != "" {
+		query.Set("access_key", p.apiKey)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrProvider, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: unexpected status %d", ErrProvider, resp.StatusCode)
+	}
+
+	var body httpRatesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("%w: invalid response: %v", ErrProvider, err)
+	}
+	if body.Success != nil && !*body.Success {
+		if body.Error != nil {
+			return nil, fmt.Errorf("%w: %d %s", ErrProvider, body.Error.Code, body.Error.Info)
+		}
+		return nil, fmt.Errorf("%w: request failed", ErrProvider)
+	}
+	if body.Base != "" && !strings.EqualFold(body.Base, base) {
+		return nil, fmt.Errorf("%w: asked for base %s, got %s", ErrProvider, base, body.Base)
+	}
+
+	rateDate, err := time.Parse(time.DateOnly, body.Date)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid date %q", ErrProvider, body.Date)
+	}
+
+	table := make(map[string]float64, len(body.Rates)+1)
+	for currency, value := range body.Rates {
+		table[strings.ToUpper(currency)] = value
+	}
+	table[base] = 1
+	return crossRates(table, base, symbols, rateDate)
+}
@@ -0,0 +1,51 @@
+// Package iban valida e normaliza números de conta no formato IBAN (ISO 13616)
+package iban
+
+import (
+	"errors"
+	"strings"
+)
+
+var ErrInvalidIBAN = errors.New("invalid IBAN")
+
+// Tamanho do IBAN nos países mais comuns; países fora da lista só passam
+// pelo dígito verificador
+var lengths = map[string]int{
+	"AT": 20, "BE": 16, "BR": 29, "CH": 21, "DE": 22, "DK": 18, "ES": 24, "FI": 18,
+	"FR": 27, "GB": 22, "IE": 22, "IT": 27, "LU": 20, "NL": 18, "NO": 15, "PL": 28,
+	"PT": 25, "SE": 24,
+}
+
+// Normalize tira espaços, passa para maiúsculas e confere o tamanho e o
+// dígito verificador (mod 97). "de89 3704 0044 0532 0130 00" vira
+// "DE89370400440532013000".
+func Normalize(value string) (string, error) {
+	s := strings.ToUpper(strings.Join(strings.Fields(value), ""))
+	if len(s) < 15 || len(s) > 34 {
+		return "", ErrInvalidIBAN
+	}
+	for i, c := range s {
+		letter := c >= 'A' && c <= 'Z'
+		digit := c >= '0' && c <= '9'
+		if (i < 2 && !letter) || (i >= 2 && i < 4 && !digit) || (!letter && !digit) {
+			return "", ErrInvalidIBAN
+		}
+	}
+	if n, ok := lengths[s[:2]]; ok && len(s) != n {
+		return "", ErrInvalidIBAN
+	}
+
+	// País e dígitos vão para o fim e cada letra vira dois dígitos (A=10)
+	remainder := 0
+	for _, c := range s[4:] + s[:4] {
+		if c >= 'A' {
+			remainder = (remainder*100 + int(c-'A'+10)) % 97
+		} else {
+			remainder = (remainder*10 + int(c-'0')) % 97
+		}
+	}
+	if remainder != 1 {
+		return "", ErrInvalidIBAN
+	}
+	return s, nil
+}
@@ -0,0 +1,26 @@
+package iban
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	valid := map[string]string{
+		"de89 3704 0044 0532 0130 00":          "DE89370400440532013000",
+		"GB82WEST12345698765432":               "GB82WEST12345698765432",
+		"BR15 0000 0000 0000 1093 2840 814 P2": "BR1500000000000010932840814P2",
+	}
+	for input, want := range valid {
+		got, err := Normalize(input)
+		if err != nil || got != want {
+			t.Errorf("Normalize(%q) = %q, %v; esperava %q", input, got, err, want)
+		}
+	}
+
+	for _, input := range []string{"", "DE89370400440532013001", "DE8937040044053201300", "1289370400440532013000", "DE89-3704-0044-0532-0130-00"} {
+		if _, err := Normalize(input); !errors.Is(err, ErrInvalidIBAN) {
+			t.Errorf("Normalize(%q): esperava o erro %v, mas obteve %v", input, ErrInvalidIBAN, err)
+		}
+	}
+}
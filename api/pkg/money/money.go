@@ -0,0 +1,292 @@
+// Package money representa valores monetários em unidades mínimas (centavos,
+// ienes, fils...) respeitando o número de casas decimais de cada moeda ISO 4217.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrCurrencyMismatch = errors.New("currency mismatch")
+	ErrOverflow         = errors.New("amount overflow")
+	ErrInvalidAmount    = errors.New("invalid amount")
+)
+
+// Casas decimais das moedas que fogem do padrão de 2 (ISO 4217)
+var exponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// Exponent devolve o número de casas decimais da moeda
+func Exponent(currency string) int {
+	if exp, ok := exponents[strings.ToUpper(currency)]; ok {
+		return exp
+	}
+	return 2
+}
+
+// Money é um valor em unidades mínimas da moeda: New(1234, "BRL") é R$ 12,34
+// e New(1234, "JPY") é ¥1234.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: strings.ToUpper(currency)}
+}
+
+// Parse lê um valor decimal ("12.34", "-0.5") na moeda. Mais casas do que a
+// moeda tem é erro, para nunca arredondar o que o usuário digitou.
+func Parse(value string, currency string) (Money, error) {
+	currency = strings.ToUpper(currency)
+	exp := Exponent(currency)
+
+	value = strings.TrimSpace(value)
+	if _, frac, ok := strings.Cut(value, "."); ok && len(frac) > exp {
+		return Money{}, fmt.Errorf("%w: %s has %d decimal places", ErrInvalidAmount, currency, exp)
+	}
+
+	r, ok := new(big.Rat).SetString(value)
+	if !ok || strings.ContainsAny(value, "/eE") {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, value)
+	}
+	r.Mul(r, new(big.Rat).SetInt(pow10(exp)))
+
+	amount, err := toInt64(r.Num())
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+func (m Money) IsZero() bool     { return m.Amount == 0 }
+func (m Money) IsNegative() bool { return m.Amount < 0 }
+
+func (m Money) sameCurrency(o Money) error {
+	if m.Currency != o.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+	}
+	return nil
+}
+
+// Add soma dois valores da mesma moeda, sem estourar int64
+func (m Money) Add(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	if (o.Amount > 0 && m.Amount > math.MaxInt64-o.Amount) || (o.Amount < 0 && m.Amount < math.MinInt64-o.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: m.Currency}, nil
+}
+
+// Sub subtrai dois valores da mesma moeda, sem estourar int64
+func (m Money) Sub(o Money) (Money, error) {
+	if err := m.sameCurrency(o); err != nil {
+		return Money{}, err
+	}
+	if (o.Amount < 0 && m.Amount > math.MaxInt64+o.Amount) || (o.Amount > 0 && m.Amount < math.MinInt64+o.Amount) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: m.Amount - o.Amount, Currency: m.Currency}, nil
+}
+
+// Neg inverte o sinal
+func (m Money) Neg() (Money, error) {
+	if m.Amount == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return Money{Amount: -m.Amount, Currency: m.Currency}, nil
+}
+
+// Allocate divide o valor proporcionalmente aos pesos sem perder centavos: cada
+// parte recebe o piso da sua fração e as unidades que sobram vão, uma a uma, para
+// as primeiras partes. Allocate(1, 1, 1) de R$ 0,10 dá 0,04 + 0,03 + 0,03.
+func (m Money) Allocate(ratios ...int) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, fmt.Errorf("%w: no ratios", ErrInvalidAmount)
+	}
+	total := big.NewInt(0)
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, fmt.Errorf("%w: negative ratio", ErrInvalidAmount)
+		}
+		total.Add(total, big.NewInt(int64(r)))
+	}
+	if total.Sign() == 0 {
+		return nil, fmt.Errorf("%w: ratios sum to zero", ErrInvalidAmount)
+	}
+
+	// Trabalha com o valor absoluto para que o resto seja sempre positivo
+	abs := new(big.Int).Abs(big.NewInt(m.Amount))
+	parts := make([]*big.Int, len(ratios))
+	remainder := new(big.Int).Set(abs)
+	for i, r := range ratios {
+		parts[i] = new(big.Int).Mul(abs, big.NewInt(int64(r)))
+		parts[i].Quo(parts[i], total)
+		remainder.Sub(remainder, parts[i])
+	}
+	for i := 0; remainder.Sign() > 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].Add(parts[i], big.NewInt(1))
+		remainder.Sub(remainder, big.NewInt(1))
+	}
+
+	result := make([]Money, len(parts))
+	for i, p := range parts {
+		if m.Amount < 0 {
+			p.Neg(p)
+		}
+		result[i] = Money{Amount: p.Int64(), Currency: m.Currency}
+	}
+	return result, nil
+}
+
+// Split divide o valor em n partes o mais iguais possível
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("%w: cannot split into %d parts", ErrInvalidAmount, n)
+	}
+	ratios := make([]int, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
+
+// Convert aplica a cotação (quanto vale 1 unidade desta moeda na moeda to),
+// ajustando as casas decimais entre as moedas e arredondando meio para o par
+// (arredondamento bancário).
+func (m Money) Convert(rate float64, to string) (Money, error) {
+	if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return Money{}, fmt.Errorf("%w: rate %v", ErrInvalidAmount, rate)
+	}
+	// A representação decimal mais curta evita carregar o erro binário do float
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(rate, 'f', -1, 64))
+
+	to = strings.ToUpper(to)
+	v := new(big.Rat).SetInt64(m.Amount)
+	v.Mul(v, r)
+	v.Mul(v, new(big.Rat).SetInt(pow10(Exponent(to))))
+	v.Quo(v, new(big.Rat).SetInt(pow10(Exponent(m.Currency))))
+
+	amount, err := RoundHalfEven(v)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: to}, nil
+}
+
+// RoundHalfEven arredonda para o inteiro mais próximo; empates vão para o par
+func RoundHalfEven(r *big.Rat) (int64, error) {
+	num, den := r.Num(), r.Denom()
+	q, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+
+	// Compara 2*|resto| com o denominador para saber de que lado está
+	twice := new(big.Int).Abs(rem)
+	twice.Lsh(twice, 1)
+	switch cmp := twice.Cmp(den); {
+	case cmp > 0, cmp == 0 && q.Bit(0) == 1:
+		if num.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return toInt64(q)
+}
+
+// Decimal formata o valor com as casas da moeda, sempre com ponto: "-12.34"
+func (m Money) Decimal() string {
+	exp := Exponent(m.Currency)
+	digits := new(big.Int).Abs(big.NewInt(m.Amount)).String()
+
+	sign := ""
+	if m.Amount < 0 {
+		sign = "-"
+	}
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+type moneyJSON struct {
+	Amount   *int64 `json:"amount,omitempty"`
+	Currency string `json:"currency"`
+	Value    string `json:"value,omitempty"`
+}
+
+// MarshalJSON escreve o valor em unidades mínimas e também como decimal:
+// {"amount": 1234, "currency": "BRL", "value": "12.34"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	amount := m.Amount
+	return json.Marshal(moneyJSON{Amount: &amount, Currency: m.Currency, Value: m.Decimal()})
+}
+
+// UnmarshalJSON aceita o objeto com amount e/ou value (se vierem os dois, precisam
+// bater) ou um número puro em unidades mínimas, caso em que a moeda fica vazia para
+// quem recebeu completar.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var amount int64
+	if err := json.Unmarshal(data, &amount); err == nil {
+		*m = Money{Amount: amount}
+		return nil
+	}
+
+	var raw moneyJSON
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%w: expected minor units or {amount, currency, value}", ErrInvalidAmount)
+	}
+	currency := strings.ToUpper(raw.Currency)
+
+	switch {
+	case raw.Value != "":
+		if currency == "" {
+			return fmt.Errorf("%w: currency is required with a decimal value", ErrInvalidAmount)
+		}
+		parsed, err := Parse(raw.Value, currency)
+		if err != nil {
+			return err
+		}
+		if raw.Amount != nil && *raw.Amount != parsed.Amount {
+			return fmt.Errorf("%w: amount and value disagree", ErrInvalidAmount)
+		}
+		*m = parsed
+	case raw.Amount != nil:
+		*m = Money{Amount: *raw.Amount, Currency: currency}
+	default:
+		return fmt.Errorf("%w: amount or value is required", ErrInvalidAmount)
+	}
+	return nil
+}
+
+func pow10(exp int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
+
+func toInt64(i *big.Int) (int64, error) {
+	if !i.IsInt64() {
+		return 0, ErrOverflow
+	}
+	return i.Int64(), nil
+}
@@ -0,0 +1,148 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestParseAndDecimal(t *testing.T) {
+	cases := []struct {
+		value    string
+		currency string
+		amount   int64
+		decimal  string
+	}{
+		{"12.34", "BRL", 1234, "12.34"},
+		{"-0.5", "BRL", -50, "-0.50"},
+		{"0.07", "usd", 7, "0.07"},
+		{"1500", "JPY", 1500, "1500"},
+		{"1.234", "KWD", 1234, "1.234"},
+		{"0.001", "KWD", 1, "0.001"},
+	}
+	for _, c := range cases {
+		m, err := Parse(c.value, c.currency)
+		if err != nil {
+			t.Fatalf("%s %s: esperava nenhum erro, mas obteve %v", c.value, c.currency, err)
+		}
+		if m.Amount != c.amount || m.Decimal() != c.decimal {
+			t.Errorf("%s %s: obteve %d (%s)", c.value, c.currency, m.Amount, m.Decimal())
+		}
+	}
+
+	for _, invalid := range [][2]string{{"12.345", "BRL"}, {"1.5", "JPY"}, {"abc", "BRL"}, {"1e3", "BRL"}} {
+		if _, err := Parse(invalid[0], invalid[1]); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("%s %s: esperava o erro %v, mas obteve %v", invalid[0], invalid[1], ErrInvalidAmount, err)
+		}
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	a, b := New(1000, "BRL"), New(250, "BRL")
+
+	if sum, err := a.Add(b); err != nil || sum.Amount != 1250 {
+		t.Errorf("soma incorreta: %v %v", sum, err)
+	}
+	if diff, err := b.Sub(a); err != nil || diff.Amount != -750 {
+		t.Errorf("subtração incorreta: %v %v", diff, err)
+	}
+	if _, err := a.Add(New(1, "USD")); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("esperava o erro %v, mas obteve %v", ErrCurrencyMismatch, err)
+	}
+	if _, err := New(math.MaxInt64, "BRL").Add(New(1, "BRL")); !errors.Is(err, ErrOverflow) {
+		t.Errorf("esperava o erro %v, mas obteve %v", ErrOverflow, err)
+	}
+	if _, err := New(math.MinInt64, "BRL").Sub(New(1, "BRL")); !errors.Is(err, ErrOverflow) {
+		t.Errorf("esperava o erro %v, mas obteve %v", ErrOverflow, err)
+	}
+	if _, err := New(math.MinInt64, "BRL").Neg(); !errors.Is(err, ErrOverflow) {
+		t.Errorf("esperava o erro %v, mas obteve %v", ErrOverflow, err)
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	sum := func(parts []Money) int64 {
+		var total int64
+		for _, p := range parts {
+			total += p.Amount
+		}
+		return total
+	}
+
+	parts, err := New(10, "BRL").Split(3)
+	if err != nil || parts[0].Amount != 4 || parts[1].Amount != 3 || parts[2].Amount != 3 {
+		t.Errorf("divisão incorreta: %v %v", parts, err)
+	}
+
+	parts, _ = New(-100, "BRL").Allocate(1, 2)
+	if sum(parts) != -100 || parts[0].Amount != -34 || parts[1].Amount != -66 {
+		t.Errorf("alocação negativa incorreta: %v", parts)
+	}
+
+	parts, _ = New(5, "JPY").Allocate(0, 1, 1)
+	if sum(parts) != 5 || parts[0].Amount != 0 {
+		t.Errorf("peso zero não deveria receber resto: %v", parts)
+	}
+
+	if _, err := New(5, "BRL").Allocate(0, 0); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidAmount, err)
+	}
+}
+
+func TestRoundHalfEven(t *testing.T) {
+	cases := map[string]int64{"5/2": 2, "7/2": 4, "-5/2": -2, "-7/2": -4, "26/10": 3, "-26/10": -3, "24/10": 2}
+	for in, want := range cases {
+		r, _ := new(big.Rat).SetString(in)
+		if got, err := RoundHalfEven(r); err != nil || got != want {
+			t.Errorf("%s: esperava %d, obteve %d (%v)", in, want, got, err)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	// US$ 10,00 a 5,4321 = R$ 54,321 → 54,32
+	if got, _ := New(1000, "USD").Convert(5.4321, "BRL"); got.Amount != 5432 || got.Currency != "BRL" {
+		t.Errorf("conversão incorreta: %v", got)
+	}
+	// ¥1000 a 0.037 = R$ 37,00
+	if got, _ := New(1000, "JPY").Convert(0.037, "BRL"); got.Amount != 3700 {
+		t.Errorf("conversão de moeda sem casas incorreta: %v", got)
+	}
+	// R$ 0,05 a 0,5 = 2,5 centavos → 2 (empate vai para o par)
+	if got, _ := New(5, "BRL").Convert(0.5, "USD"); got.Amount != 2 {
+		t.Errorf("arredondamento bancário incorreto: %v", got)
+	}
+	if _, err := New(5, "BRL").Convert(0, "USD"); !errors.Is(err, ErrInvalidAmount) {
+		t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidAmount, err)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, _ := json.Marshal(New(-1234, "BRL"))
+	if string(data) != `{"amount":-1234,"currency":"BRL","value":"-12.34"}` {
+		t.Errorf("JSON incorreto: %s", data)
+	}
+
+	cases := map[string]Money{
+		`1500`:                                          {Amount: 1500},
+		`{"amount":1500,"currency":"brl"}`:              {Amount: 1500, Currency: "BRL"},
+		`{"value":"15.00","currency":"BRL"}`:            {Amount: 1500, Currency: "BRL"},
+		`{"value":"1500","currency":"JPY"}`:             {Amount: 1500, Currency: "JPY"},
+		`{"amount":12,"value":"0.12","currency":"USD"}`: {Amount: 12, Currency: "USD"},
+	}
+	for in, want := range cases {
+		var got Money
+		if err := json.Unmarshal([]byte(in), &got); err != nil || got != want {
+			t.Errorf("%s: esperava %v, obteve %v (%v)", in, want, got, err)
+		}
+	}
+
+	for _, invalid := range []string{`{"value":"1.00"}`, `{"amount":1,"value":"0.02","currency":"BRL"}`, `{"currency":"BRL"}`, `"abc"`} {
+		var got Money
+		if err := json.Unmarshal([]byte(invalid), &got); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("%s: esperava o erro %v, mas obteve %v", invalid, ErrInvalidAmount, err)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Package pagination implementa paginação por keyset (cursor) para as listagens da API.
+//
+// O cliente envia ?limit=&cursor=&sort=. O sort aceita o nome de um campo, com "-"
+// na frente para ordem decrescente. O cursor é opaco e carrega o valor do campo de
+// ordenação e o ID do último item da página anterior.
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidLimit  = errors.New("invalid limit")
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort field")
+)
+
+// Kind diz como o valor do campo de ordenação é guardado no cursor
+type Kind int
+
+const (
+	KindString Kind = iota
+	KindInt
+	KindTime
+)
+
+// SortField é um campo pelo qual a listagem pode ser ordenada
+type SortField struct {
+	Name   string // nome aceito em ?sort=
+	Column string // coluna SQL correspondente
+	Kind   Kind
+}
+
+type cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// Params são os parâmetros de paginação já validados
+type Params struct {
+	Limit int
+	Sort  SortField
+	Desc  bool
+
+	after *cursor
+}
+
+// Parse lê limit, cursor e sort da query string. defaultSort segue o mesmo
+// formato do parâmetro (ex: "-created_at").
+func Parse(q url.Values, fields []SortField, defaultSort string) (Params, error) {
+	p := Params{Limit: DefaultLimit}
+
+	if raw := q.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > MaxLimit {
+			return Params{}, fmt.Errorf("%w: must be between 1 and %d", ErrInvalidLimit, MaxLimit)
+		}
+		p.Limit = limit
+	}
+
+	sort := q.Get("sort")
+	if sort == "" {
+		sort = defaultSort
+	}
+	p.Desc = strings.HasPrefix(sort, "-")
+	name := strings.TrimPrefix(sort, "-")
+
+	found := false
+	for _, f := range fields {
+		if f.Name == name {
+			p.Sort, found = f, true
+			break
+		}
+	}
+	if !found {
+		return Params{}, fmt.Errorf("%w: %q", ErrInvalidSort, name)
+	}
+
+	if raw := q.Get("cursor"); raw != "" {
+		c, err := decodeCursor(raw)
+		// Um cursor só vale para a mesma ordenação em que foi gerado
+		if err != nil || c.Sort != sort {
+			return Params{}, ErrInvalidCursor
+		}
+		if _, err := parseValue(c.Value, p.Sort.Kind); err != nil {
+			return Params{}, ErrInvalidCursor
+		}
+		// O ID vai para a comparação com a coluna UUID; inválido daria erro no banco
+		if _, err := uuid.Parse(c.ID); err != nil {
+			return Params{}, ErrInvalidCursor
+		}
+		p.after = c
+	}
+
+	return p, nil
+}
+
+func (p Params) sortParam() string {
+	if p.Desc {
+		return "-" + p.Sort.Name
+	}
+	return p.Sort.Name
+}
+
+// Where devolve a condição de keyset para continuar depois do cursor, usando
+// placeholders a partir de $argPos. Sem cursor, devolve "TRUE" e nenhum argumento.
+func (p Params) Where(idColumn string, argPos int) (string, []interface{}) {
+	if p.after == nil {
+		return "TRUE", nil
+	}
+
+	op := ">"
+	if p.Desc {
+		op = "<"
+	}
+	// Parse já validou o valor
+	value, _ := parseValue(p.after.Value, p.Sort.Kind)
+
+	clause := fmt.Sprintf("(%s, %s) %s ($%d, $%d)", p.Sort.Column, idColumn, op, argPos, argPos+1)
+	return clause, []interface{}{value, p.after.ID}
+}
+
+// OrderBy devolve a ordenação SQL, com o ID como desempate
+func (p Params) OrderBy(idColumn string) string {
+	dir := "ASC"
+	if p.Desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf("%s %s, %s %s", p.Sort.Column, dir, idColumn, dir)
+}
+
+// FetchLimit é quantos itens buscar: um a mais para saber se existe próxima página
+func (p Params) FetchLimit() int {
+	return p.Limit + 1
+}
+
+// Page é o envelope de resposta das listagens paginadas
+type Page[T any] struct {
+	Items      []T     `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+// NewPage monta a página a partir de até FetchLimit itens. keyOf devolve o valor
+// do campo de ordenação e o ID de um item, usados no próximo cursor.
+func NewPage[T any](items []T, p Params, keyOf func(T) (interface{}, string)) Page[T] {
+	page := Page[T]{Items: items}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+
+	if len(items) > p.Limit {
+		page.Items = items[:p.Limit]
+		value, id := keyOf(page.Items[p.Limit-1])
+		next := encodeCursor(cursor{Sort: p.sortParam(), Value: formatValue(value), ID: id})
+		page.NextCursor = &next
+	}
+	return page
+}
+
+// Map converte os itens de uma página mantendo o cursor
+func Map[T any, U any](page Page[T], fn func(T) U) Page[U] {
+	items := make([]U, len(page.Items))
+	for i, item := range page.Items {
+		items[i] = fn(item)
+	}
+	return Page[U]{Items: items, NextCursor: page.NextCursor}
+}
+
+func encodeCursor(c cursor) string {
+	// Marshal de uma struct só com strings não falha
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(raw string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func parseValue(raw string, kind Kind) (interface{}, error) {
+	switch kind {
+	case KindInt:
+		return strconv.ParseInt(raw, 10, 64)
+	case KindTime:
+		return time.Parse(time.RFC3339Nano, raw)
+	default:
+		return raw, nil
+	}
+}
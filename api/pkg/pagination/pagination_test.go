@@ -0,0 +1,123 @@
+package pagination
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type item struct {
+	ID        string
+	CreatedAt time.Time
+}
+
+var testFields = []SortField{
+	{Name: "created_at", Column: "created_at", Kind: KindTime},
+	{Name: "name", Column: "name", Kind: KindString},
+}
+
+func TestParse(t *testing.T) {
+	t.Run("deve usar os valores padrão", func(t *testing.T) {
+		p, err := Parse(url.Values{}, testFields, "-created_at")
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if p.Limit != DefaultLimit || p.Sort.Name != "created_at" || !p.Desc {
+			t.Errorf("parâmetros inesperados: %+v", p)
+		}
+		if clause, args := p.Where("id", 1); clause != "TRUE" || args != nil {
+			t.Errorf("sem cursor não deveria haver filtro: %s %v", clause, args)
+		}
+		if order := p.OrderBy("id"); order != "created_at DESC, id DESC" {
+			t.Errorf("ordenação incorreta: %s", order)
+		}
+	})
+
+	t.Run("deve rejeitar limit fora da faixa", func(t *testing.T) {
+		for _, limit := range []string{"0", "101", "abc"} {
+			_, err := Parse(url.Values{"limit": {limit}}, testFields, "name")
+			if !errors.Is(err, ErrInvalidLimit) {
+				t.Errorf("limit=%s: esperava o erro %v, mas obteve %v", limit, ErrInvalidLimit, err)
+			}
+		}
+	})
+
+	t.Run("deve rejeitar campo de ordenação desconhecido", func(t *testing.T) {
+		_, err := Parse(url.Values{"sort": {"password"}}, testFields, "name")
+		if !errors.Is(err, ErrInvalidSort) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidSort, err)
+		}
+	})
+
+	t.Run("deve rejeitar cursor adulterado", func(t *testing.T) {
+		_, err := Parse(url.Values{"cursor": {"não-é-base64"}}, testFields, "name")
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidCursor, err)
+		}
+	})
+}
+
+func TestNewPage(t *testing.T) {
+	base := time.Date(2025, 3, 31, 12, 0, 0, 123000, time.UTC)
+	items := []item{
+		{ID: "6f1c1b2e-8a4d-4c1e-9b7a-3d2f1e0c9b8a", CreatedAt: base},
+		{ID: "5e0b0a1d-7935-4b0d-8a69-2c1e0d9b8a79", CreatedAt: base.Add(-time.Hour)},
+		{ID: "4d9a9f0c-6824-4a9c-9958-1b0d9c8a7968", CreatedAt: base.Add(-2 * time.Hour)},
+	}
+	keyOf := func(i item) (interface{}, string) { return i.CreatedAt, i.ID }
+
+	p, err := Parse(url.Values{"limit": {"2"}}, testFields, "-created_at")
+	if err != nil {
+		t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+	}
+
+	page := NewPage(items, p, keyOf)
+	if len(page.Items) != 2 || page.NextCursor == nil {
+		t.Fatalf("esperava 2 itens e próximo cursor, obteve %d itens e cursor %v", len(page.Items), page.NextCursor)
+	}
+
+	t.Run("o cursor deve continuar depois do último item", func(t *testing.T) {
+		next, err := Parse(url.Values{"limit": {"2"}, "cursor": {*page.NextCursor}}, testFields, "-created_at")
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+
+		clause, args := next.Where("id", 3)
+		if clause != "(created_at, id) < ($3, $4)" {
+			t.Errorf("cláusula incorreta: %s", clause)
+		}
+		if len(args) != 2 || !args[0].(time.Time).Equal(items[1].CreatedAt) || args[1] != items[1].ID {
+			t.Errorf("argumentos incorretos: %v", args)
+		}
+	})
+
+	t.Run("o cursor não deve valer para outra ordenação", func(t *testing.T) {
+		_, err := Parse(url.Values{"cursor": {*page.NextCursor}, "sort": {"created_at"}}, testFields, "-created_at")
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidCursor, err)
+		}
+	})
+
+	t.Run("deve rejeitar cursor com ID que não é UUID", func(t *testing.T) {
+		forged := encodeCursor(cursor{Sort: "-created_at", Value: base.Format(time.RFC3339Nano), ID: "1 OR 1=1"})
+		_, err := Parse(url.Values{"cursor": {forged}}, testFields, "-created_at")
+		if !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidCursor, err)
+		}
+	})
+
+	t.Run("a última página não deve ter cursor", func(t *testing.T) {
+		last := NewPage(items[2:], p, keyOf)
+		if last.NextCursor != nil || len(last.Items) != 1 {
+			t.Errorf("página final incorreta: %+v", last)
+		}
+	})
+
+	t.Run("página vazia deve serializar como lista vazia", func(t *testing.T) {
+		empty := NewPage[item](nil, p, keyOf)
+		if empty.Items == nil {
+			t.Error("esperava uma lista vazia, não nil")
+		}
+	})
+}
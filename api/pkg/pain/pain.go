@@ -0,0 +1,278 @@
+// Package pain gera lotes de pagamento pain.001.001.03 (ISO 20022,
+// CustomerCreditTransferInitiationV03), o arquivo que os bancos aceitam para
+// transferências em lote pelo portal
+package pain
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"github.com/martinsdevv/fincore/pkg/iban"
+	"github.com/martinsdevv/fincore/pkg/money"
+)
+
+const Namespace = "urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"
+
+var ErrInvalidBatch = errors.New("invalid payment batch")
+
+var (
+	bicPattern      = regexp.MustCompile(`^[A-Z]{6}[A-Z2-9][A-NP-Z0-9]([A-Z0-9]{3})?$`)
+	currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// Party é quem paga ou recebe. BIC é opcional: sem ele, o banco encontra a
+// agência pelo IBAN.
+type Party struct {
+	Name string
+	IBAN string
+	BIC  string
+}
+
+type Payment struct {
+	EndToEndID     string // referência que volta no extrato; vazio vira NOTPROVIDED
+	Amount         int64  // unidades mínimas, positivo
+	Creditor       Party
+	RemittanceInfo string // texto livre para o recebedor
+}
+
+// Batch é um arquivo com um único bloco de pagamentos (PmtInf), todos saindo
+// da conta Debtor na mesma moeda e na mesma data
+type Batch struct {
+	MessageID     string
+	CreatedAt     time.Time
+	Debtor        Party
+	Currency      string
+	ExecutionDate time.Time
+	Payments      []Payment
+}
+
+// ControlSum é a soma dos pagamentos, em unidades mínimas
+func (b Batch) ControlSum() int64 {
+	var sum int64
+	for _, p := range b.Payments {
+		sum += p.Amount
+	}
+	return sum
+}
+
+type document struct {
+	XMLName xml.Name      `xml:"Document"`
+	Xmlns   string        `xml:"xmlns,attr"`
+	Init    initiationXML `xml:"CstmrCdtTrfInitn"`
+}
+
+type initiationXML struct {
+	GrpHdr groupHeaderXML `xml:"GrpHdr"`
+	PmtInf paymentInfoXML `xml:"PmtInf"`
+}
+
+type groupHeaderXML struct {
+	MsgId    string  `xml:"MsgId"`
+	CreDtTm  string  `xml:"CreDtTm"`
+	NbOfTxs  int     `xml:"NbOfTxs"`
+	CtrlSum  string  `xml:"CtrlSum"`
+	InitgPty nameXML `xml:"InitgPty"`
+}
+
+type paymentInfoXML struct {
+	PmtInfId    string        `xml:"PmtInfId"`
+	PmtMtd      string        `xml:"PmtMtd"`
+	NbOfTxs     int           `xml:"NbOfTxs"`
+	CtrlSum     string        `xml:"CtrlSum"`
+	ReqdExctnDt string        `xml:"ReqdExctnDt"`
+	Dbtr        nameXML       `xml:"Dbtr"`
+	DbtrAcct    accountXML    `xml:"DbtrAcct"`
+	DbtrAgt     agentXML      `xml:"DbtrAgt"`
+	ChrgBr      string        `xml:"ChrgBr,omitempty"`
+	CdtTrfTxInf []transferXML `xml:"CdtTrfTxInf"`
+}
+
+type nameXML struct {
+	Nm string `xml:"Nm"`
+}
+
+type accountXML struct {
+	IBAN string `xml:"Id>IBAN"`
+	Ccy  string `xml:"Ccy,omitempty"`
+}
+
+// agentXML usa o BIC ou, sem ele, Othr/Id NOTPROVIDED. Os opcionais são
+// ponteiros porque o omitempty de caminhos como a>b deixa o a vazio.
+type agentXML struct {
+	FinInstnId struct {
+		BIC  string   `xml:"BIC,omitempty"`
+		Othr *otherID `xml:"Othr,omitempty"`
+	} `xml:"FinInstnId"`
+}
+
+type otherID struct {
+	ID string `xml:"Id"`
+}
+
+type remittanceXML struct {
+	Ustrd string `xml:"Ustrd"`
+}
+
+type transferXML struct {
+	EndToEndId string         `xml:"PmtId>EndToEndId"`
+	Amt        amountXML      `xml:"Amt>InstdAmt"`
+	CdtrAgt    *agentXML      `xml:"CdtrAgt,omitempty"`
+	Cdtr       nameXML        `xml:"Cdtr"`
+	CdtrAcct   accountXML     `xml:"CdtrAcct"`
+	RmtInf     *remittanceXML `xml:"RmtInf,omitempty"`
+}
+
+type amountXML struct {
+	Ccy   string `xml:"Ccy,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Generate valida o lote e escreve o XML. Os limites de tamanho são os do
+// XSD: 35 caracteres para referências, 70 para nomes e 140 para o texto
+// livre.
+func Generate(w io.Writer, b Batch) error {
+	if err := validate(&b); err != nil {
+		return err
+	}
+
+	ctrlSum := money.New(b.ControlSum(), b.Currency).Decimal()
+	info := paymentInfoXML{
+		PmtInfId:    b.MessageID,
+		PmtMtd:      "TRF",
+		NbOfTxs:     len(b.Payments),
+		CtrlSum:     ctrlSum,
+		ReqdExctnDt: b.ExecutionDate.Format(time.DateOnly),
+		Dbtr:        nameXML{Nm: b.Debtor.Name},
+		DbtrAcct:    accountXML{IBAN: b.Debtor.IBAN, Ccy: b.Currency},
+		DbtrAgt:     agent(b.Debtor.BIC),
+	}
+	// SLEV (cada lado paga a sua tarifa) é obrigatório no SEPA
+	if b.Currency == "EUR" {
+		info.ChrgBr = "SLEV"
+	}
+	for _, p := range b.Payments {
+		tx := transferXML{
+			EndToEndId: p.EndToEndID,
+			Amt:        amountXML{Ccy: b.Currency, Value: money.New(p.Amount, b.Currency).Decimal()},
+			Cdtr:       nameXML{Nm: p.Creditor.Name},
+			CdtrAcct:   accountXML{IBAN: p.Creditor.IBAN},
+		}
+		if p.RemittanceInfo != "" {
+			tx.RmtInf = &remittanceXML{Ustrd: p.RemittanceInfo}
+		}
+		if tx.EndToEndId == "" {
+			tx.EndToEndId = "NOTPROVIDED"
+		}
+		if p.Creditor.BIC != "" {
+			creditorAgent := agent(p.Creditor.BIC)
+			tx.CdtrAgt = &creditorAgent
+		}
+		info.CdtTrfTxInf = append(info.CdtTrfTxInf, tx)
+	}
+
+	doc := document{
+		Xmlns: Namespace,
+		Init: initiationXML{
+			GrpHdr: groupHeaderXML{
+				MsgId:    b.MessageID,
+				CreDtTm:  b.CreatedAt.UTC().Format("2006-01-02T15:04:05"),
+				NbOfTxs:  len(b.Payments),
+				CtrlSum:  ctrlSum,
+				InitgPty: nameXML{Nm: b.Debtor.Name},
+			},
+			PmtInf: info,
+		},
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func agent(bic string) agentXML {
+	var a agentXML
+	if bic == "" {
+		a.FinInstnId.Othr = &otherID{ID: "NOTPROVIDED"}
+	} else {
+		a.FinInstnId.BIC = bic
+	}
+	return a
+}
+
+// validate confere o lote e normaliza os IBANs
+func validate(b *Batch) error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidBatch, fmt.Sprintf(format, args...))
+	}
+
+	if err := checkText("message id", b.MessageID, 35); err != nil {
+		return invalid("%v", err)
+	}
+	if !currencyPattern.MatchString(b.Currency) {
+		return invalid("currency %q is not an ISO 4217 code", b.Currency)
+	}
+	if len(b.Payments) == 0 {
+		return invalid("batch has no payments")
+	}
+	if err := checkParty("debtor", &b.Debtor); err != nil {
+		return invalid("%v", err)
+	}
+
+	var sum int64
+	for i := range b.Payments {
+		p := &b.Payments[i]
+		if p.Amount <= 0 {
+			return invalid("payment %d: amount must be positive", i+1)
+		}
+		if sum += p.Amount; sum < p.Amount {
+			return invalid("control sum overflows")
+		}
+		if err := checkParty("creditor", &p.Creditor); err != nil {
+			return invalid("payment %d: %v", i+1, err)
+		}
+		if p.EndToEndID != "" {
+			if err := checkText("end to end id", p.EndToEndID, 35); err != nil {
+				return invalid("payment %d: %v", i+1, err)
+			}
+		}
+		if utf8.RuneCountInString(p.RemittanceInfo) > 140 {
+			return invalid("payment %d: remittance information is longer than 140 characters", i+1)
+		}
+	}
+	return nil
+}
+
+func checkParty(role string, p *Party) error {
+	if err := checkText(role+" name", p.Name, 70); err != nil {
+		return err
+	}
+	normalized, err := iban.Normalize(p.IBAN)
+	if err != nil {
+		return fmt.Errorf("%s IBAN %q is invalid", role, p.IBAN)
+	}
+	p.IBAN = normalized
+	if p.BIC != "" && !bicPattern.MatchString(p.BIC) {
+		return fmt.Errorf("%s BIC %q is invalid", role, p.BIC)
+	}
+	return nil
+}
+
+func checkText(field string, value string, max int) error {
+	n := utf8.RuneCountInString(value)
+	if n == 0 || n > max {
+		return fmt.Errorf("%s must have 1 to %d characters", field, max)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package pain
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestBatch() Batch {
+	return Batch{
+		MessageID:     "FINCORE-20240110-0001",
+		CreatedAt:     time.Date(2024, 1, 10, 12, 30, 0, 0, time.UTC),
+		Debtor:        Party{Name: "Martins Comércio Ltda", IBAN: "de89 3704 0044 0532 0130 00", BIC: "COBADEFFXXX"},
+		Currency:      "EUR",
+		ExecutionDate: time.Date(2024, 1, 12, 0, 0, 0, 0, time.UTC),
+		Payments: []Payment{
+			{EndToEndID: "INV-2024-001", Amount: 25050, Creditor: Party{Name: "Papelaria Müller GmbH", IBAN: "DE02120300000000202051"}, RemittanceInfo: "Rechnung 2024-001"},
+			{Amount: 1999, Creditor: Party{Name: "Office & Co", IBAN: "GB82WEST12345698765432", BIC: "NWBKGB2L"}},
+		},
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	t.Run("deve gerar um pain.001.001.03 com os totais e as partes", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Generate(&buf, newTestBatch()); err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		out := buf.String()
+		for _, want := range []string{
+			`<CtrlSum>270.49</CtrlSum>`,
+			`<NbOfTxs>2</NbOfTxs>`,
+			`<IBAN>DE89370400440532013000</IBAN>`,
+			`<InstdAmt Ccy="EUR">250.50</InstdAmt>`,
+			`<EndToEndId>NOTPROVIDED</EndToEndId>`,
+			`<Nm>Office &amp; Co</Nm>`,
+			`<ChrgBr>SLEV</ChrgBr>`,
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("esperava %s no documento:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("deve usar NOTPROVIDED sem o BIC do pagador e as casas da moeda", func(t *testing.T) {
+		batch := newTestBatch()
+		batch.Debtor.BIC = ""
+		batch.Currency = "JPY"
+		batch.Payments = batch.Payments[:1]
+
+		var buf bytes.Buffer
+		if err := Generate(&buf, batch); err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		out := buf.String()
+		if !strings.Contains(out, "<Id>NOTPROVIDED</Id>") {
+			t.Errorf("esperava DbtrAgt NOTPROVIDED:\n%s", out)
+		}
+		if !strings.Contains(out, `<InstdAmt Ccy="JPY">25050</InstdAmt>`) || strings.Contains(out, "SLEV") {
+			t.Errorf("valor ou tarifa incorretos:\n%s", out)
+		}
+	})
+
+	t.Run("deve recusar lotes inválidos", func(t *testing.T) {
+		cases := map[string]func(b *Batch){
+			"sem pagamentos":     func(b *Batch) { b.Payments = nil },
+			"valor zero":         func(b *Batch) { b.Payments[0].Amount = 0 },
+			"IBAN inválido":      func(b *Batch) { b.Payments[1].Creditor.IBAN = "GB82WEST12345698765433" },
+			"BIC inválido":       func(b *Batch) { b.Debtor.BIC = "COBA" },
+			"nome longo":         func(b *Batch) { b.Payments[0].Creditor.Name = strings.Repeat("a", 71) },
+			"referência longa":   func(b *Batch) { b.Payments[0].EndToEndID = strings.Repeat("1", 36) },
+			"moeda inválida":     func(b *Batch) { b.Currency = "eur" },
+			"sem id da mensagem": func(b *Batch) { b.MessageID = "" },
+			"texto livre longo":  func(b *Batch) { b.Payments[0].RemittanceInfo = strings.Repeat("x", 141) },
+		}
+		for name, mutate := range cases {
+			batch := newTestBatch()
+			mutate(&batch)
+			if err := Generate(&bytes.Buffer{}, batch); !errors.Is(err, ErrInvalidBatch) {
+				t.Errorf("%s: esperava o erro %v, mas obteve %v", name, ErrInvalidBatch, err)
+			}
+		}
+	})
+}
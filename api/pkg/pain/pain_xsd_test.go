@@ -0,0 +1,54 @@
+//go:build xsd
+
+package pain
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// schemaPath é o recorte do XSD do ISO 20022 (CustomerCreditTransferInitiationV03)
+// que acompanha o repositório. Estes testes só rodam com -tags xsd, que o CI
+// liga, e falham em vez de pular quando falta o xmllint ou o schema.
+const schemaPath = "testdata/pain.001.001.03.xsd"
+
+func validateXSD(t *testing.T, document []byte) {
+	t.Helper()
+	if _, err := exec.LookPath("xmllint"); err != nil {
+		t.Fatal("xmllint não encontrado: instale o libxml2-utils para validar o XSD")
+	}
+	if _, err := os.Stat(schemaPath); err != nil {
+		t.Fatalf("XSD não encontrado em %s: %v", schemaPath, err)
+	}
+	path := filepath.Join(t.TempDir(), "pain.xml")
+	if err := os.WriteFile(path, document, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	out, err := exec.Command("xmllint", "--noout", "--schema", schemaPath, path).CombinedOutput()
+	if err != nil {
+		t.Fatalf("documento inválido contra %s: %s\n%s", schemaPath, out, document)
+	}
+}
+
+func TestGenerateXSD(t *testing.T) {
+	withoutBIC := newTestBatch()
+	withoutBIC.Debtor.BIC = ""
+	withoutBIC.Currency = "JPY"
+	withoutBIC.Payments = withoutBIC.Payments[:1]
+
+	for name, batch := range map[string]Batch{
+		"lote completo":             newTestBatch(),
+		"sem BIC do pagador em JPY": withoutBIC,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Generate(&buf, batch); err != nil {
+				t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+			}
+			validateXSD(t, buf.Bytes())
+		})
+	}
+}
@@ -0,0 +1,180 @@
+// Package recurrence calcula as ocorrências de regras no estilo RRULE (RFC 5545)
+// restritas ao que os agendamentos usam: frequência com intervalo, fim por data
+// ou por contagem e ajuste para dia útil.
+package recurrence
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+type Frequency string
+
+const (
+	Daily   Frequency = "daily"
+	Weekly  Frequency = "weekly"
+	Monthly Frequency = "monthly"
+	Yearly  Frequency = "yearly"
+)
+
+func (f Frequency) IsValid() bool {
+	switch f {
+	case Daily, Weekly, Monthly, Yearly:
+		return true
+	}
+	return false
+}
+
+// Adjustment diz para onde vai uma ocorrência que cai em fim de semana
+type Adjustment string
+
+const (
+	NoAdjustment      Adjustment = "none"
+	Following         Adjustment = "following"          // próximo dia útil
+	Preceding         Adjustment = "preceding"          // dia útil anterior
+	ModifiedFollowing Adjustment = "modified_following" // próximo, ou o anterior se mudar de mês
+)
+
+func (a Adjustment) IsValid() bool {
+	switch a {
+	case NoAdjustment, Following, Preceding, ModifiedFollowing:
+		return true
+	}
+	return false
+}
+
+// Rule repete Start a cada Interval unidades de Frequency. Termina em Until
+// (inclusivo, comparado com a data nominal) ou depois de Count ocorrências;
+// sem nenhum dos dois, não termina. Datas são dias em UTC.
+//
+// Dias do mês que não existem no mês da ocorrência (31, ou 29/02) caem no
+// último dia do mês, sem afetar as ocorrências seguintes.
+type Rule struct {
+	Frequency  Frequency
+	Interval   int
+	Start      time.Time
+	Until      *time.Time
+	Count      int
+	Adjustment Adjustment
+}
+
+// Occurrence é a ocorrência de número Index (a partir de 0). Nominal é a data
+// pela regra; Date é a data depois do ajuste para dia útil.
+type Occurrence struct {
+	Index   int
+	Nominal time.Time
+	Date    time.Time
+}
+
+func (r Rule) Validate() error {
+	switch {
+	case !r.Frequency.IsValid():
+		return errors.Join(ErrInvalidRule, errors.New("frequency must be daily, weekly, monthly or yearly"))
+	case r.Interval < 1:
+		return errors.Join(ErrInvalidRule, errors.New("interval must be at least 1"))
+	case r.Start.IsZero():
+		return errors.Join(ErrInvalidRule, errors.New("start date is required"))
+	case r.Count < 0:
+		return errors.Join(ErrInvalidRule, errors.New("count must not be negative"))
+	case r.Until != nil && r.Count > 0:
+		return errors.Join(ErrInvalidRule, errors.New("use either an end date or a count"))
+	case r.Until != nil && day(*r.Until).Before(day(r.Start)):
+		return errors.Join(ErrInvalidRule, errors.New("end date must not be before the start date"))
+	case r.Adjustment != "" && !r.Adjustment.IsValid():
+		return errors.Join(ErrInvalidRule, errors.New("adjustment must be none, following, preceding or modified_following"))
+	}
+	return nil
+}
+
+// At devolve a ocorrência de número index; false se a regra já terminou
+func (r Rule) At(index int) (Occurrence, bool) {
+	if index < 0 || (r.Count > 0 && index >= r.Count) {
+		return Occurrence{}, false
+	}
+
+	start := day(r.Start)
+	var nominal time.Time
+	switch r.Frequency {
+	case Daily:
+		nominal = start.AddDate(0, 0, index*r.Interval)
+	case Weekly:
+		nominal = start.AddDate(0, 0, 7*index*r.Interval)
+	case Monthly:
+		nominal = addMonths(start, index*r.Interval)
+	case Yearly:
+		nominal = addMonths(start, 12*index*r.Interval)
+	default:
+		return Occurrence{}, false
+	}
+
+	if r.Until != nil && nominal.After(day(*r.Until)) {
+		return Occurrence{}, false
+	}
+	return Occurrence{Index: index, Nominal: nominal, Date: Adjust(nominal, r.Adjustment)}, true
+}
+
+// List devolve até n ocorrências a partir de from
+func (r Rule) List(from int, n int) []Occurrence {
+	var occurrences []Occurrence
+	for i := from; len(occurrences) < n; i++ {
+		occ, ok := r.At(i)
+		if !ok {
+			break
+		}
+		occurrences = append(occurrences, occ)
+	}
+	return occurrences
+}
+
+// Find procura, entre as próximas limit ocorrências a partir de from, a que cai
+// em date (já ajustada)
+func (r Rule) Find(date time.Time, from int, limit int) (Occurrence, bool) {
+	date = day(date)
+	for i := from; i < from+limit; i++ {
+		occ, ok := r.At(i)
+		if !ok || occ.Nominal.After(date.AddDate(0, 0, 7)) {
+			break
+		}
+		if occ.Date.Equal(date) {
+			return occ, true
+		}
+	}
+	return Occurrence{}, false
+}
+
+// Adjust move date para um dia útil (segunda a sexta). Feriados não são considerados.
+func Adjust(date time.Time, adj Adjustment) time.Time {
+	switch adj {
+	case Following:
+		return shift(date, 1)
+	case Preceding:
+		return shift(date, -1)
+	case ModifiedFollowing:
+		if next := shift(date, 1); next.Month() == date.Month() {
+			return next
+		}
+		return shift(date, -1)
+	}
+	return date
+}
+
+func shift(date time.Time, step int) time.Time {
+	for date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		date = date.AddDate(0, 0, step)
+	}
+	return date
+}
+
+// addMonths soma months meses a t mantendo o dia, limitado ao fim do mês
+func addMonths(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	return firstOfMonth.AddDate(0, 0, min(t.Day(), lastDay)-1)
+}
+
+func day(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,113 @@
+package recurrence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(s string) time.Time {
+	d, _ := time.Parse(time.DateOnly, s)
+	return d
+}
+
+func dates(occurrences []Occurrence) []string {
+	var out []string
+	for _, occ := range occurrences {
+		out = append(out, occ.Date.Format(time.DateOnly))
+	}
+	return out
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRule_List(t *testing.T) {
+	until := date("2024-03-01")
+
+	cases := []struct {
+		name string
+		rule Rule
+		want []string
+	}{
+		{
+			name: "deve limitar o dia 31 ao fim de cada mês",
+			rule: Rule{Frequency: Monthly, Interval: 1, Start: date("2024-01-31"), Count: 4},
+			want: []string{"2024-01-31", "2024-02-29", "2024-03-31", "2024-04-30"},
+		},
+		{
+			name: "deve repetir a cada duas semanas até a data final",
+			rule: Rule{Frequency: Weekly, Interval: 2, Start: date("2024-01-03"), Until: &until},
+			want: []string{"2024-01-03", "2024-01-17", "2024-01-31", "2024-02-14", "2024-02-28"},
+		},
+		{
+			name: "deve levar 29/02 para 28/02 nos anos não bissextos",
+			rule: Rule{Frequency: Yearly, Interval: 1, Start: date("2024-02-29"), Count: 3},
+			want: []string{"2024-02-29", "2025-02-28", "2026-02-28"},
+		},
+		{
+			name: "deve mover fins de semana para o próximo dia útil",
+			rule: Rule{Frequency: Monthly, Interval: 1, Start: date("2024-06-01"), Count: 2, Adjustment: Following},
+			// 01/06 é sábado
+			want: []string{"2024-06-03", "2024-07-01"},
+		},
+		{
+			name: "deve voltar para o dia útil anterior quando o seguinte muda de mês",
+			rule: Rule{Frequency: Monthly, Interval: 1, Start: date("2024-08-31"), Count: 2, Adjustment: ModifiedFollowing},
+			// 31/08 é sábado; 30/09 é segunda
+			want: []string{"2024-08-30", "2024-09-30"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if err := c.rule.Validate(); err != nil {
+				t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+			}
+			if got := dates(c.rule.List(0, 10)); !equal(got, c.want) {
+				t.Errorf("esperava %v, obteve %v", c.want, got)
+			}
+		})
+	}
+}
+
+func TestRule_Find(t *testing.T) {
+	rule := Rule{Frequency: Monthly, Interval: 1, Start: date("2024-06-01"), Adjustment: Following}
+
+	occ, ok := rule.Find(date("2024-06-03"), 0, 100)
+	if !ok || occ.Index != 0 || !occ.Nominal.Equal(date("2024-06-01")) {
+		t.Errorf("ocorrência incorreta: %+v", occ)
+	}
+	if _, ok := rule.Find(date("2024-06-01"), 0, 100); ok {
+		t.Error("a data nominal ajustada não deveria ser encontrada")
+	}
+	if _, ok := rule.Find(date("2024-07-01"), 2, 100); ok {
+		t.Error("não deveria encontrar ocorrências antes de from")
+	}
+}
+
+func TestRule_Validate(t *testing.T) {
+	until := date("2023-12-31")
+	invalid := []Rule{
+		{Frequency: "hourly", Interval: 1, Start: date("2024-01-01")},
+		{Frequency: Daily, Interval: 0, Start: date("2024-01-01")},
+		{Frequency: Daily, Interval: 1},
+		{Frequency: Daily, Interval: 1, Start: date("2024-01-01"), Until: &until},
+		{Frequency: Daily, Interval: 1, Start: date("2023-01-01"), Until: &until, Count: 3},
+		{Frequency: Daily, Interval: 1, Start: date("2024-01-01"), Adjustment: "nearest"},
+	}
+	for _, rule := range invalid {
+		if err := rule.Validate(); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("%+v: esperava o erro %v, mas obteve %v", rule, ErrInvalidRule, err)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package statement
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// CAMTStatement é um extrato (Stmt) de um camt.053. Um arquivo pode trazer
+// extratos de várias contas, cada uma identificada pelo IBAN.
+type CAMTStatement struct {
+	ID       string
+	IBAN     string
+	Currency string // Acct/Ccy ou, sem ela, a moeda do primeiro lançamento
+	Lines    []Line
+	Errors   []RowError
+}
+
+// As tags não têm namespace: encoding/xml casa só o nome local, e assim
+// servem às versões 001.02 a 001.08 do camt.053
+type camtDocument struct {
+	XMLName    xml.Name
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	ID   string `xml:"Id"`
+	Acct struct {
+		IBAN string `xml:"Id>IBAN"`
+		Ccy  string `xml:"Ccy"`
+	} `xml:"Acct"`
+	Entries []camtEntry `xml:"Ntry"`
+}
+
+type camtEntry struct {
+	Amt struct {
+		Value string `xml:",chardata"`
+		Ccy   string `xml:"Ccy,attr"`
+	} `xml:"Amt"`
+	CdtDbtInd    string        `xml:"CdtDbtInd"`
+	RvslInd      bool          `xml:"RvslInd"`
+	Sts          camtStatus    `xml:"Sts"`
+	BookgDt      camtDate      `xml:"BookgDt"`
+	ValDt        camtDate      `xml:"ValDt"`
+	AcctSvcrRef  string        `xml:"AcctSvcrRef"`
+	AddtlNtryInf string        `xml:"AddtlNtryInf"`
+	Details      []camtDetails `xml:"NtryDtls>TxDtls"`
+}
+
+// camtStatus é "BOOK" até a versão 001.07 e <Cd>BOOK</Cd> a partir da 001.08
+type camtStatus struct {
+	Value string `xml:",chardata"`
+	Cd    string `xml:"Cd"`
+}
+
+func (s camtStatus) code() string {
+	if s.Cd != "" {
+		return strings.TrimSpace(s.Cd)
+	}
+	return strings.TrimSpace(s.Value)
+}
+
+type camtDate struct {
+	Dt   string `xml:"Dt"`
+	DtTm string `xml:"DtTm"`
+}
+
+type camtDetails struct {
+	Ustrd []string `xml:"RmtInf>Ustrd"`
+	// A partir da 001.08 o nome fica em Pty
+	CdtrNm    string `xml:"RltdPties>Cdtr>Nm"`
+	CdtrPtyNm string `xml:"RltdPties>Cdtr>Pty>Nm"`
+	DbtrNm    string `xml:"RltdPties>Dbtr>Nm"`
+	DbtrPtyNm string `xml:"RltdPties>Dbtr>Pty>Nm"`
+}
+
+// ParseCAMT053 lê os lançamentos contabilizados (Sts BOOK) de um extrato
+// camt.053; lançamentos pendentes ou informativos são ignorados. Row é a
+// posição do lançamento no seu extrato, a partir de 1, e ExternalID é o
+// AcctSvcrRef, a referência do banco, quando houver.
+func ParseCAMT053(r io.Reader, maxRows int) ([]CAMTStatement, error) {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		return decode(input, label)
+	}
+
+	var doc camtDocument
+	if err := d.Decode(&doc); err != nil {
+		if errors.Is(err, ErrUnknownEncoding) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	if doc.XMLName.Local != "Document" || !strings.Contains(doc.XMLName.Space, "camt.053") || len(doc.Statements) == 0 {
+		return nil, fmt.Errorf("%w: not a camt.053 statement", ErrInvalidFile)
+	}
+
+	statements := make([]CAMTStatement, 0, len(doc.Statements))
+	rows := 0
+	for _, stmt := range doc.Statements {
+		if stmt.Acct.IBAN == "" {
+			return nil, fmt.Errorf("%w: statement %q has no IBAN", ErrInvalidFile, stmt.ID)
+		}
+		rows += len(stmt.Entries)
+		if rows > maxRows {
+			return nil, fmt.Errorf("%w: more than %d entries", ErrInvalidFile, maxRows)
+		}
+
+		st := CAMTStatement{ID: stmt.ID, IBAN: stmt.Acct.IBAN, Currency: strings.ToUpper(stmt.Acct.Ccy)}
+		if st.Currency == "" && len(stmt.Entries) > 0 {
+			st.Currency = strings.ToUpper(stmt.Entries[0].Amt.Ccy)
+		}
+		for i, entry := range stmt.Entries {
+			if entry.Sts.code() != "BOOK" {
+				continue
+			}
+			line, err := camtLine(entry, st.Currency)
+			if err != nil {
+				st.Errors = append(st.Errors, RowError{Row: i + 1, Message: err.Error()})
+				continue
+			}
+			line.Row = i + 1
+			st.Lines = append(st.Lines, line)
+		}
+		statements = append(statements, st)
+	}
+	return statements, nil
+}
+
+func camtLine(entry camtEntry, currency string) (Line, error) {
+	var line Line
+	if c := strings.ToUpper(entry.Amt.Ccy); c != currency {
+		return line, fmt.Errorf("entry is in %s, statement is in %s", c, currency)
+	}
+
+	amount, err := ParseAmount(strings.TrimSpace(entry.Amt.Value), ".", currency)
+	if err != nil {
+		return line, err
+	}
+	if amount <= 0 {
+		return line, errors.New("amount must be positive, the direction comes from CdtDbtInd")
+	}
+	// Um estorno (RvslInd) vem com o indicador do lançamento original
+	debit := entry.CdtDbtInd == "DBIT"
+	if entry.CdtDbtInd != "DBIT" && entry.CdtDbtInd != "CRDT" {
+		return line, fmt.Errorf("invalid CdtDbtInd %q", entry.CdtDbtInd)
+	}
+	if debit != entry.RvslInd {
+		amount = -amount
+	}
+	line.Amount = amount
+
+	date := entry.BookgDt
+	if date.Dt == "" && date.DtTm == "" {
+		date = entry.ValDt
+	}
+	if line.Date, err = parseCAMTDate(date); err != nil {
+		return line, err
+	}
+
+	line.ExternalID = strings.TrimSpace(entry.AcctSvcrRef)
+	line.Description = camtDescription(entry, debit)
+	return line, nil
+}
+
+// parseCAMTDate lê o dia de Dt (AAAA-MM-DD) ou de DtTm; como no OFX, vale o
+// dia como o banco escreveu
+func parseCAMTDate(d camtDate) (time.Time, error) {
+	value := strings.TrimSpace(d.Dt)
+	if value == "" {
+		value = strings.TrimSpace(d.DtTm)
+	}
+	if len(value) < 10 {
+		return time.Time{}, fmt.Errorf("invalid booking date %q", value)
+	}
+	date, err := time.Parse(time.DateOnly, value[:10])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid booking date %q", value)
+	}
+	return date, nil
+}
+
+// camtDescription junta a contraparte (o credor num débito, o devedor num
+// crédito) e a informação de remessa; sem detalhes, usa AddtlNtryInf
+func camtDescription(entry camtEntry, debit bool) string {
+	if len(entry.Details) == 0 {
+		return strings.TrimSpace(entry.AddtlNtryInf)
+	}
+	tx := entry.Details[0]
+	party := firstNonEmpty(tx.DbtrNm, tx.DbtrPtyNm)
+	if debit {
+		party = firstNonEmpty(tx.CdtrNm, tx.CdtrPtyNm)
+	}
+
+	var parts []string
+	if party = strings.TrimSpace(party); party != "" {
+		parts = append(parts, party)
+	}
+	if info := strings.TrimSpace(strings.Join(tx.Ustrd, " ")); info != "" {
+		parts = append(parts, info)
+	}
+	if len(parts) == 0 {
+		return strings.TrimSpace(entry.AddtlNtryInf)
+	}
+	return strings.Join(parts, " - ")
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
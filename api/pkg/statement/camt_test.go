@@ -0,0 +1,68 @@
+package statement
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseCAMT053(t *testing.T) {
+	const fixture = "testdata/camt053.xml"
+
+	t.Run("deve ler os lançamentos contabilizados de cada conta", func(t *testing.T) {
+		f, err := os.Open(fixture)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		statements, err := ParseCAMT053(f, 100)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if len(statements) != 2 {
+			t.Fatalf("esperava 2 extratos, obteve %d", len(statements))
+		}
+
+		eur := statements[0]
+		if eur.IBAN != "DE89370400440532013000" || eur.Currency != "EUR" || len(eur.Lines) != 3 {
+			t.Fatalf("extrato incorreto: %+v", eur)
+		}
+		debit, credit, reversal := eur.Lines[0], eur.Lines[1], eur.Lines[2]
+		if debit.Amount != -25050 || debit.ExternalID != "BANK-0001" || debit.Description != "Papelaria Müller GmbH - Rechnung 2024-001" {
+			t.Errorf("débito incorreto: %+v", debit)
+		}
+		if credit.Amount != 400000 || credit.Date.Format("2006-01-02") != "2024-01-02" || credit.Description != "Cliente SA - Fatura 77" {
+			t.Errorf("crédito incorreto: %+v", credit)
+		}
+		// O estorno de um débito devolve o dinheiro
+		if reversal.Amount != 25050 || reversal.Row != 4 || reversal.Description != "Devolução INV-2024-001" {
+			t.Errorf("estorno incorreto: %+v", reversal)
+		}
+		if len(eur.Errors) != 1 || eur.Errors[0].Row != 5 {
+			t.Errorf("esperava o lançamento em USD recusado: %+v", eur.Errors)
+		}
+
+		gbp := statements[1]
+		if gbp.Currency != "GBP" || len(gbp.Lines) != 1 || gbp.Lines[0].ExternalID != "" || gbp.Lines[0].Amount != 2000 {
+			t.Errorf("extrato sem Ccy incorreto: %+v", gbp)
+		}
+	})
+
+	t.Run("deve recusar outros documentos e arquivos grandes demais", func(t *testing.T) {
+		pain := `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.03"><CstmrCdtTrfInitn/></Document>`
+		if _, err := ParseCAMT053(strings.NewReader(pain), 100); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidFile, err)
+		}
+
+		f, err := os.Open(fixture)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		if _, err := ParseCAMT053(f, 3); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidFile, err)
+		}
+	})
+}
@@ -0,0 +1,29 @@
+//go:build xsd
+
+package statement
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+// camtSchemaPath é o recorte do XSD do ISO 20022 (BankToCustomerStatementV02)
+// que acompanha o repositório. Este teste só roda com -tags xsd, que o CI liga,
+// e falha em vez de pular quando falta o xmllint ou o schema.
+const camtSchemaPath = "testdata/camt.053.001.02.xsd"
+
+func TestCAMT053FixtureXSD(t *testing.T) {
+	if _, err := exec.LookPath("xmllint"); err != nil {
+		t.Fatal("xmllint não encontrado: instale o libxml2-utils para validar o XSD")
+	}
+	if _, err := os.Stat(camtSchemaPath); err != nil {
+		t.Fatalf("XSD não encontrado em %s: %v", camtSchemaPath, err)
+	}
+
+	const fixture = "testdata/camt053.xml"
+	out, err := exec.Command("xmllint", "--noout", "--schema", camtSchemaPath, fixture).CombinedOutput()
+	if err != nil {
+		t.Fatalf("%s não é válido contra %s: %s", fixture, camtSchemaPath, out)
+	}
+}
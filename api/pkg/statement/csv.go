@@ -0,0 +1,230 @@
+package statement
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CSVProfile diz como ler o CSV de um banco. As colunas são achadas pelo nome
+// no cabeçalho, sem diferenciar maiúsculas. O valor vem de AmountColumn, com
+// sinal, ou do par DebitColumn/CreditColumn, em que o sinal é ignorado.
+type CSVProfile struct {
+	Encoding          string
+	Delimiter         rune
+	SkipRows          int // linhas antes do cabeçalho, como o nome da conta e o período
+	DateColumn        string
+	DateFormat        string // como "DD/MM/YYYY"; veja DateLayout
+	DescriptionColumn string
+	AmountColumn      string
+	DebitColumn       string
+	CreditColumn      string
+	DecimalSeparator  string // "," ou "."
+}
+
+func (p CSVProfile) Validate() error {
+	var errs []error
+	if p.Delimiter == 0 || p.Delimiter == '"' || p.Delimiter == '\r' || p.Delimiter == '\n' || p.Delimiter == utf8.RuneError {
+		errs = append(errs, errors.New("delimiter must be a single character other than quotes and line breaks"))
+	}
+	if p.SkipRows < 0 {
+		errs = append(errs, errors.New("skip_rows cannot be negative"))
+	}
+	if p.DateColumn == "" || p.DescriptionColumn == "" {
+		errs = append(errs, errors.New("date and description columns are required"))
+	}
+	if _, err := DateLayout(p.DateFormat); err != nil {
+		errs = append(errs, err)
+	}
+	if (p.AmountColumn == "") == (p.DebitColumn == "" && p.CreditColumn == "") {
+		errs = append(errs, errors.New("use either an amount column or debit/credit columns"))
+	} else if p.AmountColumn == "" && (p.DebitColumn == "" || p.CreditColumn == "") {
+		errs = append(errs, errors.New("debit and credit columns go together"))
+	}
+	if p.DecimalSeparator != "," && p.DecimalSeparator != "." {
+		errs = append(errs, errors.New(`decimal separator must be "," or "."`))
+	}
+	if _, err := decode(strings.NewReader(""), p.Encoding); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return errors.Join(append([]error{ErrInvalidProfile}, errs...)...)
+	}
+	return nil
+}
+
+// ParseCSV lê até maxRows lançamentos de r. Linhas em branco são puladas e as
+// que não dá para ler viram RowError, sem interromper as demais; mais linhas
+// do que maxRows é erro do arquivo inteiro.
+func ParseCSV(r io.Reader, p CSVProfile, currency string, maxRows int) ([]Line, []RowError, error) {
+	if err := p.Validate(); err != nil {
+		return nil, nil, err
+	}
+	layout, _ := DateLayout(p.DateFormat)
+	decoded, _ := decode(r, p.Encoding)
+
+	reader := csv.NewReader(decoded)
+	reader.Comma = p.Delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	for i := 0; i < p.SkipRows; i++ {
+		if _, err := reader.Read(); err != nil {
+			return nil, nil, fmt.Errorf("%w: file ends before the header", ErrInvalidFile)
+		}
+	}
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: missing header", ErrInvalidFile)
+	}
+	cols, err := columnIndexes(header, p)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var lines []Line
+	var rowErrors []RowError
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrors = append(rowErrors, RowError{Row: parseErr.StartLine, Message: parseErr.Err.Error()})
+				continue
+			}
+			return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+		}
+		row, _ := reader.FieldPos(0)
+		if isBlank(record) {
+			continue
+		}
+		if len(lines)+len(rowErrors) >= maxRows {
+			return nil, nil, fmt.Errorf("%w: more than %d rows", ErrInvalidFile, maxRows)
+		}
+
+		line, err := parseRecord(record, cols, layout, p.DecimalSeparator, currency)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: row, Message: err.Error()})
+			continue
+		}
+		line.Row = row
+		lines = append(lines, line)
+	}
+	return lines, rowErrors, nil
+}
+
+// csvColumns são as posições das colunas do perfil no cabeçalho; -1 se não usada
+type csvColumns struct {
+	date, description, amount, debit, credit int
+}
+
+func columnIndexes(header []string, p CSVProfile) (csvColumns, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if _, dup := index[key]; !dup {
+			index[key] = i
+		}
+	}
+
+	var missing []string
+	find := func(name string) int {
+		if name == "" {
+			return -1
+		}
+		i, ok := index[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			missing = append(missing, name)
+			return -1
+		}
+		return i
+	}
+	cols := csvColumns{
+		date:        find(p.DateColumn),
+		description: find(p.DescriptionColumn),
+		amount:      find(p.AmountColumn),
+		debit:       find(p.DebitColumn),
+		credit:      find(p.CreditColumn),
+	}
+	if len(missing) > 0 {
+		return cols, fmt.Errorf("%w: header has no column %s", ErrInvalidFile, strings.Join(quoteAll(missing), ", "))
+	}
+	return cols, nil
+}
+
+func parseRecord(record []string, cols csvColumns, layout string, decimalSeparator string, currency string) (Line, error) {
+	field := func(i int) string {
+		if i < 0 || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var line Line
+	date, err := time.Parse(layout, field(cols.date))
+	if err != nil {
+		return line, fmt.Errorf("invalid date %q", field(cols.date))
+	}
+	line.Date = date
+	line.Description = field(cols.description)
+
+	if cols.amount >= 0 {
+		if line.Amount, err = ParseAmount(field(cols.amount), decimalSeparator, currency); err != nil {
+			return line, err
+		}
+	} else {
+		debit, credit := field(cols.debit), field(cols.credit)
+		switch {
+		case debit != "" && credit != "":
+			return line, errors.New("row has both debit and credit")
+		case debit != "":
+			amount, err := ParseAmount(debit, decimalSeparator, currency)
+			if err != nil {
+				return line, err
+			}
+			line.Amount = -abs(amount)
+		case credit != "":
+			amount, err := ParseAmount(credit, decimalSeparator, currency)
+			if err != nil {
+				return line, err
+			}
+			line.Amount = abs(amount)
+		}
+	}
+	if line.Amount == 0 {
+		return line, errors.New("amount is zero or missing")
+	}
+	return line, nil
+}
+
+func isBlank(record []string) bool {
+	for _, f := range record {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func quoteAll(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = fmt.Sprintf("%q", n)
+	}
+	return quoted
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,121 @@
+package statement
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+func bankProfile() CSVProfile {
+	return CSVProfile{
+		Encoding:          EncodingWindows1252,
+		Delimiter:         ';',
+		SkipRows:          1,
+		DateColumn:        "Data",
+		DateFormat:        "DD/MM/YYYY",
+		DescriptionColumn: "Histórico",
+		DebitColumn:       "Débito",
+		CreditColumn:      "Crédito",
+		DecimalSeparator:  ",",
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	t.Run("deve ler Windows-1252 com colunas de débito e crédito", func(t *testing.T) {
+		content := "Conta corrente 12345-6\n" +
+			"Data;Histórico;Débito;Crédito\n" +
+			"02/01/2024;Padaria São João;1.234,56;\n" +
+			"03/01/2024;Salário;;5.000,00\n" +
+			"\n" +
+			"31/02/2024;Data inválida;10,00;\n" +
+			"04/01/2024;Sem valor;;\n"
+		encoded, err := charmap.Windows1252.NewEncoder().String(content)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		lines, rowErrors, err := ParseCSV(strings.NewReader(encoded), bankProfile(), "BRL", 100)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if len(lines) != 2 || lines[0].Amount != -123456 || lines[1].Amount != 500000 {
+			t.Fatalf("linhas incorretas: %+v", lines)
+		}
+		if lines[0].Description != "Padaria São João" || lines[0].Row != 3 || lines[0].Date.Format("2006-01-02") != "2024-01-02" {
+			t.Errorf("linha incorreta: %+v", lines[0])
+		}
+		if len(rowErrors) != 2 || rowErrors[0].Row != 6 || rowErrors[1].Row != 7 {
+			t.Errorf("erros de linha incorretos: %+v", rowErrors)
+		}
+	})
+
+	t.Run("deve ler a coluna de valor com sinal em UTF-8 com BOM", func(t *testing.T) {
+		content := "\xEF\xBB\xBFdate,title,amount\n2024-01-05,Mercado,-12.50\n2024-01-06,Estorno,\"1,000.00\"\n"
+		p := CSVProfile{
+			Delimiter: ',', DateColumn: "date", DateFormat: "YYYY-MM-DD",
+			DescriptionColumn: "title", AmountColumn: "amount", DecimalSeparator: ".",
+		}
+
+		lines, rowErrors, err := ParseCSV(strings.NewReader(content), p, "BRL", 100)
+		if err != nil || len(rowErrors) != 0 {
+			t.Fatalf("esperava nenhum erro, mas obteve %v %+v", err, rowErrors)
+		}
+		if len(lines) != 2 || lines[0].Amount != -1250 || lines[1].Amount != 100000 {
+			t.Errorf("linhas incorretas: %+v", lines)
+		}
+	})
+
+	t.Run("deve recusar cabeçalho sem as colunas do perfil e arquivos grandes demais", func(t *testing.T) {
+		var buf bytes.Buffer
+		buf.WriteString("x\nData;Descrição;Valor\n")
+		if _, _, err := ParseCSV(&buf, bankProfile(), "BRL", 100); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidFile, err)
+		}
+
+		content := "x\nData;Histórico;Débito;Crédito\n01/01/2024;A;1,00;\n02/01/2024;B;2,00;\n"
+		if _, _, err := ParseCSV(strings.NewReader(content), bankProfile(), "BRL", 1); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidFile, err)
+		}
+	})
+
+	t.Run("deve recusar perfis inconsistentes", func(t *testing.T) {
+		p := bankProfile()
+		p.AmountColumn = "Valor"
+		p.DateFormat = "dd/mm/yyyy"
+		p.Encoding = "ebcdic"
+		if err := p.Validate(); !errors.Is(err, ErrInvalidProfile) || !errors.Is(err, ErrUnknownEncoding) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidProfile, err)
+		}
+	})
+}
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		value     string
+		separator string
+		want      int64
+	}{
+		{"1.234,56", ",", 123456},
+		{"-1.234,56", ",", -123456},
+		{"1.234,56-", ",", -123456},
+		{"(10,00)", ",", -1000},
+		{"R$ -7,5", ",", -750},
+		{"-R$ 7,50", ",", -750},
+		{"1,234.56", ".", 123456},
+	}
+	for _, c := range cases {
+		got, err := ParseAmount(c.value, c.separator, "BRL")
+		if err != nil || got != c.want {
+			t.Errorf("%q: esperava %d, obteve %d (%v)", c.value, c.want, got, err)
+		}
+	}
+
+	for _, value := range []string{"", "abc", "1,234", "--5"} {
+		if _, err := ParseAmount(value, ",", "BRL"); err == nil {
+			t.Errorf("%q: esperava erro", value)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package statement
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Statement é um extrato que traz a própria moeda e identifica cada
+// lançamento, como o OFX
+type Statement struct {
+	Currency string // ISO 4217, de CURDEF
+	Lines    []Line
+	Errors   []RowError
+}
+
+var (
+	ofxCharset  = regexp.MustCompile(`(?i)CHARSET:\s*([\w-]+)`)
+	xmlEncoding = regexp.MustCompile(`(?i)encoding\s*=\s*["']([\w-]+)["']`)
+	ofxEntities = strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ")
+)
+
+// ParseOFX lê extratos OFX 1.x (SGML, em que as folhas não têm tag de
+// fechamento) e 2.x (XML). Row é a posição do lançamento no arquivo, a partir
+// de 1, e ExternalID é o FITID. Vários extratos no mesmo arquivo precisam
+// estar na mesma moeda.
+func ParseOFX(r io.Reader, maxRows int) (*Statement, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	start := bytes.Index(bytes.ToUpper(raw), []byte("<OFX>"))
+	if start < 0 {
+		return nil, fmt.Errorf("%w: no <OFX> element", ErrInvalidFile)
+	}
+
+	decoded, err := decode(bytes.NewReader(raw[start:]), ofxEncoding(raw[:start]))
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+
+	st := &Statement{}
+	var txn map[string]string
+	var inCurrency bool // dentro de <CURRENCY>, que diz a moeda do próprio lançamento
+	row := 0
+
+	finish := func() {
+		if txn == nil {
+			return
+		}
+		row++
+		line, err := ofxLine(txn, st.Currency)
+		if err != nil {
+			st.Errors = append(st.Errors, RowError{Row: row, Message: err.Error()})
+		} else {
+			line.Row = row
+			st.Lines = append(st.Lines, line)
+		}
+		txn = nil
+	}
+
+	for _, tok := range ofxTokens(string(body)) {
+		switch {
+		case tok.close && tok.name == "STMTTRN", tok.close && tok.name == "BANKTRANLIST":
+			finish()
+		case tok.close && tok.name == "CURRENCY":
+			inCurrency = false
+		case tok.close:
+		case tok.name == "STMTTRN":
+			finish()
+			if row >= maxRows {
+				return nil, fmt.Errorf("%w: more than %d transactions", ErrInvalidFile, maxRows)
+			}
+			txn = map[string]string{}
+		case tok.name == "CURRENCY":
+			inCurrency = true
+		case tok.name == "CURDEF":
+			currency := strings.ToUpper(tok.text)
+			if st.Currency != "" && st.Currency != currency {
+				return nil, fmt.Errorf("%w: statements in %s and %s", ErrInvalidFile, st.Currency, currency)
+			}
+			st.Currency = currency
+		case txn != nil && tok.name == "CURSYM" && inCurrency:
+			txn["CURRENCY"] = strings.ToUpper(tok.text)
+		case txn != nil && tok.text != "":
+			txn[tok.name] = tok.text
+		}
+	}
+	finish()
+
+	if st.Currency == "" {
+		return nil, fmt.Errorf("%w: missing CURDEF", ErrInvalidFile)
+	}
+	return st, nil
+}
+
+// ofxEncoding acha o charset no cabeçalho SGML (CHARSET:1252) ou na
+// declaração XML; sem nenhum, vale UTF-8
+func ofxEncoding(header []byte) string {
+	if m := xmlEncoding.FindSubmatch(header); m != nil {
+		return strings.ToLower(string(m[1]))
+	}
+	if m := ofxCharset.FindSubmatch(header); m != nil {
+		switch strings.ToUpper(string(m[1])) {
+		case "1252", "WINDOWS-1252":
+			return EncodingWindows1252
+		case "ISO-8859-1", "8859-1":
+			return EncodingISO88591
+		}
+	}
+	return EncodingUTF8
+}
+
+type ofxToken struct {
+	name  string
+	close bool
+	text  string // texto logo depois da tag de abertura, já sem espaços nas pontas
+}
+
+// ofxTokens quebra o corpo em tags. Serve aos dois dialetos: no SGML o valor
+// de uma folha vai até a próxima tag; no XML a tag de fechamento é ignorada.
+func ofxTokens(body string) []ofxToken {
+	var tokens []ofxToken
+	for {
+		open := strings.IndexByte(body, '<')
+		if open < 0 {
+			return tokens
+		}
+		end := strings.IndexByte(body[open:], '>')
+		if end < 0 {
+			return tokens
+		}
+		tag := body[open+1 : open+end]
+		body = body[open+end+1:]
+
+		if strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!") {
+			continue
+		}
+		tok := ofxToken{name: strings.ToUpper(strings.TrimSpace(tag))}
+		if strings.HasPrefix(tok.name, "/") {
+			tok.close, tok.name = true, strings.TrimPrefix(tok.name, "/")
+		} else {
+			next := strings.IndexByte(body, '<')
+			if next < 0 {
+				next = len(body)
+			}
+			tok.text = strings.TrimSpace(ofxEntities.Replace(body[:next]))
+		}
+		tokens = append(tokens, tok)
+	}
+}
+
+func ofxLine(txn map[string]string, currency string) (Line, error) {
+	var line Line
+	line.ExternalID = txn["FITID"]
+	if line.ExternalID == "" {
+		return line, errors.New("transaction has no FITID")
+	}
+	if c := txn["CURRENCY"]; c != "" && c != currency {
+		return line, fmt.Errorf("transaction is in %s, statement is in %s", c, currency)
+	}
+
+	date, err := parseOFXDate(txn["DTPOSTED"])
+	if err != nil {
+		return line, err
+	}
+	line.Date = date
+
+	// Alguns bancos brasileiros usam vírgula decimal no TRNAMT
+	amount := txn["TRNAMT"]
+	separator := "."
+	if strings.Contains(amount, ",") && !strings.Contains(amount, ".") {
+		separator = ","
+	}
+	if line.Amount, err = ParseAmount(amount, separator, currency); err != nil {
+		return line, err
+	}
+	if line.Amount == 0 {
+		return line, errors.New("amount is zero or missing")
+	}
+
+	line.Description = txn["NAME"]
+	if memo := txn["MEMO"]; memo != "" {
+		if line.Description == "" {
+			line.Description = memo
+		} else if !strings.Contains(line.Description, memo) {
+			line.Description += " - " + memo
+		}
+	}
+	return line, nil
+}
+
+// parseOFXDate lê o dia de AAAAMMDD[HHMMSS[.XXX]][[-3:BRT]]. O dia vale como
+// está no arquivo, que já é o dia local do banco.
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	date, err := time.Parse("20060102", value[:8])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
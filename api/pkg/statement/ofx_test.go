@@ -0,0 +1,109 @@
+package statement
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+const sgmlStatement = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+SECURITY:NONE
+ENCODING:USASCII
+CHARSET:1252
+COMPRESSION:NONE
+OLDFILEUID:NONE
+NEWFILEUID:NONE
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>BRL
+<BANKACCTFROM><BANKID>0341<ACCTID>12345-6<ACCTTYPE>CHECKING</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20240101<DTEND>20240131
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240105120000[-3:BRT]
+<TRNAMT>-150,00
+<FITID>2024010501
+<NAME>Padaria São João
+<MEMO>Cartão final 1234
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20240102
+<TRNAMT>3000.00
+<FITID>2024010201
+<MEMO>Salário &amp; bônus
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20240103
+<TRNAMT>-10.00
+<NAME>Sem FITID
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const xmlStatement = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="211" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX><CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS><CURDEF>BRL</CURDEF>
+<BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20240110</DTPOSTED><TRNAMT>-42.90</TRNAMT><FITID>abc-1</FITID><NAME>Livraria</NAME></STMTTRN>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20240111</DTPOSTED><TRNAMT>-20.00</TRNAMT><FITID>abc-2</FITID><NAME>Loja online</NAME>
+<CURRENCY><CURRATE>5.0</CURRATE><CURSYM>USD</CURSYM></CURRENCY></STMTTRN>
+</BANKTRANLIST></CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1></OFX>`
+
+func TestParseOFX(t *testing.T) {
+	t.Run("deve ler SGML em Windows-1252 com vírgula decimal", func(t *testing.T) {
+		encoded, err := charmap.Windows1252.NewEncoder().String(sgmlStatement)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		st, err := ParseOFX(strings.NewReader(encoded), 100)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if st.Currency != "BRL" || len(st.Lines) != 2 || len(st.Errors) != 1 || st.Errors[0].Row != 3 {
+			t.Fatalf("extrato incorreto: %+v", st)
+		}
+		first := st.Lines[0]
+		if first.Amount != -15000 || first.ExternalID != "2024010501" || first.Date.Format("2006-01-02") != "2024-01-05" ||
+			first.Description != "Padaria São João - Cartão final 1234" {
+			t.Errorf("lançamento incorreto: %+v", first)
+		}
+		if st.Lines[1].Description != "Salário & bônus" || st.Lines[1].Amount != 300000 {
+			t.Errorf("lançamento incorreto: %+v", st.Lines[1])
+		}
+	})
+
+	t.Run("deve ler XML e recusar lançamentos em outra moeda", func(t *testing.T) {
+		st, err := ParseOFX(strings.NewReader(xmlStatement), 100)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if len(st.Lines) != 1 || st.Lines[0].ExternalID != "abc-1" || st.Lines[0].Amount != -4290 {
+			t.Errorf("lançamentos incorretos: %+v", st.Lines)
+		}
+		if len(st.Errors) != 1 || st.Errors[0].Row != 2 {
+			t.Errorf("erros incorretos: %+v", st.Errors)
+		}
+	})
+
+	t.Run("deve recusar arquivos sem OFX, sem moeda ou grandes demais", func(t *testing.T) {
+		for _, content := range []string{"Data;Valor\n", "<OFX><STMTTRN><FITID>1</STMTTRN></OFX>"} {
+			if _, err := ParseOFX(strings.NewReader(content), 100); !errors.Is(err, ErrInvalidFile) {
+				t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidFile, err)
+			}
+		}
+		if _, err := ParseOFX(strings.NewReader(xmlStatement), 1); !errors.Is(err, ErrInvalidFile) {
+			t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidFile, err)
+		}
+	})
+}
@@ -0,0 +1,196 @@
+package statement
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// QIFOptions diz o que o QIF não traz: a ordem de dia, mês e ano nas datas
+// e o separador decimal dos valores
+type QIFOptions struct {
+	Encoding         string
+	DateFormat       string // só a ordem importa: "DD/MM/YYYY" lê 5/1'24 e 05/01/2024
+	DecimalSeparator string
+}
+
+// Tipos de conta do QIF que são listas de lançamentos simples; investimentos
+// e listas de categorias ficam de fora
+var qifBankTypes = map[string]bool{"bank": true, "cash": true, "ccard": true, "oth a": true, "oth l": true}
+
+// ParseQIF lê os lançamentos de um QIF de conta corrente, cartão ou dinheiro.
+// Row é a linha em que o registro começa. Linhas de categoria (L), número (N)
+// e divisões (S, E, $) são ignoradas.
+func ParseQIF(r io.Reader, opts QIFOptions, currency string, maxRows int) ([]Line, []RowError, error) {
+	order, err := dateOrder(opts.DateFormat)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.DecimalSeparator != "," && opts.DecimalSeparator != "." {
+		return nil, nil, fmt.Errorf(`%w: decimal separator must be "," or "."`, ErrInvalidProfile)
+	}
+	decoded, err := decode(r, opts.Encoding)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var lines []Line
+	var rowErrors []RowError
+	record := map[byte]string{}
+	start, lineNo := 0, 0
+	typed := false
+	inAccount := false // bloco !Account, que descreve a conta e não é lançamento
+
+	flush := func() error {
+		if len(record) == 0 {
+			return nil
+		}
+		if len(lines)+len(rowErrors) >= maxRows {
+			return fmt.Errorf("%w: more than %d records", ErrInvalidFile, maxRows)
+		}
+		line, err := qifLine(record, order, opts.DecimalSeparator, currency)
+		if err != nil {
+			rowErrors = append(rowErrors, RowError{Row: start, Message: err.Error()})
+		} else {
+			line.Row = start
+			lines = append(lines, line)
+		}
+		record = map[byte]string{}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(decoded)
+	for scanner.Scan() {
+		lineNo++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		if strings.HasPrefix(text, "!") {
+			header := strings.ToLower(strings.TrimSpace(text))
+			if kind, ok := strings.CutPrefix(header, "!type:"); ok {
+				if !qifBankTypes[strings.TrimSpace(kind)] {
+					return nil, nil, fmt.Errorf("%w: QIF type %q is not supported", ErrInvalidFile, strings.TrimSpace(kind))
+				}
+				typed, inAccount = true, false
+			} else if header == "!account" {
+				inAccount = true
+			}
+			continue
+		}
+		if inAccount {
+			if text[0] == '^' {
+				inAccount = false
+			}
+			continue
+		}
+		if !typed {
+			return nil, nil, fmt.Errorf("%w: missing !Type header", ErrInvalidFile)
+		}
+
+		code, value := text[0], strings.TrimSpace(text[1:])
+		if code == '^' {
+			if err := flush(); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+		if len(record) == 0 {
+			start = lineNo
+		}
+		// Divisões repetem S/E/$; só o primeiro valor de cada código importa
+		if _, seen := record[code]; !seen {
+			record[code] = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidFile, err)
+	}
+	// O último registro às vezes vem sem o ^ final
+	if err := flush(); err != nil {
+		return nil, nil, err
+	}
+	return lines, rowErrors, nil
+}
+
+func qifLine(record map[byte]string, order [3]byte, decimalSeparator string, currency string) (Line, error) {
+	var line Line
+	date, err := parseQIFDate(record['D'], order)
+	if err != nil {
+		return line, err
+	}
+	line.Date = date
+
+	amount := record['T']
+	if amount == "" {
+		amount = record['U']
+	}
+	if line.Amount, err = ParseAmount(amount, decimalSeparator, currency); err != nil {
+		return line, err
+	}
+	if line.Amount == 0 {
+		return line, errors.New("amount is zero or missing")
+	}
+
+	line.Description = record['P']
+	if line.Description == "" {
+		line.Description = record['M']
+	}
+	return line, nil
+}
+
+// dateOrder devolve a ordem de dia (D), mês (M) e ano (Y) no formato
+func dateOrder(format string) ([3]byte, error) {
+	var order [3]byte
+	if _, err := DateLayout(format); err != nil {
+		return order, err
+	}
+	positions := map[byte]int{'D': strings.Index(format, "DD"), 'M': strings.Index(format, "MM"), 'Y': strings.Index(format, "YY")}
+	keys := []byte{'D', 'M', 'Y'}
+	sort.Slice(keys, func(i, j int) bool { return positions[keys[i]] < positions[keys[j]] })
+	copy(order[:], keys)
+	return order, nil
+}
+
+// parseQIFDate aceita os jeitos que o Quicken escreve datas: sem zeros à
+// esquerda, com apóstrofo antes do ano (1/5'24) e ano com dois dígitos
+func parseQIFDate(value string, order [3]byte) (time.Time, error) {
+	parts := strings.FieldsFunc(value, func(r rune) bool { return r < '0' || r > '9' })
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+
+	var day, month, year int
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", value)
+		}
+		switch order[i] {
+		case 'D':
+			day = n
+		case 'M':
+			month = n
+		case 'Y':
+			year = n
+			if len(part) <= 2 {
+				year += 2000
+				if n >= 70 {
+					year -= 100
+				}
+			}
+		}
+	}
+
+	date := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if date.Day() != day || int(date.Month()) != month {
+		return time.Time{}, fmt.Errorf("invalid date %q", value)
+	}
+	return date, nil
+}
@@ -0,0 +1,45 @@
+package statement
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseQIF(t *testing.T) {
+	opts := QIFOptions{DateFormat: "DD/MM/YYYY", DecimalSeparator: ","}
+
+	t.Run("deve ler registros com datas do Quicken", func(t *testing.T) {
+		content := "!Account\nNCorrente\nTBank\n^\n" +
+			"!Type:Bank\n" +
+			"D5/1'24\nT-1.234,56\nPMercado\nLAlimentação\n^\n" +
+			"D06/01/2024\nU2.000,00\nMSalário\n^\n" +
+			"D31/02/2024\nT-1,00\nPInválido\n^\n" +
+			"D07/01/24\nT-3,50\nPPadaria"
+
+		lines, rowErrors, err := ParseQIF(strings.NewReader(content), opts, "BRL", 100)
+		if err != nil {
+			t.Fatalf("esperava nenhum erro, mas obteve %v", err)
+		}
+		if len(lines) != 3 || len(rowErrors) != 1 || rowErrors[0].Row != 15 {
+			t.Fatalf("esperava 3 lançamentos e 1 erro, obteve %+v %+v", lines, rowErrors)
+		}
+		if lines[0].Date.Format("2006-01-02") != "2024-01-05" || lines[0].Amount != -123456 || lines[0].Row != 6 {
+			t.Errorf("lançamento incorreto: %+v", lines[0])
+		}
+		if lines[1].Description != "Salário" || lines[1].Amount != 200000 {
+			t.Errorf("lançamento incorreto: %+v", lines[1])
+		}
+		if lines[2].Date.Format("2006-01-02") != "2024-01-07" {
+			t.Errorf("esperava o último registro mesmo sem ^, obteve %+v", lines[2])
+		}
+	})
+
+	t.Run("deve recusar investimentos e arquivos sem tipo", func(t *testing.T) {
+		for _, content := range []string{"!Type:Invst\nD1/1/24\n^\n", "D1/1/24\nT1,00\n^\n"} {
+			if _, _, err := ParseQIF(strings.NewReader(content), opts, "BRL", 100); !errors.Is(err, ErrInvalidFile) {
+				t.Errorf("esperava o erro %v, mas obteve %v", ErrInvalidFile, err)
+			}
+		}
+	})
+}
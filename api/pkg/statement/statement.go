@@ -0,0 +1,125 @@
+// Package statement lê extratos bancários e devolve os lançamentos como linhas
+// prontas para gravar, junto com os erros de cada linha que não pôde ser lida.
+package statement
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/martinsdevv/fincore/pkg/money"
+	"golang.org/x/text/encoding/charmap"
+)
+
+var (
+	// ErrInvalidFile indica um arquivo que não dá para ler como um todo, como
+	// um cabeçalho sem as colunas do perfil; linhas ruins viram RowError
+	ErrInvalidFile     = errors.New("invalid statement file")
+	ErrInvalidProfile  = errors.New("invalid import profile")
+	ErrUnknownEncoding = errors.New("unknown encoding")
+)
+
+// Line é um lançamento do extrato. Row é a linha (ou registro) no arquivo, a
+// partir de 1, para o usuário achar o que foi importado.
+type Line struct {
+	Row         int
+	Date        time.Time // dia em UTC
+	Amount      int64     // Em unidades mínimas; negativo para saídas
+	Description string
+	ExternalID  string // identificador do banco (FITID no OFX), se o formato tiver
+}
+
+// RowError é uma linha ignorada e o motivo
+type RowError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+func (e RowError) Error() string {
+	return fmt.Sprintf("row %d: %s", e.Row, e.Message)
+}
+
+// Encodings aceitos nos perfis
+const (
+	EncodingUTF8        = "utf-8"
+	EncodingWindows1252 = "windows-1252"
+	EncodingISO88591    = "iso-8859-1"
+)
+
+// decode converte r para UTF-8. Exportações de bancos brasileiros costumam vir
+// em Windows-1252; em UTF-8, o BOM do Excel é descartado.
+func decode(r io.Reader, encoding string) (io.Reader, error) {
+	switch strings.ToLower(encoding) {
+	case "", EncodingUTF8:
+		return skipBOM(r), nil
+	case EncodingWindows1252:
+		return charmap.Windows1252.NewDecoder().Reader(r), nil
+	case EncodingISO88591:
+		return charmap.ISO8859_1.NewDecoder().Reader(r), nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownEncoding, encoding)
+}
+
+func skipBOM(r io.Reader) io.Reader {
+	bom := []byte{0xEF, 0xBB, 0xBF}
+	head := make([]byte, len(bom))
+	n, err := io.ReadFull(r, head)
+	if err == nil && string(head) == string(bom) {
+		return r
+	}
+	return io.MultiReader(strings.NewReader(string(head[:n])), r)
+}
+
+// DateLayout converte um formato como "DD/MM/YYYY" para o layout do pacote
+// time. Aceita DD, MM, YYYY e YY; o resto é copiado como está.
+func DateLayout(format string) (string, error) {
+	if !strings.Contains(format, "DD") || !strings.Contains(format, "MM") || !strings.Contains(format, "YY") {
+		return "", fmt.Errorf("%w: date format %q needs DD, MM and YYYY or YY", ErrInvalidProfile, format)
+	}
+	layout := strings.NewReplacer("YYYY", "2006", "YY", "06", "MM", "01", "DD", "02").Replace(format)
+	return layout, nil
+}
+
+// ParseAmount lê um valor como os bancos escrevem: "1.234,56" ou "1,234.56"
+// conforme decimalSeparator, com "R$", sinal à esquerda ou à direita, ou
+// entre parênteses para negativos.
+func ParseAmount(value string, decimalSeparator string, currency string) (int64, error) {
+	v := strings.TrimSpace(value)
+	v = strings.TrimPrefix(v, "R$")
+	v = strings.ReplaceAll(v, " ", "")
+	v = strings.ReplaceAll(v, "\u00a0", "") // espaço não separável, comum em planilhas
+
+	negative := false
+	switch {
+	case strings.HasPrefix(v, "(") && strings.HasSuffix(v, ")"):
+		negative, v = true, v[1:len(v)-1]
+	case strings.HasSuffix(v, "-"):
+		negative, v = true, strings.TrimSuffix(v, "-")
+	case strings.HasPrefix(v, "-"):
+		negative, v = true, strings.TrimPrefix(v, "-")
+	case strings.HasPrefix(v, "+"):
+		v = strings.TrimPrefix(v, "+")
+	}
+	v = strings.TrimPrefix(v, "R$")
+
+	thousands := "."
+	if decimalSeparator == "." {
+		thousands = ","
+	}
+	v = strings.ReplaceAll(v, thousands, "")
+	v = strings.Replace(v, decimalSeparator, ".", 1)
+	if v == "" || strings.ContainsAny(v, "+-") {
+		return 0, fmt.Errorf("%w: %q", money.ErrInvalidAmount, value)
+	}
+
+	m, err := money.Parse(v, currency)
+	if err != nil {
+		return 0, err
+	}
+	if negative {
+		return -m.Amount, nil
+	}
+	return m.Amount, nil
+}
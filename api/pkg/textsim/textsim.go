@@ -0,0 +1,74 @@
+// Package textsim compara descrições de lançamentos escritas de jeitos
+// diferentes, como "COMPRA CARTAO PADARIA 12/03" no extrato e "Padaria" à mão
+package textsim
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize passa para minúsculas, tira acentos e pontuação e descarta números
+// soltos (datas, finais de cartão, parcelas), que mudam entre o extrato e o
+// lançamento manual. "Pão de Açúcar - 12/03" vira "pao de acucar".
+func Normalize(s string) string {
+	stripped, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), s)
+	if err != nil {
+		stripped = s
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(stripped), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	kept := words[:0]
+	for _, w := range words {
+		if strings.IndexFunc(w, unicode.IsLetter) >= 0 {
+			kept = append(kept, w)
+		}
+	}
+	return strings.Join(kept, " ")
+}
+
+// Similarity é o coeficiente de Dice dos pares de letras de cada palavra das
+// descrições normalizadas: 1 para textos iguais, 0 sem nada em comum. Duas
+// descrições vazias contam como iguais.
+func Similarity(a string, b string) float64 {
+	pa, pb := bigrams(Normalize(a)), bigrams(Normalize(b))
+	total := len(pa) + len(pb)
+	if total == 0 {
+		return 1
+	}
+
+	counts := make(map[string]int, len(pa))
+	for _, p := range pa {
+		counts[p]++
+	}
+	shared := 0
+	for _, p := range pb {
+		if counts[p] > 0 {
+			counts[p]--
+			shared++
+		}
+	}
+	return float64(2*shared) / float64(total)
+}
+
+// bigrams devolve os pares de letras de cada palavra; uma palavra de uma
+// letra só conta como ela mesma
+func bigrams(s string) []string {
+	var pairs []string
+	for _, word := range strings.Fields(s) {
+		r := []rune(word)
+		if len(r) == 1 {
+			pairs = append(pairs, word)
+			continue
+		}
+		for i := 0; i < len(r)-1; i++ {
+			pairs = append(pairs, string(r[i:i+2]))
+		}
+	}
+	return pairs
+}
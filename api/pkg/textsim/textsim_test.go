@@ -0,0 +1,43 @@
+package textsim
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]string{
+		"Pão de Açúcar - 12/03":         "pao de acucar",
+		"COMPRA CARTAO ***1234 PADARIA": "compra cartao padaria",
+		"Uber*Trip  SAO PAULO":          "uber trip sao paulo",
+		"Parcela 3x":                    "parcela 3x",
+		"12/03/2024":                    "",
+	}
+	for input, want := range cases {
+		if got := Normalize(input); got != want {
+			t.Errorf("Normalize(%q) = %q; esperava %q", input, got, want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want float64
+	}{
+		{"Supermercado Extra", "COMPRA CARTAO SUPERMERCADO EXTRA 0312", 0.75},
+		{"Padaria São João", "padaria sao joao", 1},
+		{"", "", 1},
+		{"Aluguel", "", 0},
+		{"Aluguel", "Netflix", 0},
+	}
+	for _, c := range cases {
+		if got := Similarity(c.a, c.b); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("Similarity(%q, %q) = %v; esperava %v", c.a, c.b, got, c.want)
+		}
+	}
+
+	if Similarity("Posto Ipiranga", "Posto Shell") >= Similarity("Posto Ipiranga", "POSTO IPIRANGA CENTRO") {
+		t.Error("esperava o mesmo posto mais parecido que outro posto")
+	}
+}
@@ -63,7 +63,7 @@ func TestMain(m *testing.M) {
 
 	// 3. Montar a Aplicação Real
 	repo := auth.NewRepository(testPool)
-	service := auth.NewService(repo, cfg.JWTSecret)
+	service := auth.NewService(repo, cfg.JWTSecret, nil, nil)
 	handler := auth.NewHandler(service, cfg.JWTSecret)
 
 	// 4. Configurar o Roteador Real